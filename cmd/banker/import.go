@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/csvimport"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/dropfolder"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+// Imports statement files with the import service used by the upload mutations
+type fileImporter struct {
+	repo     db.Repository
+	importer *importer.Service
+	// Email of the user for files outside of a user subfolder
+	user string
+	// Account CSV files are imported into, as CSV files have no account details
+	accountId *uuid.UUID
+	// CSV profile used to read CSV files. Chase's CSV format is used without one
+	profileId *uuid.UUID
+}
+
+// Registers the flags shared by the import and watch commands
+func newImporterFlags(flags *flag.FlagSet) func(repo db.Repository) *fileImporter {
+	user := flags.String("user", "", "email of the user to import files for. Files in a subfolder named after a user's email are imported for that user")
+	account := flags.String("account", "", "account id for CSV files")
+	profile := flags.String("profile", "", "CSV profile id. Chase's CSV format is used without one")
+
+	return func(repo db.Repository) *fileImporter {
+		return &fileImporter{
+			repo:      repo,
+			importer:  importer.New(repo),
+			user:      *user,
+			accountId: parseIdFlag("account", *account),
+			profileId: parseIdFlag("profile", *profile),
+		}
+	}
+}
+
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: banker import [flags] <path>...\n\nImports statement files in place. Folders are imported once like banker watch, moving each file to done/ or failed/")
+		flags.PrintDefaults()
+	}
+	newImporter := newImporterFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	conn, repo := openRepository()
+	defer conn.Close()
+
+	files := newImporter(repo)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := false
+	onReport := func(report dropfolder.Report) {
+		printReport(report)
+		failed = failed || !report.Success
+	}
+
+	for _, path := range flags.Args() {
+		info, err := os.Stat(path)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+
+		if info.IsDir() {
+			if err := dropfolder.New(path, files.importFile).RunOnce(ctx, onReport); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				failed = true
+			}
+
+			continue
+		}
+
+		onReport(files.importPath(ctx, path))
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func runWatch(args []string) {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: banker watch [flags] <dir>\n\nImports OFX, QFX, QBO and CSV files dropped into dir, moving each file to done/ or failed/ with a report")
+		flags.PrintDefaults()
+	}
+	interval := flags.Duration("interval", 30*time.Second, "how often to check for new files")
+	newImporter := newImporterFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	dir := flags.Arg(0)
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fmt.Fprintf(os.Stderr, "Not a directory: %s\n", dir)
+		os.Exit(1)
+	}
+
+	conn, repo := openRepository()
+	defer conn.Close()
+
+	files := newImporter(repo)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Watching %s for statements every %s..\n", dir, *interval)
+	dropfolder.New(dir, files.importFile).Watch(ctx, *interval, printReport)
+}
+
+// Imports a file outside of a drop folder, leaving it where it is
+func (i *fileImporter) importPath(ctx context.Context, path string) dropfolder.Report {
+	report := dropfolder.Report{
+		File:    path,
+		User:    i.user,
+		Started: time.Now(),
+	}
+
+	result, err := i.importFile(ctx, "", path)
+	report.Finished = time.Now()
+	report.Result = result
+	report.Success = err == nil
+
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	return report
+}
+
+func (i *fileImporter) importFile(ctx context.Context, user string, path string) (dropfolder.Result, error) {
+	email := user
+
+	if email == "" {
+		email = i.user
+	}
+
+	if email == "" {
+		return dropfolder.Result{}, fmt.Errorf("No user for file. Use -user or a subfolder named after the user's email")
+	}
+
+	owner, err := i.repo.GetUserByEmail(ctx, email)
+
+	if err != nil {
+		return dropfolder.Result{}, fmt.Errorf("User not found: %s", email)
+	}
+
+	name := filepath.Base(path)
+	uploadSource, parser, err := i.parser(ctx, owner.ID, name)
+
+	if err != nil {
+		return dropfolder.Result{}, err
+	}
+
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return dropfolder.Result{}, err
+	}
+
+	result, err := i.importer.ImportFile(ctx, owner.ID, importer.File{
+		Name:         name,
+		Content:      content,
+		UploadSource: uploadSource,
+		Parser:       parser,
+	})
+
+	return newResult(result), err
+}
+
+// Picks the parser for a file from its extension, as the upload mutations do
+func (i *fileImporter) parser(ctx context.Context, ownerId uuid.UUID, fileName string) (db.UploadSource, importer.Parser, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ofx", ".qfx", ".qbo":
+		return db.UploadSourceCHASEOFXUPLOAD, importer.OFXParser, nil
+	case ".csv":
+		if i.accountId == nil {
+			return "", nil, fmt.Errorf("CSV files require an account")
+		}
+
+		account, err := i.repo.GetAccount(ctx, db.GetAccountParams{
+			ID:      *i.accountId,
+			Ownerid: ownerId,
+		})
+
+		if err != nil {
+			return "", nil, fmt.Errorf("Account not found")
+		}
+
+		if i.profileId == nil {
+			return db.UploadSourceCHASECSVUPLOAD, importer.CSVParser(account, csvimport.ChaseProfile), nil
+		}
+
+		profile, err := i.repo.GetCsvProfile(ctx, db.GetCsvProfileParams{
+			ID:      *i.profileId,
+			Ownerid: ownerId,
+		})
+
+		if err != nil {
+			return "", nil, fmt.Errorf("CSV profile not found")
+		}
+
+		return db.UploadSourceCSVUPLOAD, importer.CSVParser(account, profile), nil
+	default:
+		return "", nil, fmt.Errorf("Unsupported file type: %s", fileName)
+	}
+}
+
+func newResult(result *importer.Result) dropfolder.Result {
+	if result == nil {
+		return dropfolder.Result{}
+	}
+
+	return dropfolder.Result{
+		Duplicate:           result.Duplicate,
+		AccountsUpdated:     result.Accounts.Updated,
+		AccountsFailed:      result.Accounts.Failed,
+		TransactionsUpdated: result.Transactions.Updated,
+		TransactionsFailed:  result.Transactions.Failed,
+		Errors:              result.Errors,
+	}
+}
+
+func printReport(report dropfolder.Report) {
+	name := report.File
+
+	if report.User != "" {
+		name = fmt.Sprintf("%s (%s)", report.File, report.User)
+	}
+
+	switch {
+	case !report.Success:
+		fmt.Printf("failed    %s: %s\n", name, report.Error)
+	case report.Duplicate:
+		fmt.Printf("skipped   %s: Already imported\n", name)
+	default:
+		fmt.Printf("imported  %s: %d accounts, %d transactions, %d failed\n", name, report.AccountsUpdated, report.TransactionsUpdated, report.AccountsFailed+report.TransactionsFailed)
+	}
+}
+
+func parseIdFlag(name string, value string) *uuid.UUID {
+	if value == "" {
+		return nil
+	}
+
+	id, err := uuid.Parse(value)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -%s id: %s\n", name, value)
+		os.Exit(2)
+	}
+
+	return &id
+}
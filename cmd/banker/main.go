@@ -1,34 +1,126 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	cors "github.com/proctorinc/banker/internal"
 	"github.com/proctorinc/banker/internal/auth"
 	"github.com/proctorinc/banker/internal/dataloaders"
 	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/directconnect"
 	"github.com/proctorinc/banker/internal/graphql"
+	"github.com/proctorinc/banker/internal/graphql/resolvers"
+	"github.com/proctorinc/banker/internal/importer"
+	"github.com/proctorinc/banker/internal/jobs"
+	"github.com/proctorinc/banker/internal/secrets"
+	"github.com/proctorinc/banker/internal/syncprovider"
+	"github.com/proctorinc/banker/internal/syncprovider/plaid"
 )
 
+const usage = `Usage:
+  banker [serve]                      Run the GraphQL server
+  banker import [flags] <path>...     Import statement files or drop folders once
+  banker watch [flags] <dir>          Import files dropped into a folder as they arrive
+
+Run "banker <command> -h" for the command's flags`
+
 func main() {
+	command := "serve"
+	args := []string{}
+
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+		args = os.Args[2:]
+	}
+
+	switch command {
+	case "serve":
+		serve()
+	case "import":
+		runImport(args)
+	case "watch":
+		runWatch(args)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func openRepository() (*sql.DB, db.Repository) {
 	fmt.Println("Connecting to database..")
 	conn, err := db.Open("dbname=chase-data sslmode=disable")
 	if err != nil {
 		panic(err)
 	}
+
+	return conn, db.NewRepository(conn)
+}
+
+func serve() {
+	conn, repo := openRepository()
 	defer conn.Close()
 
-	repo := db.NewRepository(conn)
+	// Background workers for queued uploads
+	pool := jobs.NewPool(4, 100)
+	pool.Start(context.Background())
+
+	// Sync providers are enabled when their credentials are set. Set
+	// PLAID_BASE_URL to use a local mock server, see cmd/plaidmock
+	providers := syncprovider.Providers{}
+
+	if clientId := os.Getenv("PLAID_CLIENT_ID"); clientId != "" {
+		providers.Register(plaid.New(plaid.Config{
+			ClientId: clientId,
+			Secret:   os.Getenv("PLAID_SECRET"),
+			BaseURL:  os.Getenv("PLAID_BASE_URL"),
+		}))
+	}
+
+	resolver := &resolvers.Resolver{
+		Repository:  repo,
+		AuthService: *auth.NewAuthService(repo),
+		DataLoaders: dataloaders.NewRetriever(),
+		Importer:    importer.New(repo),
+		Jobs:        pool,
+		Providers:   providers,
+	}
+
+	// OFX Direct Connect credentials are encrypted with BANKER_ENCRYPTION_KEY,
+	// a base64 encoded 32 byte key. Connections are synced every
+	// OFX_SYNC_INTERVAL, see cmd/ofxmock for a local OFX server
+	if key := os.Getenv("BANKER_ENCRYPTION_KEY"); key != "" {
+		cipher, err := secrets.NewCipher(key)
+		if err != nil {
+			panic(err)
+		}
+
+		interval := 24 * time.Hour
+
+		if value := os.Getenv("OFX_SYNC_INTERVAL"); value != "" {
+			interval, err = time.ParseDuration(value)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		resolver.Secrets = cipher
+		resolver.DirectConnect = directconnect.New(nil)
+		directconnect.NewScheduler(repo, interval, resolver.SyncOFXConnection).Start(context.Background())
+	}
 
-	retriever := dataloaders.NewRetriever()
 	dlMiddleware := dataloaders.Middleware(repo)
-	queryHandler := graphql.GraphqlHandler(repo, retriever)
+	queryHandler := graphql.GraphqlHandler(resolver)
 
 	r := gin.Default()
 	r.Use(cors.Cors())
 	r.Use(auth.Middleware(repo))
 	r.POST("/query", dlMiddleware(queryHandler))
+	r.GET("/query", dlMiddleware(queryHandler)) // websocket subscriptions
 	r.GET("/", graphql.NewPlaygroundHandler())
 	r.Run()
 }
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/proctorinc/banker/internal/directconnect/ofxmock"
+)
+
+// Runs a local OFX Direct Connect server. Create a connection with the url
+// http://localhost:8091 and the credentials printed below
+func main() {
+	addr := flag.String("addr", ":8091", "address to listen on")
+	flag.Parse()
+
+	fmt.Printf("Mock OFX server listening on %s\n", *addr)
+	fmt.Printf("username: %s, password: %s, org: %s, fid: %s\n", ofxmock.Username, ofxmock.Password, ofxmock.Org, ofxmock.Fid)
+	fmt.Printf("checking 1111111111 (routing number %s), credit card 4444333322221111\n", ofxmock.BankId)
+
+	if err := http.ListenAndServe(*addr, ofxmock.New()); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+
+	"github.com/proctorinc/banker/internal/syncprovider/plaid/plaidmock"
+)
+
+// Runs a local fake of the Plaid API. Start banker with
+// PLAID_BASE_URL=http://localhost:8090 PLAID_CLIENT_ID=mock-client-id PLAID_SECRET=mock-secret
+// and link with the public token printed below
+func main() {
+	addr := flag.String("addr", ":8090", "address to listen on")
+	flag.Parse()
+
+	fmt.Printf("Mock Plaid server listening on %s\n", *addr)
+	fmt.Printf("client_id: %s, secret: %s, public token: %s\n", plaidmock.ClientId, plaidmock.Secret, plaidmock.PublicToken)
+
+	if err := http.ListenAndServe(*addr, plaidmock.New()); err != nil {
+		panic(err)
+	}
+}
@@ -23,8 +23,13 @@ func GetSession(ctx context.Context) *Session {
 }
 
 func SetSession(ctx *gin.Context, session *Session) {
-	newCtx := context.WithValue(ctx.Request.Context(), sessionContextKey, session)
-	ctx.Request = ctx.Request.WithContext(newCtx)
+	ctx.Request = ctx.Request.WithContext(WithSession(ctx.Request.Context(), session))
+}
+
+// WithSession returns a context with the session, for work outside of a
+// request such as the import commands
+func WithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey, session)
 }
 
 func SetAuthToken(ctx context.Context, userId uuid.UUID) error {
@@ -0,0 +1,194 @@
+// Package categorizer predicts transaction categories with a naive Bayes
+// model trained on the transactions a user categorized themselves. Models
+// are built from the user's own history and run offline
+package categorizer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Transaction is what the model learns from and categorizes
+type Transaction struct {
+	Description string
+	MerchantId  uuid.UUID
+	// Amount in cents. Negative amounts are spending
+	Amount int32
+	Type   db.TransactionType
+}
+
+// Example is a transaction labeled with the category the user chose
+type Example struct {
+	Transaction
+	CategoryId uuid.UUID
+}
+
+// Prediction is the most likely category for a transaction
+type Prediction struct {
+	CategoryId uuid.UUID
+	// Probability of the category from 0 to 1
+	Confidence float64
+}
+
+type categoryCounts struct {
+	examples int
+	features map[string]int
+	// Sum of the feature counts
+	total int
+}
+
+// Model is a multinomial naive Bayes classifier over transaction features
+type Model struct {
+	categories map[uuid.UUID]*categoryCounts
+	vocabulary map[string]bool
+	examples   int
+}
+
+func New() *Model {
+	return &Model{
+		categories: map[uuid.UUID]*categoryCounts{},
+		vocabulary: map[string]bool{},
+	}
+}
+
+// Train builds a model from labeled examples
+func Train(examples []Example) *Model {
+	model := New()
+
+	for _, example := range examples {
+		model.Add(example)
+	}
+
+	return model
+}
+
+// Add trains the model with one more example
+func (m *Model) Add(example Example) {
+	counts, ok := m.categories[example.CategoryId]
+
+	if !ok {
+		counts = &categoryCounts{features: map[string]int{}}
+		m.categories[example.CategoryId] = counts
+	}
+
+	counts.examples++
+	m.examples++
+
+	for _, feature := range Features(example.Transaction) {
+		counts.features[feature]++
+		counts.total++
+		m.vocabulary[feature] = true
+	}
+}
+
+// Examples is the number of examples the model was trained with
+func (m *Model) Examples() int {
+	return m.examples
+}
+
+// Predict returns the most likely category for tx. It returns false when the
+// model can't tell categories apart, because it knows fewer than two
+// categories or none of the transaction's features
+func (m *Model) Predict(tx Transaction) (Prediction, bool) {
+	if len(m.categories) < 2 {
+		return Prediction{}, false
+	}
+
+	features := []string{}
+
+	for _, feature := range Features(tx) {
+		if m.vocabulary[feature] {
+			features = append(features, feature)
+		}
+	}
+
+	if len(features) == 0 {
+		return Prediction{}, false
+	}
+
+	// Log probabilities with add one smoothing, so features a category
+	// hasn't seen lower its score without ruling it out
+	scores := make(map[uuid.UUID]float64, len(m.categories))
+	best := Prediction{}
+	bestScore := math.Inf(-1)
+	vocabulary := float64(len(m.vocabulary))
+
+	for id, counts := range m.categories {
+		score := math.Log(float64(counts.examples+1) / float64(m.examples+len(m.categories)))
+
+		for _, feature := range features {
+			score += math.Log(float64(counts.features[feature]+1) / (float64(counts.total) + vocabulary))
+		}
+
+		scores[id] = score
+
+		if score > bestScore || (score == bestScore && id.String() < best.CategoryId.String()) {
+			bestScore = score
+			best.CategoryId = id
+		}
+	}
+
+	// Normalize the best score into a probability
+	sum := 0.0
+
+	for _, score := range scores {
+		sum += math.Exp(score - bestScore)
+	}
+
+	best.Confidence = 1 / sum
+
+	return best, true
+}
+
+var wordPattern = regexp.MustCompile(`[A-Z][A-Z&']+`)
+
+// Amount buckets in cents, e.g. under $5, under $20
+var amountBuckets = []int32{500, 2000, 5000, 10000, 25000, 100000}
+
+// Features are the description's words, the merchant, the amount's
+// direction and size, and the transaction type
+func Features(tx Transaction) []string {
+	features := []string{}
+	seen := map[string]bool{}
+
+	for _, word := range wordPattern.FindAllString(strings.ToUpper(tx.Description), -1) {
+		if !seen[word] {
+			seen[word] = true
+			features = append(features, "word:"+word)
+		}
+	}
+
+	if tx.MerchantId != uuid.Nil {
+		features = append(features, "merchant:"+tx.MerchantId.String())
+	}
+
+	features = append(features, amountFeature(tx.Amount))
+
+	if tx.Type != "" {
+		features = append(features, "type:"+string(tx.Type))
+	}
+
+	return features
+}
+
+func amountFeature(amount int32) string {
+	direction := "in"
+
+	if amount < 0 {
+		direction = "out"
+		amount = -amount
+	}
+
+	for i, limit := range amountBuckets {
+		if amount < limit {
+			return fmt.Sprintf("amount:%s:%d", direction, i)
+		}
+	}
+
+	return fmt.Sprintf("amount:%s:%d", direction, len(amountBuckets))
+}
@@ -0,0 +1,174 @@
+package categorizer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+var (
+	groceries  = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	dining     = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	income     = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+	restaurant = uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+)
+
+func example(category uuid.UUID, description string, amount int32, merchantId uuid.UUID) Example {
+	return Example{
+		Transaction: Transaction{
+			Description: description,
+			MerchantId:  merchantId,
+			Amount:      amount,
+			Type:        db.TransactionTypeDEBIT,
+		},
+		CategoryId: category,
+	}
+}
+
+func TestPredict(t *testing.T) {
+	model := Train([]Example{
+		example(groceries, "SAFEWAY STORE 1234", -8420, uuid.Nil),
+		example(groceries, "TRADER JOE'S #552", -4210, uuid.Nil),
+		example(groceries, "SAFEWAY FUEL", -3900, uuid.Nil),
+		example(dining, "SQ *NOPA", -6400, restaurant),
+		example(dining, "DOORDASH BURGERS", -2850, uuid.Nil),
+		example(income, "ACME CORP PAYROLL", 250000, uuid.Nil),
+	})
+
+	tests := []struct {
+		name string
+		tx   Transaction
+		want uuid.UUID
+	}{
+		{
+			name: "description words",
+			tx:   Transaction{Description: "SAFEWAY STORE 9876", Amount: -5600, Type: db.TransactionTypeDEBIT},
+			want: groceries,
+		},
+		{
+			name: "merchant",
+			tx:   Transaction{Description: "SQ *TABLE 12", MerchantId: restaurant, Amount: -7000, Type: db.TransactionTypeDEBIT},
+			want: dining,
+		},
+		{
+			name: "large deposit",
+			tx:   Transaction{Description: "ACME CORP PAYROLL", Amount: 250000, Type: db.TransactionTypeCREDIT},
+			want: income,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := model.Predict(tt.tx)
+
+			if !ok {
+				t.Fatalf("Predict() made no prediction")
+			}
+
+			if got.CategoryId != tt.want {
+				t.Fatalf("Predict() = %s, want %s", got.CategoryId, tt.want)
+			}
+
+			if got.Confidence <= 0 || got.Confidence > 1 {
+				t.Fatalf("Predict() confidence = %f, want between 0 and 1", got.Confidence)
+			}
+		})
+	}
+}
+
+func TestPredictWithoutEnoughData(t *testing.T) {
+	tests := []struct {
+		name     string
+		examples []Example
+		tx       Transaction
+	}{
+		{
+			name:     "untrained",
+			examples: nil,
+			tx:       Transaction{Description: "SAFEWAY"},
+		},
+		{
+			name: "one category",
+			examples: []Example{
+				example(groceries, "SAFEWAY", -1000, uuid.Nil),
+				example(groceries, "TRADER JOE'S", -2000, uuid.Nil),
+			},
+			tx: Transaction{Description: "SAFEWAY"},
+		},
+		{
+			name: "no known features",
+			examples: []Example{
+				example(groceries, "SAFEWAY", -1000, uuid.Nil),
+				example(dining, "DOORDASH", -2000, uuid.Nil),
+			},
+			tx: Transaction{Description: "1234"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := Train(tt.examples)
+
+			if got, ok := model.Predict(tt.tx); ok {
+				t.Fatalf("Predict() = %+v, want no prediction", got)
+			}
+		})
+	}
+}
+
+func TestConfidenceGrowsWithExamples(t *testing.T) {
+	model := New()
+	model.Add(example(groceries, "SAFEWAY", -1000, uuid.Nil))
+	model.Add(example(dining, "DOORDASH", -2000, uuid.Nil))
+
+	tx := Transaction{Description: "SAFEWAY", Amount: -1000, Type: db.TransactionTypeDEBIT}
+	before, _ := model.Predict(tx)
+
+	for i := 0; i < 5; i++ {
+		model.Add(example(groceries, "SAFEWAY", -1000, uuid.Nil))
+	}
+
+	after, _ := model.Predict(tx)
+
+	if model.Examples() != 7 {
+		t.Fatalf("Examples() = %d, want 7", model.Examples())
+	}
+
+	if after.Confidence <= before.Confidence {
+		t.Fatalf("confidence went from %f to %f, want it to grow", before.Confidence, after.Confidence)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   Transaction
+		want []string
+	}{
+		{
+			name: "words are deduplicated and digits dropped",
+			tx:   Transaction{Description: "Safeway safeway #1234 fuel", Amount: -4500, Type: db.TransactionTypePOS},
+			want: []string{"word:SAFEWAY", "word:FUEL", "amount:out:2", "type:POS"},
+		},
+		{
+			name: "merchant and income",
+			tx:   Transaction{Description: "ACME", MerchantId: restaurant, Amount: 250000},
+			want: []string{"word:ACME", "merchant:" + restaurant.String(), "amount:in:6"},
+		},
+		{
+			name: "small amount",
+			tx:   Transaction{Amount: -499},
+			want: []string{"amount:out:0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Features(tt.tx); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Features() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package chase
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/sourceid"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// ISO 20022 camt.053 bank to customer statement. Tags are matched without
+// namespaces so every camt.053.001.xx version parses with the same structs
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	Id       string        `xml:"Id"`
+	Account  camtAccount   `xml:"Acct"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	OtherId  string `xml:"Id>Othr>Id"`
+	TypeCode string `xml:"Tp>Cd"`
+	Currency string `xml:"Ccy"`
+	Name     string `xml:"Nm"`
+	BIC      string `xml:"Svcr>FinInstnId>BIC"`
+	BICFI    string `xml:"Svcr>FinInstnId>BICFI"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtBalance struct {
+	Code          string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount        camtAmount `xml:"Amt"`
+	CreditOrDebit string     `xml:"CdtDbtInd"`
+	Date          camtDate   `xml:"Dt"`
+}
+
+type camtStatus struct {
+	Value string `xml:",chardata"` // camt.053.001.02 - 07
+	Code  string `xml:"Cd"`        // camt.053.001.08 onwards
+}
+
+type camtEntry struct {
+	Reference         string          `xml:"NtryRef"`
+	Amount            camtAmount      `xml:"Amt"`
+	CreditOrDebit     string          `xml:"CdtDbtInd"`
+	Reversal          bool            `xml:"RvslInd"`
+	Status            camtStatus      `xml:"Sts"`
+	BookingDate       camtDate        `xml:"BookgDt"`
+	ValueDate         camtDate        `xml:"ValDt"`
+	ServicerReference string          `xml:"AcctSvcrRef"`
+	Domain            string          `xml:"BkTxCd>Domn>Cd"`
+	Family            string          `xml:"BkTxCd>Domn>Fmly>Cd"`
+	SubFamily         string          `xml:"BkTxCd>Domn>Fmly>SubFmlyCd"`
+	Proprietary       string          `xml:"BkTxCd>Prtry>Cd"`
+	Details           []camtTxDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo    string          `xml:"AddtlNtryInf"`
+}
+
+type camtTxDetails struct {
+	DebtorName     string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPartyNm  string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	CreditorName   string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPtyNm  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	Unstructured   []string `xml:"RmtInf>Ustrd"`
+	AdditionalInfo string   `xml:"AddtlTxInf"`
+}
+
+// ParseCamt053 parses every statement in an ISO 20022 camt.053 file into the
+// same structures as OFX statements. Pending entries are skipped
+func ParseCamt053(reader io.Reader) ([]ChaseOFXResult, error) {
+	var document camtDocument
+
+	if err := xml.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, fmt.Errorf("Failed to parse camt.053 file: %w", err)
+	}
+
+	if len(document.Statements) == 0 {
+		return nil, fmt.Errorf("No statements found. camt.053 BkToCstmrStmt required")
+	}
+
+	var results []ChaseOFXResult
+
+	for _, stmt := range document.Statements {
+		result, err := parseCamtStatement(stmt)
+
+		if err != nil {
+			return nil, fmt.Errorf("Statement %s: %w", stmt.Id, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func parseCamtStatement(stmt camtStatement) (ChaseOFXResult, error) {
+	accountId := firstNonEmpty(stmt.Account.IBAN, stmt.Account.OtherId)
+
+	if accountId == "" {
+		return ChaseOFXResult{}, fmt.Errorf("missing account id")
+	}
+
+	accountType := camtAccountType(stmt.Account.TypeCode)
+	name := stmt.Account.Name
+
+	if name == "" {
+		caser := cases.Title(language.AmericanEnglish)
+		name = fmt.Sprintf("%s Account %s", caser.String(strings.ToLower(string(accountType))), lastFour(accountId))
+	}
+
+	account := ChaseOFXAccount{
+		BankId:          firstNonEmpty(stmt.Account.BIC, stmt.Account.BICFI),
+		AccountId:       accountId,
+		IsoCurrencyCode: stmt.Account.Currency,
+		Type:            accountType,
+		Name:            name,
+	}
+
+	for _, balance := range stmt.Balances {
+		amount, err := parseCamtAmount(balance.Amount, balance.CreditOrDebit)
+
+		if err != nil {
+			return ChaseOFXResult{}, err
+		}
+
+		switch balance.Code {
+		// Closing booked balance
+		case "CLBD":
+			date, err := parseCamtDate(balance.Date)
+
+			if err != nil {
+				return ChaseOFXResult{}, err
+			}
+
+			account.CurrentBalance = amount
+			account.BalanceDate = date
+
+			if account.IsoCurrencyCode == "" {
+				account.IsoCurrencyCode = balance.Amount.Currency
+			}
+		// Closing available balance
+		case "CLAV":
+			available := amount
+			account.AvailableBalance = &available
+		}
+	}
+
+	if account.IsoCurrencyCode == "" {
+		account.IsoCurrencyCode = "EUR"
+	}
+
+	var transactions []ChaseOFXTransaction
+
+	for i, entry := range stmt.Entries {
+		status := strings.TrimSpace(firstNonEmpty(entry.Status.Code, entry.Status.Value))
+
+		if status != "" && status != "BOOK" {
+			continue
+		}
+
+		tx, err := parseCamtEntry(entry)
+
+		if err != nil {
+			return ChaseOFXResult{}, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	setHashedSourceIds("CAMT", accountId, transactions)
+
+	return ChaseOFXResult{
+		Account:      account,
+		Transactions: transactions,
+	}, nil
+}
+
+func parseCamtEntry(entry camtEntry) (ChaseOFXTransaction, error) {
+	// The indicator is the direction of the entry itself, also for reversals
+	amount, err := parseCamtAmount(entry.Amount, entry.CreditOrDebit)
+
+	if err != nil {
+		return ChaseOFXTransaction{}, err
+	}
+
+	date, err := parseCamtDate(entry.BookingDate)
+
+	if err != nil {
+		date, err = parseCamtDate(entry.ValueDate)
+	}
+
+	if err != nil {
+		return ChaseOFXTransaction{}, fmt.Errorf("missing booking date")
+	}
+
+	// Related parties are those of the original entry, which a reversal
+	// booked the other way
+	original := entry.CreditOrDebit
+
+	if entry.Reversal {
+		original = map[string]string{"CRDT": "DBIT", "DBIT": "CRDT"}[original]
+	}
+
+	var payee string
+	var remittance []string
+
+	for _, details := range entry.Details {
+		// The counterparty is the creditor when money left the account
+		if original == "DBIT" {
+			payee = firstNonEmpty(payee, details.CreditorName, details.CreditorPtyNm)
+		} else {
+			payee = firstNonEmpty(payee, details.DebtorName, details.DebtorPartyNm)
+		}
+
+		remittance = append(remittance, details.Unstructured...)
+
+		if details.AdditionalInfo != "" {
+			remittance = append(remittance, details.AdditionalInfo)
+		}
+	}
+
+	memo := strings.Join(remittance, " ")
+
+	if memo == "" {
+		memo = entry.AdditionalInfo
+	}
+
+	transactionType := BankTransactionCodeType(entry.Domain, entry.Family, entry.SubFamily)
+
+	// Some banks only send proprietary codes, often the SWIFT MT940 code
+	if transactionType == "" {
+		transactionType = SwiftTransactionCodeType(entry.Proprietary)
+	}
+
+	if transactionType == "" {
+		transactionType = signedTransactionType(amount)
+	}
+
+	return ChaseOFXTransaction{
+		Id:          firstNonEmpty(entry.ServicerReference, entry.Reference),
+		Type:        string(transactionType),
+		DatePosted:  date,
+		Amount:      amount,
+		Payee:       truncate(payee, 255),
+		Description: truncate(collapseSpaces(getDescription(payee, memo)), 255),
+	}, nil
+}
+
+// BankTransactionCodeType maps an ISO 20022 bank transaction code onto a
+// transaction type. The sub family is the most specific so it is checked
+// first. Returns an empty type when the code has no equivalent
+func BankTransactionCodeType(domain string, family string, subFamily string) db.TransactionType {
+	switch subFamily {
+	case "POSD", "POSC", "SMRT":
+		return db.TransactionTypePOS
+	case "CWDL", "ATMD":
+		return db.TransactionTypeATM
+	case "CDPT", "ATMC":
+		return db.TransactionTypeDEP
+	case "INTR":
+		return db.TransactionTypeINT
+	case "DVCA", "DVOP":
+		return db.TransactionTypeDIV
+	case "CHRG", "FEES", "COMM", "ADBT":
+		return db.TransactionTypeFEE
+	case "SALA", "PRCT":
+		return db.TransactionTypeDIRECTDEP
+	case "STDO":
+		return db.TransactionTypeREPEATPMT
+	case "ESDD", "BBDD", "PMDD", "OODD":
+		return db.TransactionTypeDIRECTDEBIT
+	case "CCHQ", "BCHQ", "CCCH", "URCQ", "XBCQ":
+		return db.TransactionTypeCHECK
+	case "BOOK", "ESCT", "DMCT", "XBCT", "SDVA", "AUTT":
+		return db.TransactionTypeXFER
+	}
+
+	switch family {
+	case "RDDT", "IDDT":
+		return db.TransactionTypeDIRECTDEBIT
+	case "RCDT", "ICDT":
+		return db.TransactionTypeXFER
+	case "CCRD", "MCRD":
+		return db.TransactionTypePOS
+	case "CHCK", "ICHQ", "RCHQ":
+		return db.TransactionTypeCHECK
+	case "CNTR":
+		return db.TransactionTypeCASH
+	}
+
+	if domain == "ACMT" {
+		return db.TransactionTypeSRVCHG
+	}
+
+	return ""
+}
+
+// Maps an ISO 20022 cash account type code onto an account type
+func camtAccountType(code string) db.AccountType {
+	switch code {
+	case "SVGS":
+		return db.AccountTypeSAVINGS
+	case "CARD":
+		return db.AccountTypeCREDIT
+	case "LOAN":
+		return db.AccountTypeCREDITLINE
+	case "MOMA":
+		return db.AccountTypeMONEYMRKT
+	}
+
+	return db.AccountTypeCHECKING
+}
+
+func parseCamtAmount(amount camtAmount, creditOrDebit string) (float32, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 32)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount.Value)
+	}
+
+	if creditOrDebit == "DBIT" {
+		value = -value
+	}
+
+	return float32(value), nil
+}
+
+func parseCamtDate(date camtDate) (time.Time, error) {
+	if date.Date != "" {
+		return time.Parse("2006-01-02", strings.TrimSpace(date.Date))
+	}
+
+	if date.DateTime != "" {
+		dateTime, err := time.Parse(time.RFC3339, strings.TrimSpace(date.DateTime))
+
+		// Offsets are optional in ISO 20022 date times
+		if err != nil {
+			dateTime, err = time.Parse("2006-01-02T15:04:05", strings.TrimSpace(date.DateTime))
+		}
+
+		return dateTime, err
+	}
+
+	return time.Time{}, fmt.Errorf("missing date")
+}
+
+// Gives transactions without a bank reference a stable hashed id. References
+// are only unique per account, so they are namespaced by the account id
+func setHashedSourceIds(prefix string, accountId string, transactions []ChaseOFXTransaction) {
+	hasher := sourceid.NewHasher(prefix)
+
+	for i, tx := range transactions {
+		if tx.Id != "" && tx.Id != "NONREF" {
+			transactions[i].Id = fmt.Sprintf("%s:%s:%s", prefix, accountId, tx.Id)
+			continue
+		}
+
+		key := fmt.Sprintf(
+			"%s|%s|%.2f|%s",
+			accountId,
+			tx.DatePosted.Format("2006-01-02"),
+			tx.Amount,
+			strings.ToUpper(tx.Description),
+		)
+		transactions[i].Id = hasher.Id(key)
+	}
+}
+
+func signedTransactionType(amount float32) db.TransactionType {
+	if amount < 0 {
+		return db.TransactionTypeDEBIT
+	}
+
+	return db.TransactionTypeCREDIT
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// Truncates to at most max bytes without splitting a character
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+
+	for max > 0 && !utf8.RuneStart(value[max]) {
+		max--
+	}
+
+	return value[:max]
+}
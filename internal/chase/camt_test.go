@@ -0,0 +1,155 @@
+package chase
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+func TestParseCamt053(t *testing.T) {
+	file, err := os.Open("testdata/statement.camt053.xml")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	results, err := ParseCamt053(file)
+
+	if err != nil {
+		t.Fatalf("ParseCamt053() error = %v", err)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("ParseCamt053() returned %d statements, want 1", len(results))
+	}
+
+	account := results[0].Account
+	available := float32(3400)
+	wantAccount := ChaseOFXAccount{
+		BankId:           "COBADEFFXXX",
+		AccountId:        "DE89370400440532013000",
+		IsoCurrencyCode:  "EUR",
+		Type:             db.AccountTypeCHECKING,
+		CurrentBalance:   3457.5,
+		AvailableBalance: &available,
+		BalanceDate:      time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+		Name:             "Checking Account 3000",
+	}
+
+	if !reflect.DeepEqual(account, wantAccount) {
+		t.Errorf("account = %+v, want %+v", account, wantAccount)
+	}
+
+	tests := []struct {
+		name string
+		want ChaseOFXTransaction
+	}{
+		{
+			name: "card payment",
+			want: ChaseOFXTransaction{
+				Id:          "CAMT:DE89370400440532013000:REF-001",
+				Type:        string(db.TransactionTypePOS),
+				DatePosted:  time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC),
+				Amount:      -42.5,
+				Payee:       "ELECTRONICS STORE",
+				Description: "ELECTRONICS STORE Headphones",
+			},
+		},
+		{
+			name: "reversed card payment is a credit from the merchant",
+			want: ChaseOFXTransaction{
+				Id:          "CAMT:DE89370400440532013000:REF-002",
+				Type:        string(db.TransactionTypePOS),
+				DatePosted:  time.Date(2024, time.January, 12, 0, 0, 0, 0, time.UTC),
+				Amount:      42.5,
+				Payee:       "ELECTRONICS STORE",
+				Description: "ELECTRONICS STORE Refund headphones",
+			},
+		},
+		{
+			name: "salary",
+			want: ChaseOFXTransaction{
+				Id:          "CAMT:DE89370400440532013000:REF-003",
+				Type:        string(db.TransactionTypeDIRECTDEP),
+				DatePosted:  time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+				Amount:      2500,
+				Payee:       "ACME GMBH",
+				Description: "ACME GMBH Salary January",
+			},
+		},
+		{
+			name: "reversed credit without a reference",
+			want: ChaseOFXTransaction{
+				Type:        string(db.TransactionTypeSRVCHG),
+				DatePosted:  time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC),
+				Amount:      -15,
+				Payee:       "ACME GMBH",
+				Description: "ACME GMBH Returned bonus",
+			},
+		},
+	}
+
+	transactions := results[0].Transactions
+
+	// The pending entry is skipped
+	if len(transactions) != len(tests) {
+		t.Fatalf("ParseCamt053() returned %d transactions, want %d", len(transactions), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transactions[i]
+
+			// Entries without a reference get a hashed id
+			if tt.want.Id == "" {
+				if !strings.HasPrefix(got.Id, "CAMT:") || strings.Contains(got.Id, "DE89") {
+					t.Errorf("Id = %s, want a hashed id", got.Id)
+				}
+
+				tt.want.Id = got.Id
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transaction = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBankTransactionCodeType(t *testing.T) {
+	tests := []struct {
+		name      string
+		domain    string
+		family    string
+		subFamily string
+		want      db.TransactionType
+	}{
+		{name: "card payment", domain: "PMNT", family: "CCRD", subFamily: "POSD", want: db.TransactionTypePOS},
+		{name: "cash withdrawal", domain: "PMNT", family: "CCRD", subFamily: "CWDL", want: db.TransactionTypeATM},
+		{name: "salary", domain: "PMNT", family: "RCDT", subFamily: "SALA", want: db.TransactionTypeDIRECTDEP},
+		{name: "direct debit", domain: "PMNT", family: "RDDT", subFamily: "ESDD", want: db.TransactionTypeDIRECTDEBIT},
+		{name: "standing order", domain: "PMNT", family: "ICDT", subFamily: "STDO", want: db.TransactionTypeREPEATPMT},
+		{name: "cheque", domain: "PMNT", family: "ICHQ", subFamily: "CCHQ", want: db.TransactionTypeCHECK},
+		{name: "interest", domain: "ACMT", family: "MDOP", subFamily: "INTR", want: db.TransactionTypeINT},
+		{name: "fees", domain: "ACMT", family: "MDOP", subFamily: "CHRG", want: db.TransactionTypeFEE},
+		{name: "sub family before family", domain: "PMNT", family: "RCDT", subFamily: "ESCT", want: db.TransactionTypeXFER},
+		{name: "unknown sub family uses the family", domain: "PMNT", family: "RDDT", subFamily: "OTHR", want: db.TransactionTypeDIRECTDEBIT},
+		{name: "cash", domain: "PMNT", family: "CNTR", subFamily: "OTHR", want: db.TransactionTypeCASH},
+		{name: "account management", domain: "ACMT", family: "OPCL", subFamily: "OTHR", want: db.TransactionTypeSRVCHG},
+		{name: "unknown", domain: "XTND", family: "NTAV", subFamily: "NTAV", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BankTransactionCodeType(tt.domain, tt.family, tt.subFamily); got != tt.want {
+				t.Errorf("BankTransactionCodeType(%q, %q, %q) = %q, want %q", tt.domain, tt.family, tt.subFamily, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package chase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aclindsa/ofxgo"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Securities are identified by their CUSIP or ISIN
+type ChaseOFXSecurityId struct {
+	UniqueId     string
+	UniqueIdType string
+}
+
+type ChaseOFXSecurity struct {
+	Id     ChaseOFXSecurityId
+	Ticker string
+	Name   string
+	Type   db.SecurityType
+}
+
+type ChaseOFXHolding struct {
+	Security    ChaseOFXSecurityId
+	Units       float64
+	UnitPrice   float64
+	MarketValue float32
+}
+
+type ChaseOFXInvestmentTransaction struct {
+	Id         string
+	Type       db.InvestmentTransactionType
+	TradeDate  time.Time
+	SettleDate time.Time
+	// Nil for transactions without a security, e.g. margin interest
+	Security *ChaseOFXSecurityId
+	// Positive when units are bought and negative when they are sold
+	Units     float64
+	UnitPrice float64
+	// Negative when cash leaves the account
+	Amount      float32
+	Fees        float32
+	Description string
+}
+
+// Holdings are a snapshot of the positions on the statement's BalanceDate
+type ChaseOFXInvestment struct {
+	Securities   []ChaseOFXSecurity
+	Holdings     []ChaseOFXHolding
+	Transactions []ChaseOFXInvestmentTransaction
+}
+
+// Parses the securities in the file's SECLIST, which investment statements
+// reference by id
+func parseSecurities(messages []ofxgo.Message) map[ChaseOFXSecurityId]ChaseOFXSecurity {
+	securities := map[ChaseOFXSecurityId]ChaseOFXSecurity{}
+
+	for _, message := range messages {
+		list, ok := message.(*ofxgo.SecurityList)
+
+		if !ok {
+			continue
+		}
+
+		for _, security := range list.Securities {
+			var info ofxgo.SecInfo
+			var securityType db.SecurityType
+
+			switch s := security.(type) {
+			case ofxgo.StockInfo:
+				info, securityType = s.SecInfo, db.SecurityTypeSTOCK
+			case ofxgo.MFInfo:
+				info, securityType = s.SecInfo, db.SecurityTypeMUTUALFUND
+			case ofxgo.DebtInfo:
+				info, securityType = s.SecInfo, db.SecurityTypeDEBT
+			case ofxgo.OptInfo:
+				info, securityType = s.SecInfo, db.SecurityTypeOPTION
+			case ofxgo.OtherInfo:
+				info, securityType = s.SecInfo, db.SecurityTypeOTHER
+			default:
+				continue
+			}
+
+			id := parseSecurityId(info.SecID)
+			securities[id] = ChaseOFXSecurity{
+				Id:     id,
+				Ticker: info.Ticker.String(),
+				Name:   truncate(collapseSpaces(info.SecName.String()), 255),
+				Type:   securityType,
+			}
+		}
+	}
+
+	return securities
+}
+
+func parseInvestmentAccount(stmt *ofxgo.InvStatementResponse, securities map[ChaseOFXSecurityId]ChaseOFXSecurity) ChaseOFXResult {
+	accountId := stmt.InvAcctFrom.AcctID.String()
+	investment := &ChaseOFXInvestment{}
+	referenced := map[ChaseOFXSecurityId]bool{}
+	reference := func(id ChaseOFXSecurityId) {
+		if referenced[id] {
+			return
+		}
+
+		referenced[id] = true
+		security, ok := securities[id]
+
+		// Securities missing from the SECLIST are saved by id
+		if !ok {
+			security = ChaseOFXSecurity{Id: id, Name: id.UniqueId, Type: db.SecurityTypeOTHER}
+		}
+
+		investment.Securities = append(investment.Securities, security)
+	}
+
+	var marketValue float32
+
+	for _, position := range stmt.InvPosList {
+		holding, ok := parsePosition(position)
+
+		if !ok {
+			continue
+		}
+
+		reference(holding.Security)
+		marketValue += holding.MarketValue
+		investment.Holdings = append(investment.Holdings, holding)
+	}
+
+	var transactions []ChaseOFXTransaction
+
+	if stmt.InvTranList != nil {
+		for _, tx := range stmt.InvTranList.InvTransactions {
+			transaction, ok := parseInvestmentTransaction(tx)
+
+			if !ok {
+				continue
+			}
+
+			if transaction.Security != nil {
+				reference(*transaction.Security)
+				transaction.Description = investmentDescription(transaction, securities[*transaction.Security])
+			} else {
+				transaction.Description = investmentDescription(transaction, ChaseOFXSecurity{})
+			}
+
+			investment.Transactions = append(investment.Transactions, transaction)
+		}
+
+		// Cash deposits, withdrawals and transfers are regular transactions
+		for _, bankTransactions := range stmt.InvTranList.BankTransactions {
+			for _, tx := range bankTransactions.Transactions {
+				transactions = append(transactions, parseTransaction(tx))
+			}
+		}
+	}
+
+	// The balance is the value of every position plus available cash
+	var available *float32
+
+	if stmt.InvBal != nil {
+		available = parseBalance(&stmt.InvBal.AvailCash)
+		marketValue += *available
+	}
+
+	account := ChaseOFXAccount{
+		AccountId:        accountId,
+		IsoCurrencyCode:  stmt.CurDef.String(),
+		Type:             db.AccountTypeINVESTMENT,
+		CurrentBalance:   marketValue,
+		AvailableBalance: available,
+		BalanceDate:      parseBalanceDate(stmt.DtAsOf),
+		Name:             fmt.Sprintf("Investment Account %s", lastFour(accountId)),
+	}
+
+	return ChaseOFXResult{
+		Account:      account,
+		Transactions: transactions,
+		Investment:   investment,
+	}
+}
+
+func parsePosition(position ofxgo.Position) (ChaseOFXHolding, bool) {
+	var pos ofxgo.InvPosition
+
+	switch p := position.(type) {
+	case ofxgo.StockPosition:
+		pos = p.InvPos
+	case ofxgo.MFPosition:
+		pos = p.InvPos
+	case ofxgo.DebtPosition:
+		pos = p.InvPos
+	case ofxgo.OptPosition:
+		pos = p.InvPos
+	case ofxgo.OtherPosition:
+		pos = p.InvPos
+	default:
+		return ChaseOFXHolding{}, false
+	}
+
+	units, _ := pos.Units.Float64()
+	unitPrice, _ := pos.UnitPrice.Float64()
+	marketValue, _ := pos.MktVal.Float32()
+
+	// Short positions are held as negative units
+	if pos.PosType == ofxgo.PosTypeShort && units > 0 {
+		units = -units
+		marketValue = -marketValue
+	}
+
+	return ChaseOFXHolding{
+		Security:    parseSecurityId(pos.SecID),
+		Units:       units,
+		UnitPrice:   unitPrice,
+		MarketValue: marketValue,
+	}, true
+}
+
+func parseInvestmentTransaction(tx ofxgo.InvTransaction) (ChaseOFXInvestmentTransaction, bool) {
+	switch t := tx.(type) {
+	case ofxgo.BuyDebt:
+		return parseBuy(t.InvBuy), true
+	case ofxgo.BuyMF:
+		return parseBuy(t.InvBuy), true
+	case ofxgo.BuyOpt:
+		return parseBuy(t.InvBuy), true
+	case ofxgo.BuyOther:
+		return parseBuy(t.InvBuy), true
+	case ofxgo.BuyStock:
+		return parseBuy(t.InvBuy), true
+	case ofxgo.SellDebt:
+		return parseSell(t.InvSell), true
+	case ofxgo.SellMF:
+		return parseSell(t.InvSell), true
+	case ofxgo.SellOpt:
+		return parseSell(t.InvSell), true
+	case ofxgo.SellOther:
+		return parseSell(t.InvSell), true
+	case ofxgo.SellStock:
+		return parseSell(t.InvSell), true
+	case ofxgo.Income:
+		transaction := newInvestmentTransaction(t.InvTran, incomeType(t.IncomeType.String()), &t.SecID)
+		transaction.Amount, _ = t.Total.Float32()
+		return transaction, true
+	case ofxgo.Reinvest:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeREINVEST, &t.SecID)
+		transaction.Units, _ = t.Units.Float64()
+		transaction.UnitPrice, _ = t.UnitPrice.Float64()
+		transaction.Amount, _ = t.Total.Float32()
+		transaction.Fees = sumAmounts(t.Commission, t.Fees, t.Load, t.Taxes)
+		return transaction, true
+	case ofxgo.RetOfCap:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeRETURNOFCAPITAL, &t.SecID)
+		transaction.Amount, _ = t.Total.Float32()
+		return transaction, true
+	case ofxgo.InvExpense:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeFEE, &t.SecID)
+		transaction.Amount, _ = t.Total.Float32()
+		return transaction, true
+	case ofxgo.MarginInterest:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeFEE, nil)
+		transaction.Amount, _ = t.Total.Float32()
+		return transaction, true
+	case ofxgo.JrnlFund:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeTRANSFER, nil)
+		transaction.Amount, _ = t.Total.Float32()
+		return transaction, true
+	case ofxgo.JrnlSec:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeTRANSFER, &t.SecID)
+		transaction.Units, _ = t.Units.Float64()
+		return transaction, true
+	case ofxgo.Transfer:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeTRANSFER, &t.SecID)
+		transaction.Units, _ = t.Units.Float64()
+		transaction.UnitPrice, _ = t.UnitPrice.Float64()
+
+		if t.TferAction == ofxgo.TferActionOut && transaction.Units > 0 {
+			transaction.Units = -transaction.Units
+		}
+
+		return transaction, true
+	case ofxgo.Split:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeSPLIT, &t.SecID)
+		oldUnits, _ := t.OldUnits.Float64()
+		newUnits, _ := t.NewUnits.Float64()
+		transaction.Units = newUnits - oldUnits
+		transaction.Amount, _ = t.FracCash.Float32()
+		return transaction, true
+	case ofxgo.ClosureOpt:
+		transaction := newInvestmentTransaction(t.InvTran, db.InvestmentTransactionTypeOTHER, &t.SecID)
+		transaction.Units, _ = t.Units.Float64()
+		return transaction, true
+	}
+
+	return ChaseOFXInvestmentTransaction{}, false
+}
+
+func parseBuy(buy ofxgo.InvBuy) ChaseOFXInvestmentTransaction {
+	transaction := newInvestmentTransaction(buy.InvTran, db.InvestmentTransactionTypeBUY, &buy.SecID)
+	transaction.Units, _ = buy.Units.Float64()
+	transaction.UnitPrice, _ = buy.UnitPrice.Float64()
+	transaction.Amount, _ = buy.Total.Float32()
+	transaction.Fees = sumAmounts(buy.Commission, buy.Fees, buy.Load, buy.Taxes)
+
+	return transaction
+}
+
+func parseSell(sell ofxgo.InvSell) ChaseOFXInvestmentTransaction {
+	transaction := newInvestmentTransaction(sell.InvTran, db.InvestmentTransactionTypeSELL, &sell.SecID)
+	transaction.Units, _ = sell.Units.Float64()
+	transaction.UnitPrice, _ = sell.UnitPrice.Float64()
+	transaction.Amount, _ = sell.Total.Float32()
+	transaction.Fees = sumAmounts(sell.Commission, sell.Fees, sell.Load, sell.Taxes)
+
+	// Some institutions report sold units as positive
+	if transaction.Units > 0 {
+		transaction.Units = -transaction.Units
+	}
+
+	return transaction
+}
+
+func newInvestmentTransaction(tran ofxgo.InvTran, transactionType db.InvestmentTransactionType, securityId *ofxgo.SecurityID) ChaseOFXInvestmentTransaction {
+	transaction := ChaseOFXInvestmentTransaction{
+		Id:          tran.FiTID.String(),
+		Type:        transactionType,
+		TradeDate:   tran.DtTrade.Time,
+		Description: tran.Memo.String(),
+	}
+
+	if tran.DtSettle != nil {
+		transaction.SettleDate = tran.DtSettle.Time
+	}
+
+	if securityId != nil && securityId.UniqueID != "" {
+		id := parseSecurityId(*securityId)
+		transaction.Security = &id
+	}
+
+	return transaction
+}
+
+// Income types are DIV, INTEREST, CGLONG, CGSHORT and MISC
+func incomeType(value string) db.InvestmentTransactionType {
+	switch value {
+	case "DIV":
+		return db.InvestmentTransactionTypeDIVIDEND
+	case "INTEREST":
+		return db.InvestmentTransactionTypeINTEREST
+	case "CGLONG", "CGSHORT":
+		return db.InvestmentTransactionTypeCAPITALGAIN
+	}
+
+	return db.InvestmentTransactionTypeOTHER
+}
+
+// Uses the memo, or describes the transaction from its type and security
+func investmentDescription(transaction ChaseOFXInvestmentTransaction, security ChaseOFXSecurity) string {
+	description := collapseSpaces(transaction.Description)
+
+	if description == "" {
+		name := strings.ReplaceAll(strings.ToLower(string(transaction.Type)), "_", " ")
+		description = strings.ToUpper(name[:1]) + name[1:]
+
+		if label := firstNonEmpty(security.Ticker, security.Name); label != "" {
+			description = fmt.Sprintf("%s %s", description, label)
+		}
+	}
+
+	return truncate(description, 255)
+}
+
+func parseSecurityId(id ofxgo.SecurityID) ChaseOFXSecurityId {
+	return ChaseOFXSecurityId{
+		UniqueId:     strings.TrimSpace(id.UniqueID.String()),
+		UniqueIdType: strings.ToUpper(strings.TrimSpace(id.UniqueIDType.String())),
+	}
+}
+
+func sumAmounts(amounts ...ofxgo.Amount) float32 {
+	var total float32
+
+	for _, amount := range amounts {
+		value, _ := amount.Float32()
+		total += value
+	}
+
+	return total
+}
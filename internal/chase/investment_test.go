@@ -0,0 +1,189 @@
+package chase
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+func TestParseChaseOFXInvestment(t *testing.T) {
+	file, err := os.Open("testdata/investment.ofx")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	results, err := ParseChaseOFX(file)
+
+	if err != nil {
+		t.Fatalf("ParseChaseOFX() error = %v", err)
+	}
+
+	if len(results) != 1 || results[0].Investment == nil {
+		t.Fatalf("ParseChaseOFX() returned %+v, want 1 investment statement", results)
+	}
+
+	account := results[0].Account
+	available := float32(500)
+
+	if !account.BalanceDate.Equal(time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("BalanceDate = %v, want 2024-01-31 12:00 UTC", account.BalanceDate)
+	}
+
+	account.BalanceDate = time.Time{}
+	wantAccount := ChaseOFXAccount{
+		AccountId:       "987654321",
+		IsoCurrencyCode: "USD",
+		Type:            db.AccountTypeINVESTMENT,
+		// Positions, with the short position negative, plus available cash
+		CurrentBalance:   7050,
+		AvailableBalance: &available,
+		Name:             "Investment Account 4321",
+	}
+
+	if !reflect.DeepEqual(account, wantAccount) {
+		t.Errorf("account = %+v, want %+v", account, wantAccount)
+	}
+
+	apple := ChaseOFXSecurityId{UniqueId: "037833100", UniqueIdType: "CUSIP"}
+	fund := ChaseOFXSecurityId{UniqueId: "922908728", UniqueIdType: "CUSIP"}
+	unlisted := ChaseOFXSecurityId{UniqueId: "88160R101", UniqueIdType: "CUSIP"}
+	investment := results[0].Investment
+
+	t.Run("securities", func(t *testing.T) {
+		want := []ChaseOFXSecurity{
+			{Id: apple, Ticker: "AAPL", Name: "Apple Inc.", Type: db.SecurityTypeSTOCK},
+			{Id: fund, Ticker: "VTSAX", Name: "Vanguard Total Stock Market Index Admiral", Type: db.SecurityTypeMUTUALFUND},
+			// Missing from the SECLIST, so saved by id
+			{Id: unlisted, Name: "88160R101", Type: db.SecurityTypeOTHER},
+		}
+
+		if !reflect.DeepEqual(investment.Securities, want) {
+			t.Errorf("Securities = %+v, want %+v", investment.Securities, want)
+		}
+	})
+
+	t.Run("positions", func(t *testing.T) {
+		want := []ChaseOFXHolding{
+			{Security: apple, Units: 10, UnitPrice: 190, MarketValue: 1900},
+			{Security: fund, Units: 50.5, UnitPrice: 100, MarketValue: 5050},
+			{Security: unlisted, Units: -2, UnitPrice: 200, MarketValue: -400},
+		}
+
+		if !reflect.DeepEqual(investment.Holdings, want) {
+			t.Errorf("Holdings = %+v, want %+v", investment.Holdings, want)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		want           ChaseOFXInvestmentTransaction
+		wantTradeDate  time.Time
+		wantSettleDate time.Time
+	}{
+		{
+			name: "buy",
+			want: ChaseOFXInvestmentTransaction{
+				Id:          "BUY-001",
+				Type:        db.InvestmentTransactionTypeBUY,
+				Security:    &apple,
+				Units:       10,
+				UnitPrice:   185,
+				Amount:      -1854.95,
+				Fees:        4.95,
+				Description: "Buy AAPL",
+			},
+			wantTradeDate:  time.Date(2024, time.January, 5, 12, 0, 0, 0, time.UTC),
+			wantSettleDate: time.Date(2024, time.January, 9, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sell with positive units",
+			want: ChaseOFXInvestmentTransaction{
+				Id:          "SELL-001",
+				Type:        db.InvestmentTransactionTypeSELL,
+				Security:    &fund,
+				Units:       -5,
+				UnitPrice:   98,
+				Amount:      489,
+				Fees:        1,
+				Description: "Rebalance",
+			},
+			wantTradeDate: time.Date(2024, time.January, 10, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "dividend",
+			want: ChaseOFXInvestmentTransaction{
+				Id:          "DIV-001",
+				Type:        db.InvestmentTransactionTypeDIVIDEND,
+				Security:    &fund,
+				Amount:      25.3,
+				Description: "Dividend VTSAX",
+			},
+			wantTradeDate: time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "reinvestment",
+			want: ChaseOFXInvestmentTransaction{
+				Id:          "REINV-001",
+				Type:        db.InvestmentTransactionTypeREINVEST,
+				Security:    &fund,
+				Units:       0.25,
+				UnitPrice:   101.2,
+				Amount:      -25.3,
+				Description: "Reinvest VTSAX",
+			},
+			wantTradeDate: time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "margin interest without a security",
+			want: ChaseOFXInvestmentTransaction{
+				Id:          "INT-001",
+				Type:        db.InvestmentTransactionTypeFEE,
+				Amount:      -1.5,
+				Description: "Fee",
+			},
+			wantTradeDate: time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	if len(investment.Transactions) != len(tests) {
+		t.Fatalf("ParseChaseOFX() returned %d investment transactions, want %d", len(investment.Transactions), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := investment.Transactions[i]
+
+			if !got.TradeDate.Equal(tt.wantTradeDate) {
+				t.Errorf("TradeDate = %v, want %v", got.TradeDate, tt.wantTradeDate)
+			}
+
+			if !got.SettleDate.Equal(tt.wantSettleDate) {
+				t.Errorf("SettleDate = %v, want %v", got.SettleDate, tt.wantSettleDate)
+			}
+
+			got.TradeDate, got.SettleDate = time.Time{}, time.Time{}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transaction = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("cash transactions", func(t *testing.T) {
+		transactions := results[0].Transactions
+
+		if len(transactions) != 1 {
+			t.Fatalf("Transactions = %+v, want 1 deposit", transactions)
+		}
+
+		if got := transactions[0]; got.Id != "DEP-001" || got.Amount != 1000 || got.Description != "ACH DEPOSIT" {
+			t.Errorf("transaction = %+v, want the 1000.00 ACH DEPOSIT", got)
+		}
+	})
+}
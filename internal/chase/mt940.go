@@ -0,0 +1,433 @@
+package chase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+var (
+	mt940FieldExpression       = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+	mt940BalanceExpression     = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})(\d+,\d*)$`)
+	mt940StatementExpression   = regexp.MustCompile(`^(\d{6})(\d{4})?(R?[CD])([A-Z])?(\d+,\d*)([SNF][A-Z0-9]{3})([^\n]*?)(?://([^\n]*))?(?:\n([\s\S]*))?$`)
+	mt940SubfieldExpression    = regexp.MustCompile(`\?(\d{2})`)
+	mt940StructuredExpression  = regexp.MustCompile(`^\d{3}\?`)
+	mt940TagValueExpression    = regexp.MustCompile(`/(NAME|REMI|CNTP|TRTP|EREF|IBAN|BIC|ADDR|MARF|CSID|PREF|ORDP|BENM|PURP|RTRN|ULTC|ULTD)/`)
+	mt940SepaTagExpression     = regexp.MustCompile(`(EREF|KREF|MREF|CRED|DEBT|SVWZ|ABWA|ABWE|IBAN|BIC)\+`)
+	mt940SwiftBlockExpression  = regexp.MustCompile(`^\{\d:.*?\{4:`)
+	mt940SwiftHeaderExpression = regexp.MustCompile(`^\{\d:`)
+)
+
+// A tag and its value, which may continue over several lines
+type mt940Field struct {
+	Tag   string
+	Value string
+}
+
+// ParseMT940 parses the statements in a SWIFT MT940 file into the same
+// structures as OFX statements. Statements split over several messages
+// are combined per account
+func ParseMT940(reader io.Reader) ([]ChaseOFXResult, error) {
+	fields, err := readMT940Fields(reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ChaseOFXResult
+	accountIndex := map[string]int{}
+	current := -1
+	lastTransaction := false
+
+	for _, field := range fields {
+		switch field.Tag {
+		// Account identification
+		case "25":
+			accountId, bankId := parseMT940Account(field.Value)
+			index, ok := accountIndex[accountId]
+
+			if !ok {
+				caser := cases.Title(language.AmericanEnglish)
+				results = append(results, ChaseOFXResult{
+					Account: ChaseOFXAccount{
+						BankId:    bankId,
+						AccountId: accountId,
+						Type:      db.AccountTypeCHECKING,
+						Name:      fmt.Sprintf("%s Account %s", caser.String(strings.ToLower(string(db.AccountTypeCHECKING))), lastFour(accountId)),
+					},
+				})
+				index = len(results) - 1
+				accountIndex[accountId] = index
+			}
+
+			current = index
+			lastTransaction = false
+			continue
+		}
+
+		if current < 0 {
+			continue
+		}
+
+		result := &results[current]
+
+		switch field.Tag {
+		// Opening balance, only used for the currency
+		case "60F", "60M":
+			_, _, currency, err := parseMT940Balance(field.Value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if result.Account.IsoCurrencyCode == "" {
+				result.Account.IsoCurrencyCode = currency
+			}
+		// Statement line
+		case "61":
+			tx, err := parseMT940StatementLine(field.Value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			result.Transactions = append(result.Transactions, tx)
+			lastTransaction = true
+			continue
+		// Information to account owner for the previous statement line
+		case "86":
+			if lastTransaction {
+				tx := &result.Transactions[len(result.Transactions)-1]
+				payee, memo := parseMT940Information(field.Value)
+				tx.Payee = truncate(payee, 255)
+				tx.Description = truncate(collapseSpaces(firstNonEmpty(getDescription(payee, memo), tx.Description)), 255)
+			}
+		// Closing booked balance
+		case "62F", "62M":
+			amount, date, currency, err := parseMT940Balance(field.Value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			// Later statements for the same account replace the balance
+			if !date.Before(result.Account.BalanceDate) {
+				result.Account.CurrentBalance = amount
+				result.Account.BalanceDate = date
+				result.Account.IsoCurrencyCode = currency
+			}
+		// Closing available balance
+		case "64":
+			amount, _, _, err := parseMT940Balance(field.Value)
+
+			if err != nil {
+				return nil, err
+			}
+
+			result.Account.AvailableBalance = &amount
+		}
+
+		lastTransaction = false
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("No statements found. MT940 :25: account required")
+	}
+
+	for i := range results {
+		setHashedSourceIds("MT940", results[i].Account.AccountId, results[i].Transactions)
+	}
+
+	return results, nil
+}
+
+// SwiftTransactionCodeType maps a SWIFT transaction type identification
+// code, e.g. NTRF, onto a transaction type. Returns an empty type when
+// the code has no equivalent
+func SwiftTransactionCodeType(code string) db.TransactionType {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	if len(code) == 4 && strings.ContainsRune("NSF", rune(code[0])) {
+		code = code[1:]
+	}
+
+	switch code {
+	case "TRF", "BOE", "FEX":
+		return db.TransactionTypeXFER
+	case "CHK", "ECK", "TCK":
+		return db.TransactionTypeCHECK
+	case "DDT":
+		return db.TransactionTypeDIRECTDEBIT
+	case "STO":
+		return db.TransactionTypeREPEATPMT
+	case "INT":
+		return db.TransactionTypeINT
+	case "DIV":
+		return db.TransactionTypeDIV
+	case "CHG":
+		return db.TransactionTypeSRVCHG
+	case "COM", "BRF":
+		return db.TransactionTypeFEE
+	case "POS":
+		return db.TransactionTypePOS
+	case "ATM":
+		return db.TransactionTypeATM
+	}
+
+	return ""
+}
+
+// Splits the file into fields, dropping SWIFT envelope blocks
+func readMT940Fields(reader io.Reader) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r ")
+
+		if mt940SwiftHeaderExpression.MatchString(line) {
+			line = mt940SwiftBlockExpression.ReplaceAllString(line, "")
+
+			if mt940SwiftHeaderExpression.MatchString(line) {
+				continue
+			}
+		}
+
+		// End of message
+		if line == "" || line == "-" || line == "-}" || strings.HasPrefix(line, "-}{") {
+			continue
+		}
+
+		if match := mt940FieldExpression.FindStringSubmatch(line); match != nil {
+			fields = append(fields, mt940Field{Tag: match[1], Value: match[2]})
+		} else if len(fields) > 0 {
+			fields[len(fields)-1].Value += "\n" + line
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read MT940 file: %w", err)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Failed to parse MT940 file: no fields found")
+	}
+
+	return fields, nil
+}
+
+// Account identification is either an IBAN or bank code/account number,
+// optionally followed by the currency
+func parseMT940Account(value string) (string, string) {
+	value = strings.TrimSpace(value)
+
+	if bankId, accountId, ok := strings.Cut(value, "/"); ok {
+		return strings.TrimSpace(accountId), strings.TrimSpace(bankId)
+	}
+
+	return value, ""
+}
+
+func parseMT940Balance(value string) (float32, time.Time, string, error) {
+	match := mt940BalanceExpression.FindStringSubmatch(strings.TrimSpace(value))
+
+	if match == nil {
+		return 0, time.Time{}, "", fmt.Errorf("Invalid MT940 balance %q", value)
+	}
+
+	date, err := time.Parse("060102", match[2])
+
+	if err != nil {
+		return 0, time.Time{}, "", fmt.Errorf("Invalid MT940 balance date %q", match[2])
+	}
+
+	amount, err := parseMT940Amount(match[4])
+
+	if err != nil {
+		return 0, time.Time{}, "", err
+	}
+
+	if match[1] == "D" {
+		amount = -amount
+	}
+
+	return amount, date, match[3], nil
+}
+
+// Parses a :61: statement line, e.g.
+// 2401150115D12,50NTRFNONREF//0115123456
+func parseMT940StatementLine(value string) (ChaseOFXTransaction, error) {
+	match := mt940StatementExpression.FindStringSubmatch(value)
+
+	if match == nil {
+		return ChaseOFXTransaction{}, fmt.Errorf("Invalid MT940 statement line %q", value)
+	}
+
+	valueDate, err := time.Parse("060102", match[1])
+
+	if err != nil {
+		return ChaseOFXTransaction{}, fmt.Errorf("Invalid MT940 value date %q", match[1])
+	}
+
+	date := valueDate
+
+	// The booking date has no year, so take the value date's year
+	// and correct for statements crossing the new year
+	if match[2] != "" {
+		entryDate, err := time.Parse("0102", match[2])
+
+		if err != nil {
+			return ChaseOFXTransaction{}, fmt.Errorf("Invalid MT940 entry date %q", match[2])
+		}
+
+		date = time.Date(valueDate.Year(), entryDate.Month(), entryDate.Day(), 0, 0, 0, 0, time.UTC)
+
+		if date.Sub(valueDate) > 180*24*time.Hour {
+			date = date.AddDate(-1, 0, 0)
+		} else if valueDate.Sub(date) > 180*24*time.Hour {
+			date = date.AddDate(1, 0, 0)
+		}
+	}
+
+	amount, err := parseMT940Amount(match[5])
+
+	if err != nil {
+		return ChaseOFXTransaction{}, err
+	}
+
+	// Debits and reversals of credits take money out of the account
+	if match[3] == "D" || match[3] == "RC" {
+		amount = -amount
+	}
+
+	transactionType := SwiftTransactionCodeType(match[6])
+
+	if transactionType == "" {
+		transactionType = signedTransactionType(amount)
+	}
+
+	customerReference := strings.TrimSpace(match[7])
+	supplementary := collapseSpaces(match[9])
+
+	if customerReference == "NONREF" {
+		customerReference = ""
+	}
+
+	return ChaseOFXTransaction{
+		Id:          strings.TrimSpace(match[8]),
+		Type:        string(transactionType),
+		DatePosted:  date,
+		Amount:      amount,
+		Description: truncate(firstNonEmpty(supplementary, customerReference), 255),
+	}, nil
+}
+
+// Parses the :86: information into a payee and memo. Supports the German
+// structured format (?20 remittance, ?32 name), the /NAME/ /REMI/ tag
+// format and free text
+func parseMT940Information(value string) (string, string) {
+	if mt940StructuredExpression.MatchString(value) {
+		value = strings.ReplaceAll(value, "\n", "")
+		subfields := map[int]string{}
+		indexes := mt940SubfieldExpression.FindAllStringSubmatchIndex(value, -1)
+
+		for i, index := range indexes {
+			code, _ := strconv.Atoi(value[index[2]:index[3]])
+			end := len(value)
+
+			if i+1 < len(indexes) {
+				end = indexes[i+1][0]
+			}
+
+			subfields[code] = value[index[1]:end]
+		}
+
+		var remittance []string
+
+		for code := 20; code <= 29; code++ {
+			remittance = append(remittance, subfields[code])
+		}
+
+		for code := 60; code <= 63; code++ {
+			remittance = append(remittance, subfields[code])
+		}
+
+		payee := collapseSpaces(subfields[32] + subfields[33])
+		memo := strings.Join(remittance, "")
+
+		// SEPA remittance is tagged, keep only the purpose (SVWZ+)
+		if indexes := mt940SepaTagExpression.FindAllStringSubmatchIndex(memo, -1); len(indexes) > 0 {
+			purpose := ""
+
+			for i, index := range indexes {
+				if memo[index[2]:index[3]] == "SVWZ" {
+					end := len(memo)
+
+					if i+1 < len(indexes) {
+						end = indexes[i+1][0]
+					}
+
+					purpose = memo[index[1]:end]
+				}
+			}
+
+			if purpose != "" {
+				memo = purpose
+			}
+		}
+
+		memo = collapseSpaces(memo)
+
+		if memo == "" {
+			memo = collapseSpaces(subfields[0])
+		}
+
+		return payee, memo
+	}
+
+	value = strings.ReplaceAll(value, "\n", " ")
+
+	if strings.HasPrefix(value, "/") {
+		tags := map[string]string{}
+		indexes := mt940TagValueExpression.FindAllStringSubmatchIndex(value, -1)
+
+		for i, index := range indexes {
+			end := len(value)
+
+			if i+1 < len(indexes) {
+				end = indexes[i+1][0]
+			}
+
+			tags[value[index[2]:index[3]]] = strings.Trim(value[index[1]:end], "/ ")
+		}
+
+		payee := tags["NAME"]
+
+		// CNTP holds account/BIC/name/city of the counterparty
+		if counterparty := strings.Split(tags["CNTP"], "/"); payee == "" && len(counterparty) > 2 {
+			payee = counterparty[2]
+		}
+
+		return collapseSpaces(payee), collapseSpaces(firstNonEmpty(tags["REMI"], tags["TRTP"]))
+	}
+
+	return "", collapseSpaces(value)
+}
+
+func parseMT940Amount(value string) (float32, error) {
+	amount, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 32)
+
+	if err != nil {
+		return 0, fmt.Errorf("Invalid MT940 amount %q", value)
+	}
+
+	return float32(amount), nil
+}
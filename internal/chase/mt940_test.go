@@ -0,0 +1,352 @@
+package chase
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+func TestParseMT940(t *testing.T) {
+	file, err := os.Open("testdata/statement.mt940")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer file.Close()
+
+	results, err := ParseMT940(file)
+
+	if err != nil {
+		t.Fatalf("ParseMT940() error = %v", err)
+	}
+
+	// Both messages are for the same account
+	if len(results) != 1 {
+		t.Fatalf("ParseMT940() returned %d statements, want 1", len(results))
+	}
+
+	account := results[0].Account
+	available := float32(2500)
+	wantAccount := ChaseOFXAccount{
+		BankId:           "37040044",
+		AccountId:        "0532013000",
+		IsoCurrencyCode:  "EUR",
+		Type:             db.AccountTypeCHECKING,
+		CurrentBalance:   2557.5,
+		AvailableBalance: &available,
+		BalanceDate:      time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC),
+		Name:             "Checking Account 3000",
+	}
+
+	if !reflect.DeepEqual(account, wantAccount) {
+		t.Errorf("account = %+v, want %+v", account, wantAccount)
+	}
+
+	tests := []struct {
+		name string
+		want ChaseOFXTransaction
+	}{
+		{
+			name: "structured information over several lines",
+			want: ChaseOFXTransaction{
+				Id:          "MT940:0532013000:BANKREF1",
+				Type:        string(db.TransactionTypeREPEATPMT),
+				DatePosted:  time.Date(2023, time.December, 29, 0, 0, 0, 0, time.UTC),
+				Amount:      -850,
+				Payee:       "VERMIETER GMBH",
+				Description: "VERMIETER GMBH Miete Dezember 2023",
+			},
+		},
+		{
+			name: "booked in the year after the value date",
+			want: ChaseOFXTransaction{
+				Id:          "MT940:0532013000:BANKREF2",
+				Type:        string(db.TransactionTypeXFER),
+				DatePosted:  time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+				Amount:      2500,
+				Payee:       "ACME GMBH",
+				Description: "ACME GMBH Salary December",
+			},
+		},
+		{
+			name: "booked in the year before the value date",
+			want: ChaseOFXTransaction{
+				Id:          "MT940:0532013000:BANKREF3",
+				Type:        string(db.TransactionTypePOS),
+				DatePosted:  time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC),
+				Amount:      -5,
+				Payee:       "COFFEE SHOP",
+				Description: "COFFEE SHOP Card payment",
+			},
+		},
+		{
+			name: "reversed debit is a credit",
+			want: ChaseOFXTransaction{
+				Id:          "MT940:0532013000:BANKREF4",
+				Type:        string(db.TransactionTypeSRVCHG),
+				DatePosted:  time.Date(2024, time.January, 3, 0, 0, 0, 0, time.UTC),
+				Amount:      12.5,
+				Description: "Fee refund",
+			},
+		},
+		{
+			name: "reversed credit without a reference",
+			want: ChaseOFXTransaction{
+				Type:        string(db.TransactionTypeXFER),
+				DatePosted:  time.Date(2024, time.January, 4, 0, 0, 0, 0, time.UTC),
+				Amount:      -100,
+				Description: "Returned deposit",
+			},
+		},
+	}
+
+	transactions := results[0].Transactions
+
+	if len(transactions) != len(tests) {
+		t.Fatalf("ParseMT940() returned %d transactions, want %d", len(transactions), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := transactions[i]
+
+			// Entries without a reference get a hashed id
+			if tt.want.Id == "" {
+				if !strings.HasPrefix(got.Id, "MT940:") || strings.Contains(got.Id, "0532013000") {
+					t.Errorf("Id = %s, want a hashed id", got.Id)
+				}
+
+				tt.want.Id = got.Id
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("transaction = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMT940Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "empty file",
+			input: "",
+			want:  "Failed to parse MT940 file: no fields found",
+		},
+		{
+			name:  "no account",
+			input: ":20:STMT\n:60F:C240101EUR1,00\n",
+			want:  "No statements found. MT940 :25: account required",
+		},
+		{
+			name:  "invalid statement line",
+			input: ":25:DE89370400440532013000\n:61:240101\n",
+			want:  `Invalid MT940 statement line "240101"`,
+		},
+		{
+			name:  "invalid balance",
+			input: ":25:DE89370400440532013000\n:62F:C2401EUR1,00\n",
+			want:  `Invalid MT940 balance "C2401EUR1,00"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMT940(strings.NewReader(tt.input))
+
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("ParseMT940() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMT940StatementLine(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		wantDate   time.Time
+		wantAmount float32
+		wantType   db.TransactionType
+		wantId     string
+	}{
+		{
+			name:       "value date only",
+			value:      "240115D12,50NTRFNONREF//0115123456",
+			wantDate:   time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			wantAmount: -12.5,
+			wantType:   db.TransactionTypeXFER,
+			wantId:     "0115123456",
+		},
+		{
+			name:       "booking date in the next year",
+			value:      "2312310102C1,00NMSCNONREF",
+			wantDate:   time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+			wantAmount: 1,
+			wantType:   db.TransactionTypeCREDIT,
+		},
+		{
+			name:       "booking date in the previous year",
+			value:      "2401021230D1,00NMSCNONREF",
+			wantDate:   time.Date(2023, time.December, 30, 0, 0, 0, 0, time.UTC),
+			wantAmount: -1,
+			wantType:   db.TransactionTypeDEBIT,
+		},
+		{
+			name:       "funds code",
+			value:      "2401150115CR99,NTRFNONREF",
+			wantDate:   time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			wantAmount: 99,
+			wantType:   db.TransactionTypeXFER,
+		},
+		{
+			name:       "reversal of credit",
+			value:      "240115RC5,00NMSCNONREF",
+			wantDate:   time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			wantAmount: -5,
+			wantType:   db.TransactionTypeDEBIT,
+		},
+		{
+			name:       "reversal of debit",
+			value:      "240115RD5,00NMSCNONREF",
+			wantDate:   time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			wantAmount: 5,
+			wantType:   db.TransactionTypeCREDIT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMT940StatementLine(tt.value)
+
+			if err != nil {
+				t.Fatalf("parseMT940StatementLine() error = %v", err)
+			}
+
+			if !got.DatePosted.Equal(tt.wantDate) {
+				t.Errorf("DatePosted = %v, want %v", got.DatePosted, tt.wantDate)
+			}
+
+			if got.Amount != tt.wantAmount {
+				t.Errorf("Amount = %v, want %v", got.Amount, tt.wantAmount)
+			}
+
+			if got.Type != string(tt.wantType) {
+				t.Errorf("Type = %s, want %s", got.Type, tt.wantType)
+			}
+
+			if got.Id != tt.wantId {
+				t.Errorf("Id = %s, want %s", got.Id, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestParseMT940Information(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		wantPayee string
+		wantMemo  string
+	}{
+		{
+			name:      "structured remittance",
+			value:     "166?00GUTSCHRIFT?20Invoice 4711?21 paid in full?32ACME GMBH",
+			wantPayee: "ACME GMBH",
+			wantMemo:  "Invoice 4711 paid in full",
+		},
+		{
+			name:     "structured remittance continued in ?60 to ?63",
+			value:    "166?00GUTSCHRIFT?20Part one ?29two ?60three ?63four",
+			wantMemo: "Part one two three four",
+		},
+		{
+			name:      "SEPA purpose",
+			value:     "105?00SEPA-LASTSCHRIFT?20EREF+E2E-1?21MREF+M-77?22CRED+DE98ZZZ09999999999?23SVWZ+Electricity ?24January?32STADTWERKE ?33MUENCHEN",
+			wantPayee: "STADTWERKE MUENCHEN",
+			wantMemo:  "Electricity January",
+		},
+		{
+			name:     "SEPA without purpose keeps the tags",
+			value:    "105?00SEPA-LASTSCHRIFT?20EREF+E2E-1",
+			wantMemo: "EREF+E2E-1",
+		},
+		{
+			name:     "posting text when there is no remittance",
+			value:    "835?00ABSCHLUSS",
+			wantMemo: "ABSCHLUSS",
+		},
+		{
+			name:      "subfields split over lines",
+			value:     "166?00GUTSCHRIFT?20Invoice\n 4711?32ACME\n GMBH",
+			wantPayee: "ACME GMBH",
+			wantMemo:  "Invoice 4711",
+		},
+		{
+			name:      "tagged",
+			value:     "/NAME/ACME GMBH/REMI/Invoice 4711/",
+			wantPayee: "ACME GMBH",
+			wantMemo:  "Invoice 4711",
+		},
+		{
+			name:      "tagged counterparty",
+			value:     "/TRTP/SEPA OVERBOEKING/CNTP/NL91ABNA0417164300/ABNANL2A/J DOE/AMSTERDAM/",
+			wantPayee: "J DOE",
+			wantMemo:  "SEPA OVERBOEKING",
+		},
+		{
+			name:     "free text",
+			value:    "Card payment\n coffee",
+			wantMemo: "Card payment coffee",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payee, memo := parseMT940Information(tt.value)
+
+			if payee != tt.wantPayee {
+				t.Errorf("payee = %q, want %q", payee, tt.wantPayee)
+			}
+
+			if memo != tt.wantMemo {
+				t.Errorf("memo = %q, want %q", memo, tt.wantMemo)
+			}
+		})
+	}
+}
+
+func TestSwiftTransactionCodeType(t *testing.T) {
+	tests := []struct {
+		code string
+		want db.TransactionType
+	}{
+		{code: "NTRF", want: db.TransactionTypeXFER},
+		{code: "SCHK", want: db.TransactionTypeCHECK},
+		{code: "NDDT", want: db.TransactionTypeDIRECTDEBIT},
+		{code: "NSTO", want: db.TransactionTypeREPEATPMT},
+		{code: "NINT", want: db.TransactionTypeINT},
+		{code: "NCHG", want: db.TransactionTypeSRVCHG},
+		{code: "FCOM", want: db.TransactionTypeFEE},
+		{code: "npos", want: db.TransactionTypePOS},
+		{code: "ATM", want: db.TransactionTypeATM},
+		{code: "NMSC", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := SwiftTransactionCodeType(tt.code); got != tt.want {
+				t.Errorf("SwiftTransactionCodeType(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
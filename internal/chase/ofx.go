@@ -19,7 +19,8 @@ type ChaseOFXAccount struct {
 	IsoCurrencyCode  string
 	Type             db.AccountType
 	CurrentBalance   float32
-	AvailableBalance float32
+	AvailableBalance *float32
+	BalanceDate      time.Time
 	Name             string
 }
 
@@ -33,14 +34,26 @@ type ChaseOFXTransaction struct {
 	PayeeFull   string
 	CheckNumber string
 	Description string
+	// Pending transactions are replaced by their posted transaction on a
+	// later import
+	Pending        bool
+	AuthorizedDate time.Time
+	// Source id of the pending transaction this posted transaction replaces,
+	// for sources that link them
+	PendingId string
 }
 
 type ChaseOFXResult struct {
 	Account      ChaseOFXAccount
 	Transactions []ChaseOFXTransaction
+	// Set for investment statements
+	Investment *ChaseOFXInvestment
 }
 
-func ParseChaseOFX(reader io.Reader) (*ChaseOFXResult, error) {
+// ParseChaseOFX parses every bank, credit card and investment statement in an
+// OFX file.
+// Combined downloads can hold several accounts, e.g. checking and savings
+func ParseChaseOFX(reader io.Reader) ([]ChaseOFXResult, error) {
 	response, err := ofxgo.ParseResponse(reader)
 
 	if err != nil {
@@ -54,120 +67,141 @@ func ParseChaseOFX(reader io.Reader) (*ChaseOFXResult, error) {
 		return nil, fmt.Errorf("Nonzero signon status (%d: %s) with message: %s", response.Signon.Status.Code, meaning, response.Signon.Status.Message)
 	}
 
-	if len(response.Bank) > 0 {
-		return parseBankAccount(response)
-	} else if len(response.CreditCard) > 0 {
-		return parseCreditCard(response)
-	}
-
-	return nil, fmt.Errorf("Unsupported account type. Supported: Bank account, credit card")
-}
+	var results []ChaseOFXResult
 
-func parseBankAccount(response *ofxgo.Response) (*ChaseOFXResult, error) {
-	if stmt, ok := response.Bank[0].(*ofxgo.StatementResponse); ok {
-		current, _ := stmt.BalAmt.Float32()
-		available, _ := stmt.AvailBalAmt.Float32()
-		accountId := stmt.BankAcctFrom.AcctID.String()
-		accountType := db.AccountType(stmt.BankAcctFrom.AcctType.String())
-		caser := cases.Title(language.AmericanEnglish)
-		accountTypeFormatted := caser.String(strings.ToLower(string(accountType)))
-
-		account := ChaseOFXAccount{
-			BankId:           stmt.BankAcctFrom.BankID.String(),
-			AccountId:        accountId,
-			IsoCurrencyCode:  stmt.CurDef.String(),
-			Type:             accountType,
-			CurrentBalance:   current,
-			AvailableBalance: available,
-			Name:             fmt.Sprintf("%s Account %s", accountTypeFormatted, accountId[len(accountId)-4:]),
+	for _, message := range response.Bank {
+		if stmt, ok := message.(*ofxgo.StatementResponse); ok {
+			results = append(results, parseBankAccount(stmt))
 		}
-		var transactions []ChaseOFXTransaction
-
-		for _, tx := range stmt.BankTranList.Transactions {
-			amount, _ := tx.TrnAmt.Float32()
-			name := tx.Name.String()
-
-			if tx.Payee != nil {
-				name = tx.Payee.Name.String()
-			}
-
-			transaction := ChaseOFXTransaction{
-				Id:          tx.FiTID.String(),
-				Type:        tx.TrnType.String(),
-				DatePosted:  tx.DtPosted.Time,
-				Amount:      amount,
-				PayeeId:     tx.PayeeID.String(),
-				Payee:       name,
-				PayeeFull:   tx.ExtdName.String(),
-				CheckNumber: tx.CheckNum.String(),
-				Description: getDescription(name, tx.Memo.String()),
-			}
-
-			transactions = append(transactions, transaction)
+	}
+
+	for _, message := range response.CreditCard {
+		if stmt, ok := message.(*ofxgo.CCStatementResponse); ok {
+			results = append(results, parseCreditCard(stmt))
 		}
+	}
+
+	securities := parseSecurities(response.SecList)
 
-		result := &ChaseOFXResult{
-			Account:      account,
-			Transactions: transactions,
+	for _, message := range response.InvStmt {
+		if stmt, ok := message.(*ofxgo.InvStatementResponse); ok {
+			results = append(results, parseInvestmentAccount(stmt, securities))
 		}
+	}
 
-		return result, nil
+	if len(results) == 0 {
+		return nil, fmt.Errorf("Unsupported account type. Supported: Bank account, credit card, investment account")
 	}
 
-	return nil, fmt.Errorf("ofx: failed to parse bank account data")
+	return results, nil
 }
 
-func parseCreditCard(response *ofxgo.Response) (*ChaseOFXResult, error) {
-	if stmt, ok := response.CreditCard[0].(*ofxgo.CCStatementResponse); ok {
-		current, _ := stmt.BalAmt.Float32()
-		available, _ := stmt.AvailBalAmt.Float32()
-		accountId := stmt.CCAcctFrom.AcctID.String()
-		accountType := db.AccountTypeCREDIT
-		caser := cases.Title(language.AmericanEnglish)
-		accountTypeFormatted := caser.String(strings.ToLower(string(accountType)))
-
-		account := ChaseOFXAccount{
-			AccountId:        accountId,
-			IsoCurrencyCode:  stmt.CurDef.String(),
-			Type:             accountType,
-			CurrentBalance:   current,
-			AvailableBalance: available,
-			Name:             fmt.Sprintf("%s Card %s", accountTypeFormatted, accountId[len(accountId)-4:]),
-		}
-		var transactions []ChaseOFXTransaction
-
-		for _, tx := range stmt.BankTranList.Transactions {
-			amount, _ := tx.TrnAmt.Float32()
-			name := tx.Name.String()
-
-			if tx.Payee != nil {
-				name = tx.Payee.Name.String()
-			}
-
-			transaction := ChaseOFXTransaction{
-				Id:          tx.FiTID.String(),
-				Type:        tx.TrnType.String(),
-				DatePosted:  tx.DtPosted.Time,
-				Amount:      amount,
-				PayeeId:     tx.PayeeID.String(),
-				Payee:       name,
-				PayeeFull:   tx.ExtdName.String(),
-				CheckNumber: tx.CheckNum.String(),
-				Description: getDescription(name, tx.Memo.String()),
-			}
-
-			transactions = append(transactions, transaction)
-		}
+func parseBankAccount(stmt *ofxgo.StatementResponse) ChaseOFXResult {
+	current, _ := stmt.BalAmt.Float32()
+	accountId := stmt.BankAcctFrom.AcctID.String()
+	accountType := db.AccountType(stmt.BankAcctFrom.AcctType.String())
+	caser := cases.Title(language.AmericanEnglish)
+	accountTypeFormatted := caser.String(strings.ToLower(string(accountType)))
+
+	account := ChaseOFXAccount{
+		BankId:           stmt.BankAcctFrom.BankID.String(),
+		AccountId:        accountId,
+		IsoCurrencyCode:  stmt.CurDef.String(),
+		Type:             accountType,
+		CurrentBalance:   current,
+		AvailableBalance: parseBalance(stmt.AvailBalAmt),
+		BalanceDate:      parseBalanceDate(stmt.DtAsOf),
+		Name:             fmt.Sprintf("%s Account %s", accountTypeFormatted, lastFour(accountId)),
+	}
 
-		result := &ChaseOFXResult{
-			Account:      account,
-			Transactions: transactions,
-		}
+	return ChaseOFXResult{
+		Account:      account,
+		Transactions: parseTransactions(stmt.BankTranList),
+	}
+}
+
+func parseCreditCard(stmt *ofxgo.CCStatementResponse) ChaseOFXResult {
+	current, _ := stmt.BalAmt.Float32()
+	accountId := stmt.CCAcctFrom.AcctID.String()
+	accountType := db.AccountTypeCREDIT
+	caser := cases.Title(language.AmericanEnglish)
+	accountTypeFormatted := caser.String(strings.ToLower(string(accountType)))
+
+	account := ChaseOFXAccount{
+		AccountId:        accountId,
+		IsoCurrencyCode:  stmt.CurDef.String(),
+		Type:             accountType,
+		CurrentBalance:   current,
+		AvailableBalance: parseBalance(stmt.AvailBalAmt),
+		BalanceDate:      parseBalanceDate(stmt.DtAsOf),
+		Name:             fmt.Sprintf("%s Card %s", accountTypeFormatted, lastFour(accountId)),
+	}
+
+	return ChaseOFXResult{
+		Account:      account,
+		Transactions: parseTransactions(stmt.BankTranList),
+	}
+}
+
+func parseTransactions(transactionList *ofxgo.TransactionList) []ChaseOFXTransaction {
+	var transactions []ChaseOFXTransaction
+
+	if transactionList == nil {
+		return transactions
+	}
+
+	for _, tx := range transactionList.Transactions {
+		transactions = append(transactions, parseTransaction(tx))
+	}
+
+	return transactions
+}
+
+func parseTransaction(tx ofxgo.Transaction) ChaseOFXTransaction {
+	amount, _ := tx.TrnAmt.Float32()
+	name := tx.Name.String()
+
+	if tx.Payee != nil {
+		name = tx.Payee.Name.String()
+	}
+
+	return ChaseOFXTransaction{
+		Id:          tx.FiTID.String(),
+		Type:        tx.TrnType.String(),
+		DatePosted:  tx.DtPosted.Time,
+		Amount:      amount,
+		PayeeId:     tx.PayeeID.String(),
+		Payee:       name,
+		PayeeFull:   tx.ExtdName.String(),
+		CheckNumber: tx.CheckNum.String(),
+		Description: getDescription(name, tx.Memo.String()),
+	}
+}
+
+func parseBalance(amount *ofxgo.Amount) *float32 {
+	if amount == nil {
+		return nil
+	}
+
+	balance, _ := amount.Float32()
+	return &balance
+}
+
+// Balances without an as-of date are treated as current
+func parseBalanceDate(date ofxgo.Date) time.Time {
+	if date.IsZero() {
+		return time.Now()
+	}
+
+	return date.Time
+}
 
-		return result, nil
+func lastFour(accountId string) string {
+	if len(accountId) <= 4 {
+		return accountId
 	}
 
-	return nil, fmt.Errorf("ofx: failed to parse credit card data")
+	return accountId[len(accountId)-4:]
 }
 
 func getDescription(name string, memo string) string {
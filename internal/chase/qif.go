@@ -0,0 +1,430 @@
+package chase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/sourceid"
+)
+
+// QIF section headers for the account types we import
+const (
+	QIFTypeBank  = "Bank"
+	QIFTypeCCard = "CCard"
+)
+
+type QIFSplit struct {
+	Category string
+	Memo     string
+	Amount   float32
+}
+
+type QIFTransaction struct {
+	Date        time.Time
+	Amount      float32
+	Payee       string
+	Memo        string
+	CheckNumber string
+	Category    string
+	Cleared     string
+	Address     []string
+	Splits      []QIFSplit
+}
+
+type QIFAccount struct {
+	Name        string
+	Type        string // QIFTypeBank or QIFTypeCCard
+	Description string
+}
+
+type QIFResult struct {
+	Account      QIFAccount
+	Transactions []QIFTransaction
+}
+
+// ParseQIF parses the bank and credit card sections of a QIF file. Files
+// exported with account lists hold one section per !Account block, otherwise
+// each !Type header starts a new section
+func ParseQIF(reader io.Reader) ([]QIFResult, error) {
+	var results []QIFResult
+	var current *QIFResult
+	var account *QIFAccount
+	var tx QIFTransaction
+	var split *QIFSplit
+	hasFields := false
+	section := ""
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		if line[0] == '!' {
+			header := strings.TrimSpace(line[1:])
+
+			switch {
+			case strings.EqualFold(header, "Account"):
+				section = "Account"
+				account = &QIFAccount{}
+			case strings.HasPrefix(strings.ToLower(header), "type:"):
+				section = strings.TrimSpace(header[len("type:"):])
+
+				if !isSupportedQIFType(section) {
+					continue
+				}
+
+				result := QIFResult{Account: QIFAccount{Type: normalizeQIFType(section)}}
+
+				if account != nil {
+					result.Account.Name = account.Name
+					result.Account.Description = account.Description
+				}
+
+				results = append(results, result)
+				current = &results[len(results)-1]
+			default:
+				// Options such as !Option:AutoSwitch hold no records
+				section = ""
+			}
+
+			continue
+		}
+
+		code, value := line[0], strings.TrimSpace(line[1:])
+
+		if section == "Account" {
+			switch code {
+			case 'N':
+				account.Name = value
+			case 'D':
+				account.Description = value
+			}
+
+			continue
+		}
+
+		// Skip records in sections we do not import, e.g. investments
+		if current == nil || !isSupportedQIFType(section) {
+			continue
+		}
+
+		switch code {
+		case 'D':
+			date, err := parseQIFDate(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %w", lineNumber, err)
+			}
+
+			tx.Date = date
+		case 'T', 'U':
+			amount, err := parseQIFAmount(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %w", lineNumber, err)
+			}
+
+			tx.Amount = amount
+		case 'P':
+			tx.Payee = value
+		case 'M':
+			tx.Memo = value
+		case 'N':
+			tx.CheckNumber = value
+		case 'L':
+			tx.Category = value
+		case 'C':
+			tx.Cleared = value
+		case 'A':
+			tx.Address = append(tx.Address, value)
+		case 'S':
+			tx.Splits = append(tx.Splits, QIFSplit{Category: value})
+			split = &tx.Splits[len(tx.Splits)-1]
+		case 'E':
+			if split == nil {
+				return nil, fmt.Errorf("Line %d: split memo without split category", lineNumber)
+			}
+
+			split.Memo = value
+		case '$':
+			if split == nil {
+				return nil, fmt.Errorf("Line %d: split amount without split category", lineNumber)
+			}
+
+			amount, err := parseQIFAmount(value)
+
+			if err != nil {
+				return nil, fmt.Errorf("Line %d: %w", lineNumber, err)
+			}
+
+			split.Amount = amount
+		case '^':
+			if hasFields {
+				if err := validateQIFTransaction(tx); err != nil {
+					return nil, fmt.Errorf("Line %d: %w", lineNumber, err)
+				}
+
+				current.Transactions = append(current.Transactions, tx)
+			}
+
+			tx = QIFTransaction{}
+			split = nil
+			hasFields = false
+			continue
+		}
+
+		hasFields = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read QIF file: %w", err)
+	}
+
+	if hasFields {
+		return nil, fmt.Errorf("Line %d: transaction missing ^ terminator", lineNumber)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("Unsupported QIF file. Supported: !Type:Bank, !Type:CCard")
+	}
+
+	return results, nil
+}
+
+// AccountType maps the QIF section type onto an account type
+func (account QIFAccount) AccountType() db.AccountType {
+	if account.Type == QIFTypeCCard {
+		return db.AccountTypeCREDIT
+	}
+
+	return db.AccountTypeCHECKING
+}
+
+// TransactionType infers a transaction type, as QIF records carry none
+func (tx QIFTransaction) TransactionType() db.TransactionType {
+	if tx.CheckNumber != "" {
+		if _, err := strconv.Atoi(tx.CheckNumber); err == nil {
+			return db.TransactionTypeCHECK
+		}
+	}
+
+	if tx.Amount < 0 {
+		return db.TransactionTypeDEBIT
+	}
+
+	return db.TransactionTypeCREDIT
+}
+
+// Description combines the payee and memo like OFX descriptions
+func (tx QIFTransaction) Description() string {
+	return getDescription(tx.Payee, tx.Memo)
+}
+
+// QIFCategoryName returns a category without its class, e.g. Food:Groceries
+// for Food:Groceries/Vacation. Transfers to other accounts, e.g. [Savings],
+// have no category
+func QIFCategoryName(value string) string {
+	name, _, _ := strings.Cut(value, "/")
+	name = strings.TrimSpace(name)
+
+	if strings.HasPrefix(name, "[") {
+		return ""
+	}
+
+	return name
+}
+
+// QIFSourceIds derives a stable source id for each QIF transaction.
+// Like CSV exports, QIF records carry no ids so they are hashed
+func QIFSourceIds(accountId string, transactions []QIFTransaction) []string {
+	sourceIds := make([]string, len(transactions))
+	hasher := sourceid.NewHasher("QIF")
+
+	for i, tx := range transactions {
+		key := fmt.Sprintf(
+			"%s|%s|%.2f|%s|%s",
+			accountId,
+			tx.Date.Format("2006-01-02"),
+			tx.Amount,
+			strings.ToUpper(strings.TrimSpace(tx.Description())),
+			tx.CheckNumber,
+		)
+		sourceIds[i] = hasher.Id(key)
+	}
+
+	return sourceIds
+}
+
+// WriteQIF writes transactions as a single QIF section for the account type
+func WriteQIF(writer io.Writer, accountType db.AccountType, transactions []QIFTransaction) error {
+	qifType := QIFTypeBank
+
+	if accountType == db.AccountTypeCREDIT || accountType == db.AccountTypeCREDITLINE {
+		qifType = QIFTypeCCard
+	}
+
+	w := bufio.NewWriter(writer)
+	fmt.Fprintf(w, "!Type:%s\n", qifType)
+
+	for _, tx := range transactions {
+		fmt.Fprintf(w, "D%s\n", tx.Date.Format("01/02/2006"))
+		fmt.Fprintf(w, "T%.2f\n", tx.Amount)
+		writeQIFField(w, 'C', tx.Cleared)
+		writeQIFField(w, 'N', tx.CheckNumber)
+		writeQIFField(w, 'P', tx.Payee)
+		writeQIFField(w, 'M', tx.Memo)
+		writeQIFField(w, 'L', tx.Category)
+
+		for _, line := range tx.Address {
+			writeQIFField(w, 'A', line)
+		}
+
+		for _, split := range tx.Splits {
+			fmt.Fprintf(w, "S%s\n", qifLine(split.Category))
+			writeQIFField(w, 'E', split.Memo)
+			fmt.Fprintf(w, "$%.2f\n", split.Amount)
+		}
+
+		fmt.Fprintln(w, "^")
+	}
+
+	return w.Flush()
+}
+
+func writeQIFField(w io.Writer, code byte, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "%c%s\n", code, qifLine(value))
+	}
+}
+
+// QIF fields are line based, so values cannot span lines
+func qifLine(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
+
+func isSupportedQIFType(qifType string) bool {
+	return strings.EqualFold(qifType, QIFTypeBank) || strings.EqualFold(qifType, QIFTypeCCard)
+}
+
+func normalizeQIFType(qifType string) string {
+	if strings.EqualFold(qifType, QIFTypeCCard) {
+		return QIFTypeCCard
+	}
+
+	return QIFTypeBank
+}
+
+func validateQIFTransaction(tx QIFTransaction) error {
+	if tx.Date.IsZero() {
+		return fmt.Errorf("transaction missing date")
+	}
+
+	if len(tx.Splits) == 0 {
+		return nil
+	}
+
+	var total float32
+
+	for _, split := range tx.Splits {
+		total += split.Amount
+	}
+
+	// Compare in cents to ignore float rounding
+	if int(total*100+0.5*sign(total)) != int(tx.Amount*100+0.5*sign(tx.Amount)) {
+		return fmt.Errorf("splits total %.2f does not match transaction amount %.2f", total, tx.Amount)
+	}
+
+	return nil
+}
+
+func sign(value float32) float32 {
+	if value < 0 {
+		return -1
+	}
+
+	return 1
+}
+
+// Parses QIF dates. Quicken writes US month/day order with 2 or 4 digit
+// years, using an apostrophe before the year for 2000 onwards, e.g. 1/15'24
+func parseQIFDate(value string) (time.Time, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	century := 0
+
+	if i := strings.Index(value, "'"); i >= 0 {
+		value = value[:i] + "/" + value[i+1:]
+		century = 2000
+	}
+
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.'
+	})
+
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	numbers := make([]int, 3)
+
+	for i, part := range parts {
+		number, err := strconv.Atoi(part)
+
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+
+		numbers[i] = number
+	}
+
+	month, day, year := numbers[0], numbers[1], numbers[2]
+
+	// ISO dates, e.g. 2024-01-15
+	if len(parts[0]) == 4 {
+		year, month, day = numbers[0], numbers[1], numbers[2]
+	}
+
+	if year < 100 {
+		switch {
+		case century > 0:
+			year += century
+		case year < 70:
+			year += 2000
+		default:
+			year += 1900
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+
+	if date.Month() != time.Month(month) || date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	return date, nil
+}
+
+func parseQIFAmount(value string) (float32, error) {
+	cleaned := strings.NewReplacer(",", "", "$", "", " ", "").Replace(value)
+	amount, err := strconv.ParseFloat(cleaned, 32)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	return float32(amount), nil
+}
@@ -0,0 +1,81 @@
+package chase
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+func TestWriteQIF(t *testing.T) {
+	transactions := []QIFTransaction{
+		{
+			Date:     time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+			Amount:   -4.5,
+			Payee:    "BLUE BOTTLE COFFEE",
+			Category: "Food:Coffee",
+		},
+		{
+			Date:     time.Date(2024, time.January, 20, 0, 0, 0, 0, time.UTC),
+			Amount:   -120,
+			Payee:    "COSTCO",
+			Memo:     "Monthly\nshop",
+			Category: "Food:Groceries",
+			Splits: []QIFSplit{
+				{Category: "Food:Groceries", Memo: "Weekly shop", Amount: -80},
+				{Amount: -40},
+			},
+		},
+	}
+
+	var export strings.Builder
+
+	if err := WriteQIF(&export, db.AccountTypeCHECKING, transactions); err != nil {
+		t.Fatalf("WriteQIF() error = %v", err)
+	}
+
+	want := "!Type:Bank\n" +
+		"D01/15/2024\nT-4.50\nPBLUE BOTTLE COFFEE\nLFood:Coffee\n^\n" +
+		"D01/20/2024\nT-120.00\nPCOSTCO\nMMonthly shop\nLFood:Groceries\nSFood:Groceries\nEWeekly shop\n$-80.00\nS\n$-40.00\n^\n"
+
+	if export.String() != want {
+		t.Fatalf("WriteQIF() = %q, want %q", export.String(), want)
+	}
+
+	results, err := ParseQIF(strings.NewReader(export.String()))
+
+	if err != nil {
+		t.Fatalf("ParseQIF() error = %v", err)
+	}
+
+	// Lines can't hold new lines, so the memo is written on one line
+	transactions[1].Memo = "Monthly shop"
+
+	if len(results) != 1 || !reflect.DeepEqual(results[0].Transactions, transactions) {
+		t.Errorf("ParseQIF() = %+v, want %+v", results, transactions)
+	}
+}
+
+func TestQIFCategoryName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "Food:Groceries", want: "Food:Groceries"},
+		{value: "Food:Groceries/Vacation", want: "Food:Groceries"},
+		{value: " Household ", want: "Household"},
+		{value: "[Savings]", want: ""},
+		{value: "[Savings]/Vacation", want: ""},
+		{value: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := QIFCategoryName(tt.value); got != tt.want {
+				t.Errorf("QIFCategoryName(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
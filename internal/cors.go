@@ -5,12 +5,15 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Origins allowed to make credentialed requests to the API
+var AllowedOrigins = []string{
+	"http://localhost:5173",
+}
+
 func Cors() gin.HandlerFunc {
 	corsConfig := cors.DefaultConfig()
 
-	corsConfig.AllowOrigins = []string{
-		"http://localhost:5173",
-	}
+	corsConfig.AllowOrigins = AllowedOrigins
 
 	corsConfig.AllowCredentials = true
 
@@ -0,0 +1,433 @@
+package csvimport
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/sourceid"
+)
+
+const DefaultDateFormat = "MM/DD/YYYY"
+
+// ChaseProfile is the built-in profile for Chase checking and savings exports
+var ChaseProfile = db.CsvProfile{
+	Name:              "Chase",
+	Institution:       sql.NullString{String: "Chase", Valid: true},
+	Datecolumn:        "Posting Date",
+	Descriptioncolumn: "Description",
+	Amountcolumn:      sql.NullString{String: "Amount", Valid: true},
+	Typecolumn:        sql.NullString{String: "Type", Valid: true},
+	Checknumbercolumn: sql.NullString{String: "Check or Slip #", Valid: true},
+	Balancecolumn:     sql.NullString{String: "Balance", Valid: true},
+	Dateformat:        DefaultDateFormat,
+	Signconvention:    db.SignConventionDEBITSNEGATIVE,
+	Hasheader:         true,
+}
+
+type Transaction struct {
+	Date        time.Time
+	Description string
+	Amount      float32
+	Type        db.TransactionType
+	CheckNumber string
+	// Running balance after the transaction, for profiles with a balance column
+	Balance *float32
+}
+
+var transactionTypes = map[string]db.TransactionType{
+	"CREDIT":      db.TransactionTypeCREDIT,
+	"DEBIT":       db.TransactionTypeDEBIT,
+	"INT":         db.TransactionTypeINT,
+	"INTEREST":    db.TransactionTypeINT,
+	"DIV":         db.TransactionTypeDIV,
+	"DIVIDEND":    db.TransactionTypeDIV,
+	"FEE":         db.TransactionTypeFEE,
+	"SRVCHG":      db.TransactionTypeSRVCHG,
+	"DEP":         db.TransactionTypeDEP,
+	"DEPOSIT":     db.TransactionTypeDEP,
+	"ATM":         db.TransactionTypeATM,
+	"POS":         db.TransactionTypePOS,
+	"XFER":        db.TransactionTypeXFER,
+	"TRANSFER":    db.TransactionTypeXFER,
+	"CHECK":       db.TransactionTypeCHECK,
+	"PAYMENT":     db.TransactionTypePAYMENT,
+	"CASH":        db.TransactionTypeCASH,
+	"DIRECTDEP":   db.TransactionTypeDIRECTDEP,
+	"DIRECTDEBIT": db.TransactionTypeDIRECTDEBIT,
+	"REPEATPMT":   db.TransactionTypeREPEATPMT,
+	"OTHER":       db.TransactionTypeOTHER,
+	// Chase transaction types
+	"ACH_CREDIT":         db.TransactionTypeDIRECTDEP,
+	"ACH_DEBIT":          db.TransactionTypeDIRECTDEBIT,
+	"ACCT_XFER":          db.TransactionTypeXFER,
+	"ATM_DEPOSIT":        db.TransactionTypeATM,
+	"BILLPAY":            db.TransactionTypePAYMENT,
+	"CHASE_TO_PARTNERFI": db.TransactionTypeXFER,
+	"CHECK_DEPOSIT":      db.TransactionTypeDEP,
+	"CHECK_PAID":         db.TransactionTypeCHECK,
+	"DEBIT_CARD":         db.TransactionTypePOS,
+	"FEE_TRANSACTION":    db.TransactionTypeFEE,
+	"LOAN_PMT":           db.TransactionTypePAYMENT,
+	"MISC_CREDIT":        db.TransactionTypeCREDIT,
+	"MISC_DEBIT":         db.TransactionTypeDEBIT,
+	"PARTNERFI_TO_CHASE": db.TransactionTypeXFER,
+	"QUICKPAY_CREDIT":    db.TransactionTypeXFER,
+	"QUICKPAY_DEBIT":     db.TransactionTypeXFER,
+	"REFUND_TRANSACTION": db.TransactionTypeCREDIT,
+	"WIRE_INCOMING":      db.TransactionTypeXFER,
+	"WIRE_OUTGOING":      db.TransactionTypeXFER,
+}
+
+// Date format tokens in the order they must be replaced
+var dateTokens = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"DD", "02"},
+	{"D", "2"},
+}
+
+// ValidateProfile checks a profile has everything needed to read a file
+func ValidateProfile(profile db.CsvProfile) error {
+	if strings.TrimSpace(profile.Datecolumn) == "" {
+		return fmt.Errorf("Date column is required")
+	}
+
+	if strings.TrimSpace(profile.Descriptioncolumn) == "" {
+		return fmt.Errorf("Description column is required")
+	}
+
+	if !profile.Amountcolumn.Valid && !profile.Debitcolumn.Valid && !profile.Creditcolumn.Valid {
+		return fmt.Errorf("An amount column or debit/credit columns are required")
+	}
+
+	if profile.Signconvention != db.SignConventionDEBITSNEGATIVE &&
+		profile.Signconvention != db.SignConventionDEBITSPOSITIVE {
+		return fmt.Errorf("Invalid sign convention: %s", profile.Signconvention)
+	}
+
+	if profile.Skiprows < 0 {
+		return fmt.Errorf("Skip rows must not be negative")
+	}
+
+	if !profile.Hasheader {
+		for _, column := range profileColumns(profile) {
+			if _, err := strconv.Atoi(column); err != nil {
+				return fmt.Errorf("Columns must be indexes when the file has no header: %s", column)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Parse reads transactions from a CSV file using a column-mapping profile
+func Parse(reader io.Reader, profile db.CsvProfile) ([]Transaction, error) {
+	if err := ValidateProfile(profile); err != nil {
+		return nil, err
+	}
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	records, err := r.ReadAll()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read CSV file: %w", err)
+	}
+
+	if int(profile.Skiprows) >= len(records) {
+		return []Transaction{}, nil
+	}
+
+	records = records[profile.Skiprows:]
+	header := map[string]int{}
+
+	if profile.Hasheader {
+		for i, name := range records[0] {
+			header[normalizeColumn(name)] = i
+		}
+		records = records[1:]
+	}
+
+	columns, err := newColumns(profile, header)
+
+	if err != nil {
+		return nil, err
+	}
+
+	layout := DateLayout(profile.Dateformat)
+	transactions := []Transaction{}
+
+	for i, record := range records {
+		row := i + int(profile.Skiprows) + 1
+
+		if profile.Hasheader {
+			row++
+		}
+
+		// Skip blank lines and summary rows without a date
+		dateValue := strings.TrimSpace(columns.date.value(record))
+
+		if dateValue == "" {
+			continue
+		}
+
+		date, err := time.Parse(layout, dateValue)
+
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: invalid date %q, expected %s", row, dateValue, profile.Dateformat)
+		}
+
+		amount, err := columns.amount(record, profile.Signconvention)
+
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %w", row, err)
+		}
+
+		balance, err := columns.runningBalance(record)
+
+		if err != nil {
+			return nil, fmt.Errorf("Row %d: %w", row, err)
+		}
+
+		transactions = append(transactions, Transaction{
+			Date:        date,
+			Description: strings.TrimSpace(columns.description.value(record)),
+			Amount:      amount,
+			Type:        parseType(columns.transactionType.value(record), amount),
+			CheckNumber: strings.TrimSpace(columns.checkNumber.value(record)),
+			Balance:     balance,
+		})
+	}
+
+	return transactions, nil
+}
+
+// DateLayout converts a profile date format like MM/DD/YYYY into a Go layout
+func DateLayout(format string) string {
+	if format == "" {
+		format = DefaultDateFormat
+	}
+
+	layout := format
+
+	for _, t := range dateTokens {
+		layout = strings.ReplaceAll(layout, t.token, "\x00"+t.layout+"\x00")
+	}
+
+	return strings.ReplaceAll(layout, "\x00", "")
+}
+
+// LatestBalance returns the transaction holding the most recent running
+// balance. Banks often list the newest transactions first, so ties keep the
+// earliest row
+func LatestBalance(transactions []Transaction) (*Transaction, bool) {
+	var latest *Transaction
+
+	for i, tx := range transactions {
+		if tx.Balance != nil && (latest == nil || tx.Date.After(latest.Date)) {
+			latest = &transactions[i]
+		}
+	}
+
+	return latest, latest != nil
+}
+
+// SourceIds derives a stable source id for each transaction. Like Chase CSV
+// exports, generic CSV files carry no transaction ids, so ids are a hash of
+// the account and row contents, with repeated rows told apart by order.
+func SourceIds(accountId string, transactions []Transaction) []string {
+	sourceIds := make([]string, len(transactions))
+	hasher := sourceid.NewHasher("CSV")
+
+	for i, tx := range transactions {
+		key := fmt.Sprintf(
+			"%s|%s|%.2f|%s",
+			accountId,
+			tx.Date.Format("2006-01-02"),
+			tx.Amount,
+			strings.ToUpper(tx.Description),
+		)
+		sourceIds[i] = hasher.Id(key)
+	}
+
+	return sourceIds
+}
+
+type column struct {
+	index int
+}
+
+func (c column) value(record []string) string {
+	if c.index < 0 || c.index >= len(record) {
+		return ""
+	}
+
+	return record[c.index]
+}
+
+type columns struct {
+	date            column
+	description     column
+	amountColumn    column
+	debit           column
+	credit          column
+	transactionType column
+	checkNumber     column
+	balance         column
+}
+
+func newColumns(profile db.CsvProfile, header map[string]int) (*columns, error) {
+	var err error
+	result := &columns{}
+	lookup := func(name string, required bool) column {
+		if err != nil || (!required && name == "") {
+			return column{index: -1}
+		}
+
+		var c column
+		c, err = resolveColumn(name, header)
+		return c
+	}
+
+	result.date = lookup(profile.Datecolumn, true)
+	result.description = lookup(profile.Descriptioncolumn, true)
+	result.amountColumn = lookup(profile.Amountcolumn.String, false)
+	result.debit = lookup(profile.Debitcolumn.String, false)
+	result.credit = lookup(profile.Creditcolumn.String, false)
+	result.transactionType = lookup(profile.Typecolumn.String, false)
+	result.checkNumber = lookup(profile.Checknumbercolumn.String, false)
+	result.balance = lookup(profile.Balancecolumn.String, false)
+
+	return result, err
+}
+
+func (c *columns) amount(record []string, convention db.SignConvention) (float32, error) {
+	if c.amountColumn.index >= 0 {
+		amount, err := parseAmount(c.amountColumn.value(record))
+
+		if err != nil {
+			return 0, err
+		}
+
+		if convention == db.SignConventionDEBITSPOSITIVE {
+			amount = -amount
+		}
+
+		return amount, nil
+	}
+
+	// Split columns hold unsigned values, debits are money out
+	debit, err := parseAmount(c.debit.value(record))
+
+	if err != nil {
+		return 0, err
+	}
+
+	credit, err := parseAmount(c.credit.value(record))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return float32(math.Abs(float64(credit)) - math.Abs(float64(debit))), nil
+}
+
+func (c *columns) runningBalance(record []string) (*float32, error) {
+	value := c.balance.value(record)
+
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	balance, err := parseAmount(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &balance, nil
+}
+
+func resolveColumn(name string, header map[string]int) (column, error) {
+	if index, err := strconv.Atoi(strings.TrimSpace(name)); err == nil {
+		return column{index: index}, nil
+	}
+
+	if index, ok := header[normalizeColumn(name)]; ok {
+		return column{index: index}, nil
+	}
+
+	return column{}, fmt.Errorf("Column not found in file: %s", name)
+}
+
+func parseAmount(value string) (float32, error) {
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return 0, nil
+	}
+
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	value = strings.NewReplacer("(", "", ")", "", "$", "", ",", "", " ", "").Replace(value)
+	amount, err := strconv.ParseFloat(value, 32)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return float32(amount), nil
+}
+
+func parseType(value string, amount float32) db.TransactionType {
+	if transactionType, ok := transactionTypes[strings.ToUpper(strings.TrimSpace(value))]; ok {
+		return transactionType
+	}
+
+	if amount < 0 {
+		return db.TransactionTypeDEBIT
+	}
+
+	return db.TransactionTypeCREDIT
+}
+
+func normalizeColumn(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
+func profileColumns(profile db.CsvProfile) []string {
+	columns := []string{profile.Datecolumn, profile.Descriptioncolumn}
+
+	for _, column := range []sql.NullString{
+		profile.Amountcolumn,
+		profile.Debitcolumn,
+		profile.Creditcolumn,
+		profile.Typecolumn,
+		profile.Checknumbercolumn,
+		profile.Balancecolumn,
+	} {
+		if column.Valid {
+			columns = append(columns, column.String)
+		}
+	}
+
+	return columns
+}
@@ -0,0 +1,344 @@
+package csvimport
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/testutil"
+)
+
+func nullColumn(name string) sql.NullString {
+	return sql.NullString{String: name, Valid: true}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile db.CsvProfile
+		file    string
+		want    []Transaction
+		wantErr string
+	}{
+		{
+			name: "signed amount column",
+			profile: db.CsvProfile{
+				Datecolumn:        "Date",
+				Descriptioncolumn: "Description",
+				Amountcolumn:      nullColumn("Amount"),
+				Dateformat:        DefaultDateFormat,
+				Signconvention:    db.SignConventionDEBITSNEGATIVE,
+				Hasheader:         true,
+			},
+			file: "Date,Description,Amount\n01/15/2024, COFFEE SHOP ,-4.50\n01/16/2024,PAYROLL,\"$1,200.00\"\n",
+			want: []Transaction{
+				{Date: testutil.Date("2024-01-15"), Description: "COFFEE SHOP", Amount: -4.5, Type: db.TransactionTypeDEBIT},
+				{Date: testutil.Date("2024-01-16"), Description: "PAYROLL", Amount: 1200, Type: db.TransactionTypeCREDIT},
+			},
+		},
+		{
+			name: "debits positive",
+			profile: db.CsvProfile{
+				Datecolumn:        "Date",
+				Descriptioncolumn: "Description",
+				Amountcolumn:      nullColumn("Amount"),
+				Dateformat:        "YYYY-MM-DD",
+				Signconvention:    db.SignConventionDEBITSPOSITIVE,
+				Hasheader:         true,
+			},
+			file: "Date,Description,Amount\n2024-01-15,GROCERIES,25.10\n2024-01-16,REFUND,(5.00)\n",
+			want: []Transaction{
+				{Date: testutil.Date("2024-01-15"), Description: "GROCERIES", Amount: -25.1, Type: db.TransactionTypeDEBIT},
+				{Date: testutil.Date("2024-01-16"), Description: "REFUND", Amount: 5, Type: db.TransactionTypeCREDIT},
+			},
+		},
+		{
+			name: "debit and credit columns",
+			profile: db.CsvProfile{
+				Datecolumn:        "Date",
+				Descriptioncolumn: "Memo",
+				Debitcolumn:       nullColumn("Debit"),
+				Creditcolumn:      nullColumn("Credit"),
+				Typecolumn:        nullColumn("Type"),
+				Dateformat:        "DD/MM/YYYY",
+				Signconvention:    db.SignConventionDEBITSNEGATIVE,
+				Hasheader:         true,
+			},
+			file: "Date,Memo,Debit,Credit,Type\n15/01/2024,ATM WITHDRAWAL,40.00,,atm\n16/01/2024,INTEREST,,0.12,INT\n",
+			want: []Transaction{
+				{Date: testutil.Date("2024-01-15"), Description: "ATM WITHDRAWAL", Amount: -40, Type: db.TransactionTypeATM},
+				{Date: testutil.Date("2024-01-16"), Description: "INTEREST", Amount: 0.12, Type: db.TransactionTypeINT},
+			},
+		},
+		{
+			name: "indexed columns without a header",
+			profile: db.CsvProfile{
+				Datecolumn:        "0",
+				Descriptioncolumn: "2",
+				Amountcolumn:      nullColumn("1"),
+				Dateformat:        DefaultDateFormat,
+				Signconvention:    db.SignConventionDEBITSNEGATIVE,
+				Skiprows:          1,
+				Hasheader:         false,
+			},
+			file: "Exported transactions\n01/15/2024,-9.99,STREAMING\n\n01/17/2024,-3.00,PARKING\n",
+			want: []Transaction{
+				{Date: testutil.Date("2024-01-15"), Description: "STREAMING", Amount: -9.99, Type: db.TransactionTypeDEBIT},
+				{Date: testutil.Date("2024-01-17"), Description: "PARKING", Amount: -3, Type: db.TransactionTypeDEBIT},
+			},
+		},
+		{
+			name:    "chase export",
+			profile: ChaseProfile,
+			file: "Details,Posting Date,Description,Amount,Type,Balance,Check or Slip #\n" +
+				"DEBIT,01/16/2024,CARD PURCHASE,-12.34,DEBIT_CARD,987.66,,\n" +
+				"CHECK,01/15/2024,CHECK 101,-100.00,CHECK_PAID,,101,\n",
+			want: []Transaction{
+				{Date: testutil.Date("2024-01-16"), Description: "CARD PURCHASE", Amount: -12.34, Type: db.TransactionTypePOS, Balance: ptr(float32(987.66))},
+				{Date: testutil.Date("2024-01-15"), Description: "CHECK 101", Amount: -100, Type: db.TransactionTypeCHECK, CheckNumber: "101"},
+			},
+		},
+		{
+			name: "missing column",
+			profile: db.CsvProfile{
+				Datecolumn:        "Date",
+				Descriptioncolumn: "Payee",
+				Amountcolumn:      nullColumn("Amount"),
+				Signconvention:    db.SignConventionDEBITSNEGATIVE,
+				Hasheader:         true,
+			},
+			file:    "Date,Description,Amount\n01/15/2024,COFFEE,-4.50\n",
+			wantErr: "Column not found in file: Payee",
+		},
+		{
+			name: "invalid date",
+			profile: db.CsvProfile{
+				Datecolumn:        "Date",
+				Descriptioncolumn: "Description",
+				Amountcolumn:      nullColumn("Amount"),
+				Signconvention:    db.SignConventionDEBITSNEGATIVE,
+				Hasheader:         true,
+			},
+			file:    "Date,Description,Amount\n2024-01-15,COFFEE,-4.50\n",
+			wantErr: "Row 2: invalid date",
+		},
+		{
+			name: "invalid amount",
+			profile: db.CsvProfile{
+				Datecolumn:        "Date",
+				Descriptioncolumn: "Description",
+				Amountcolumn:      nullColumn("Amount"),
+				Signconvention:    db.SignConventionDEBITSNEGATIVE,
+				Hasheader:         true,
+			},
+			file:    "Date,Description,Amount\n01/15/2024,COFFEE,four\n",
+			wantErr: "Row 2: invalid amount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.file), tt.profile)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() returned %d transactions, want %d", len(got), len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				if !reflect.DeepEqual(got[i], want) {
+					t.Errorf("Parse()[%d] = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	valid := db.CsvProfile{
+		Datecolumn:        "Date",
+		Descriptioncolumn: "Description",
+		Amountcolumn:      nullColumn("Amount"),
+		Signconvention:    db.SignConventionDEBITSNEGATIVE,
+		Hasheader:         true,
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(profile *db.CsvProfile)
+		wantErr string
+	}{
+		{
+			name: "valid",
+			edit: func(profile *db.CsvProfile) {},
+		},
+		{
+			name: "debit and credit columns",
+			edit: func(profile *db.CsvProfile) {
+				profile.Amountcolumn = sql.NullString{}
+				profile.Debitcolumn = nullColumn("Debit")
+				profile.Creditcolumn = nullColumn("Credit")
+			},
+		},
+		{
+			name:    "missing date column",
+			edit:    func(profile *db.CsvProfile) { profile.Datecolumn = " " },
+			wantErr: "Date column is required",
+		},
+		{
+			name:    "missing description column",
+			edit:    func(profile *db.CsvProfile) { profile.Descriptioncolumn = "" },
+			wantErr: "Description column is required",
+		},
+		{
+			name:    "missing amount columns",
+			edit:    func(profile *db.CsvProfile) { profile.Amountcolumn = sql.NullString{} },
+			wantErr: "An amount column or debit/credit columns are required",
+		},
+		{
+			name:    "invalid sign convention",
+			edit:    func(profile *db.CsvProfile) { profile.Signconvention = "SIDEWAYS" },
+			wantErr: "Invalid sign convention",
+		},
+		{
+			name:    "negative skip rows",
+			edit:    func(profile *db.CsvProfile) { profile.Skiprows = -1 },
+			wantErr: "Skip rows must not be negative",
+		},
+		{
+			name:    "named columns without a header",
+			edit:    func(profile *db.CsvProfile) { profile.Hasheader = false },
+			wantErr: "Columns must be indexes when the file has no header",
+		},
+		{
+			name: "chase profile",
+			edit: func(profile *db.CsvProfile) { *profile = ChaseProfile },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := valid
+			tt.edit(&profile)
+			err := ValidateProfile(profile)
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ValidateProfile() error = %v", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ValidateProfile() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "01/02/2006"},
+		{"MM/DD/YYYY", "01/02/2006"},
+		{"DD.MM.YY", "02.01.06"},
+		{"YYYY-MM-DD", "2006-01-02"},
+		{"D MMM YYYY", "2 Jan 2006"},
+		{"M/D/YYYY", "1/2/2006"},
+	}
+
+	for _, tt := range tests {
+		if got := DateLayout(tt.format); got != tt.want {
+			t.Errorf("DateLayout(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestSourceIds(t *testing.T) {
+	coffee := Transaction{Date: testutil.Date("2024-01-15"), Description: "Coffee", Amount: -4.5}
+	ids := SourceIds("1234", []Transaction{coffee, coffee})
+
+	if ids[0] == ids[1] {
+		t.Errorf("SourceIds() gave repeated rows the same id %s", ids[0])
+	}
+
+	upper := coffee
+	upper.Description = "COFFEE"
+
+	if other := SourceIds("1234", []Transaction{upper}); other[0] != ids[0] {
+		t.Errorf("SourceIds() = %s for an uppercase description, want %s", other[0], ids[0])
+	}
+
+	if again := SourceIds("1234", []Transaction{coffee}); again[0] != ids[0] {
+		t.Errorf("SourceIds() = %s on a second import, want %s", again[0], ids[0])
+	}
+
+	if other := SourceIds("5678", []Transaction{coffee}); other[0] == ids[0] {
+		t.Errorf("SourceIds() gave the same id to different accounts")
+	}
+}
+
+func TestLatestBalance(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []Transaction
+		want         int
+	}{
+		{
+			name:         "no balances",
+			transactions: []Transaction{{Date: testutil.Date("2024-01-15")}},
+			want:         -1,
+		},
+		{
+			name: "newest date",
+			transactions: []Transaction{
+				{Date: testutil.Date("2024-01-15"), Balance: ptr(float32(10))},
+				{Date: testutil.Date("2024-01-17"), Balance: ptr(float32(20))},
+				{Date: testutil.Date("2024-01-18")},
+			},
+			want: 1,
+		},
+		{
+			name: "ties keep the first row",
+			transactions: []Transaction{
+				{Date: testutil.Date("2024-01-17"), Balance: ptr(float32(20))},
+				{Date: testutil.Date("2024-01-17"), Balance: ptr(float32(30))},
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LatestBalance(tt.transactions)
+
+			if tt.want == -1 {
+				if ok {
+					t.Fatalf("LatestBalance() = %+v, want none", got)
+				}
+
+				return
+			}
+
+			if !ok || got != &tt.transactions[tt.want] {
+				t.Fatalf("LatestBalance() = %+v, want row %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -3,13 +3,18 @@ package dataloaders
 //go:generate go run github.com/vektah/dataloaden TransactionLoader string []github.com/proctorinc/banker/internal/db.Transaction
 //go:generate go run github.com/vektah/dataloaden TransactionCountLoader string int64
 //go:generate go run github.com/vektah/dataloaden MerchantLoader string github.com/proctorinc/banker/internal/db.Merchant
+//go:generate go run github.com/vektah/dataloaden GlobalMerchantLoader string github.com/proctorinc/banker/internal/db.GlobalMerchant
 //go:generate go run github.com/vektah/dataloaden FundAllocationLoader string []github.com/proctorinc/banker/internal/db.FundAllocation
 //go:generate go run github.com/vektah/dataloaden FundAllocationCountLoader string int64
+//go:generate go run github.com/vektah/dataloaden TransactionSplitLoader string []github.com/proctorinc/banker/internal/db.TransactionSplit
+//go:generate go run github.com/vektah/dataloaden TagLoader string []github.com/proctorinc/banker/internal/db.Tag
+//go:generate go run github.com/vektah/dataloaden AccountBalanceLoader string *github.com/proctorinc/banker/internal/db.AccountBalance
 
 import (
 	"context"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/proctorinc/banker/internal/db"
 )
 
@@ -19,30 +24,53 @@ const key = contextKey("dataloaders")
 
 // Loaders holds references to the individual dataloaders.
 type Loaders struct {
-	TransactionsByAccountId       func(limit int32, start int32) *TransactionLoader
-	TransactionsByMerchantId      func(limit int32, start int32) *TransactionLoader
-	CountTransactionsByAccountId  *TransactionCountLoader
-	CountTransactionsByMerchantId *TransactionCountLoader
+	TransactionsByAccountId       func(limit int32, start int32, includePending bool) *TransactionLoader
+	TransactionsByMerchantId      func(limit int32, start int32, includePending bool) *TransactionLoader
+	CountTransactionsByAccountId  func(includePending bool) *TransactionCountLoader
+	CountTransactionsByMerchantId func(includePending bool) *TransactionCountLoader
 	MerchantByTransactionId       *MerchantLoader
+	GlobalMerchantById            *GlobalMerchantLoader
 	FundAllocationsByFundId       func(limit int32, start int32) *FundAllocationLoader
 	CountFundAllocationsByFundId  *FundAllocationCountLoader
+	SplitsByTransactionId         *TransactionSplitLoader
+	TagsByTransactionId           *TagLoader
+	LatestBalanceByAccountId      *AccountBalanceLoader
 }
 
-func newLoaders(ctx context.Context, repo db.Repository) *Loaders {
+func newLoaders(ctx context.Context, repo db.Repository, userId uuid.UUID) *Loaders {
+	// Counts are batched across every account or merchant in the request,
+	// so there is one loader for each includePending value
+	countByAccountId := map[bool]*TransactionCountLoader{
+		true:  newCountTransactionsByAccountIdLoader(ctx, repo, true),
+		false: newCountTransactionsByAccountIdLoader(ctx, repo, false),
+	}
+	countByMerchantId := map[bool]*TransactionCountLoader{
+		true:  newCountTransactionsByMerchantIdLoader(ctx, repo, userId, true),
+		false: newCountTransactionsByMerchantIdLoader(ctx, repo, userId, false),
+	}
+
 	return &Loaders{
-		TransactionsByAccountId: func(limit int32, start int32) *TransactionLoader {
-			return newTransactionsByAccountIdLoader(ctx, repo, limit, start)
+		TransactionsByAccountId: func(limit int32, start int32, includePending bool) *TransactionLoader {
+			return newTransactionsByAccountIdLoader(ctx, repo, limit, start, includePending)
+		},
+		TransactionsByMerchantId: func(limit int32, start int32, includePending bool) *TransactionLoader {
+			return newTransactionsByMerchantIdLoader(ctx, repo, userId, limit, start, includePending)
 		},
-		TransactionsByMerchantId: func(limit int32, start int32) *TransactionLoader {
-			return newTransactionsByMerchantIdLoader(ctx, repo, limit, start)
+		CountTransactionsByAccountId: func(includePending bool) *TransactionCountLoader {
+			return countByAccountId[includePending]
 		},
-		CountTransactionsByAccountId:  newCountTransactionsByAccountIdLoader(ctx, repo),
-		CountTransactionsByMerchantId: newCountTransactionsByMerchantIdLoader(ctx, repo),
-		MerchantByTransactionId:       newMerchantLoader(ctx, repo),
+		CountTransactionsByMerchantId: func(includePending bool) *TransactionCountLoader {
+			return countByMerchantId[includePending]
+		},
+		MerchantByTransactionId: newMerchantLoader(ctx, repo, userId),
+		GlobalMerchantById:      newGlobalMerchantLoader(ctx, repo),
 		FundAllocationsByFundId: func(limit int32, start int32) *FundAllocationLoader {
 			return newFundAllocationsByFundIdLoader(ctx, repo, limit, start)
 		},
 		CountFundAllocationsByFundId: newCountFundAllocationsByFundIdLoader(ctx, repo),
+		SplitsByTransactionId:        newSplitsByTransactionIdLoader(ctx, repo, userId),
+		TagsByTransactionId:          newTagsByTransactionIdLoader(ctx, repo, userId),
+		LatestBalanceByAccountId:     newLatestBalanceByAccountIdLoader(ctx, repo, userId),
 	}
 }
 
@@ -62,15 +90,16 @@ func NewRetriever() Retriever {
 	return &retriever{key: key}
 }
 
-func newTransactionsByAccountIdLoader(ctx context.Context, repo db.Repository, limit int32, start int32) *TransactionLoader {
+func newTransactionsByAccountIdLoader(ctx context.Context, repo db.Repository, limit int32, start int32, includePending bool) *TransactionLoader {
 	return NewTransactionLoader(TransactionLoaderConfig{
 		MaxBatch: 100,
 		Wait:     5 * time.Millisecond,
 		Fetch: func(accountIds []string) ([][]db.Transaction, []error) {
 			res, err := repo.ListTransactionsByAccountIds(ctx, db.ListTransactionsByAccountIdsParams{
-				Accountids: accountIds,
-				Limit:      limit * int32(len(accountIds)), // Query for up to the combined limit
-				Start:      start,
+				Accountids:     accountIds,
+				Limit:          limit * int32(len(accountIds)), // Query for up to the combined limit
+				Includepending: includePending,
+				Start:          start,
 			})
 
 			if err != nil {
@@ -99,15 +128,17 @@ func newTransactionsByAccountIdLoader(ctx context.Context, repo db.Repository, l
 	})
 }
 
-func newTransactionsByMerchantIdLoader(ctx context.Context, repo db.Repository, limit int32, start int32) *TransactionLoader {
+func newTransactionsByMerchantIdLoader(ctx context.Context, repo db.Repository, userId uuid.UUID, limit int32, start int32, includePending bool) *TransactionLoader {
 	return NewTransactionLoader(TransactionLoaderConfig{
 		MaxBatch: 100,
 		Wait:     5 * time.Millisecond,
 		Fetch: func(merchantIds []string) ([][]db.Transaction, []error) {
 			res, err := repo.ListTransactionsByMerchantIds(ctx, db.ListTransactionsByMerchantIdsParams{
-				Merchantids: merchantIds,
-				Limit:       limit,
-				Start:       start,
+				Ownerid:        userId,
+				Merchantids:    merchantIds,
+				Limit:          limit,
+				Includepending: includePending,
+				Start:          start,
 			})
 
 			if err != nil {
@@ -131,21 +162,31 @@ func newTransactionsByMerchantIdLoader(ctx context.Context, repo db.Repository,
 	})
 }
 
-func newCountTransactionsByAccountIdLoader(ctx context.Context, repo db.Repository) *TransactionCountLoader {
+func newCountTransactionsByAccountIdLoader(ctx context.Context, repo db.Repository, includePending bool) *TransactionCountLoader {
 	return NewTransactionCountLoader(TransactionCountLoaderConfig{
 		MaxBatch: 100,
 		Wait:     5 * time.Millisecond,
 		Fetch: func(accountIds []string) ([]int64, []error) {
-			res, err := repo.CountTransactionsByAccountIds(ctx, accountIds)
+			res, err := repo.CountTransactionsByAccountIds(ctx, db.CountTransactionsByAccountIdsParams{
+				Accountids:     accountIds,
+				Includepending: includePending,
+			})
 
 			if err != nil {
 				return nil, []error{err}
 			}
 
+			// Accounts without transactions have no row
+			countByAccountId := make(map[string]int64, len(accountIds))
+
+			for _, r := range res {
+				countByAccountId[r.Accountid.String()] = r.Count
+			}
+
 			counts := make([]int64, len(accountIds))
 
-			for i, r := range res {
-				counts[i] = r.Count
+			for i, accountId := range accountIds {
+				counts[i] = countByAccountId[accountId]
 			}
 
 			return counts, nil
@@ -153,21 +194,32 @@ func newCountTransactionsByAccountIdLoader(ctx context.Context, repo db.Reposito
 	})
 }
 
-func newCountTransactionsByMerchantIdLoader(ctx context.Context, repo db.Repository) *TransactionCountLoader {
+func newCountTransactionsByMerchantIdLoader(ctx context.Context, repo db.Repository, userId uuid.UUID, includePending bool) *TransactionCountLoader {
 	return NewTransactionCountLoader(TransactionCountLoaderConfig{
 		MaxBatch: 100,
 		Wait:     5 * time.Millisecond,
 		Fetch: func(merchantIds []string) ([]int64, []error) {
-			res, err := repo.CountTransactionsByMerchantIds(ctx, merchantIds)
+			res, err := repo.CountTransactionsByMerchantIds(ctx, db.CountTransactionsByMerchantIdsParams{
+				Ownerid:        userId,
+				Merchantids:    merchantIds,
+				Includepending: includePending,
+			})
 
 			if err != nil {
 				return nil, []error{err}
 			}
 
+			// Merchants without transactions have no row
+			countByMerchantId := make(map[string]int64, len(merchantIds))
+
+			for _, r := range res {
+				countByMerchantId[r.Merchantid.String()] = r.Count
+			}
+
 			counts := make([]int64, len(merchantIds))
 
-			for i, r := range res {
-				counts[i] = r.Count
+			for i, merchantId := range merchantIds {
+				counts[i] = countByMerchantId[merchantId]
 			}
 
 			return counts, nil
@@ -175,12 +227,15 @@ func newCountTransactionsByMerchantIdLoader(ctx context.Context, repo db.Reposit
 	})
 }
 
-func newMerchantLoader(ctx context.Context, repo db.Repository) *MerchantLoader {
+func newMerchantLoader(ctx context.Context, repo db.Repository, userId uuid.UUID) *MerchantLoader {
 	return NewMerchantLoader(MerchantLoaderConfig{
 		MaxBatch: 100,
 		Wait:     5 * time.Millisecond,
 		Fetch: func(merchantIds []string) ([]db.Merchant, []error) {
-			res, err := repo.ListMerchantsByMerchantIds(ctx, merchantIds)
+			res, err := repo.ListMerchantsByMerchantIds(ctx, db.ListMerchantsByMerchantIdsParams{
+				Ownerid:     userId,
+				Merchantids: merchantIds,
+			})
 
 			if err != nil {
 				return nil, []error{err}
@@ -203,6 +258,34 @@ func newMerchantLoader(ctx context.Context, repo db.Repository) *MerchantLoader
 	})
 }
 
+func newGlobalMerchantLoader(ctx context.Context, repo db.Repository) *GlobalMerchantLoader {
+	return NewGlobalMerchantLoader(GlobalMerchantLoaderConfig{
+		MaxBatch: 100,
+		Wait:     5 * time.Millisecond,
+		Fetch: func(globalMerchantIds []string) ([]db.GlobalMerchant, []error) {
+			res, err := repo.ListGlobalMerchantsByIds(ctx, globalMerchantIds)
+
+			if err != nil {
+				return nil, []error{err}
+			}
+
+			groupByGlobalMerchantId := make(map[string]db.GlobalMerchant, len(globalMerchantIds))
+
+			for i, r := range res {
+				groupByGlobalMerchantId[r.ID.String()] = res[i]
+			}
+
+			result := make([]db.GlobalMerchant, len(globalMerchantIds))
+
+			for i, globalMerchantId := range globalMerchantIds {
+				result[i] = groupByGlobalMerchantId[globalMerchantId]
+			}
+
+			return result, nil
+		},
+	})
+}
+
 func newFundAllocationsByFundIdLoader(ctx context.Context, repo db.Repository, limit int32, start int32) *FundAllocationLoader {
 	return NewFundAllocationLoader(FundAllocationLoaderConfig{
 		MaxBatch: 100,
@@ -262,3 +345,97 @@ func newCountFundAllocationsByFundIdLoader(ctx context.Context, repo db.Reposito
 		},
 	})
 }
+
+func newSplitsByTransactionIdLoader(ctx context.Context, repo db.Repository, userId uuid.UUID) *TransactionSplitLoader {
+	return NewTransactionSplitLoader(TransactionSplitLoaderConfig{
+		MaxBatch: 100,
+		Wait:     5 * time.Millisecond,
+		Fetch: func(transactionIds []string) ([][]db.TransactionSplit, []error) {
+			res, err := repo.ListTransactionSplitsByTransactionIds(ctx, db.ListTransactionSplitsByTransactionIdsParams{
+				Ownerid:        userId,
+				Transactionids: transactionIds,
+			})
+
+			if err != nil {
+				return nil, []error{err}
+			}
+
+			groupByTransactionId := make(map[string][]db.TransactionSplit, len(transactionIds))
+
+			for _, r := range res {
+				groupByTransactionId[r.Transactionid.String()] = append(groupByTransactionId[r.Transactionid.String()], r)
+			}
+
+			result := make([][]db.TransactionSplit, len(transactionIds))
+
+			for i, transactionId := range transactionIds {
+				result[i] = groupByTransactionId[transactionId]
+			}
+
+			return result, nil
+		},
+	})
+}
+
+func newTagsByTransactionIdLoader(ctx context.Context, repo db.Repository, userId uuid.UUID) *TagLoader {
+	return NewTagLoader(TagLoaderConfig{
+		MaxBatch: 100,
+		Wait:     5 * time.Millisecond,
+		Fetch: func(transactionIds []string) ([][]db.Tag, []error) {
+			res, err := repo.ListTagsByTransactionIds(ctx, db.ListTagsByTransactionIdsParams{
+				Ownerid:        userId,
+				Transactionids: transactionIds,
+			})
+
+			if err != nil {
+				return nil, []error{err}
+			}
+
+			groupByTransactionId := make(map[string][]db.Tag, len(transactionIds))
+
+			for _, r := range res {
+				groupByTransactionId[r.Transactionid.String()] = append(groupByTransactionId[r.Transactionid.String()], r.Tag)
+			}
+
+			result := make([][]db.Tag, len(transactionIds))
+
+			for i, transactionId := range transactionIds {
+				result[i] = groupByTransactionId[transactionId]
+			}
+
+			return result, nil
+		},
+	})
+}
+
+// Loads each account's latest balance, which is nil for accounts without one
+func newLatestBalanceByAccountIdLoader(ctx context.Context, repo db.Repository, userId uuid.UUID) *AccountBalanceLoader {
+	return NewAccountBalanceLoader(AccountBalanceLoaderConfig{
+		MaxBatch: 100,
+		Wait:     5 * time.Millisecond,
+		Fetch: func(accountIds []string) ([]*db.AccountBalance, []error) {
+			res, err := repo.ListLatestAccountBalancesByAccountIds(ctx, db.ListLatestAccountBalancesByAccountIdsParams{
+				Ownerid:    userId,
+				Accountids: accountIds,
+			})
+
+			if err != nil {
+				return nil, []error{err}
+			}
+
+			groupByAccountId := make(map[string]*db.AccountBalance, len(accountIds))
+
+			for i, r := range res {
+				groupByAccountId[r.Accountid.String()] = &res[i]
+			}
+
+			result := make([]*db.AccountBalance, len(accountIds))
+
+			for i, accountId := range accountIds {
+				result[i] = groupByAccountId[accountId]
+			}
+
+			return result, nil
+		},
+	})
+}
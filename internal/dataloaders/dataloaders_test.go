@@ -0,0 +1,107 @@
+package dataloaders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+type countRepository struct {
+	db.Repository
+	counts  map[uuid.UUID]int64
+	pending map[uuid.UUID]int64
+}
+
+func (r *countRepository) CountTransactionsByAccountIds(ctx context.Context, arg db.CountTransactionsByAccountIdsParams) ([]db.CountTransactionsByAccountIdsRow, error) {
+	var rows []db.CountTransactionsByAccountIdsRow
+
+	for id, count := range r.counts {
+		if arg.Includepending {
+			count += r.pending[id]
+		}
+
+		rows = append(rows, db.CountTransactionsByAccountIdsRow{Count: count, Accountid: id})
+	}
+
+	return rows, nil
+}
+
+func TestCountTransactionsByAccountId(t *testing.T) {
+	first, second, empty := uuid.New(), uuid.New(), uuid.New()
+	repo := &countRepository{
+		counts:  map[uuid.UUID]int64{first: 3, second: 5},
+		pending: map[uuid.UUID]int64{second: 2},
+	}
+	loaders := newLoaders(context.Background(), repo, uuid.New())
+	ids := []string{second.String(), empty.String(), first.String()}
+
+	tests := []struct {
+		name           string
+		includePending bool
+		want           []int64
+	}{
+		{name: "with pending", includePending: true, want: []int64{7, 0, 3}},
+		{name: "posted only", includePending: false, want: []int64{5, 0, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts, errs := loaders.CountTransactionsByAccountId(tt.includePending).LoadAll(ids)
+
+			for _, err := range errs {
+				if err != nil {
+					t.Fatalf("LoadAll() error = %v", err)
+				}
+			}
+
+			for i, count := range counts {
+				if count != tt.want[i] {
+					t.Errorf("count of %s = %d, want %d", ids[i], count, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+type balanceRepository struct {
+	db.Repository
+	balances []db.AccountBalance
+}
+
+func (r *balanceRepository) ListLatestAccountBalancesByAccountIds(ctx context.Context, arg db.ListLatestAccountBalancesByAccountIdsParams) ([]db.AccountBalance, error) {
+	return r.balances, nil
+}
+
+func TestLatestBalanceByAccountId(t *testing.T) {
+	first, second, empty := uuid.New(), uuid.New(), uuid.New()
+	repo := &balanceRepository{
+		balances: []db.AccountBalance{
+			{ID: uuid.New(), Accountid: first, Current: 1250},
+			{ID: uuid.New(), Accountid: second, Current: -300},
+		},
+	}
+	loaders := newLoaders(context.Background(), repo, uuid.New())
+	ids := []string{second.String(), empty.String(), first.String()}
+
+	balances, errs := loaders.LatestBalanceByAccountId.LoadAll(ids)
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+	}
+
+	if balances[0] == nil || balances[0].Current != -300 {
+		t.Errorf("balance of %s = %+v, want -300", ids[0], balances[0])
+	}
+
+	if balances[1] != nil {
+		t.Errorf("balance of %s = %+v, want nil", ids[1], balances[1])
+	}
+
+	if balances[2] == nil || balances[2].Current != 1250 {
+		t.Errorf("balance of %s = %+v, want 1250", ids[2], balances[2])
+	}
+}
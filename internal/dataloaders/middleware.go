@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
 	"github.com/proctorinc/banker/internal/db"
 )
 
@@ -11,7 +13,14 @@ import (
 func Middleware(repo db.Repository) func(gin.HandlerFunc) gin.HandlerFunc {
 	return func(next gin.HandlerFunc) gin.HandlerFunc {
 		return func(ctx *gin.Context) {
-			loaders := newLoaders(ctx, repo)
+			// Loaders only return merchants owned by the user making the request
+			var userId uuid.UUID
+
+			if user := auth.GetCurrentUser(ctx.Request.Context()); user != nil {
+				userId = user.ID
+			}
+
+			loaders := newLoaders(ctx, repo, userId)
 			newCtx := context.WithValue(ctx.Request.Context(), key, loaders)
 			ctx.Request = ctx.Request.WithContext(newCtx)
 			ctx.Next()
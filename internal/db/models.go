@@ -22,6 +22,7 @@ const (
 	AccountTypeMONEYMRKT  AccountType = "MONEYMRKT"
 	AccountTypeCREDITLINE AccountType = "CREDITLINE"
 	AccountTypeCD         AccountType = "CD"
+	AccountTypeINVESTMENT AccountType = "INVESTMENT"
 )
 
 func (e *AccountType) Scan(src interface{}) error {
@@ -59,6 +60,71 @@ func (ns NullAccountType) Value() (driver.Value, error) {
 	return string(ns.AccountType), nil
 }
 
+func (e AccountType) Valid() bool {
+	switch e {
+	case AccountTypeCREDIT,
+		AccountTypeCHECKING,
+		AccountTypeSAVINGS,
+		AccountTypeMONEYMRKT,
+		AccountTypeCREDITLINE,
+		AccountTypeCD,
+		AccountTypeINVESTMENT:
+		return true
+	}
+	return false
+}
+
+type CategorySource string
+
+const (
+	CategorySourceMANUAL     CategorySource = "MANUAL"
+	CategorySourceCLASSIFIER CategorySource = "CLASSIFIER"
+)
+
+func (e *CategorySource) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = CategorySource(s)
+	case string:
+		*e = CategorySource(s)
+	default:
+		return fmt.Errorf("unsupported scan type for CategorySource: %T", src)
+	}
+	return nil
+}
+
+type NullCategorySource struct {
+	CategorySource CategorySource
+	Valid          bool // Valid is true if CategorySource is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullCategorySource) Scan(value interface{}) error {
+	if value == nil {
+		ns.CategorySource, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.CategorySource.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullCategorySource) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.CategorySource), nil
+}
+
+func (e CategorySource) Valid() bool {
+	switch e {
+	case CategorySourceMANUAL,
+		CategorySourceCLASSIFIER:
+		return true
+	}
+	return false
+}
+
 type FundType string
 
 const (
@@ -101,6 +167,243 @@ func (ns NullFundType) Value() (driver.Value, error) {
 	return string(ns.FundType), nil
 }
 
+func (e FundType) Valid() bool {
+	switch e {
+	case FundTypeSAVINGS,
+		FundTypeBUDGET:
+		return true
+	}
+	return false
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusCOMPLETED ImportStatus = "COMPLETED"
+	ImportStatusFAILED    ImportStatus = "FAILED"
+	ImportStatusREVERTED  ImportStatus = "REVERTED"
+)
+
+func (e *ImportStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ImportStatus(s)
+	case string:
+		*e = ImportStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ImportStatus: %T", src)
+	}
+	return nil
+}
+
+type NullImportStatus struct {
+	ImportStatus ImportStatus
+	Valid        bool // Valid is true if ImportStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullImportStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.ImportStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ImportStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullImportStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ImportStatus), nil
+}
+
+func (e ImportStatus) Valid() bool {
+	switch e {
+	case ImportStatusCOMPLETED,
+		ImportStatusFAILED,
+		ImportStatusREVERTED:
+		return true
+	}
+	return false
+}
+
+type InvestmentTransactionType string
+
+const (
+	InvestmentTransactionTypeBUY             InvestmentTransactionType = "BUY"
+	InvestmentTransactionTypeSELL            InvestmentTransactionType = "SELL"
+	InvestmentTransactionTypeDIVIDEND        InvestmentTransactionType = "DIVIDEND"
+	InvestmentTransactionTypeINTEREST        InvestmentTransactionType = "INTEREST"
+	InvestmentTransactionTypeCAPITALGAIN     InvestmentTransactionType = "CAPITAL_GAIN"
+	InvestmentTransactionTypeREINVEST        InvestmentTransactionType = "REINVEST"
+	InvestmentTransactionTypeRETURNOFCAPITAL InvestmentTransactionType = "RETURN_OF_CAPITAL"
+	InvestmentTransactionTypeFEE             InvestmentTransactionType = "FEE"
+	InvestmentTransactionTypeTRANSFER        InvestmentTransactionType = "TRANSFER"
+	InvestmentTransactionTypeSPLIT           InvestmentTransactionType = "SPLIT"
+	InvestmentTransactionTypeOTHER           InvestmentTransactionType = "OTHER"
+)
+
+func (e *InvestmentTransactionType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = InvestmentTransactionType(s)
+	case string:
+		*e = InvestmentTransactionType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for InvestmentTransactionType: %T", src)
+	}
+	return nil
+}
+
+type NullInvestmentTransactionType struct {
+	InvestmentTransactionType InvestmentTransactionType
+	Valid                     bool // Valid is true if InvestmentTransactionType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullInvestmentTransactionType) Scan(value interface{}) error {
+	if value == nil {
+		ns.InvestmentTransactionType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.InvestmentTransactionType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullInvestmentTransactionType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.InvestmentTransactionType), nil
+}
+
+func (e InvestmentTransactionType) Valid() bool {
+	switch e {
+	case InvestmentTransactionTypeBUY,
+		InvestmentTransactionTypeSELL,
+		InvestmentTransactionTypeDIVIDEND,
+		InvestmentTransactionTypeINTEREST,
+		InvestmentTransactionTypeCAPITALGAIN,
+		InvestmentTransactionTypeREINVEST,
+		InvestmentTransactionTypeRETURNOFCAPITAL,
+		InvestmentTransactionTypeFEE,
+		InvestmentTransactionTypeTRANSFER,
+		InvestmentTransactionTypeSPLIT,
+		InvestmentTransactionTypeOTHER:
+		return true
+	}
+	return false
+}
+
+type ManualAssetType string
+
+const (
+	ManualAssetTypeASSET     ManualAssetType = "ASSET"
+	ManualAssetTypeLIABILITY ManualAssetType = "LIABILITY"
+)
+
+func (e *ManualAssetType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ManualAssetType(s)
+	case string:
+		*e = ManualAssetType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ManualAssetType: %T", src)
+	}
+	return nil
+}
+
+type NullManualAssetType struct {
+	ManualAssetType ManualAssetType
+	Valid           bool // Valid is true if ManualAssetType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullManualAssetType) Scan(value interface{}) error {
+	if value == nil {
+		ns.ManualAssetType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ManualAssetType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullManualAssetType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ManualAssetType), nil
+}
+
+func (e ManualAssetType) Valid() bool {
+	switch e {
+	case ManualAssetTypeASSET,
+		ManualAssetTypeLIABILITY:
+		return true
+	}
+	return false
+}
+
+type MerchantRuleType string
+
+const (
+	MerchantRuleTypePREFIX      MerchantRuleType = "PREFIX"
+	MerchantRuleTypeCONTAINS    MerchantRuleType = "CONTAINS"
+	MerchantRuleTypeREGEX       MerchantRuleType = "REGEX"
+	MerchantRuleTypeAMOUNTRANGE MerchantRuleType = "AMOUNT_RANGE"
+)
+
+func (e *MerchantRuleType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MerchantRuleType(s)
+	case string:
+		*e = MerchantRuleType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MerchantRuleType: %T", src)
+	}
+	return nil
+}
+
+type NullMerchantRuleType struct {
+	MerchantRuleType MerchantRuleType
+	Valid            bool // Valid is true if MerchantRuleType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMerchantRuleType) Scan(value interface{}) error {
+	if value == nil {
+		ns.MerchantRuleType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MerchantRuleType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMerchantRuleType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MerchantRuleType), nil
+}
+
+func (e MerchantRuleType) Valid() bool {
+	switch e {
+	case MerchantRuleTypePREFIX,
+		MerchantRuleTypeCONTAINS,
+		MerchantRuleTypeREGEX,
+		MerchantRuleTypeAMOUNTRANGE:
+		return true
+	}
+	return false
+}
+
 type Role string
 
 const (
@@ -143,6 +446,174 @@ func (ns NullRole) Value() (driver.Value, error) {
 	return string(ns.Role), nil
 }
 
+func (e Role) Valid() bool {
+	switch e {
+	case RoleUSER,
+		RoleADMIN:
+		return true
+	}
+	return false
+}
+
+type SecurityType string
+
+const (
+	SecurityTypeSTOCK      SecurityType = "STOCK"
+	SecurityTypeMUTUALFUND SecurityType = "MUTUALFUND"
+	SecurityTypeDEBT       SecurityType = "DEBT"
+	SecurityTypeOPTION     SecurityType = "OPTION"
+	SecurityTypeOTHER      SecurityType = "OTHER"
+)
+
+func (e *SecurityType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SecurityType(s)
+	case string:
+		*e = SecurityType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SecurityType: %T", src)
+	}
+	return nil
+}
+
+type NullSecurityType struct {
+	SecurityType SecurityType
+	Valid        bool // Valid is true if SecurityType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSecurityType) Scan(value interface{}) error {
+	if value == nil {
+		ns.SecurityType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SecurityType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSecurityType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SecurityType), nil
+}
+
+func (e SecurityType) Valid() bool {
+	switch e {
+	case SecurityTypeSTOCK,
+		SecurityTypeMUTUALFUND,
+		SecurityTypeDEBT,
+		SecurityTypeOPTION,
+		SecurityTypeOTHER:
+		return true
+	}
+	return false
+}
+
+type SignConvention string
+
+const (
+	SignConventionDEBITSNEGATIVE SignConvention = "DEBITS_NEGATIVE"
+	SignConventionDEBITSPOSITIVE SignConvention = "DEBITS_POSITIVE"
+)
+
+func (e *SignConvention) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = SignConvention(s)
+	case string:
+		*e = SignConvention(s)
+	default:
+		return fmt.Errorf("unsupported scan type for SignConvention: %T", src)
+	}
+	return nil
+}
+
+type NullSignConvention struct {
+	SignConvention SignConvention
+	Valid          bool // Valid is true if SignConvention is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullSignConvention) Scan(value interface{}) error {
+	if value == nil {
+		ns.SignConvention, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.SignConvention.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullSignConvention) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.SignConvention), nil
+}
+
+func (e SignConvention) Valid() bool {
+	switch e {
+	case SignConventionDEBITSNEGATIVE,
+		SignConventionDEBITSPOSITIVE:
+		return true
+	}
+	return false
+}
+
+type TransactionStatus string
+
+const (
+	TransactionStatusPENDING TransactionStatus = "PENDING"
+	TransactionStatusPOSTED  TransactionStatus = "POSTED"
+)
+
+func (e *TransactionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TransactionStatus(s)
+	case string:
+		*e = TransactionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TransactionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTransactionStatus struct {
+	TransactionStatus TransactionStatus
+	Valid             bool // Valid is true if TransactionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTransactionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TransactionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TransactionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTransactionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TransactionStatus), nil
+}
+
+func (e TransactionStatus) Valid() bool {
+	switch e {
+	case TransactionStatusPENDING,
+		TransactionStatusPOSTED:
+		return true
+	}
+	return false
+}
+
 type TransactionType string
 
 const (
@@ -200,12 +671,41 @@ func (ns NullTransactionType) Value() (driver.Value, error) {
 	return string(ns.TransactionType), nil
 }
 
+func (e TransactionType) Valid() bool {
+	switch e {
+	case TransactionTypeCREDIT,
+		TransactionTypeDEBIT,
+		TransactionTypeINT,
+		TransactionTypeDIV,
+		TransactionTypeFEE,
+		TransactionTypeSRVCHG,
+		TransactionTypeDEP,
+		TransactionTypeATM,
+		TransactionTypePOS,
+		TransactionTypeXFER,
+		TransactionTypeCHECK,
+		TransactionTypePAYMENT,
+		TransactionTypeCASH,
+		TransactionTypeDIRECTDEP,
+		TransactionTypeDIRECTDEBIT,
+		TransactionTypeREPEATPMT,
+		TransactionTypeOTHER:
+		return true
+	}
+	return false
+}
+
 type UploadSource string
 
 const (
-	UploadSourceCHASECSVUPLOAD UploadSource = "CHASE:CSV_UPLOAD"
-	UploadSourceCHASEOFXUPLOAD UploadSource = "CHASE:OFX_UPLOAD"
-	UploadSourcePLAID          UploadSource = "PLAID"
+	UploadSourceCHASECSVUPLOAD   UploadSource = "CHASE:CSV_UPLOAD"
+	UploadSourceCHASEOFXUPLOAD   UploadSource = "CHASE:OFX_UPLOAD"
+	UploadSourceCSVUPLOAD        UploadSource = "CSV_UPLOAD"
+	UploadSourceQIFUPLOAD        UploadSource = "QIF_UPLOAD"
+	UploadSourceCAMT053UPLOAD    UploadSource = "CAMT053_UPLOAD"
+	UploadSourceMT940UPLOAD      UploadSource = "MT940_UPLOAD"
+	UploadSourcePLAID            UploadSource = "PLAID"
+	UploadSourceOFXDIRECTCONNECT UploadSource = "OFX_DIRECT_CONNECT"
 )
 
 func (e *UploadSource) Scan(src interface{}) error {
@@ -243,21 +743,76 @@ func (ns NullUploadSource) Value() (driver.Value, error) {
 	return string(ns.UploadSource), nil
 }
 
+func (e UploadSource) Valid() bool {
+	switch e {
+	case UploadSourceCHASECSVUPLOAD,
+		UploadSourceCHASEOFXUPLOAD,
+		UploadSourceCSVUPLOAD,
+		UploadSourceQIFUPLOAD,
+		UploadSourceCAMT053UPLOAD,
+		UploadSourceMT940UPLOAD,
+		UploadSourcePLAID,
+		UploadSourceOFXDIRECTCONNECT:
+		return true
+	}
+	return false
+}
+
 type Account struct {
-	ID            uuid.UUID
-	Sourceid      string
-	Type          AccountType
-	Name          string
-	Routingnumber sql.NullString
-	Updated       time.Time
-	Ownerid       uuid.UUID
+	ID               uuid.UUID
+	Sourceid         string
+	Type             AccountType
+	Name             string
+	Routingnumber    sql.NullString
+	Updated          time.Time
+	Ownerid          uuid.UUID
+	Syncconnectionid uuid.NullUUID
+	Synccursor       sql.NullString
+}
+
+type AccountBalance struct {
+	ID         uuid.UUID
+	Date       time.Time
+	Current    int32
+	Available  sql.NullInt32
+	Accountid  uuid.UUID
+	Ownerid    uuid.UUID
+	Syncitemid uuid.NullUUID
 }
 
 type AccountSyncItem struct {
-	ID           uuid.UUID
-	Date         time.Time
-	Uploadsource UploadSource
-	Accountid    uuid.UUID
+	ID            uuid.UUID
+	Date          time.Time
+	Uploadsource  UploadSource
+	Accountid     uuid.UUID
+	Importbatchid uuid.NullUUID
+}
+
+type Category struct {
+	ID       uuid.UUID
+	Name     string
+	Parentid uuid.NullUUID
+	Ownerid  uuid.NullUUID
+	Created  time.Time
+}
+
+type CsvProfile struct {
+	ID                uuid.UUID
+	Name              string
+	Institution       sql.NullString
+	Datecolumn        string
+	Descriptioncolumn string
+	Amountcolumn      sql.NullString
+	Debitcolumn       sql.NullString
+	Creditcolumn      sql.NullString
+	Typecolumn        sql.NullString
+	Checknumbercolumn sql.NullString
+	Dateformat        string
+	Signconvention    SignConvention
+	Skiprows          int32
+	Hasheader         bool
+	Ownerid           uuid.UUID
+	Balancecolumn     sql.NullString
 }
 
 type Fund struct {
@@ -279,11 +834,84 @@ type FundAllocation struct {
 	Fundid      uuid.UUID
 }
 
+type GlobalMerchant struct {
+	ID         uuid.UUID
+	Name       string
+	Website    sql.NullString
+	Categoryid uuid.NullUUID
+	Aliases    []string
+	Created    time.Time
+}
+
+type Holding struct {
+	ID          uuid.UUID
+	Date        time.Time
+	Units       float64
+	Unitprice   float64
+	Marketvalue int32
+	Securityid  uuid.UUID
+	Accountid   uuid.UUID
+	Ownerid     uuid.UUID
+	Syncitemid  uuid.NullUUID
+}
+
+type ImportBatch struct {
+	ID                  uuid.UUID
+	Filename            string
+	Filehash            string
+	Uploadsource        UploadSource
+	Status              ImportStatus
+	Accountsupdated     int32
+	Accountsfailed      int32
+	Transactionsupdated int32
+	Transactionsfailed  int32
+	Errors              []string
+	Created             time.Time
+	Ownerid             uuid.UUID
+}
+
+type InvestmentTransaction struct {
+	ID              uuid.UUID
+	Sourceid        string
+	Type            InvestmentTransactionType
+	Tradedate       time.Time
+	Settledate      sql.NullTime
+	Units           float64
+	Unitprice       float64
+	Amount          int32
+	Fees            int32
+	Isocurrencycode string
+	Description     string
+	Securityid      uuid.NullUUID
+	Accountid       uuid.UUID
+	Ownerid         uuid.UUID
+	Syncitemid      uuid.NullUUID
+}
+
+type ManualAsset struct {
+	ID      uuid.UUID
+	Name    string
+	Type    ManualAssetType
+	Ownerid uuid.UUID
+}
+
+type ManualAssetValuation struct {
+	ID      uuid.UUID
+	Date    time.Time
+	Value   int32
+	Assetid uuid.UUID
+	Ownerid uuid.UUID
+}
+
 type Merchant struct {
-	ID       uuid.UUID
-	Name     string
-	Sourceid sql.NullString
-	Ownerid  uuid.UUID
+	ID               uuid.UUID
+	Name             string
+	Sourceid         sql.NullString
+	Ownerid          uuid.UUID
+	Syncitemid       uuid.NullUUID
+	Website          sql.NullString
+	Categoryid       uuid.NullUUID
+	Globalmerchantid uuid.NullUUID
 }
 
 type MerchantKey struct {
@@ -294,9 +922,90 @@ type MerchantKey struct {
 	Ownerid      uuid.UUID
 }
 
+type MerchantRule struct {
+	ID           uuid.UUID
+	Priority     int32
+	Type         MerchantRuleType
+	Pattern      string
+	Minamount    sql.NullInt32
+	Maxamount    sql.NullInt32
+	Uploadsource NullUploadSource
+	Merchantid   uuid.UUID
+	Ownerid      uuid.UUID
+	Created      time.Time
+}
+
+type OfxConnection struct {
+	ID            uuid.UUID
+	Url           string
+	Org           string
+	Fid           string
+	Appid         string
+	Appversion    string
+	Ofxversion    string
+	Credentials   string
+	Lastattempted sql.NullTime
+	Lastsynced    sql.NullTime
+	Lasterror     sql.NullString
+	Created       time.Time
+	Accountid     uuid.UUID
+	Ownerid       uuid.UUID
+}
+
+type Security struct {
+	ID           uuid.UUID
+	Uniqueid     string
+	Uniqueidtype string
+	Ticker       sql.NullString
+	Name         string
+	Type         SecurityType
+}
+
+type SyncConnection struct {
+	ID          uuid.UUID
+	Provider    UploadSource
+	Itemid      string
+	Accesstoken string
+	Lastsynced  sql.NullTime
+	Created     time.Time
+	Ownerid     uuid.UUID
+}
+
+type Tag struct {
+	ID      uuid.UUID
+	Name    string
+	Ownerid uuid.UUID
+	Created time.Time
+}
+
 type Transaction struct {
+	ID                 uuid.UUID
+	Sourceid           string
+	Amount             int32
+	Payeeid            sql.NullString
+	Payee              sql.NullString
+	Payeefull          sql.NullString
+	Isocurrencycode    string
+	Date               time.Time
+	Description        string
+	Type               TransactionType
+	Checknumber        sql.NullString
+	Updated            time.Time
+	Merchantid         uuid.UUID
+	Ownerid            uuid.UUID
+	Accountid          uuid.UUID
+	Syncitemid         uuid.NullUUID
+	Status             TransactionStatus
+	Authorizeddate     sql.NullTime
+	Categoryid         uuid.NullUUID
+	Categorysource     NullCategorySource
+	Categoryconfidence sql.NullFloat64
+	Needsreview        bool
+	Note               string
+}
+
+type TransactionRevision struct {
 	ID              uuid.UUID
-	Sourceid        string
 	Amount          int32
 	Payeeid         sql.NullString
 	Payee           sql.NullString
@@ -307,9 +1016,27 @@ type Transaction struct {
 	Type            TransactionType
 	Checknumber     sql.NullString
 	Updated         time.Time
-	Merchantid      uuid.UUID
-	Ownerid         uuid.UUID
-	Accountid       uuid.UUID
+	Created         time.Time
+	Transactionid   uuid.UUID
+	Syncitemid      uuid.UUID
+	Sourceid        string
+	Status          TransactionStatus
+	Authorizeddate  sql.NullTime
+}
+
+type TransactionSplit struct {
+	ID            uuid.UUID
+	Transactionid uuid.UUID
+	Amount        int32
+	Categoryid    uuid.NullUUID
+	Note          string
+	Fundid        uuid.NullUUID
+	Ownerid       uuid.UUID
+}
+
+type TransactionTag struct {
+	Transactionid uuid.UUID
+	Tagid         uuid.UUID
 }
 
 type User struct {
@@ -31,6 +31,11 @@ SELECT * FROM accounts
 WHERE id = $1 and ownerId = $2
 LIMIT 1;
 
+-- name: GetAccountBySourceId :one
+SELECT * FROM accounts
+WHERE ownerId = $1 AND sourceId = $2
+LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts AS a
 WHERE ownerId = $1
@@ -51,15 +56,20 @@ INSERT INTO accounts (
     ownerId
 )
 VALUES ($1, $2, $3, $4, $5, $6)
-ON CONFLICT (sourceId) DO UPDATE
+ON CONFLICT (ownerId, sourceId) DO UPDATE
 SET
     type = $2,
     name = $3,
     routingNumber = $4,
     updated = $5
--- WHERE ownerId = $7 -- HOW DO WE INCLUDE OWNER ID FOR UPDATE
 RETURNING *;
 
+-- name: DeleteEmptyAccount :execrows
+DELETE FROM accounts AS a
+WHERE a.id = $1
+    AND NOT EXISTS (SELECT 1 FROM account_sync_items AS s WHERE s.accountId = a.id)
+    AND NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.accountId = a.id);
+
 -- ACCOUNT SYNC ITEMS
 
 -- name: GetLastSync :one
@@ -76,6 +86,66 @@ INSERT INTO account_sync_items (
 VALUES ($1, $2)
 RETURNING *;
 
+-- name: GetAccountSyncItem :one
+SELECT s.* FROM account_sync_items AS s
+JOIN accounts AS a ON s.accountId = a.id
+WHERE s.id = $1 AND a.ownerId = $2;
+
+-- name: ListImportBatchSyncItems :many
+SELECT * FROM account_sync_items
+WHERE importBatchId = $1;
+
+-- name: SetSyncItemsImportBatch :exec
+UPDATE account_sync_items
+SET importBatchId = $1
+WHERE id::varchar = ANY(@syncItemIds::varchar[]);
+
+-- name: DeleteAccountSyncItem :exec
+DELETE FROM account_sync_items
+WHERE id = $1;
+
+-- ACCOUNT BALANCES
+
+-- name: GetLatestAccountBalance :one
+SELECT * FROM account_balances
+WHERE accountId = $1
+ORDER BY date DESC
+LIMIT 1;
+
+-- name: ListLatestAccountBalancesByAccountIds :many
+-- Each account's latest balance. Accounts without a balance have no row
+SELECT DISTINCT ON (accountId) * FROM account_balances
+WHERE ownerId = @ownerId AND accountId::varchar = ANY(@accountIds::varchar[])
+ORDER BY accountId, date DESC;
+
+-- name: ListAccountBalances :many
+SELECT * FROM account_balances
+WHERE accountId = $1 AND date BETWEEN @startdate AND @enddate
+ORDER BY date;
+
+-- name: UpsertAccountBalance :one
+INSERT INTO account_balances (
+    accountId,
+    date,
+    current,
+    available,
+    ownerId,
+    syncItemId
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (accountId, date) DO UPDATE
+SET
+    current = $3,
+    available = $4,
+    syncItemId = $6
+RETURNING *;
+
+-- name: ListNetWorthAccountBalances :many
+SELECT b.*, a.type AS accountType FROM account_balances AS b
+JOIN accounts AS a ON b.accountId = a.id
+WHERE b.ownerId = $1 AND b.date <= @enddate
+ORDER BY b.date;
+
 -- TRANSACTIONS
 
 -- name: GetTransaction :one
@@ -83,15 +153,44 @@ SELECT * FROM transactions
 WHERE id = $1 and ownerId = $2
 LIMIT 1;
 
--- name: ListTransactions :many
+-- name: GetTransactionBySourceId :one
 SELECT * FROM transactions
-WHERE ownerId = $1
-ORDER BY date DESC
+WHERE ownerId = $1 AND sourceId = $2
+LIMIT 1;
+
+-- name: ListTransactions :many
+-- Transactions without a category have their merchant's category. Split
+-- transactions only have their parts' categories, with parts without a
+-- category in the transaction's category. Filtering by a top level category
+-- includes its subcategories. Filtering by tags matches transactions with any
+-- of the lowercase tag names
+SELECT t.* FROM transactions AS t
+JOIN merchants AS m ON t.merchantId = m.id
+WHERE t.ownerId = $1 AND (@includePending::boolean OR t.status = 'POSTED')
+    AND (sqlc.narg('categoryId')::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM categories AS c
+        WHERE (c.id = sqlc.narg('categoryId') OR c.parentId = sqlc.narg('categoryId'))
+            AND c.id IN (
+                SELECT COALESCE(s.categoryId, t.categoryId, m.categoryId)
+                FROM transaction_splits AS s
+                WHERE s.transactionId = t.id
+                UNION ALL
+                SELECT COALESCE(t.categoryId, m.categoryId)
+                WHERE NOT EXISTS (SELECT 1 FROM transaction_splits AS s WHERE s.transactionId = t.id)
+            )
+    ))
+    AND (sqlc.narg('needsReview')::boolean IS NULL OR t.needsReview = sqlc.narg('needsReview'))
+    AND (sqlc.narg('tags')::varchar[] IS NULL OR EXISTS (
+        SELECT 1 FROM transaction_tags AS tt
+        JOIN tags AS tag ON tt.tagId = tag.id
+        WHERE tt.transactionId = t.id AND lower(tag.name) = ANY(sqlc.narg('tags')::varchar[])
+    ))
+ORDER BY t.date DESC
 LIMIT $2 OFFSET @start;
 
 -- name: ListTransactionsByDates :many
 SELECT * FROM transactions
-WHERE ownerId = $1 AND date BETWEEN @startdate AND @enddate
+WHERE ownerId = $1 AND date BETWEEN @startdate AND @enddate AND (@includePending::boolean OR status = 'POSTED')
 ORDER BY date DESC
 LIMIT $2 OFFSET @start;
 
@@ -99,28 +198,37 @@ LIMIT $2 OFFSET @start;
 SELECT t.* FROM transactions AS t, accounts AS a
 WHERE t.accountId = a.id
     AND a.id::varchar = ANY(@accountIds::varchar[])
+    AND (@includePending::boolean OR t.status = 'POSTED')
 ORDER BY date DESC
 LIMIT $1 OFFSET @start;
 
+-- name: ListAccountTransactions :many
+SELECT * FROM transactions
+WHERE accountId = $1 AND ownerId = $2
+ORDER BY date, sourceId;
+
 -- name: ListTransactionsByMerchantIds :many
 SELECT t.* FROM transactions AS t, merchants AS m
-WHERE t.merchantId = m.id
+WHERE t.ownerId = @ownerId
+    AND t.merchantId = m.id
     AND m.id::varchar = ANY(@merchantIds::varchar[])
+    AND (@includePending::boolean OR t.status = 'POSTED')
 ORDER BY date DESC
 LIMIT $1 OFFSET @start;
 
 -- name: ListSpendingTransactions :many
 SELECT * FROM transactions
 WHERE ownerId = $1
-    AND amount < 0
-    AND date BETWEEN @startdate AND @enddate
+    AND amount < 0 AND date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR status = 'POSTED')
 ORDER BY date DESC
-LIMIT $1 OFFSET @start;
+LIMIT $2 OFFSET @start;
 
 -- name: ListIncomeTransactions :many
 SELECT * FROM transactions
 WHERE ownerId = $1
     AND amount >= 0 AND date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR status = 'POSTED')
 ORDER BY date DESC
 LIMIT $2 OFFSET @start;
 
@@ -137,36 +245,60 @@ ORDER BY date
 LIMIT $2 OFFSET @start;
 
 -- name: CountTransactions :one
-SELECT count(id) FROM transactions AS a
-WHERE ownerId = $1;
+SELECT count(t.id) FROM transactions AS t
+JOIN merchants AS m ON t.merchantId = m.id
+WHERE t.ownerId = $1 AND (@includePending::boolean OR t.status = 'POSTED')
+    AND (sqlc.narg('categoryId')::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM categories AS c
+        WHERE (c.id = sqlc.narg('categoryId') OR c.parentId = sqlc.narg('categoryId'))
+            AND c.id IN (
+                SELECT COALESCE(s.categoryId, t.categoryId, m.categoryId)
+                FROM transaction_splits AS s
+                WHERE s.transactionId = t.id
+                UNION ALL
+                SELECT COALESCE(t.categoryId, m.categoryId)
+                WHERE NOT EXISTS (SELECT 1 FROM transaction_splits AS s WHERE s.transactionId = t.id)
+            )
+    ))
+    AND (sqlc.narg('needsReview')::boolean IS NULL OR t.needsReview = sqlc.narg('needsReview'))
+    AND (sqlc.narg('tags')::varchar[] IS NULL OR EXISTS (
+        SELECT 1 FROM transaction_tags AS tt
+        JOIN tags AS tag ON tt.tagId = tag.id
+        WHERE tt.transactionId = t.id AND lower(tag.name) = ANY(sqlc.narg('tags')::varchar[])
+    ));
 
 -- name: CountTransactionsByDates :one
 SELECT count(id) FROM transactions AS a
-WHERE ownerId = $1 AND date BETWEEN @startdate AND @enddate;
+WHERE ownerId = $1 AND date BETWEEN @startdate AND @enddate AND (@includePending::boolean OR status = 'POSTED');
 
 -- name: CountTransactionsByAccountIds :many
 SELECT count(t.id), a.id as accountId FROM transactions AS t, accounts AS a
 WHERE t.accountId = a.id
     AND a.id::varchar = ANY(@accountIds::varchar[])
+    AND (@includePending::boolean OR t.status = 'POSTED')
 GROUP BY a.id;
 
 -- name: CountTransactionsByMerchantIds :many
 SELECT count(t.id), m.id as merchantId FROM transactions AS t, merchants AS m
-WHERE t.merchantId = m.id
+WHERE t.ownerId = @ownerId
+    AND t.merchantId = m.id
     AND m.id::varchar = ANY(@merchantIds::varchar[])
+    AND (@includePending::boolean OR t.status = 'POSTED')
 GROUP BY m.id;
 
 -- name: CountIncomeTransactions :one
 SELECT count(t.id) as merchantId FROM transactions AS t
 WHERE ownerId = $1
     AND amount >= 0
-    AND date BETWEEN @startdate AND @enddate;
+    AND date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR status = 'POSTED');
 
 -- name: CountSpendingTransactions :one
 SELECT count(t.id) as merchantId FROM transactions AS t
 WHERE ownerId = $1
     AND amount < 0
-    AND date BETWEEN @startdate AND @enddate;
+    AND date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR status = 'POSTED');
 
 -- name: UpsertTransaction :one
 INSERT INTO transactions (
@@ -183,10 +315,13 @@ INSERT INTO transactions (
     updated,
     ownerId,
     accountId,
-    merchantId
+    merchantId,
+    syncItemId,
+    status,
+    authorizedDate
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-ON CONFLICT (sourceId) DO UPDATE
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+ON CONFLICT (ownerId, sourceId) DO UPDATE
 SET
     amount = $2,
     payeeId = $3,
@@ -197,21 +332,153 @@ SET
     description = $8,
     type = $9,
     checkNumber = $10,
-    updated = $11
--- WHERE ownerId = $13 -- HOW DO WE INCLUDE OWNER ID FOR UPDATE, NO VALIDATION
+    updated = $11,
+    -- A posted transaction is never set back to pending
+    status = CASE WHEN transactions.status = 'POSTED' THEN transactions.status ELSE $16 END,
+    authorizedDate = COALESCE($17, transactions.authorizedDate)
 RETURNING *;
 
+-- name: DeleteSyncItemTransactions :execrows
+DELETE FROM transactions
+WHERE syncItemId = $1;
+
+-- name: DeleteTransactionsBySourceIds :execrows
+DELETE FROM transactions
+WHERE ownerId = $1 AND sourceId = ANY(@sourceIds::varchar[]);
+
 -- name: UpdateTransaction :one
 UPDATE transactions
 SET amount = $3
 WHERE id = $1 AND ownerId = $2
 RETURNING *;
 
+-- name: ListPendingTransactions :many
+SELECT * FROM transactions
+WHERE accountId = $1 AND status = 'PENDING' AND date BETWEEN @startdate AND @enddate
+ORDER BY date, sourceId;
+
+-- name: UpdateTransactionSourceId :exec
+UPDATE transactions
+SET sourceId = @newSourceId
+WHERE ownerId = @ownerId AND sourceId = @sourceId;
+
+-- name: SetTransactionCategory :one
+-- Categories set by the user are labels the categorizer learns from
+UPDATE transactions
+SET
+    categoryId = $3,
+    categorySource = CASE WHEN $3::uuid IS NULL THEN NULL ELSE 'MANUAL'::CATEGORY_SOURCE END,
+    categoryConfidence = NULL,
+    needsReview = FALSE
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: SetTransactionPrediction :exec
+-- Predictions only categorize transactions without a category, so they never
+-- replace the user's choice
+UPDATE transactions
+SET
+    categoryId = $3,
+    categorySource = 'CLASSIFIER',
+    categoryConfidence = $4,
+    needsReview = $5
+WHERE id = $1 AND ownerId = $2 AND categoryId IS NULL;
+
+-- name: ListTransactionsByIds :many
+SELECT * FROM transactions
+WHERE ownerId = @ownerId AND id::varchar = ANY(@transactionIds::varchar[])
+ORDER BY date DESC;
+
+-- name: SetTransactionNote :one
+UPDATE transactions
+SET note = $3
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: ListLabeledTransactions :many
+SELECT description, merchantId, amount, type, categoryId FROM transactions
+WHERE ownerId = $1 AND categorySource = 'MANUAL' AND categoryId IS NOT NULL;
+
 -- name: DeleteTransaction :one
 DELETE FROM transactions
 WHERE id = $1
 RETURNING *;
 
+-- TRANSACTION SPLITS
+
+-- name: ListTransactionSplitsByTransactionIds :many
+SELECT * FROM transaction_splits
+WHERE ownerId = @ownerId AND transactionId::varchar = ANY(@transactionIds::varchar[])
+ORDER BY transactionId, abs(amount) DESC, id;
+
+-- name: CreateTransactionSplit :one
+INSERT INTO transaction_splits (transactionId, amount, categoryId, note, fundId, ownerId)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeleteTransactionSplits :exec
+DELETE FROM transaction_splits
+WHERE transactionId = $1 AND ownerId = $2;
+
+-- name: RebalanceTransactionSplits :exec
+-- Adds the difference between split transactions' amounts and their parts to
+-- their largest part, for when an import or revert changed the amount. Every
+-- split transaction of the user is rebalanced when transactionId is null
+UPDATE transaction_splits AS s
+SET amount = s.amount + t.amount - (
+    SELECT SUM(p.amount) FROM transaction_splits AS p WHERE p.transactionId = t.id
+)
+FROM transactions AS t
+WHERE s.transactionId = t.id AND t.ownerId = @ownerId
+    AND (sqlc.narg('transactionId')::uuid IS NULL OR t.id = sqlc.narg('transactionId'))
+    AND s.id = (
+        SELECT p.id FROM transaction_splits AS p
+        WHERE p.transactionId = t.id
+        ORDER BY abs(p.amount) DESC, p.id
+        LIMIT 1
+    );
+
+-- TAGS
+
+-- name: ListTags :many
+SELECT * FROM tags
+WHERE ownerId = $1
+ORDER BY lower(name);
+
+-- name: ListTagsByTransactionIds :many
+SELECT tt.transactionId, sqlc.embed(tag) FROM transaction_tags AS tt
+JOIN tags AS tag ON tt.tagId = tag.id
+WHERE tag.ownerId = @ownerId AND tt.transactionId::varchar = ANY(@transactionIds::varchar[])
+ORDER BY lower(tag.name);
+
+-- name: UpsertTag :one
+-- Finds a tag by name ignoring case, creating it if the user doesn't have it
+INSERT INTO tags (name, ownerId)
+VALUES ($1, $2)
+ON CONFLICT (ownerId, lower(name)) DO UPDATE
+SET name = tags.name
+RETURNING *;
+
+-- name: DeleteTag :one
+DELETE FROM tags
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: AddTransactionTags :execrows
+-- Tags the user's transactions with the tags named in lowercase names
+INSERT INTO transaction_tags (transactionId, tagId)
+SELECT t.id, tag.id FROM transactions AS t, tags AS tag
+WHERE t.ownerId = @ownerId AND t.id::varchar = ANY(@transactionIds::varchar[])
+    AND tag.ownerId = @ownerId AND lower(tag.name) = ANY(@names::varchar[])
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTransactionTags :execrows
+DELETE FROM transaction_tags AS tt
+USING transactions AS t, tags AS tag
+WHERE tt.transactionId = t.id AND tt.tagId = tag.id
+    AND t.ownerId = @ownerId AND t.id::varchar = ANY(@transactionIds::varchar[])
+    AND lower(tag.name) = ANY(@names::varchar[]);
+
 -- MERCHANTS
 
 -- name: GetMerchant :one
@@ -220,21 +487,31 @@ WHERE id = $1 and ownerId = $2
 LIMIT 1;
 
 -- name: ListMerchantsByMerchantIds :many
-SELECT m.* FROM transactions AS t, merchants AS m
-WHERE t.merchantId = m.id AND m.id::varchar = ANY(@merchantIds::varchar[])
-ORDER BY date DESC;
+SELECT * FROM merchants
+WHERE ownerId = @ownerId AND id::varchar = ANY(@merchantIds::varchar[]);
 
 -- name: GetMerchantByKey :one
+-- Keys are LIKE patterns matched against the uppercase description. The
+-- longest matching key is the most specific
 SELECT m.* FROM merchants AS m JOIN merchant_keys AS k ON m.id = k.merchantId
-WHERE uploadSource = $1 AND keymatch LIKE $2;
+WHERE k.ownerId = $1 AND k.uploadSource = $2 AND upper(@description::varchar) LIKE k.keymatch
+ORDER BY length(k.keymatch) DESC
+LIMIT 1;
 
 -- name: GetMerchantByName :one
 SELECT * FROM merchants
-WHERE name = $1;
+WHERE ownerId = $1 AND name = $2
+LIMIT 1;
 
 -- name: GetMerchantBySourceId :one
 SELECT * FROM merchants
-WHERE sourceId = $1;
+WHERE ownerId = $1 AND sourceId = $2
+LIMIT 1;
+
+-- name: GetMerchantByGlobalMerchantId :one
+SELECT * FROM merchants
+WHERE ownerId = $1 AND globalMerchantId = $2
+LIMIT 1;
 
 -- name: ListMerchants :many
 SELECT * FROM merchants
@@ -250,9 +527,140 @@ WHERE ownerId = $1;
 INSERT INTO merchants (
     name,
     sourceId,
+    ownerId,
+    syncItemId,
+    globalMerchantId,
+    categoryId
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: UpdateMerchantOverrides :one
+UPDATE merchants
+SET
+    website = $3,
+    categoryId = $4,
+    globalMerchantId = $5
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: RenameMerchant :one
+UPDATE merchants
+SET name = $3
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: MoveMerchantTransactions :exec
+UPDATE transactions
+SET merchantId = @targetId
+WHERE ownerId = @ownerId AND merchantId::varchar = ANY(@merchantIds::varchar[]);
+
+-- name: MoveTransactionsToMerchant :execrows
+UPDATE transactions
+SET merchantId = @targetId
+WHERE ownerId = @ownerId
+    AND merchantId = @merchantId
+    AND id::varchar = ANY(@transactionIds::varchar[]);
+
+-- name: ListMerchantTransactionsByIds :many
+SELECT sqlc.embed(t), s.uploadSource FROM transactions AS t
+LEFT JOIN account_sync_items AS s ON t.syncItemId = s.id
+WHERE t.ownerId = @ownerId
+    AND t.merchantId = @merchantId
+    AND t.id::varchar = ANY(@transactionIds::varchar[])
+ORDER BY t.date DESC;
+
+-- name: DeleteMerchants :execrows
+DELETE FROM merchants
+WHERE ownerId = @ownerId AND id::varchar = ANY(@merchantIds::varchar[]);
+
+-- name: DeleteSyncItemMerchants :execrows
+DELETE FROM merchants AS m
+WHERE m.syncItemId = $1
+    AND NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.merchantId = m.id)
+    AND NOT EXISTS (SELECT 1 FROM merchant_rules AS r WHERE r.merchantId = m.id);
+
+-- CATEGORIES
+
+-- name: GetCategory :one
+-- Finds a default category or one of the user's own
+SELECT * FROM categories
+WHERE id = @id AND (ownerId IS NULL OR ownerId = @ownerId::uuid)
+LIMIT 1;
+
+-- name: ListCategories :many
+SELECT * FROM categories
+WHERE ownerId IS NULL OR ownerId = @ownerId::uuid
+ORDER BY name;
+
+-- name: CountSubcategories :one
+SELECT count(id) FROM categories
+WHERE parentId = $1;
+
+-- name: CreateCategory :one
+INSERT INTO categories (
+    name,
+    parentId,
     ownerId
 )
-VALUES ($1, $2, $3)
+VALUES (@name, @parentId, @ownerId::uuid)
+RETURNING *;
+
+-- name: UpdateCategory :one
+-- Default categories have no owner, so they can't be changed
+UPDATE categories
+SET
+    name = @name,
+    parentId = @parentId
+WHERE id = @id AND ownerId = @ownerId::uuid
+RETURNING *;
+
+-- name: DeleteCategory :one
+DELETE FROM categories
+WHERE id = @id AND ownerId = @ownerId::uuid
+RETURNING *;
+
+-- GLOBAL MERCHANTS
+
+-- name: GetGlobalMerchant :one
+SELECT * FROM global_merchants
+WHERE id = $1
+LIMIT 1;
+
+-- name: FindGlobalMerchant :one
+-- Finds the global merchant with a name or alias, ignoring case
+SELECT * FROM global_merchants
+WHERE upper(name) = upper(@name::varchar) OR upper(@name::varchar) = ANY(aliases)
+LIMIT 1;
+
+-- name: ListGlobalMerchants :many
+SELECT * FROM global_merchants
+WHERE @search::varchar = '' OR name ILIKE '%' || @search::varchar || '%'
+ORDER BY name
+LIMIT $1;
+
+-- name: ListGlobalMerchantsByIds :many
+SELECT * FROM global_merchants
+WHERE id::varchar = ANY(@ids::varchar[]);
+
+-- name: CreateGlobalMerchant :one
+INSERT INTO global_merchants (
+    name,
+    website,
+    categoryId,
+    aliases
+)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: UpdateGlobalMerchant :one
+UPDATE global_merchants
+SET
+    name = $2,
+    website = $3,
+    categoryId = $4,
+    aliases = $5
+WHERE id = $1
 RETURNING *;
 
 -- MERCHANT KEYS
@@ -267,27 +675,173 @@ INSERT INTO merchant_keys (
 VALUES ($1, $2, $3, $4)
 RETURNING *;
 
+-- name: UpsertMerchantKey :one
+INSERT INTO merchant_keys (
+    keymatch,
+    uploadSource,
+    merchantId,
+    ownerId
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (ownerId, keymatch) DO UPDATE
+SET
+    uploadSource = EXCLUDED.uploadSource,
+    merchantId = EXCLUDED.merchantId
+RETURNING *;
+
+-- name: GetMerchantByKeyMatch :one
+SELECT m.* FROM merchants AS m JOIN merchant_keys AS k ON m.id = k.merchantId
+WHERE k.ownerId = $1 AND k.keymatch = $2
+LIMIT 1;
+
+-- name: MoveMerchantKeys :exec
+UPDATE merchant_keys
+SET merchantId = @targetId
+WHERE ownerId = @ownerId AND merchantId::varchar = ANY(@merchantIds::varchar[]);
+
+-- name: MoveMatchingMerchantKeys :exec
+-- Moves the keys that match the target's transactions to the target, leaving
+-- keys that still match one of the merchant's own transactions
+UPDATE merchant_keys AS k
+SET merchantId = @targetId
+WHERE k.ownerId = @ownerId
+    AND k.merchantId = @merchantId
+    AND EXISTS (
+        SELECT 1 FROM transactions AS t
+        WHERE t.merchantId = @targetId
+            AND (upper(t.description) LIKE k.keymatch OR strpos(upper(t.description), k.keymatch) > 0)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM transactions AS t
+        WHERE t.merchantId = @merchantId
+            AND (upper(t.description) LIKE k.keymatch OR strpos(upper(t.description), k.keymatch) > 0)
+    );
+
+-- name: DeleteSyncItemMerchantKeys :exec
+DELETE FROM merchant_keys AS k
+USING merchants AS m
+WHERE k.merchantId = m.id
+    AND m.syncItemId = $1
+    AND NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.merchantId = m.id)
+    AND NOT EXISTS (SELECT 1 FROM merchant_rules AS r WHERE r.merchantId = m.id);
+
+-- MERCHANT RULES
+
+-- name: GetMerchantRule :one
+SELECT * FROM merchant_rules
+WHERE id = $1 AND ownerId = $2
+LIMIT 1;
+
+-- name: ListMerchantRules :many
+SELECT * FROM merchant_rules
+WHERE ownerId = $1
+ORDER BY priority, created;
+
+-- name: ListUploadSourceMerchantRules :many
+-- Rules without an upload source apply to every source
+SELECT * FROM merchant_rules
+WHERE ownerId = $1 AND (uploadSource IS NULL OR uploadSource = $2)
+ORDER BY priority, created;
+
+-- name: CreateMerchantRule :one
+INSERT INTO merchant_rules (
+    priority,
+    type,
+    pattern,
+    minAmount,
+    maxAmount,
+    uploadSource,
+    merchantId,
+    ownerId
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: UpdateMerchantRule :one
+UPDATE merchant_rules
+SET
+    priority = $3,
+    type = $4,
+    pattern = $5,
+    minAmount = $6,
+    maxAmount = $7,
+    uploadSource = $8,
+    merchantId = $9
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: DeleteMerchantRule :one
+DELETE FROM merchant_rules
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: MoveMerchantRules :exec
+UPDATE merchant_rules
+SET merchantId = @targetId
+WHERE ownerId = @ownerId AND merchantId::varchar = ANY(@merchantIds::varchar[]);
+
+-- name: ListMerchantRuleCandidates :many
+-- Every transaction with the source it was imported from, for testing a rule
+-- against the user's history
+SELECT sqlc.embed(t), s.uploadSource FROM transactions AS t
+LEFT JOIN account_sync_items AS s ON t.syncItemId = s.id
+WHERE t.ownerId = $1
+ORDER BY t.date DESC;
+
 -- STATS
 
 -- name: GetTotalSpending :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND amount < 0 AND date BETWEEN @startdate AND @enddate;
+-- Split transactions are counted by their parts
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND COALESCE(s.amount, t.amount) < 0 AND t.date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR t.status = 'POSTED');
 
 -- name: GetTotalIncome :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND amount > 0 AND date BETWEEN @startdate AND @enddate;
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND COALESCE(s.amount, t.amount) > 0 AND t.date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR t.status = 'POSTED');
 
 -- name: GetNetIncome :one
 SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND date BETWEEN @startdate AND @enddate;
+WHERE ownerId = $1 AND date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR status = 'POSTED');
 
 -- name: GetAccountSpending :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND accountId = $2 AND amount < 0;
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND t.accountId = $2 AND COALESCE(s.amount, t.amount) < 0;
 
 -- name: GetAccountIncome :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND accountId = $2 AND amount > 0;
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND t.accountId = $2 AND COALESCE(s.amount, t.amount) > 0;
+
+-- name: GetSpendingByCategory :many
+-- Spending grouped by category. Split transactions are grouped by their parts'
+-- categories. Parts and transactions without a category have the
+-- transaction's category, then the merchant's
+SELECT COALESCE(s.categoryId, t.categoryId, m.categoryId) AS categoryId, count(DISTINCT t.id), SUM(COALESCE(s.amount, t.amount))::bigint AS total
+FROM transactions AS t
+JOIN merchants AS m ON t.merchantId = m.id
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND COALESCE(s.amount, t.amount) < 0 AND t.date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR t.status = 'POSTED')
+GROUP BY COALESCE(s.categoryId, t.categoryId, m.categoryId);
+
+-- name: GetTagTotals :many
+-- Spending and income of each tag's transactions across accounts and
+-- categories. Split transactions are counted by their parts
+SELECT tt.tagId, count(DISTINCT t.id),
+    COALESCE(SUM(CASE WHEN COALESCE(s.amount, t.amount) < 0 THEN COALESCE(s.amount, t.amount) ELSE 0 END), 0)::bigint AS spending,
+    COALESCE(SUM(CASE WHEN COALESCE(s.amount, t.amount) > 0 THEN COALESCE(s.amount, t.amount) ELSE 0 END), 0)::bigint AS income
+FROM transaction_tags AS tt
+JOIN transactions AS t ON tt.transactionId = t.id
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND t.date BETWEEN @startdate AND @enddate
+    AND (@includePending::boolean OR t.status = 'POSTED')
+GROUP BY tt.tagId;
 
 
 -- FUNDS
@@ -297,6 +851,11 @@ INSERT INTO funds (type, name, goal, startDate, endDate, ownerId)
 VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
+-- name: GetFund :one
+SELECT * FROM funds
+WHERE id = $1 AND ownerId = $2
+LIMIT 1;
+
 -- name: ListSavingsFunds :many
 SELECT * FROM funds
 WHERE ownerId = $1 AND type = 'SAVINGS'
@@ -346,6 +905,379 @@ FROM fund_allocations AS a, funds AS f
 WHERE f.ownerId = $1 AND a.date <= @enddate;
 
 
+-- CSV PROFILES
+
+-- name: GetCsvProfile :one
+SELECT * FROM csv_profiles
+WHERE id = $1 AND ownerId = $2
+LIMIT 1;
+
+-- name: ListCsvProfiles :many
+SELECT * FROM csv_profiles
+WHERE ownerId = $1
+ORDER BY name;
+
+-- name: CreateCsvProfile :one
+INSERT INTO csv_profiles (
+    name,
+    institution,
+    dateColumn,
+    descriptionColumn,
+    amountColumn,
+    debitColumn,
+    creditColumn,
+    typeColumn,
+    checkNumberColumn,
+    dateFormat,
+    signConvention,
+    skipRows,
+    hasHeader,
+    ownerId,
+    balanceColumn
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING *;
+
+-- name: UpdateCsvProfile :one
+UPDATE csv_profiles
+SET
+    name = $3,
+    institution = $4,
+    dateColumn = $5,
+    descriptionColumn = $6,
+    amountColumn = $7,
+    debitColumn = $8,
+    creditColumn = $9,
+    typeColumn = $10,
+    checkNumberColumn = $11,
+    dateFormat = $12,
+    signConvention = $13,
+    skipRows = $14,
+    hasHeader = $15,
+    balanceColumn = $16
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- name: DeleteCsvProfile :one
+DELETE FROM csv_profiles
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+
+-- MANUAL ASSETS
+
+-- name: GetManualAsset :one
+SELECT * FROM manual_assets
+WHERE id = $1 AND ownerId = $2
+LIMIT 1;
+
+-- name: ListManualAssets :many
+SELECT * FROM manual_assets
+WHERE ownerId = $1
+ORDER BY name;
+
+-- name: CreateManualAsset :one
+INSERT INTO manual_assets (name, type, ownerId)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: DeleteManualAsset :one
+DELETE FROM manual_assets
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+
+-- MANUAL ASSET VALUATIONS
+
+-- name: ListManualAssetValuations :many
+SELECT * FROM manual_asset_valuations
+WHERE assetId = $1
+ORDER BY date DESC;
+
+-- name: ListNetWorthAssetValuations :many
+SELECT v.*, m.type AS assetType FROM manual_asset_valuations AS v
+JOIN manual_assets AS m ON v.assetId = m.id
+WHERE v.ownerId = $1 AND v.date <= @enddate
+ORDER BY v.date;
+
+-- name: UpsertManualAssetValuation :one
+INSERT INTO manual_asset_valuations (assetId, date, value, ownerId)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (assetId, date) DO UPDATE
+SET value = $3
+RETURNING *;
+
+
+-- IMPORT BATCHES
+
+-- name: GetCompletedImportBatch :one
+SELECT * FROM import_batches
+WHERE ownerId = $1 AND fileHash = $2 AND status = 'COMPLETED'
+LIMIT 1;
+
+-- name: ListImportBatches :many
+SELECT * FROM import_batches
+WHERE ownerId = $1
+ORDER BY created DESC
+LIMIT $2;
+
+-- name: CreateImportBatch :one
+INSERT INTO import_batches (
+    fileName,
+    fileHash,
+    uploadSource,
+    status,
+    accountsUpdated,
+    accountsFailed,
+    transactionsUpdated,
+    transactionsFailed,
+    errors,
+    ownerId
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: RevertImportBatch :exec
+-- Marks the batch reverted once none of its sync items are left
+UPDATE import_batches AS b
+SET status = 'REVERTED'
+WHERE b.id = $1
+    AND NOT EXISTS (SELECT 1 FROM account_sync_items AS s WHERE s.importBatchId = b.id);
+
+
+-- TRANSACTION REVISIONS
+
+-- name: CreateTransactionRevision :exec
+INSERT INTO transaction_revisions (
+    transactionId,
+    syncItemId,
+    amount,
+    payeeId,
+    payee,
+    payeeFull,
+    isoCurrencyCode,
+    date,
+    description,
+    type,
+    checkNumber,
+    updated,
+    sourceId,
+    status,
+    authorizedDate
+)
+SELECT id, @syncItemId, amount, payeeId, payee, payeeFull, isoCurrencyCode, date, description, type, checkNumber, updated, sourceId, status, authorizedDate
+FROM transactions
+WHERE ownerId = @ownerId AND sourceId = @sourceId
+ON CONFLICT (syncItemId, transactionId) DO NOTHING;
+
+-- name: CountLaterTransactionRevisions :one
+SELECT count(later.id) FROM transaction_revisions AS later
+WHERE later.syncItemId <> $1 AND (
+    later.transactionId IN (SELECT id FROM transactions WHERE syncItemId = $1)
+    OR EXISTS (
+        SELECT 1 FROM transaction_revisions AS r
+        WHERE r.syncItemId = $1
+            AND r.transactionId = later.transactionId
+            AND r.created < later.created
+    )
+);
+
+-- name: RestoreTransactionRevisions :execrows
+UPDATE transactions AS t
+SET
+    amount = r.amount,
+    payeeId = r.payeeId,
+    payee = r.payee,
+    payeeFull = r.payeeFull,
+    isoCurrencyCode = r.isoCurrencyCode,
+    date = r.date,
+    description = r.description,
+    type = r.type,
+    checkNumber = r.checkNumber,
+    updated = r.updated,
+    sourceId = r.sourceId,
+    status = r.status,
+    authorizedDate = r.authorizedDate
+FROM transaction_revisions AS r
+WHERE r.transactionId = t.id AND r.syncItemId = $1;
+
+
+-- SYNC CONNECTIONS
+
+-- name: GetSyncConnection :one
+SELECT * FROM sync_connections
+WHERE id = $1 AND ownerId = $2
+LIMIT 1;
+
+-- name: ListSyncConnections :many
+SELECT * FROM sync_connections
+WHERE ownerId = $1
+ORDER BY created;
+
+-- name: UpsertSyncConnection :one
+INSERT INTO sync_connections (
+    provider, itemId, accessToken, ownerId
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (provider, itemId) DO UPDATE
+SET accessToken = EXCLUDED.accessToken
+WHERE sync_connections.ownerId = EXCLUDED.ownerId
+RETURNING *;
+
+-- name: UpdateSyncConnectionSynced :exec
+UPDATE sync_connections
+SET lastSynced = NOW()
+WHERE id = $1;
+
+-- name: GetSyncConnectionCursor :one
+SELECT syncCursor FROM accounts
+WHERE syncConnectionId = $1 AND syncCursor IS NOT NULL
+LIMIT 1;
+
+-- name: LinkSyncConnectionAccounts :exec
+UPDATE accounts
+SET syncConnectionId = $1
+WHERE ownerId = $2 AND sourceId = ANY(@sourceIds::varchar[]);
+
+-- name: UpdateSyncConnectionCursor :exec
+UPDATE accounts
+SET syncCursor = $2
+WHERE syncConnectionId = $1;
+
+-- OFX CONNECTIONS
+
+-- name: GetOfxConnection :one
+SELECT * FROM ofx_connections
+WHERE id = $1 AND ownerId = $2
+LIMIT 1;
+
+-- name: ListOfxConnections :many
+SELECT * FROM ofx_connections
+WHERE ownerId = $1
+ORDER BY created;
+
+-- name: ListDueOfxConnections :many
+SELECT * FROM ofx_connections
+WHERE lastAttempted IS NULL OR lastAttempted < @attemptedBefore::timestamp
+ORDER BY lastAttempted NULLS FIRST;
+
+-- name: UpsertOfxConnection :one
+INSERT INTO ofx_connections (
+    url, org, fid, appId, appVersion, ofxVersion, credentials, accountId, ownerId
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (accountId) DO UPDATE
+SET url = EXCLUDED.url,
+    org = EXCLUDED.org,
+    fid = EXCLUDED.fid,
+    appId = EXCLUDED.appId,
+    appVersion = EXCLUDED.appVersion,
+    ofxVersion = EXCLUDED.ofxVersion,
+    credentials = EXCLUDED.credentials,
+    lastError = NULL
+WHERE ofx_connections.ownerId = EXCLUDED.ownerId
+RETURNING *;
+
+-- name: UpdateOfxConnectionSynced :exec
+UPDATE ofx_connections
+SET lastAttempted = NOW(), lastSynced = NOW(), lastError = NULL
+WHERE id = $1;
+
+-- name: UpdateOfxConnectionFailed :exec
+UPDATE ofx_connections
+SET lastAttempted = NOW(), lastError = $2
+WHERE id = $1;
+
+-- name: DeleteOfxConnection :one
+DELETE FROM ofx_connections
+WHERE id = $1 AND ownerId = $2
+RETURNING *;
+
+-- SECURITIES
+
+-- name: UpsertSecurity :one
+-- Securities are shared by every user's accounts, so statements only fill
+-- in details a security is missing, e.g. one first saved by id
+INSERT INTO securities (
+    uniqueId, uniqueIdType, ticker, name, type
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (uniqueIdType, uniqueId) DO UPDATE
+SET ticker = COALESCE(securities.ticker, EXCLUDED.ticker),
+    name = CASE WHEN securities.name = securities.uniqueId THEN EXCLUDED.name ELSE securities.name END,
+    type = CASE WHEN securities.type = 'OTHER' THEN EXCLUDED.type ELSE securities.type END
+RETURNING *;
+
+-- name: GetSecurity :one
+SELECT * FROM securities
+WHERE id = $1
+LIMIT 1;
+
+-- HOLDINGS
+
+-- name: DeleteAccountHoldings :exec
+DELETE FROM holdings
+WHERE accountId = $1 AND date = $2;
+
+-- name: CreateHolding :one
+INSERT INTO holdings (
+    date, units, unitPrice, marketValue, securityId, accountId, ownerId, syncItemId
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: ListAccountHoldings :many
+SELECT * FROM holdings
+WHERE accountId = $1 AND date = (
+    SELECT MAX(date) FROM holdings
+    WHERE accountId = $1 AND date <= @asOf::date
+)
+ORDER BY marketValue DESC;
+
+-- INVESTMENT TRANSACTIONS
+
+-- name: UpsertInvestmentTransaction :one
+INSERT INTO investment_transactions (
+    sourceId,
+    type,
+    tradeDate,
+    settleDate,
+    units,
+    unitPrice,
+    amount,
+    fees,
+    isoCurrencyCode,
+    description,
+    securityId,
+    accountId,
+    ownerId,
+    syncItemId
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (accountId, sourceId) DO UPDATE
+SET
+    type = $2,
+    tradeDate = $3,
+    settleDate = $4,
+    units = $5,
+    unitPrice = $6,
+    amount = $7,
+    fees = $8,
+    isoCurrencyCode = $9,
+    description = $10,
+    securityId = $11,
+    syncItemId = $14
+RETURNING *;
+
+-- name: ListAccountInvestmentTransactions :many
+SELECT * FROM investment_transactions
+WHERE accountId = $1 AND tradeDate BETWEEN @startdate AND @enddate
+ORDER BY tradeDate DESC, sourceId;
+
 -- MONTHS
 
 -- name: ListMonths :many
@@ -14,6 +14,29 @@ import (
 	"github.com/lib/pq"
 )
 
+const addTransactionTags = `-- name: AddTransactionTags :execrows
+INSERT INTO transaction_tags (transactionId, tagId)
+SELECT t.id, tag.id FROM transactions AS t, tags AS tag
+WHERE t.ownerId = $1 AND t.id::varchar = ANY($2::varchar[])
+    AND tag.ownerId = $1 AND lower(tag.name) = ANY($3::varchar[])
+ON CONFLICT DO NOTHING
+`
+
+type AddTransactionTagsParams struct {
+	Ownerid        uuid.UUID
+	Transactionids []string
+	Names          []string
+}
+
+// Tags the user's transactions with the tags named in lowercase names
+func (q *Queries) AddTransactionTags(ctx context.Context, arg AddTransactionTagsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addTransactionTags, arg.Ownerid, pq.Array(arg.Transactionids), pq.Array(arg.Names))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const countAccounts = `-- name: CountAccounts :one
 SELECT count(id) FROM accounts AS a
 WHERE ownerId = $1
@@ -78,21 +101,48 @@ SELECT count(t.id) as merchantId FROM transactions AS t
 WHERE ownerId = $1
     AND amount >= 0
     AND date BETWEEN $2 AND $3
+    AND ($4::boolean OR status = 'POSTED')
 `
 
 type CountIncomeTransactionsParams struct {
-	Ownerid   uuid.UUID
-	Startdate time.Time
-	Enddate   time.Time
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
 }
 
 func (q *Queries) CountIncomeTransactions(ctx context.Context, arg CountIncomeTransactionsParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countIncomeTransactions, arg.Ownerid, arg.Startdate, arg.Enddate)
+	row := q.db.QueryRowContext(ctx, countIncomeTransactions,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
 	var merchantid int64
 	err := row.Scan(&merchantid)
 	return merchantid, err
 }
 
+const countLaterTransactionRevisions = `-- name: CountLaterTransactionRevisions :one
+SELECT count(later.id) FROM transaction_revisions AS later
+WHERE later.syncItemId <> $1 AND (
+    later.transactionId IN (SELECT id FROM transactions WHERE syncItemId = $1)
+    OR EXISTS (
+        SELECT 1 FROM transaction_revisions AS r
+        WHERE r.syncItemId = $1
+            AND r.transactionId = later.transactionId
+            AND r.created < later.created
+    )
+)
+`
+
+func (q *Queries) CountLaterTransactionRevisions(ctx context.Context, syncitemid uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countLaterTransactionRevisions, syncitemid)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countMerchants = `-- name: CountMerchants :one
 SELECT count(id) FROM merchants
 WHERE ownerId = $1
@@ -122,28 +172,80 @@ SELECT count(t.id) as merchantId FROM transactions AS t
 WHERE ownerId = $1
     AND amount < 0
     AND date BETWEEN $2 AND $3
+    AND ($4::boolean OR status = 'POSTED')
 `
 
 type CountSpendingTransactionsParams struct {
-	Ownerid   uuid.UUID
-	Startdate time.Time
-	Enddate   time.Time
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
 }
 
 func (q *Queries) CountSpendingTransactions(ctx context.Context, arg CountSpendingTransactionsParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countSpendingTransactions, arg.Ownerid, arg.Startdate, arg.Enddate)
+	row := q.db.QueryRowContext(ctx, countSpendingTransactions,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
 	var merchantid int64
 	err := row.Scan(&merchantid)
 	return merchantid, err
 }
 
+const countSubcategories = `-- name: CountSubcategories :one
+SELECT count(id) FROM categories
+WHERE parentId = $1
+`
+
+func (q *Queries) CountSubcategories(ctx context.Context, parentid uuid.NullUUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countSubcategories, parentid)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countTransactions = `-- name: CountTransactions :one
-SELECT count(id) FROM transactions AS a
-WHERE ownerId = $1
+SELECT count(t.id) FROM transactions AS t
+JOIN merchants AS m ON t.merchantId = m.id
+WHERE t.ownerId = $1 AND ($2::boolean OR t.status = 'POSTED')
+    AND ($3::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM categories AS c
+        WHERE (c.id = $3 OR c.parentId = $3)
+            AND c.id IN (
+                SELECT COALESCE(s.categoryId, t.categoryId, m.categoryId)
+                FROM transaction_splits AS s
+                WHERE s.transactionId = t.id
+                UNION ALL
+                SELECT COALESCE(t.categoryId, m.categoryId)
+                WHERE NOT EXISTS (SELECT 1 FROM transaction_splits AS s WHERE s.transactionId = t.id)
+            )
+    ))
+    AND ($4::boolean IS NULL OR t.needsReview = $4)
+    AND ($5::varchar[] IS NULL OR EXISTS (
+        SELECT 1 FROM transaction_tags AS tt
+        JOIN tags AS tag ON tt.tagId = tag.id
+        WHERE tt.transactionId = t.id AND lower(tag.name) = ANY($5::varchar[])
+    ))
 `
 
-func (q *Queries) CountTransactions(ctx context.Context, ownerid uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countTransactions, ownerid)
+type CountTransactionsParams struct {
+	Ownerid        uuid.UUID
+	Includepending bool
+	Categoryid     uuid.NullUUID
+	Needsreview    sql.NullBool
+	Tags           []string
+}
+
+func (q *Queries) CountTransactions(ctx context.Context, arg CountTransactionsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransactions,
+		arg.Ownerid,
+		arg.Includepending,
+		arg.Categoryid,
+		arg.Needsreview,
+		pq.Array(arg.Tags),
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -153,16 +255,22 @@ const countTransactionsByAccountIds = `-- name: CountTransactionsByAccountIds :m
 SELECT count(t.id), a.id as accountId FROM transactions AS t, accounts AS a
 WHERE t.accountId = a.id
     AND a.id::varchar = ANY($1::varchar[])
+    AND ($2::boolean OR t.status = 'POSTED')
 GROUP BY a.id
 `
 
+type CountTransactionsByAccountIdsParams struct {
+	Accountids     []string
+	Includepending bool
+}
+
 type CountTransactionsByAccountIdsRow struct {
 	Count     int64
 	Accountid uuid.UUID
 }
 
-func (q *Queries) CountTransactionsByAccountIds(ctx context.Context, accountids []string) ([]CountTransactionsByAccountIdsRow, error) {
-	rows, err := q.db.QueryContext(ctx, countTransactionsByAccountIds, pq.Array(accountids))
+func (q *Queries) CountTransactionsByAccountIds(ctx context.Context, arg CountTransactionsByAccountIdsParams) ([]CountTransactionsByAccountIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTransactionsByAccountIds, pq.Array(arg.Accountids), arg.Includepending)
 	if err != nil {
 		return nil, err
 	}
@@ -186,17 +294,23 @@ func (q *Queries) CountTransactionsByAccountIds(ctx context.Context, accountids
 
 const countTransactionsByDates = `-- name: CountTransactionsByDates :one
 SELECT count(id) FROM transactions AS a
-WHERE ownerId = $1 AND date BETWEEN $2 AND $3
+WHERE ownerId = $1 AND date BETWEEN $2 AND $3 AND ($4::boolean OR status = 'POSTED')
 `
 
 type CountTransactionsByDatesParams struct {
-	Ownerid   uuid.UUID
-	Startdate time.Time
-	Enddate   time.Time
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
 }
 
 func (q *Queries) CountTransactionsByDates(ctx context.Context, arg CountTransactionsByDatesParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countTransactionsByDates, arg.Ownerid, arg.Startdate, arg.Enddate)
+	row := q.db.QueryRowContext(ctx, countTransactionsByDates,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -204,18 +318,26 @@ func (q *Queries) CountTransactionsByDates(ctx context.Context, arg CountTransac
 
 const countTransactionsByMerchantIds = `-- name: CountTransactionsByMerchantIds :many
 SELECT count(t.id), m.id as merchantId FROM transactions AS t, merchants AS m
-WHERE t.merchantId = m.id
-    AND m.id::varchar = ANY($1::varchar[])
+WHERE t.ownerId = $1
+    AND t.merchantId = m.id
+    AND m.id::varchar = ANY($2::varchar[])
+    AND ($3::boolean OR t.status = 'POSTED')
 GROUP BY m.id
 `
 
+type CountTransactionsByMerchantIdsParams struct {
+	Ownerid        uuid.UUID
+	Merchantids    []string
+	Includepending bool
+}
+
 type CountTransactionsByMerchantIdsRow struct {
 	Count      int64
 	Merchantid uuid.UUID
 }
 
-func (q *Queries) CountTransactionsByMerchantIds(ctx context.Context, merchantids []string) ([]CountTransactionsByMerchantIdsRow, error) {
-	rows, err := q.db.QueryContext(ctx, countTransactionsByMerchantIds, pq.Array(merchantids))
+func (q *Queries) CountTransactionsByMerchantIds(ctx context.Context, arg CountTransactionsByMerchantIdsParams) ([]CountTransactionsByMerchantIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTransactionsByMerchantIds, arg.Ownerid, pq.Array(arg.Merchantids), arg.Includepending)
 	if err != nil {
 		return nil, err
 	}
@@ -243,7 +365,7 @@ INSERT INTO account_sync_items (
     uploadSource
 )
 VALUES ($1, $2)
-RETURNING id, date, uploadsource, accountid
+RETURNING id, date, uploadsource, accountid, importbatchid
 `
 
 type CreateAccountSyncItemParams struct {
@@ -259,6 +381,116 @@ func (q *Queries) CreateAccountSyncItem(ctx context.Context, arg CreateAccountSy
 		&i.Date,
 		&i.Uploadsource,
 		&i.Accountid,
+		&i.Importbatchid,
+	)
+	return i, err
+}
+
+const createCategory = `-- name: CreateCategory :one
+INSERT INTO categories (
+    name,
+    parentId,
+    ownerId
+)
+VALUES ($1, $2, $3::uuid)
+RETURNING id, name, parentid, ownerid, created
+`
+
+type CreateCategoryParams struct {
+	Name     string
+	Parentid uuid.NullUUID
+	Ownerid  uuid.UUID
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, createCategory, arg.Name, arg.Parentid, arg.Ownerid)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Parentid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createCsvProfile = `-- name: CreateCsvProfile :one
+INSERT INTO csv_profiles (
+    name,
+    institution,
+    dateColumn,
+    descriptionColumn,
+    amountColumn,
+    debitColumn,
+    creditColumn,
+    typeColumn,
+    checkNumberColumn,
+    dateFormat,
+    signConvention,
+    skipRows,
+    hasHeader,
+    ownerId,
+    balanceColumn
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+RETURNING id, name, institution, datecolumn, descriptioncolumn, amountcolumn, debitcolumn, creditcolumn, typecolumn, checknumbercolumn, dateformat, signconvention, skiprows, hasheader, ownerid, balancecolumn
+`
+
+type CreateCsvProfileParams struct {
+	Name              string
+	Institution       sql.NullString
+	Datecolumn        string
+	Descriptioncolumn string
+	Amountcolumn      sql.NullString
+	Debitcolumn       sql.NullString
+	Creditcolumn      sql.NullString
+	Typecolumn        sql.NullString
+	Checknumbercolumn sql.NullString
+	Dateformat        string
+	Signconvention    SignConvention
+	Skiprows          int32
+	Hasheader         bool
+	Ownerid           uuid.UUID
+	Balancecolumn     sql.NullString
+}
+
+func (q *Queries) CreateCsvProfile(ctx context.Context, arg CreateCsvProfileParams) (CsvProfile, error) {
+	row := q.db.QueryRowContext(ctx, createCsvProfile,
+		arg.Name,
+		arg.Institution,
+		arg.Datecolumn,
+		arg.Descriptioncolumn,
+		arg.Amountcolumn,
+		arg.Debitcolumn,
+		arg.Creditcolumn,
+		arg.Typecolumn,
+		arg.Checknumbercolumn,
+		arg.Dateformat,
+		arg.Signconvention,
+		arg.Skiprows,
+		arg.Hasheader,
+		arg.Ownerid,
+		arg.Balancecolumn,
+	)
+	var i CsvProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Institution,
+		&i.Datecolumn,
+		&i.Descriptioncolumn,
+		&i.Amountcolumn,
+		&i.Debitcolumn,
+		&i.Creditcolumn,
+		&i.Typecolumn,
+		&i.Checknumbercolumn,
+		&i.Dateformat,
+		&i.Signconvention,
+		&i.Skiprows,
+		&i.Hasheader,
+		&i.Ownerid,
+		&i.Balancecolumn,
 	)
 	return i, err
 }
@@ -302,30 +534,215 @@ func (q *Queries) CreateFund(ctx context.Context, arg CreateFundParams) (Fund, e
 	return i, err
 }
 
+const createGlobalMerchant = `-- name: CreateGlobalMerchant :one
+INSERT INTO global_merchants (
+    name,
+    website,
+    categoryId,
+    aliases
+)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, website, categoryid, aliases, created
+`
+
+type CreateGlobalMerchantParams struct {
+	Name       string
+	Website    sql.NullString
+	Categoryid uuid.NullUUID
+	Aliases    []string
+}
+
+func (q *Queries) CreateGlobalMerchant(ctx context.Context, arg CreateGlobalMerchantParams) (GlobalMerchant, error) {
+	row := q.db.QueryRowContext(ctx, createGlobalMerchant,
+		arg.Name,
+		arg.Website,
+		arg.Categoryid,
+		pq.Array(arg.Aliases),
+	)
+	var i GlobalMerchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Website,
+		&i.Categoryid,
+		pq.Array(&i.Aliases),
+		&i.Created,
+	)
+	return i, err
+}
+
+const createHolding = `-- name: CreateHolding :one
+INSERT INTO holdings (
+    date, units, unitPrice, marketValue, securityId, accountId, ownerId, syncItemId
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, date, units, unitprice, marketvalue, securityid, accountid, ownerid, syncitemid
+`
+
+type CreateHoldingParams struct {
+	Date        time.Time
+	Units       float64
+	Unitprice   float64
+	Marketvalue int32
+	Securityid  uuid.UUID
+	Accountid   uuid.UUID
+	Ownerid     uuid.UUID
+	Syncitemid  uuid.NullUUID
+}
+
+func (q *Queries) CreateHolding(ctx context.Context, arg CreateHoldingParams) (Holding, error) {
+	row := q.db.QueryRowContext(ctx, createHolding,
+		arg.Date,
+		arg.Units,
+		arg.Unitprice,
+		arg.Marketvalue,
+		arg.Securityid,
+		arg.Accountid,
+		arg.Ownerid,
+		arg.Syncitemid,
+	)
+	var i Holding
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Units,
+		&i.Unitprice,
+		&i.Marketvalue,
+		&i.Securityid,
+		&i.Accountid,
+		&i.Ownerid,
+		&i.Syncitemid,
+	)
+	return i, err
+}
+
+const createImportBatch = `-- name: CreateImportBatch :one
+INSERT INTO import_batches (
+    fileName,
+    fileHash,
+    uploadSource,
+    status,
+    accountsUpdated,
+    accountsFailed,
+    transactionsUpdated,
+    transactionsFailed,
+    errors,
+    ownerId
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, filename, filehash, uploadsource, status, accountsupdated, accountsfailed, transactionsupdated, transactionsfailed, errors, created, ownerid
+`
+
+type CreateImportBatchParams struct {
+	Filename            string
+	Filehash            string
+	Uploadsource        UploadSource
+	Status              ImportStatus
+	Accountsupdated     int32
+	Accountsfailed      int32
+	Transactionsupdated int32
+	Transactionsfailed  int32
+	Errors              []string
+	Ownerid             uuid.UUID
+}
+
+func (q *Queries) CreateImportBatch(ctx context.Context, arg CreateImportBatchParams) (ImportBatch, error) {
+	row := q.db.QueryRowContext(ctx, createImportBatch,
+		arg.Filename,
+		arg.Filehash,
+		arg.Uploadsource,
+		arg.Status,
+		arg.Accountsupdated,
+		arg.Accountsfailed,
+		arg.Transactionsupdated,
+		arg.Transactionsfailed,
+		pq.Array(arg.Errors),
+		arg.Ownerid,
+	)
+	var i ImportBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.Filehash,
+		&i.Uploadsource,
+		&i.Status,
+		&i.Accountsupdated,
+		&i.Accountsfailed,
+		&i.Transactionsupdated,
+		&i.Transactionsfailed,
+		pq.Array(&i.Errors),
+		&i.Created,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const createManualAsset = `-- name: CreateManualAsset :one
+INSERT INTO manual_assets (name, type, ownerId)
+VALUES ($1, $2, $3)
+RETURNING id, name, type, ownerid
+`
+
+type CreateManualAssetParams struct {
+	Name    string
+	Type    ManualAssetType
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) CreateManualAsset(ctx context.Context, arg CreateManualAssetParams) (ManualAsset, error) {
+	row := q.db.QueryRowContext(ctx, createManualAsset, arg.Name, arg.Type, arg.Ownerid)
+	var i ManualAsset
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
 const createMerchant = `-- name: CreateMerchant :one
 INSERT INTO merchants (
     name,
     sourceId,
-    ownerId
+    ownerId,
+    syncItemId,
+    globalMerchantId,
+    categoryId
 )
-VALUES ($1, $2, $3)
-RETURNING id, name, sourceid, ownerid
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid
 `
 
 type CreateMerchantParams struct {
-	Name     string
-	Sourceid sql.NullString
-	Ownerid  uuid.UUID
+	Name             string
+	Sourceid         sql.NullString
+	Ownerid          uuid.UUID
+	Syncitemid       uuid.NullUUID
+	Globalmerchantid uuid.NullUUID
+	Categoryid       uuid.NullUUID
 }
 
 func (q *Queries) CreateMerchant(ctx context.Context, arg CreateMerchantParams) (Merchant, error) {
-	row := q.db.QueryRowContext(ctx, createMerchant, arg.Name, arg.Sourceid, arg.Ownerid)
+	row := q.db.QueryRowContext(ctx, createMerchant,
+		arg.Name,
+		arg.Sourceid,
+		arg.Ownerid,
+		arg.Syncitemid,
+		arg.Globalmerchantid,
+		arg.Categoryid,
+	)
 	var i Merchant
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Sourceid,
 		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
 	)
 	return i, err
 }
@@ -368,6 +785,131 @@ func (q *Queries) CreateMerchantKey(ctx context.Context, arg CreateMerchantKeyPa
 	return i, err
 }
 
+const createMerchantRule = `-- name: CreateMerchantRule :one
+INSERT INTO merchant_rules (
+    priority,
+    type,
+    pattern,
+    minAmount,
+    maxAmount,
+    uploadSource,
+    merchantId,
+    ownerId
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, priority, type, pattern, minamount, maxamount, uploadsource, merchantid, ownerid, created
+`
+
+type CreateMerchantRuleParams struct {
+	Priority     int32
+	Type         MerchantRuleType
+	Pattern      string
+	Minamount    sql.NullInt32
+	Maxamount    sql.NullInt32
+	Uploadsource NullUploadSource
+	Merchantid   uuid.UUID
+	Ownerid      uuid.UUID
+}
+
+func (q *Queries) CreateMerchantRule(ctx context.Context, arg CreateMerchantRuleParams) (MerchantRule, error) {
+	row := q.db.QueryRowContext(ctx, createMerchantRule,
+		arg.Priority,
+		arg.Type,
+		arg.Pattern,
+		arg.Minamount,
+		arg.Maxamount,
+		arg.Uploadsource,
+		arg.Merchantid,
+		arg.Ownerid,
+	)
+	var i MerchantRule
+	err := row.Scan(
+		&i.ID,
+		&i.Priority,
+		&i.Type,
+		&i.Pattern,
+		&i.Minamount,
+		&i.Maxamount,
+		&i.Uploadsource,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const createTransactionRevision = `-- name: CreateTransactionRevision :exec
+INSERT INTO transaction_revisions (
+    transactionId,
+    syncItemId,
+    amount,
+    payeeId,
+    payee,
+    payeeFull,
+    isoCurrencyCode,
+    date,
+    description,
+    type,
+    checkNumber,
+    updated,
+    sourceId,
+    status,
+    authorizedDate
+)
+SELECT id, $1, amount, payeeId, payee, payeeFull, isoCurrencyCode, date, description, type, checkNumber, updated, sourceId, status, authorizedDate
+FROM transactions
+WHERE ownerId = $2 AND sourceId = $3
+ON CONFLICT (syncItemId, transactionId) DO NOTHING
+`
+
+type CreateTransactionRevisionParams struct {
+	Syncitemid uuid.UUID
+	Ownerid    uuid.UUID
+	Sourceid   string
+}
+
+func (q *Queries) CreateTransactionRevision(ctx context.Context, arg CreateTransactionRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createTransactionRevision, arg.Syncitemid, arg.Ownerid, arg.Sourceid)
+	return err
+}
+
+const createTransactionSplit = `-- name: CreateTransactionSplit :one
+INSERT INTO transaction_splits (transactionId, amount, categoryId, note, fundId, ownerId)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, transactionid, amount, categoryid, note, fundid, ownerid
+`
+
+type CreateTransactionSplitParams struct {
+	Transactionid uuid.UUID
+	Amount        int32
+	Categoryid    uuid.NullUUID
+	Note          string
+	Fundid        uuid.NullUUID
+	Ownerid       uuid.UUID
+}
+
+func (q *Queries) CreateTransactionSplit(ctx context.Context, arg CreateTransactionSplitParams) (TransactionSplit, error) {
+	row := q.db.QueryRowContext(ctx, createTransactionSplit,
+		arg.Transactionid,
+		arg.Amount,
+		arg.Categoryid,
+		arg.Note,
+		arg.Fundid,
+		arg.Ownerid,
+	)
+	var i TransactionSplit
+	err := row.Scan(
+		&i.ID,
+		&i.Transactionid,
+		&i.Amount,
+		&i.Categoryid,
+		&i.Note,
+		&i.Fundid,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, email, passwordHash)
 VALUES ($1, $2, $3)
@@ -393,23 +935,290 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
-const deleteTransaction = `-- name: DeleteTransaction :one
-DELETE FROM transactions
+const deleteAccountHoldings = `-- name: DeleteAccountHoldings :exec
+DELETE FROM holdings
+WHERE accountId = $1 AND date = $2
+`
+
+type DeleteAccountHoldingsParams struct {
+	Accountid uuid.UUID
+	Date      time.Time
+}
+
+func (q *Queries) DeleteAccountHoldings(ctx context.Context, arg DeleteAccountHoldingsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountHoldings, arg.Accountid, arg.Date)
+	return err
+}
+
+const deleteAccountSyncItem = `-- name: DeleteAccountSyncItem :exec
+DELETE FROM account_sync_items
 WHERE id = $1
-RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid
 `
 
-func (q *Queries) DeleteTransaction(ctx context.Context, id uuid.UUID) (Transaction, error) {
-	row := q.db.QueryRowContext(ctx, deleteTransaction, id)
-	var i Transaction
+func (q *Queries) DeleteAccountSyncItem(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountSyncItem, id)
+	return err
+}
+
+const deleteCategory = `-- name: DeleteCategory :one
+DELETE FROM categories
+WHERE id = $1 AND ownerId = $2::uuid
+RETURNING id, name, parentid, ownerid, created
+`
+
+type DeleteCategoryParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, deleteCategory, arg.ID, arg.Ownerid)
+	var i Category
 	err := row.Scan(
 		&i.ID,
-		&i.Sourceid,
-		&i.Amount,
-		&i.Payeeid,
-		&i.Payee,
-		&i.Payeefull,
-		&i.Isocurrencycode,
+		&i.Name,
+		&i.Parentid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const deleteCsvProfile = `-- name: DeleteCsvProfile :one
+DELETE FROM csv_profiles
+WHERE id = $1 AND ownerId = $2
+RETURNING id, name, institution, datecolumn, descriptioncolumn, amountcolumn, debitcolumn, creditcolumn, typecolumn, checknumbercolumn, dateformat, signconvention, skiprows, hasheader, ownerid, balancecolumn
+`
+
+type DeleteCsvProfileParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) DeleteCsvProfile(ctx context.Context, arg DeleteCsvProfileParams) (CsvProfile, error) {
+	row := q.db.QueryRowContext(ctx, deleteCsvProfile, arg.ID, arg.Ownerid)
+	var i CsvProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Institution,
+		&i.Datecolumn,
+		&i.Descriptioncolumn,
+		&i.Amountcolumn,
+		&i.Debitcolumn,
+		&i.Creditcolumn,
+		&i.Typecolumn,
+		&i.Checknumbercolumn,
+		&i.Dateformat,
+		&i.Signconvention,
+		&i.Skiprows,
+		&i.Hasheader,
+		&i.Ownerid,
+		&i.Balancecolumn,
+	)
+	return i, err
+}
+
+const deleteEmptyAccount = `-- name: DeleteEmptyAccount :execrows
+DELETE FROM accounts AS a
+WHERE a.id = $1
+    AND NOT EXISTS (SELECT 1 FROM account_sync_items AS s WHERE s.accountId = a.id)
+    AND NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.accountId = a.id)
+`
+
+func (q *Queries) DeleteEmptyAccount(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteEmptyAccount, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteManualAsset = `-- name: DeleteManualAsset :one
+DELETE FROM manual_assets
+WHERE id = $1 AND ownerId = $2
+RETURNING id, name, type, ownerid
+`
+
+type DeleteManualAssetParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) DeleteManualAsset(ctx context.Context, arg DeleteManualAssetParams) (ManualAsset, error) {
+	row := q.db.QueryRowContext(ctx, deleteManualAsset, arg.ID, arg.Ownerid)
+	var i ManualAsset
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const deleteMerchantRule = `-- name: DeleteMerchantRule :one
+DELETE FROM merchant_rules
+WHERE id = $1 AND ownerId = $2
+RETURNING id, priority, type, pattern, minamount, maxamount, uploadsource, merchantid, ownerid, created
+`
+
+type DeleteMerchantRuleParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) DeleteMerchantRule(ctx context.Context, arg DeleteMerchantRuleParams) (MerchantRule, error) {
+	row := q.db.QueryRowContext(ctx, deleteMerchantRule, arg.ID, arg.Ownerid)
+	var i MerchantRule
+	err := row.Scan(
+		&i.ID,
+		&i.Priority,
+		&i.Type,
+		&i.Pattern,
+		&i.Minamount,
+		&i.Maxamount,
+		&i.Uploadsource,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const deleteMerchants = `-- name: DeleteMerchants :execrows
+DELETE FROM merchants
+WHERE ownerId = $1 AND id::varchar = ANY($2::varchar[])
+`
+
+type DeleteMerchantsParams struct {
+	Ownerid     uuid.UUID
+	Merchantids []string
+}
+
+func (q *Queries) DeleteMerchants(ctx context.Context, arg DeleteMerchantsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMerchants, arg.Ownerid, pq.Array(arg.Merchantids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOfxConnection = `-- name: DeleteOfxConnection :one
+DELETE FROM ofx_connections
+WHERE id = $1 AND ownerId = $2
+RETURNING id, url, org, fid, appid, appversion, ofxversion, credentials, lastattempted, lastsynced, lasterror, created, accountid, ownerid
+`
+
+type DeleteOfxConnectionParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) DeleteOfxConnection(ctx context.Context, arg DeleteOfxConnectionParams) (OfxConnection, error) {
+	row := q.db.QueryRowContext(ctx, deleteOfxConnection, arg.ID, arg.Ownerid)
+	var i OfxConnection
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Org,
+		&i.Fid,
+		&i.Appid,
+		&i.Appversion,
+		&i.Ofxversion,
+		&i.Credentials,
+		&i.Lastattempted,
+		&i.Lastsynced,
+		&i.Lasterror,
+		&i.Created,
+		&i.Accountid,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const deleteSyncItemMerchantKeys = `-- name: DeleteSyncItemMerchantKeys :exec
+DELETE FROM merchant_keys AS k
+USING merchants AS m
+WHERE k.merchantId = m.id
+    AND m.syncItemId = $1
+    AND NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.merchantId = m.id)
+    AND NOT EXISTS (SELECT 1 FROM merchant_rules AS r WHERE r.merchantId = m.id)
+`
+
+func (q *Queries) DeleteSyncItemMerchantKeys(ctx context.Context, syncitemid uuid.NullUUID) error {
+	_, err := q.db.ExecContext(ctx, deleteSyncItemMerchantKeys, syncitemid)
+	return err
+}
+
+const deleteSyncItemMerchants = `-- name: DeleteSyncItemMerchants :execrows
+DELETE FROM merchants AS m
+WHERE m.syncItemId = $1
+    AND NOT EXISTS (SELECT 1 FROM transactions AS t WHERE t.merchantId = m.id)
+    AND NOT EXISTS (SELECT 1 FROM merchant_rules AS r WHERE r.merchantId = m.id)
+`
+
+func (q *Queries) DeleteSyncItemMerchants(ctx context.Context, syncitemid uuid.NullUUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSyncItemMerchants, syncitemid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteSyncItemTransactions = `-- name: DeleteSyncItemTransactions :execrows
+DELETE FROM transactions
+WHERE syncItemId = $1
+`
+
+func (q *Queries) DeleteSyncItemTransactions(ctx context.Context, syncitemid uuid.NullUUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSyncItemTransactions, syncitemid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTag = `-- name: DeleteTag :one
+DELETE FROM tags
+WHERE id = $1 AND ownerId = $2
+RETURNING id, name, ownerid, created
+`
+
+type DeleteTagParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) DeleteTag(ctx context.Context, arg DeleteTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, deleteTag, arg.ID, arg.Ownerid)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const deleteTransaction = `-- name: DeleteTransaction :one
+DELETE FROM transactions
+WHERE id = $1
+RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note
+`
+
+func (q *Queries) DeleteTransaction(ctx context.Context, id uuid.UUID) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, deleteTransaction, id)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Amount,
+		&i.Payeeid,
+		&i.Payee,
+		&i.Payeefull,
+		&i.Isocurrencycode,
 		&i.Date,
 		&i.Description,
 		&i.Type,
@@ -418,10 +1227,51 @@ func (q *Queries) DeleteTransaction(ctx context.Context, id uuid.UUID) (Transact
 		&i.Merchantid,
 		&i.Ownerid,
 		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
 	)
 	return i, err
 }
 
+const deleteTransactionSplits = `-- name: DeleteTransactionSplits :exec
+DELETE FROM transaction_splits
+WHERE transactionId = $1 AND ownerId = $2
+`
+
+type DeleteTransactionSplitsParams struct {
+	Transactionid uuid.UUID
+	Ownerid       uuid.UUID
+}
+
+func (q *Queries) DeleteTransactionSplits(ctx context.Context, arg DeleteTransactionSplitsParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTransactionSplits, arg.Transactionid, arg.Ownerid)
+	return err
+}
+
+const deleteTransactionsBySourceIds = `-- name: DeleteTransactionsBySourceIds :execrows
+DELETE FROM transactions
+WHERE ownerId = $1 AND sourceId = ANY($2::varchar[])
+`
+
+type DeleteTransactionsBySourceIdsParams struct {
+	Ownerid   uuid.UUID
+	Sourceids []string
+}
+
+func (q *Queries) DeleteTransactionsBySourceIds(ctx context.Context, arg DeleteTransactionsBySourceIdsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTransactionsBySourceIds, arg.Ownerid, pq.Array(arg.Sourceids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteUser = `-- name: DeleteUser :one
 DELETE FROM users
 WHERE id = $1
@@ -441,9 +1291,30 @@ func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (User, error) {
 	return i, err
 }
 
+const findGlobalMerchant = `-- name: FindGlobalMerchant :one
+SELECT id, name, website, categoryid, aliases, created FROM global_merchants
+WHERE upper(name) = upper($1::varchar) OR upper($1::varchar) = ANY(aliases)
+LIMIT 1
+`
+
+// Finds the global merchant with a name or alias, ignoring case
+func (q *Queries) FindGlobalMerchant(ctx context.Context, name string) (GlobalMerchant, error) {
+	row := q.db.QueryRowContext(ctx, findGlobalMerchant, name)
+	var i GlobalMerchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Website,
+		&i.Categoryid,
+		pq.Array(&i.Aliases),
+		&i.Created,
+	)
+	return i, err
+}
+
 const getAccount = `-- name: GetAccount :one
 
-SELECT id, sourceid, type, name, routingnumber, updated, ownerid FROM accounts
+SELECT id, sourceid, type, name, routingnumber, updated, ownerid, syncconnectionid, synccursor FROM accounts
 WHERE id = $1 and ownerId = $2
 LIMIT 1
 `
@@ -465,13 +1336,44 @@ func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account
 		&i.Routingnumber,
 		&i.Updated,
 		&i.Ownerid,
+		&i.Syncconnectionid,
+		&i.Synccursor,
+	)
+	return i, err
+}
+
+const getAccountBySourceId = `-- name: GetAccountBySourceId :one
+SELECT id, sourceid, type, name, routingnumber, updated, ownerid, syncconnectionid, synccursor FROM accounts
+WHERE ownerId = $1 AND sourceId = $2
+LIMIT 1
+`
+
+type GetAccountBySourceIdParams struct {
+	Ownerid  uuid.UUID
+	Sourceid string
+}
+
+func (q *Queries) GetAccountBySourceId(ctx context.Context, arg GetAccountBySourceIdParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountBySourceId, arg.Ownerid, arg.Sourceid)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Type,
+		&i.Name,
+		&i.Routingnumber,
+		&i.Updated,
+		&i.Ownerid,
+		&i.Syncconnectionid,
+		&i.Synccursor,
 	)
 	return i, err
 }
 
 const getAccountIncome = `-- name: GetAccountIncome :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND accountId = $2 AND amount > 0
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND t.accountId = $2 AND COALESCE(s.amount, t.amount) > 0
 `
 
 type GetAccountIncomeParams struct {
@@ -487,8 +1389,9 @@ func (q *Queries) GetAccountIncome(ctx context.Context, arg GetAccountIncomePara
 }
 
 const getAccountSpending = `-- name: GetAccountSpending :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND accountId = $2 AND amount < 0
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND t.accountId = $2 AND COALESCE(s.amount, t.amount) < 0
 `
 
 type GetAccountSpendingParams struct {
@@ -503,456 +1406,591 @@ func (q *Queries) GetAccountSpending(ctx context.Context, arg GetAccountSpending
 	return sum, err
 }
 
-const getFundAllocationsStats = `-- name: GetFundAllocationsStats :one
-SELECT
-    COALESCE(sum(CASE WHEN a.amount > 0 THEN a.amount ELSE 0 END), 0) as saved,
-    COALESCE(sum(CASE WHEN a.amount > 0 THEN a.amount ELSE 0 END), 0) as spent,
-    COALESCE(sum(a.amount), 0) as net
-FROM fund_allocations AS a, funds AS f
-WHERE f.ownerId = $1 AND a.date <= $2
+const getAccountSyncItem = `-- name: GetAccountSyncItem :one
+SELECT s.id, s.date, s.uploadsource, s.accountid, s.importbatchid FROM account_sync_items AS s
+JOIN accounts AS a ON s.accountId = a.id
+WHERE s.id = $1 AND a.ownerId = $2
 `
 
-type GetFundAllocationsStatsParams struct {
+type GetAccountSyncItemParams struct {
+	ID      uuid.UUID
 	Ownerid uuid.UUID
-	Enddate time.Time
-}
-
-type GetFundAllocationsStatsRow struct {
-	Saved interface{}
-	Spent interface{}
-	Net   interface{}
-}
-
-func (q *Queries) GetFundAllocationsStats(ctx context.Context, arg GetFundAllocationsStatsParams) (GetFundAllocationsStatsRow, error) {
-	row := q.db.QueryRowContext(ctx, getFundAllocationsStats, arg.Ownerid, arg.Enddate)
-	var i GetFundAllocationsStatsRow
-	err := row.Scan(&i.Saved, &i.Spent, &i.Net)
-	return i, err
-}
-
-const getFundTotal = `-- name: GetFundTotal :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM fund_allocations
-WHERE fundId = $1
-`
-
-func (q *Queries) GetFundTotal(ctx context.Context, fundid uuid.UUID) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getFundTotal, fundid)
-	var sum interface{}
-	err := row.Scan(&sum)
-	return sum, err
 }
 
-const getLastSync = `-- name: GetLastSync :one
-
-SELECT id, date, uploadsource, accountid FROM account_sync_items
-WHERE accountId = $1
-ORDER BY date DESC
-LIMIT 1
-`
-
-// ACCOUNT SYNC ITEMS
-func (q *Queries) GetLastSync(ctx context.Context, accountid uuid.UUID) (AccountSyncItem, error) {
-	row := q.db.QueryRowContext(ctx, getLastSync, accountid)
+func (q *Queries) GetAccountSyncItem(ctx context.Context, arg GetAccountSyncItemParams) (AccountSyncItem, error) {
+	row := q.db.QueryRowContext(ctx, getAccountSyncItem, arg.ID, arg.Ownerid)
 	var i AccountSyncItem
 	err := row.Scan(
 		&i.ID,
 		&i.Date,
 		&i.Uploadsource,
 		&i.Accountid,
+		&i.Importbatchid,
 	)
 	return i, err
 }
 
-const getMerchant = `-- name: GetMerchant :one
-
-SELECT id, name, sourceid, ownerid FROM merchants
-WHERE id = $1 and ownerId = $2
+const getCategory = `-- name: GetCategory :one
+SELECT id, name, parentid, ownerid, created FROM categories
+WHERE id = $1 AND (ownerId IS NULL OR ownerId = $2::uuid)
 LIMIT 1
 `
 
-type GetMerchantParams struct {
+type GetCategoryParams struct {
 	ID      uuid.UUID
 	Ownerid uuid.UUID
 }
 
-// MERCHANTS
-func (q *Queries) GetMerchant(ctx context.Context, arg GetMerchantParams) (Merchant, error) {
-	row := q.db.QueryRowContext(ctx, getMerchant, arg.ID, arg.Ownerid)
-	var i Merchant
+// Finds a default category or one of the user's own
+func (q *Queries) GetCategory(ctx context.Context, arg GetCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategory, arg.ID, arg.Ownerid)
+	var i Category
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.Sourceid,
+		&i.Parentid,
 		&i.Ownerid,
+		&i.Created,
 	)
 	return i, err
 }
 
-const getMerchantByKey = `-- name: GetMerchantByKey :one
-SELECT m.id, m.name, m.sourceid, m.ownerid FROM merchants AS m JOIN merchant_keys AS k ON m.id = k.merchantId
-WHERE uploadSource = $1 AND keymatch LIKE $2
+const getCompletedImportBatch = `-- name: GetCompletedImportBatch :one
+SELECT id, filename, filehash, uploadsource, status, accountsupdated, accountsfailed, transactionsupdated, transactionsfailed, errors, created, ownerid FROM import_batches
+WHERE ownerId = $1 AND fileHash = $2 AND status = 'COMPLETED'
+LIMIT 1
 `
 
-type GetMerchantByKeyParams struct {
-	Uploadsource UploadSource
-	Keymatch     string
+type GetCompletedImportBatchParams struct {
+	Ownerid  uuid.UUID
+	Filehash string
 }
 
-func (q *Queries) GetMerchantByKey(ctx context.Context, arg GetMerchantByKeyParams) (Merchant, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantByKey, arg.Uploadsource, arg.Keymatch)
-	var i Merchant
+func (q *Queries) GetCompletedImportBatch(ctx context.Context, arg GetCompletedImportBatchParams) (ImportBatch, error) {
+	row := q.db.QueryRowContext(ctx, getCompletedImportBatch, arg.Ownerid, arg.Filehash)
+	var i ImportBatch
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Sourceid,
+		&i.Filename,
+		&i.Filehash,
+		&i.Uploadsource,
+		&i.Status,
+		&i.Accountsupdated,
+		&i.Accountsfailed,
+		&i.Transactionsupdated,
+		&i.Transactionsfailed,
+		pq.Array(&i.Errors),
+		&i.Created,
 		&i.Ownerid,
 	)
 	return i, err
 }
 
-const getMerchantByName = `-- name: GetMerchantByName :one
-SELECT id, name, sourceid, ownerid FROM merchants
-WHERE name = $1
+const getCsvProfile = `-- name: GetCsvProfile :one
+SELECT id, name, institution, datecolumn, descriptioncolumn, amountcolumn, debitcolumn, creditcolumn, typecolumn, checknumbercolumn, dateformat, signconvention, skiprows, hasheader, ownerid, balancecolumn FROM csv_profiles
+WHERE id = $1 AND ownerId = $2
+LIMIT 1
 `
 
-func (q *Queries) GetMerchantByName(ctx context.Context, name string) (Merchant, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantByName, name)
-	var i Merchant
+type GetCsvProfileParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) GetCsvProfile(ctx context.Context, arg GetCsvProfileParams) (CsvProfile, error) {
+	row := q.db.QueryRowContext(ctx, getCsvProfile, arg.ID, arg.Ownerid)
+	var i CsvProfile
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
-		&i.Sourceid,
+		&i.Institution,
+		&i.Datecolumn,
+		&i.Descriptioncolumn,
+		&i.Amountcolumn,
+		&i.Debitcolumn,
+		&i.Creditcolumn,
+		&i.Typecolumn,
+		&i.Checknumbercolumn,
+		&i.Dateformat,
+		&i.Signconvention,
+		&i.Skiprows,
+		&i.Hasheader,
 		&i.Ownerid,
+		&i.Balancecolumn,
 	)
 	return i, err
 }
 
-const getMerchantBySourceId = `-- name: GetMerchantBySourceId :one
-SELECT id, name, sourceid, ownerid FROM merchants
-WHERE sourceId = $1
+const getFund = `-- name: GetFund :one
+SELECT id, type, name, goal, startdate, enddate, ownerid FROM funds
+WHERE id = $1 AND ownerId = $2
+LIMIT 1
 `
 
-func (q *Queries) GetMerchantBySourceId(ctx context.Context, sourceid sql.NullString) (Merchant, error) {
-	row := q.db.QueryRowContext(ctx, getMerchantBySourceId, sourceid)
-	var i Merchant
+type GetFundParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) GetFund(ctx context.Context, arg GetFundParams) (Fund, error) {
+	row := q.db.QueryRowContext(ctx, getFund, arg.ID, arg.Ownerid)
+	var i Fund
 	err := row.Scan(
 		&i.ID,
+		&i.Type,
 		&i.Name,
-		&i.Sourceid,
+		&i.Goal,
+		&i.Startdate,
+		&i.Enddate,
 		&i.Ownerid,
 	)
 	return i, err
 }
 
-const getNetIncome = `-- name: GetNetIncome :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND date BETWEEN $2 AND $3
+const getFundAllocationsStats = `-- name: GetFundAllocationsStats :one
+SELECT
+    COALESCE(sum(CASE WHEN a.amount > 0 THEN a.amount ELSE 0 END), 0) as saved,
+    COALESCE(sum(CASE WHEN a.amount > 0 THEN a.amount ELSE 0 END), 0) as spent,
+    COALESCE(sum(a.amount), 0) as net
+FROM fund_allocations AS a, funds AS f
+WHERE f.ownerId = $1 AND a.date <= $2
 `
 
-type GetNetIncomeParams struct {
-	Ownerid   uuid.UUID
-	Startdate time.Time
-	Enddate   time.Time
+type GetFundAllocationsStatsParams struct {
+	Ownerid uuid.UUID
+	Enddate time.Time
 }
 
-func (q *Queries) GetNetIncome(ctx context.Context, arg GetNetIncomeParams) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getNetIncome, arg.Ownerid, arg.Startdate, arg.Enddate)
+type GetFundAllocationsStatsRow struct {
+	Saved interface{}
+	Spent interface{}
+	Net   interface{}
+}
+
+func (q *Queries) GetFundAllocationsStats(ctx context.Context, arg GetFundAllocationsStatsParams) (GetFundAllocationsStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getFundAllocationsStats, arg.Ownerid, arg.Enddate)
+	var i GetFundAllocationsStatsRow
+	err := row.Scan(&i.Saved, &i.Spent, &i.Net)
+	return i, err
+}
+
+const getFundTotal = `-- name: GetFundTotal :one
+SELECT COALESCE(SUM(amount), 0) as Sum FROM fund_allocations
+WHERE fundId = $1
+`
+
+func (q *Queries) GetFundTotal(ctx context.Context, fundid uuid.UUID) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getFundTotal, fundid)
 	var sum interface{}
 	err := row.Scan(&sum)
 	return sum, err
 }
 
-const getTotalIncome = `-- name: GetTotalIncome :one
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND amount > 0 AND date BETWEEN $2 AND $3
+const getGlobalMerchant = `-- name: GetGlobalMerchant :one
+SELECT id, name, website, categoryid, aliases, created FROM global_merchants
+WHERE id = $1
+LIMIT 1
 `
 
-type GetTotalIncomeParams struct {
-	Ownerid   uuid.UUID
-	Startdate time.Time
-	Enddate   time.Time
+// GLOBAL MERCHANTS
+func (q *Queries) GetGlobalMerchant(ctx context.Context, id uuid.UUID) (GlobalMerchant, error) {
+	row := q.db.QueryRowContext(ctx, getGlobalMerchant, id)
+	var i GlobalMerchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Website,
+		&i.Categoryid,
+		pq.Array(&i.Aliases),
+		&i.Created,
+	)
+	return i, err
 }
 
-func (q *Queries) GetTotalIncome(ctx context.Context, arg GetTotalIncomeParams) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getTotalIncome, arg.Ownerid, arg.Startdate, arg.Enddate)
-	var sum interface{}
-	err := row.Scan(&sum)
-	return sum, err
+const getLastSync = `-- name: GetLastSync :one
+
+SELECT id, date, uploadsource, accountid, importbatchid FROM account_sync_items
+WHERE accountId = $1
+ORDER BY date DESC
+LIMIT 1
+`
+
+// ACCOUNT SYNC ITEMS
+func (q *Queries) GetLastSync(ctx context.Context, accountid uuid.UUID) (AccountSyncItem, error) {
+	row := q.db.QueryRowContext(ctx, getLastSync, accountid)
+	var i AccountSyncItem
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Uploadsource,
+		&i.Accountid,
+		&i.Importbatchid,
+	)
+	return i, err
 }
 
-const getTotalSpending = `-- name: GetTotalSpending :one
+const getLatestAccountBalance = `-- name: GetLatestAccountBalance :one
+SELECT id, date, current, available, accountid, ownerid, syncitemid FROM account_balances
+WHERE accountId = $1
+ORDER BY date DESC
+LIMIT 1
+`
 
-SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
-WHERE ownerId = $1 AND amount < 0 AND date BETWEEN $2 AND $3
+func (q *Queries) GetLatestAccountBalance(ctx context.Context, accountid uuid.UUID) (AccountBalance, error) {
+	row := q.db.QueryRowContext(ctx, getLatestAccountBalance, accountid)
+	var i AccountBalance
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Current,
+		&i.Available,
+		&i.Accountid,
+		&i.Ownerid,
+		&i.Syncitemid,
+	)
+	return i, err
+}
+
+const getManualAsset = `-- name: GetManualAsset :one
+SELECT id, name, type, ownerid FROM manual_assets
+WHERE id = $1 AND ownerId = $2
+LIMIT 1
 `
 
-type GetTotalSpendingParams struct {
-	Ownerid   uuid.UUID
-	Startdate time.Time
-	Enddate   time.Time
+type GetManualAssetParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
 }
 
-// STATS
-func (q *Queries) GetTotalSpending(ctx context.Context, arg GetTotalSpendingParams) (interface{}, error) {
-	row := q.db.QueryRowContext(ctx, getTotalSpending, arg.Ownerid, arg.Startdate, arg.Enddate)
-	var sum interface{}
-	err := row.Scan(&sum)
-	return sum, err
+func (q *Queries) GetManualAsset(ctx context.Context, arg GetManualAssetParams) (ManualAsset, error) {
+	row := q.db.QueryRowContext(ctx, getManualAsset, arg.ID, arg.Ownerid)
+	var i ManualAsset
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Ownerid,
+	)
+	return i, err
 }
 
-const getTransaction = `-- name: GetTransaction :one
+const getMerchant = `-- name: GetMerchant :one
 
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
+SELECT id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid FROM merchants
 WHERE id = $1 and ownerId = $2
 LIMIT 1
 `
 
-type GetTransactionParams struct {
+type GetMerchantParams struct {
 	ID      uuid.UUID
 	Ownerid uuid.UUID
 }
 
-// TRANSACTIONS
-func (q *Queries) GetTransaction(ctx context.Context, arg GetTransactionParams) (Transaction, error) {
-	row := q.db.QueryRowContext(ctx, getTransaction, arg.ID, arg.Ownerid)
-	var i Transaction
+// MERCHANTS
+func (q *Queries) GetMerchant(ctx context.Context, arg GetMerchantParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, getMerchant, arg.ID, arg.Ownerid)
+	var i Merchant
 	err := row.Scan(
 		&i.ID,
+		&i.Name,
 		&i.Sourceid,
-		&i.Amount,
-		&i.Payeeid,
-		&i.Payee,
-		&i.Payeefull,
-		&i.Isocurrencycode,
-		&i.Date,
-		&i.Description,
-		&i.Type,
-		&i.Checknumber,
-		&i.Updated,
-		&i.Merchantid,
 		&i.Ownerid,
-		&i.Accountid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
 	)
 	return i, err
 }
 
-const getUser = `-- name: GetUser :one
-
-SELECT id, role, username, email, passwordhash FROM users
-WHERE id = $1
+const getMerchantByGlobalMerchantId = `-- name: GetMerchantByGlobalMerchantId :one
+SELECT id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid FROM merchants
+WHERE ownerId = $1 AND globalMerchantId = $2
+LIMIT 1
 `
 
-// USERS
-func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUser, id)
-	var i User
+type GetMerchantByGlobalMerchantIdParams struct {
+	Ownerid          uuid.UUID
+	Globalmerchantid uuid.NullUUID
+}
+
+func (q *Queries) GetMerchantByGlobalMerchantId(ctx context.Context, arg GetMerchantByGlobalMerchantIdParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantByGlobalMerchantId, arg.Ownerid, arg.Globalmerchantid)
+	var i Merchant
 	err := row.Scan(
 		&i.ID,
-		&i.Role,
-		&i.Username,
-		&i.Email,
-		&i.Passwordhash,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
 	)
 	return i, err
 }
 
-const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, role, username, email, passwordhash FROM users
-WHERE email = $1
+const getMerchantByKey = `-- name: GetMerchantByKey :one
+SELECT m.id, m.name, m.sourceid, m.ownerid, m.syncitemid, m.website, m.categoryid, m.globalmerchantid FROM merchants AS m JOIN merchant_keys AS k ON m.id = k.merchantId
+WHERE k.ownerId = $1 AND k.uploadSource = $2 AND upper($3::varchar) LIKE k.keymatch
+ORDER BY length(k.keymatch) DESC
+LIMIT 1
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
-	var i User
+type GetMerchantByKeyParams struct {
+	Ownerid      uuid.UUID
+	Uploadsource UploadSource
+	Description  string
+}
+
+// Keys are LIKE patterns matched against the uppercase description. The
+// longest matching key is the most specific
+func (q *Queries) GetMerchantByKey(ctx context.Context, arg GetMerchantByKeyParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantByKey, arg.Ownerid, arg.Uploadsource, arg.Description)
+	var i Merchant
 	err := row.Scan(
 		&i.ID,
-		&i.Role,
-		&i.Username,
-		&i.Email,
-		&i.Passwordhash,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
 	)
 	return i, err
 }
 
-const listAccountIncomeTransactions = `-- name: ListAccountIncomeTransactions :many
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
-WHERE ownerId = $1 AND accountId = $2 AND amount >= 0
-ORDER BY date
-LIMIT $2 OFFSET $3
+const getMerchantByKeyMatch = `-- name: GetMerchantByKeyMatch :one
+SELECT m.id, m.name, m.sourceid, m.ownerid, m.syncitemid, m.website, m.categoryid, m.globalmerchantid FROM merchants AS m JOIN merchant_keys AS k ON m.id = k.merchantId
+WHERE k.ownerId = $1 AND k.keymatch = $2
+LIMIT 1
 `
 
-type ListAccountIncomeTransactionsParams struct {
-	Ownerid uuid.UUID
-	Limit   int32
-	Start   int32
+type GetMerchantByKeyMatchParams struct {
+	Ownerid  uuid.UUID
+	Keymatch string
 }
 
-func (q *Queries) ListAccountIncomeTransactions(ctx context.Context, arg ListAccountIncomeTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listAccountIncomeTransactions, arg.Ownerid, arg.Limit, arg.Start)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Transaction
-	for rows.Next() {
-		var i Transaction
-		if err := rows.Scan(
-			&i.ID,
-			&i.Sourceid,
-			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
-			&i.Date,
-			&i.Description,
-			&i.Type,
-			&i.Checknumber,
-			&i.Updated,
-			&i.Merchantid,
-			&i.Ownerid,
-			&i.Accountid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) GetMerchantByKeyMatch(ctx context.Context, arg GetMerchantByKeyMatchParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantByKeyMatch, arg.Ownerid, arg.Keymatch)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
+	)
+	return i, err
 }
 
-const listAccountSpendingTransactions = `-- name: ListAccountSpendingTransactions :many
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
-WHERE ownerId = $1 AND accountId = $2 AND amount < 0
-ORDER BY date DESC
-LIMIT $2 OFFSET $3
+const getMerchantByName = `-- name: GetMerchantByName :one
+SELECT id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid FROM merchants
+WHERE ownerId = $1 AND name = $2
+LIMIT 1
 `
 
-type ListAccountSpendingTransactionsParams struct {
+type GetMerchantByNameParams struct {
 	Ownerid uuid.UUID
-	Limit   int32
-	Start   int32
+	Name    string
 }
 
-func (q *Queries) ListAccountSpendingTransactions(ctx context.Context, arg ListAccountSpendingTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listAccountSpendingTransactions, arg.Ownerid, arg.Limit, arg.Start)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Transaction
-	for rows.Next() {
-		var i Transaction
-		if err := rows.Scan(
-			&i.ID,
-			&i.Sourceid,
-			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
-			&i.Date,
-			&i.Description,
-			&i.Type,
-			&i.Checknumber,
-			&i.Updated,
-			&i.Merchantid,
-			&i.Ownerid,
-			&i.Accountid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) GetMerchantByName(ctx context.Context, arg GetMerchantByNameParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantByName, arg.Ownerid, arg.Name)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
+	)
+	return i, err
 }
 
-const listAccounts = `-- name: ListAccounts :many
-SELECT id, sourceid, type, name, routingnumber, updated, ownerid FROM accounts AS a
-WHERE ownerId = $1
-ORDER BY a.name
-LIMIT $2 OFFSET $3
+const getMerchantBySourceId = `-- name: GetMerchantBySourceId :one
+SELECT id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid FROM merchants
+WHERE ownerId = $1 AND sourceId = $2
+LIMIT 1
 `
 
-type ListAccountsParams struct {
+type GetMerchantBySourceIdParams struct {
+	Ownerid  uuid.UUID
+	Sourceid sql.NullString
+}
+
+func (q *Queries) GetMerchantBySourceId(ctx context.Context, arg GetMerchantBySourceIdParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantBySourceId, arg.Ownerid, arg.Sourceid)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
+	)
+	return i, err
+}
+
+const getMerchantRule = `-- name: GetMerchantRule :one
+SELECT id, priority, type, pattern, minamount, maxamount, uploadsource, merchantid, ownerid, created FROM merchant_rules
+WHERE id = $1 AND ownerId = $2
+LIMIT 1
+`
+
+type GetMerchantRuleParams struct {
+	ID      uuid.UUID
 	Ownerid uuid.UUID
-	Limit   int32
-	Start   int32
 }
 
-func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Ownerid, arg.Limit, arg.Start)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Account
-	for rows.Next() {
-		var i Account
-		if err := rows.Scan(
-			&i.ID,
-			&i.Sourceid,
-			&i.Type,
-			&i.Name,
-			&i.Routingnumber,
-			&i.Updated,
-			&i.Ownerid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+// MERCHANT RULES
+func (q *Queries) GetMerchantRule(ctx context.Context, arg GetMerchantRuleParams) (MerchantRule, error) {
+	row := q.db.QueryRowContext(ctx, getMerchantRule, arg.ID, arg.Ownerid)
+	var i MerchantRule
+	err := row.Scan(
+		&i.ID,
+		&i.Priority,
+		&i.Type,
+		&i.Pattern,
+		&i.Minamount,
+		&i.Maxamount,
+		&i.Uploadsource,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
 }
 
-const listBudgetFunds = `-- name: ListBudgetFunds :many
-SELECT id, type, name, goal, startdate, enddate, ownerid FROM funds
-WHERE ownerId = $1 AND type = 'BUDGET'
-ORDER BY name
-LIMIT $2 OFFSET $3
+const getNetIncome = `-- name: GetNetIncome :one
+SELECT COALESCE(SUM(amount), 0) as Sum FROM transactions
+WHERE ownerId = $1 AND date BETWEEN $2 AND $3
+    AND ($4::boolean OR status = 'POSTED')
 `
 
-type ListBudgetFundsParams struct {
+type GetNetIncomeParams struct {
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+}
+
+func (q *Queries) GetNetIncome(ctx context.Context, arg GetNetIncomeParams) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getNetIncome,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
+	var sum interface{}
+	err := row.Scan(&sum)
+	return sum, err
+}
+
+const getOfxConnection = `-- name: GetOfxConnection :one
+SELECT id, url, org, fid, appid, appversion, ofxversion, credentials, lastattempted, lastsynced, lasterror, created, accountid, ownerid FROM ofx_connections
+WHERE id = $1 AND ownerId = $2
+LIMIT 1
+`
+
+type GetOfxConnectionParams struct {
+	ID      uuid.UUID
 	Ownerid uuid.UUID
-	Limit   int32
-	Start   int32
 }
 
-func (q *Queries) ListBudgetFunds(ctx context.Context, arg ListBudgetFundsParams) ([]Fund, error) {
-	rows, err := q.db.QueryContext(ctx, listBudgetFunds, arg.Ownerid, arg.Limit, arg.Start)
+func (q *Queries) GetOfxConnection(ctx context.Context, arg GetOfxConnectionParams) (OfxConnection, error) {
+	row := q.db.QueryRowContext(ctx, getOfxConnection, arg.ID, arg.Ownerid)
+	var i OfxConnection
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Org,
+		&i.Fid,
+		&i.Appid,
+		&i.Appversion,
+		&i.Ofxversion,
+		&i.Credentials,
+		&i.Lastattempted,
+		&i.Lastsynced,
+		&i.Lasterror,
+		&i.Created,
+		&i.Accountid,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const getSecurity = `-- name: GetSecurity :one
+SELECT id, uniqueid, uniqueidtype, ticker, name, type FROM securities
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetSecurity(ctx context.Context, id uuid.UUID) (Security, error) {
+	row := q.db.QueryRowContext(ctx, getSecurity, id)
+	var i Security
+	err := row.Scan(
+		&i.ID,
+		&i.Uniqueid,
+		&i.Uniqueidtype,
+		&i.Ticker,
+		&i.Name,
+		&i.Type,
+	)
+	return i, err
+}
+
+const getSpendingByCategory = `-- name: GetSpendingByCategory :many
+SELECT COALESCE(s.categoryId, t.categoryId, m.categoryId) AS categoryId, count(DISTINCT t.id), SUM(COALESCE(s.amount, t.amount))::bigint AS total
+FROM transactions AS t
+JOIN merchants AS m ON t.merchantId = m.id
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND COALESCE(s.amount, t.amount) < 0 AND t.date BETWEEN $2 AND $3
+    AND ($4::boolean OR t.status = 'POSTED')
+GROUP BY COALESCE(s.categoryId, t.categoryId, m.categoryId)
+`
+
+type GetSpendingByCategoryParams struct {
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+}
+
+type GetSpendingByCategoryRow struct {
+	Categoryid uuid.NullUUID
+	Count      int64
+	Total      int64
+}
+
+// Spending grouped by category. Split transactions are grouped by their parts'
+// categories. Parts and transactions without a category have the
+// transaction's category, then the merchant's
+func (q *Queries) GetSpendingByCategory(ctx context.Context, arg GetSpendingByCategoryParams) ([]GetSpendingByCategoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSpendingByCategory,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Fund
+	var items []GetSpendingByCategoryRow
 	for rows.Next() {
-		var i Fund
-		if err := rows.Scan(
-			&i.ID,
-			&i.Type,
-			&i.Name,
-			&i.Goal,
-			&i.Startdate,
-			&i.Enddate,
-			&i.Ownerid,
-		); err != nil {
+		var i GetSpendingByCategoryRow
+		if err := rows.Scan(&i.Categoryid, &i.Count, &i.Total); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -966,38 +2004,92 @@ func (q *Queries) ListBudgetFunds(ctx context.Context, arg ListBudgetFundsParams
 	return items, nil
 }
 
-const listFundAllocationsByFundIds = `-- name: ListFundAllocationsByFundIds :many
+const getSyncConnection = `-- name: GetSyncConnection :one
+SELECT id, provider, itemid, accesstoken, lastsynced, created, ownerid FROM sync_connections
+WHERE id = $1 AND ownerId = $2
+LIMIT 1
+`
 
-SELECT a.id, a.description, a.amount, a.date, a.ownerid, a.fundid FROM fund_allocations AS a, funds AS f
-WHERE a.fundId = f.id
-    AND f.id::varchar = ANY($2::varchar[])
-ORDER BY date DESC
-LIMIT $1 OFFSET $3
+type GetSyncConnectionParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) GetSyncConnection(ctx context.Context, arg GetSyncConnectionParams) (SyncConnection, error) {
+	row := q.db.QueryRowContext(ctx, getSyncConnection, arg.ID, arg.Ownerid)
+	var i SyncConnection
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.Itemid,
+		&i.Accesstoken,
+		&i.Lastsynced,
+		&i.Created,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const getSyncConnectionCursor = `-- name: GetSyncConnectionCursor :one
+SELECT syncCursor FROM accounts
+WHERE syncConnectionId = $1 AND syncCursor IS NOT NULL
+LIMIT 1
 `
 
-type ListFundAllocationsByFundIdsParams struct {
-	Limit   int32
-	Fundids []string
-	Start   int32
+func (q *Queries) GetSyncConnectionCursor(ctx context.Context, syncconnectionid uuid.NullUUID) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, getSyncConnectionCursor, syncconnectionid)
+	var synccursor sql.NullString
+	err := row.Scan(&synccursor)
+	return synccursor, err
 }
 
-// FUND ALLOCATIONS
-func (q *Queries) ListFundAllocationsByFundIds(ctx context.Context, arg ListFundAllocationsByFundIdsParams) ([]FundAllocation, error) {
-	rows, err := q.db.QueryContext(ctx, listFundAllocationsByFundIds, arg.Limit, pq.Array(arg.Fundids), arg.Start)
+const getTagTotals = `-- name: GetTagTotals :many
+SELECT tt.tagId, count(DISTINCT t.id),
+    COALESCE(SUM(CASE WHEN COALESCE(s.amount, t.amount) < 0 THEN COALESCE(s.amount, t.amount) ELSE 0 END), 0)::bigint AS spending,
+    COALESCE(SUM(CASE WHEN COALESCE(s.amount, t.amount) > 0 THEN COALESCE(s.amount, t.amount) ELSE 0 END), 0)::bigint AS income
+FROM transaction_tags AS tt
+JOIN transactions AS t ON tt.transactionId = t.id
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND t.date BETWEEN $2 AND $3
+    AND ($4::boolean OR t.status = 'POSTED')
+GROUP BY tt.tagId
+`
+
+type GetTagTotalsParams struct {
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+}
+
+type GetTagTotalsRow struct {
+	Tagid    uuid.UUID
+	Count    int64
+	Spending int64
+	Income   int64
+}
+
+// Spending and income of each tag's transactions across accounts and
+// categories. Split transactions are counted by their parts
+func (q *Queries) GetTagTotals(ctx context.Context, arg GetTagTotalsParams) ([]GetTagTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTagTotals,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []FundAllocation
+	var items []GetTagTotalsRow
 	for rows.Next() {
-		var i FundAllocation
+		var i GetTagTotalsRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Description,
-			&i.Amount,
-			&i.Date,
-			&i.Ownerid,
-			&i.Fundid,
+			&i.Tagid,
+			&i.Count,
+			&i.Spending,
+			&i.Income,
 		); err != nil {
 			return nil, err
 		}
@@ -1012,53 +2104,231 @@ func (q *Queries) ListFundAllocationsByFundIds(ctx context.Context, arg ListFund
 	return items, nil
 }
 
-const listIncomeTransactions = `-- name: ListIncomeTransactions :many
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
-WHERE ownerId = $1
-    AND amount >= 0 AND date BETWEEN $3 AND $4
-ORDER BY date DESC
-LIMIT $2 OFFSET $5
+const getTotalIncome = `-- name: GetTotalIncome :one
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND COALESCE(s.amount, t.amount) > 0 AND t.date BETWEEN $2 AND $3
+    AND ($4::boolean OR t.status = 'POSTED')
 `
 
-type ListIncomeTransactionsParams struct {
-	Ownerid   uuid.UUID
-	Limit     int32
-	Startdate time.Time
-	Enddate   time.Time
-	Start     int32
+type GetTotalIncomeParams struct {
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
 }
 
-func (q *Queries) ListIncomeTransactions(ctx context.Context, arg ListIncomeTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listIncomeTransactions,
+func (q *Queries) GetTotalIncome(ctx context.Context, arg GetTotalIncomeParams) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getTotalIncome,
 		arg.Ownerid,
-		arg.Limit,
 		arg.Startdate,
 		arg.Enddate,
-		arg.Start,
+		arg.Includepending,
 	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Transaction
-	for rows.Next() {
-		var i Transaction
-		if err := rows.Scan(
-			&i.ID,
-			&i.Sourceid,
-			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
+	var sum interface{}
+	err := row.Scan(&sum)
+	return sum, err
+}
+
+const getTotalSpending = `-- name: GetTotalSpending :one
+
+SELECT COALESCE(SUM(COALESCE(s.amount, t.amount)), 0) as Sum FROM transactions AS t
+LEFT JOIN transaction_splits AS s ON s.transactionId = t.id
+WHERE t.ownerId = $1 AND COALESCE(s.amount, t.amount) < 0 AND t.date BETWEEN $2 AND $3
+    AND ($4::boolean OR t.status = 'POSTED')
+`
+
+type GetTotalSpendingParams struct {
+	Ownerid        uuid.UUID
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+}
+
+// STATS
+// Split transactions are counted by their parts
+func (q *Queries) GetTotalSpending(ctx context.Context, arg GetTotalSpendingParams) (interface{}, error) {
+	row := q.db.QueryRowContext(ctx, getTotalSpending,
+		arg.Ownerid,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+	)
+	var sum interface{}
+	err := row.Scan(&sum)
+	return sum, err
+}
+
+const getTransaction = `-- name: GetTransaction :one
+
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE id = $1 and ownerId = $2
+LIMIT 1
+`
+
+type GetTransactionParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+}
+
+// TRANSACTIONS
+func (q *Queries) GetTransaction(ctx context.Context, arg GetTransactionParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getTransaction, arg.ID, arg.Ownerid)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Amount,
+		&i.Payeeid,
+		&i.Payee,
+		&i.Payeefull,
+		&i.Isocurrencycode,
+		&i.Date,
+		&i.Description,
+		&i.Type,
+		&i.Checknumber,
+		&i.Updated,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getTransactionBySourceId = `-- name: GetTransactionBySourceId :one
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1 AND sourceId = $2
+LIMIT 1
+`
+
+type GetTransactionBySourceIdParams struct {
+	Ownerid  uuid.UUID
+	Sourceid string
+}
+
+func (q *Queries) GetTransactionBySourceId(ctx context.Context, arg GetTransactionBySourceIdParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, getTransactionBySourceId, arg.Ownerid, arg.Sourceid)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Amount,
+		&i.Payeeid,
+		&i.Payee,
+		&i.Payeefull,
+		&i.Isocurrencycode,
+		&i.Date,
+		&i.Description,
+		&i.Type,
+		&i.Checknumber,
+		&i.Updated,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+
+SELECT id, role, username, email, passwordhash FROM users
+WHERE id = $1
+`
+
+// USERS
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Role,
+		&i.Username,
+		&i.Email,
+		&i.Passwordhash,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, role, username, email, passwordhash FROM users
+WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Role,
+		&i.Username,
+		&i.Email,
+		&i.Passwordhash,
+	)
+	return i, err
+}
+
+const linkSyncConnectionAccounts = `-- name: LinkSyncConnectionAccounts :exec
+UPDATE accounts
+SET syncConnectionId = $1
+WHERE ownerId = $2 AND sourceId = ANY($3::varchar[])
+`
+
+type LinkSyncConnectionAccountsParams struct {
+	Syncconnectionid uuid.NullUUID
+	Ownerid          uuid.UUID
+	Sourceids        []string
+}
+
+func (q *Queries) LinkSyncConnectionAccounts(ctx context.Context, arg LinkSyncConnectionAccountsParams) error {
+	_, err := q.db.ExecContext(ctx, linkSyncConnectionAccounts, arg.Syncconnectionid, arg.Ownerid, pq.Array(arg.Sourceids))
+	return err
+}
+
+const listAccountBalances = `-- name: ListAccountBalances :many
+SELECT id, date, current, available, accountid, ownerid, syncitemid FROM account_balances
+WHERE accountId = $1 AND date BETWEEN $2 AND $3
+ORDER BY date
+`
+
+type ListAccountBalancesParams struct {
+	Accountid uuid.UUID
+	Startdate time.Time
+	Enddate   time.Time
+}
+
+func (q *Queries) ListAccountBalances(ctx context.Context, arg ListAccountBalancesParams) ([]AccountBalance, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountBalances, arg.Accountid, arg.Startdate, arg.Enddate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountBalance
+	for rows.Next() {
+		var i AccountBalance
+		if err := rows.Scan(
+			&i.ID,
 			&i.Date,
-			&i.Description,
-			&i.Type,
-			&i.Checknumber,
-			&i.Updated,
-			&i.Merchantid,
-			&i.Ownerid,
+			&i.Current,
+			&i.Available,
 			&i.Accountid,
+			&i.Ownerid,
+			&i.Syncitemid,
 		); err != nil {
 			return nil, err
 		}
@@ -1073,33 +2343,39 @@ func (q *Queries) ListIncomeTransactions(ctx context.Context, arg ListIncomeTran
 	return items, nil
 }
 
-const listMerchants = `-- name: ListMerchants :many
-SELECT id, name, sourceid, ownerid FROM merchants
-WHERE ownerId = $1
-ORDER BY name
-LIMIT $2 OFFSET $3
+const listAccountHoldings = `-- name: ListAccountHoldings :many
+SELECT id, date, units, unitprice, marketvalue, securityid, accountid, ownerid, syncitemid FROM holdings
+WHERE accountId = $1 AND date = (
+    SELECT MAX(date) FROM holdings
+    WHERE accountId = $1 AND date <= $2::date
+)
+ORDER BY marketValue DESC
 `
 
-type ListMerchantsParams struct {
-	Ownerid uuid.UUID
-	Limit   int32
-	Start   int32
+type ListAccountHoldingsParams struct {
+	Accountid uuid.UUID
+	Asof      time.Time
 }
 
-func (q *Queries) ListMerchants(ctx context.Context, arg ListMerchantsParams) ([]Merchant, error) {
-	rows, err := q.db.QueryContext(ctx, listMerchants, arg.Ownerid, arg.Limit, arg.Start)
+func (q *Queries) ListAccountHoldings(ctx context.Context, arg ListAccountHoldingsParams) ([]Holding, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountHoldings, arg.Accountid, arg.Asof)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Merchant
+	var items []Holding
 	for rows.Next() {
-		var i Merchant
+		var i Holding
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Sourceid,
+			&i.Date,
+			&i.Units,
+			&i.Unitprice,
+			&i.Marketvalue,
+			&i.Securityid,
+			&i.Accountid,
 			&i.Ownerid,
+			&i.Syncitemid,
 		); err != nil {
 			return nil, err
 		}
@@ -1114,26 +2390,52 @@ func (q *Queries) ListMerchants(ctx context.Context, arg ListMerchantsParams) ([
 	return items, nil
 }
 
-const listMerchantsByMerchantIds = `-- name: ListMerchantsByMerchantIds :many
-SELECT m.id, m.name, m.sourceid, m.ownerid FROM transactions AS t, merchants AS m
-WHERE t.merchantId = m.id AND m.id::varchar = ANY($1::varchar[])
-ORDER BY date DESC
+const listAccountIncomeTransactions = `-- name: ListAccountIncomeTransactions :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1 AND accountId = $2 AND amount >= 0
+ORDER BY date
+LIMIT $2 OFFSET $3
 `
 
-func (q *Queries) ListMerchantsByMerchantIds(ctx context.Context, merchantids []string) ([]Merchant, error) {
-	rows, err := q.db.QueryContext(ctx, listMerchantsByMerchantIds, pq.Array(merchantids))
+type ListAccountIncomeTransactionsParams struct {
+	Ownerid uuid.UUID
+	Limit   int32
+	Start   int32
+}
+
+func (q *Queries) ListAccountIncomeTransactions(ctx context.Context, arg ListAccountIncomeTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountIncomeTransactions, arg.Ownerid, arg.Limit, arg.Start)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Merchant
+	var items []Transaction
 	for rows.Next() {
-		var i Merchant
+		var i Transaction
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
 			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
 			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -1148,34 +2450,44 @@ func (q *Queries) ListMerchantsByMerchantIds(ctx context.Context, merchantids []
 	return items, nil
 }
 
-const listMonths = `-- name: ListMonths :many
-
-SELECT EXTRACT(YEAR FROM t.date) AS year,
-       EXTRACT(MONTH FROM t.date) AS month,
-       COUNT(*) AS count
-FROM transactions AS t
-WHERE ownerId = $1
-GROUP BY EXTRACT(YEAR FROM t.date), EXTRACT(MONTH FROM t.date)
-ORDER BY year DESC, month DESC
+const listAccountInvestmentTransactions = `-- name: ListAccountInvestmentTransactions :many
+SELECT id, sourceid, type, tradedate, settledate, units, unitprice, amount, fees, isocurrencycode, description, securityid, accountid, ownerid, syncitemid FROM investment_transactions
+WHERE accountId = $1 AND tradeDate BETWEEN $2 AND $3
+ORDER BY tradeDate DESC, sourceId
 `
 
-type ListMonthsRow struct {
-	Year  string
-	Month string
-	Count int64
+type ListAccountInvestmentTransactionsParams struct {
+	Accountid uuid.UUID
+	Startdate time.Time
+	Enddate   time.Time
 }
 
-// MONTHS
-func (q *Queries) ListMonths(ctx context.Context, ownerid uuid.UUID) ([]ListMonthsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listMonths, ownerid)
+func (q *Queries) ListAccountInvestmentTransactions(ctx context.Context, arg ListAccountInvestmentTransactionsParams) ([]InvestmentTransaction, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountInvestmentTransactions, arg.Accountid, arg.Startdate, arg.Enddate)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListMonthsRow
+	var items []InvestmentTransaction
 	for rows.Next() {
-		var i ListMonthsRow
-		if err := rows.Scan(&i.Year, &i.Month, &i.Count); err != nil {
+		var i InvestmentTransaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Type,
+			&i.Tradedate,
+			&i.Settledate,
+			&i.Units,
+			&i.Unitprice,
+			&i.Amount,
+			&i.Fees,
+			&i.Isocurrencycode,
+			&i.Description,
+			&i.Securityid,
+			&i.Accountid,
+			&i.Ownerid,
+			&i.Syncitemid,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1189,37 +2501,53 @@ func (q *Queries) ListMonths(ctx context.Context, ownerid uuid.UUID) ([]ListMont
 	return items, nil
 }
 
-const listSavingsFunds = `-- name: ListSavingsFunds :many
-SELECT id, type, name, goal, startdate, enddate, ownerid FROM funds
-WHERE ownerId = $1 AND type = 'SAVINGS'
-ORDER BY name
+const listAccountSpendingTransactions = `-- name: ListAccountSpendingTransactions :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1 AND accountId = $2 AND amount < 0
+ORDER BY date DESC
 LIMIT $2 OFFSET $3
 `
 
-type ListSavingsFundsParams struct {
+type ListAccountSpendingTransactionsParams struct {
 	Ownerid uuid.UUID
 	Limit   int32
 	Start   int32
 }
 
-func (q *Queries) ListSavingsFunds(ctx context.Context, arg ListSavingsFundsParams) ([]Fund, error) {
-	rows, err := q.db.QueryContext(ctx, listSavingsFunds, arg.Ownerid, arg.Limit, arg.Start)
+func (q *Queries) ListAccountSpendingTransactions(ctx context.Context, arg ListAccountSpendingTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountSpendingTransactions, arg.Ownerid, arg.Limit, arg.Start)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Fund
+	var items []Transaction
 	for rows.Next() {
-		var i Fund
+		var i Transaction
 		if err := rows.Scan(
 			&i.ID,
-			&i.Type,
-			&i.Name,
-			&i.Goal,
-			&i.Startdate,
-			&i.Enddate,
-			&i.Ownerid,
-		); err != nil {
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1233,29 +2561,19 @@ func (q *Queries) ListSavingsFunds(ctx context.Context, arg ListSavingsFundsPara
 	return items, nil
 }
 
-const listSpendingTransactions = `-- name: ListSpendingTransactions :many
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
-WHERE ownerId = $1
-    AND amount < 0
-    AND date BETWEEN $2 AND $3
-ORDER BY date DESC
-LIMIT $1 OFFSET $4
+const listAccountTransactions = `-- name: ListAccountTransactions :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE accountId = $1 AND ownerId = $2
+ORDER BY date, sourceId
 `
 
-type ListSpendingTransactionsParams struct {
-	Limit     int32
-	Startdate time.Time
-	Enddate   time.Time
-	Start     int32
+type ListAccountTransactionsParams struct {
+	Accountid uuid.UUID
+	Ownerid   uuid.UUID
 }
 
-func (q *Queries) ListSpendingTransactions(ctx context.Context, arg ListSpendingTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listSpendingTransactions,
-		arg.Limit,
-		arg.Startdate,
-		arg.Enddate,
-		arg.Start,
-	)
+func (q *Queries) ListAccountTransactions(ctx context.Context, arg ListAccountTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransactions, arg.Accountid, arg.Ownerid)
 	if err != nil {
 		return nil, err
 	}
@@ -1279,6 +2597,14 @@ func (q *Queries) ListSpendingTransactions(ctx context.Context, arg ListSpending
 			&i.Merchantid,
 			&i.Ownerid,
 			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
 		); err != nil {
 			return nil, err
 		}
@@ -1293,44 +2619,38 @@ func (q *Queries) ListSpendingTransactions(ctx context.Context, arg ListSpending
 	return items, nil
 }
 
-const listTransactions = `-- name: ListTransactions :many
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
+const listAccounts = `-- name: ListAccounts :many
+SELECT id, sourceid, type, name, routingnumber, updated, ownerid, syncconnectionid, synccursor FROM accounts AS a
 WHERE ownerId = $1
-ORDER BY date DESC
+ORDER BY a.name
 LIMIT $2 OFFSET $3
 `
 
-type ListTransactionsParams struct {
+type ListAccountsParams struct {
 	Ownerid uuid.UUID
 	Limit   int32
 	Start   int32
 }
 
-func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listTransactions, arg.Ownerid, arg.Limit, arg.Start)
+func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Ownerid, arg.Limit, arg.Start)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Transaction
+	var items []Account
 	for rows.Next() {
-		var i Transaction
+		var i Account
 		if err := rows.Scan(
 			&i.ID,
 			&i.Sourceid,
-			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
-			&i.Date,
-			&i.Description,
 			&i.Type,
-			&i.Checknumber,
+			&i.Name,
+			&i.Routingnumber,
 			&i.Updated,
-			&i.Merchantid,
 			&i.Ownerid,
-			&i.Accountid,
+			&i.Syncconnectionid,
+			&i.Synccursor,
 		); err != nil {
 			return nil, err
 		}
@@ -1345,45 +2665,161 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 	return items, nil
 }
 
-const listTransactionsByAccountIds = `-- name: ListTransactionsByAccountIds :many
-SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid FROM transactions AS t, accounts AS a
-WHERE t.accountId = a.id
-    AND a.id::varchar = ANY($2::varchar[])
-ORDER BY date DESC
-LIMIT $1 OFFSET $3
+const listBudgetFunds = `-- name: ListBudgetFunds :many
+SELECT id, type, name, goal, startdate, enddate, ownerid FROM funds
+WHERE ownerId = $1 AND type = 'BUDGET'
+ORDER BY name
+LIMIT $2 OFFSET $3
 `
 
-type ListTransactionsByAccountIdsParams struct {
-	Limit      int32
-	Accountids []string
-	Start      int32
+type ListBudgetFundsParams struct {
+	Ownerid uuid.UUID
+	Limit   int32
+	Start   int32
 }
 
-func (q *Queries) ListTransactionsByAccountIds(ctx context.Context, arg ListTransactionsByAccountIdsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listTransactionsByAccountIds, arg.Limit, pq.Array(arg.Accountids), arg.Start)
+func (q *Queries) ListBudgetFunds(ctx context.Context, arg ListBudgetFundsParams) ([]Fund, error) {
+	rows, err := q.db.QueryContext(ctx, listBudgetFunds, arg.Ownerid, arg.Limit, arg.Start)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Transaction
+	var items []Fund
 	for rows.Next() {
-		var i Transaction
+		var i Fund
 		if err := rows.Scan(
 			&i.ID,
-			&i.Sourceid,
-			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
-			&i.Date,
-			&i.Description,
 			&i.Type,
-			&i.Checknumber,
-			&i.Updated,
-			&i.Merchantid,
+			&i.Name,
+			&i.Goal,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategories = `-- name: ListCategories :many
+SELECT id, name, parentid, ownerid, created FROM categories
+WHERE ownerId IS NULL OR ownerId = $1::uuid
+ORDER BY name
+`
+
+func (q *Queries) ListCategories(ctx context.Context, ownerid uuid.UUID) ([]Category, error) {
+	rows, err := q.db.QueryContext(ctx, listCategories, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Category
+	for rows.Next() {
+		var i Category
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Parentid,
+			&i.Ownerid,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCsvProfiles = `-- name: ListCsvProfiles :many
+SELECT id, name, institution, datecolumn, descriptioncolumn, amountcolumn, debitcolumn, creditcolumn, typecolumn, checknumbercolumn, dateformat, signconvention, skiprows, hasheader, ownerid, balancecolumn FROM csv_profiles
+WHERE ownerId = $1
+ORDER BY name
+`
+
+func (q *Queries) ListCsvProfiles(ctx context.Context, ownerid uuid.UUID) ([]CsvProfile, error) {
+	rows, err := q.db.QueryContext(ctx, listCsvProfiles, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CsvProfile
+	for rows.Next() {
+		var i CsvProfile
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Institution,
+			&i.Datecolumn,
+			&i.Descriptioncolumn,
+			&i.Amountcolumn,
+			&i.Debitcolumn,
+			&i.Creditcolumn,
+			&i.Typecolumn,
+			&i.Checknumbercolumn,
+			&i.Dateformat,
+			&i.Signconvention,
+			&i.Skiprows,
+			&i.Hasheader,
 			&i.Ownerid,
+			&i.Balancecolumn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueOfxConnections = `-- name: ListDueOfxConnections :many
+SELECT id, url, org, fid, appid, appversion, ofxversion, credentials, lastattempted, lastsynced, lasterror, created, accountid, ownerid FROM ofx_connections
+WHERE lastAttempted IS NULL OR lastAttempted < $1::timestamp
+ORDER BY lastAttempted NULLS FIRST
+`
+
+func (q *Queries) ListDueOfxConnections(ctx context.Context, attemptedbefore time.Time) ([]OfxConnection, error) {
+	rows, err := q.db.QueryContext(ctx, listDueOfxConnections, attemptedbefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OfxConnection
+	for rows.Next() {
+		var i OfxConnection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Org,
+			&i.Fid,
+			&i.Appid,
+			&i.Appversion,
+			&i.Ofxversion,
+			&i.Credentials,
+			&i.Lastattempted,
+			&i.Lastsynced,
+			&i.Lasterror,
+			&i.Created,
 			&i.Accountid,
+			&i.Ownerid,
 		); err != nil {
 			return nil, err
 		}
@@ -1398,52 +2834,80 @@ func (q *Queries) ListTransactionsByAccountIds(ctx context.Context, arg ListTran
 	return items, nil
 }
 
-const listTransactionsByDates = `-- name: ListTransactionsByDates :many
-SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid FROM transactions
-WHERE ownerId = $1 AND date BETWEEN $3 AND $4
+const listFundAllocationsByFundIds = `-- name: ListFundAllocationsByFundIds :many
+
+SELECT a.id, a.description, a.amount, a.date, a.ownerid, a.fundid FROM fund_allocations AS a, funds AS f
+WHERE a.fundId = f.id
+    AND f.id::varchar = ANY($2::varchar[])
 ORDER BY date DESC
-LIMIT $2 OFFSET $5
+LIMIT $1 OFFSET $3
 `
 
-type ListTransactionsByDatesParams struct {
-	Ownerid   uuid.UUID
-	Limit     int32
-	Startdate time.Time
-	Enddate   time.Time
-	Start     int32
+type ListFundAllocationsByFundIdsParams struct {
+	Limit   int32
+	Fundids []string
+	Start   int32
 }
 
-func (q *Queries) ListTransactionsByDates(ctx context.Context, arg ListTransactionsByDatesParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listTransactionsByDates,
-		arg.Ownerid,
-		arg.Limit,
-		arg.Startdate,
-		arg.Enddate,
-		arg.Start,
-	)
+// FUND ALLOCATIONS
+func (q *Queries) ListFundAllocationsByFundIds(ctx context.Context, arg ListFundAllocationsByFundIdsParams) ([]FundAllocation, error) {
+	rows, err := q.db.QueryContext(ctx, listFundAllocationsByFundIds, arg.Limit, pq.Array(arg.Fundids), arg.Start)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Transaction
+	var items []FundAllocation
 	for rows.Next() {
-		var i Transaction
+		var i FundAllocation
 		if err := rows.Scan(
 			&i.ID,
-			&i.Sourceid,
+			&i.Description,
 			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
 			&i.Date,
-			&i.Description,
-			&i.Type,
-			&i.Checknumber,
-			&i.Updated,
-			&i.Merchantid,
 			&i.Ownerid,
-			&i.Accountid,
+			&i.Fundid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGlobalMerchants = `-- name: ListGlobalMerchants :many
+SELECT id, name, website, categoryid, aliases, created FROM global_merchants
+WHERE $2::varchar = '' OR name ILIKE '%' || $2::varchar || '%'
+ORDER BY name
+LIMIT $1
+`
+
+type ListGlobalMerchantsParams struct {
+	Limit  int32
+	Search string
+}
+
+func (q *Queries) ListGlobalMerchants(ctx context.Context, arg ListGlobalMerchantsParams) ([]GlobalMerchant, error) {
+	rows, err := q.db.QueryContext(ctx, listGlobalMerchants, arg.Limit, arg.Search)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GlobalMerchant
+	for rows.Next() {
+		var i GlobalMerchant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Website,
+			&i.Categoryid,
+			pq.Array(&i.Aliases),
+			&i.Created,
 		); err != nil {
 			return nil, err
 		}
@@ -1458,64 +2922,2218 @@ func (q *Queries) ListTransactionsByDates(ctx context.Context, arg ListTransacti
 	return items, nil
 }
 
-const listTransactionsByMerchantIds = `-- name: ListTransactionsByMerchantIds :many
-SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid FROM transactions AS t, merchants AS m
-WHERE t.merchantId = m.id
-    AND m.id::varchar = ANY($2::varchar[])
-ORDER BY date DESC
-LIMIT $1 OFFSET $3
+const listGlobalMerchantsByIds = `-- name: ListGlobalMerchantsByIds :many
+SELECT id, name, website, categoryid, aliases, created FROM global_merchants
+WHERE id::varchar = ANY($1::varchar[])
+`
+
+func (q *Queries) ListGlobalMerchantsByIds(ctx context.Context, ids []string) ([]GlobalMerchant, error) {
+	rows, err := q.db.QueryContext(ctx, listGlobalMerchantsByIds, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GlobalMerchant
+	for rows.Next() {
+		var i GlobalMerchant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Website,
+			&i.Categoryid,
+			pq.Array(&i.Aliases),
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportBatchSyncItems = `-- name: ListImportBatchSyncItems :many
+SELECT id, date, uploadsource, accountid, importbatchid FROM account_sync_items
+WHERE importBatchId = $1
+`
+
+func (q *Queries) ListImportBatchSyncItems(ctx context.Context, importbatchid uuid.NullUUID) ([]AccountSyncItem, error) {
+	rows, err := q.db.QueryContext(ctx, listImportBatchSyncItems, importbatchid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountSyncItem
+	for rows.Next() {
+		var i AccountSyncItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Uploadsource,
+			&i.Accountid,
+			&i.Importbatchid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportBatches = `-- name: ListImportBatches :many
+SELECT id, filename, filehash, uploadsource, status, accountsupdated, accountsfailed, transactionsupdated, transactionsfailed, errors, created, ownerid FROM import_batches
+WHERE ownerId = $1
+ORDER BY created DESC
+LIMIT $2
+`
+
+type ListImportBatchesParams struct {
+	Ownerid uuid.UUID
+	Limit   int32
+}
+
+func (q *Queries) ListImportBatches(ctx context.Context, arg ListImportBatchesParams) ([]ImportBatch, error) {
+	rows, err := q.db.QueryContext(ctx, listImportBatches, arg.Ownerid, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ImportBatch
+	for rows.Next() {
+		var i ImportBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.Filehash,
+			&i.Uploadsource,
+			&i.Status,
+			&i.Accountsupdated,
+			&i.Accountsfailed,
+			&i.Transactionsupdated,
+			&i.Transactionsfailed,
+			pq.Array(&i.Errors),
+			&i.Created,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listIncomeTransactions = `-- name: ListIncomeTransactions :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1
+    AND amount >= 0 AND date BETWEEN $3 AND $4
+    AND ($5::boolean OR status = 'POSTED')
+ORDER BY date DESC
+LIMIT $2 OFFSET $6
+`
+
+type ListIncomeTransactionsParams struct {
+	Ownerid        uuid.UUID
+	Limit          int32
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+	Start          int32
+}
+
+func (q *Queries) ListIncomeTransactions(ctx context.Context, arg ListIncomeTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listIncomeTransactions,
+		arg.Ownerid,
+		arg.Limit,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+		arg.Start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLabeledTransactions = `-- name: ListLabeledTransactions :many
+SELECT description, merchantId, amount, type, categoryId FROM transactions
+WHERE ownerId = $1 AND categorySource = 'MANUAL' AND categoryId IS NOT NULL
+`
+
+type ListLabeledTransactionsRow struct {
+	Description string
+	Merchantid  uuid.UUID
+	Amount      int32
+	Type        TransactionType
+	Categoryid  uuid.NullUUID
+}
+
+func (q *Queries) ListLabeledTransactions(ctx context.Context, ownerid uuid.UUID) ([]ListLabeledTransactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLabeledTransactions, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLabeledTransactionsRow
+	for rows.Next() {
+		var i ListLabeledTransactionsRow
+		if err := rows.Scan(
+			&i.Description,
+			&i.Merchantid,
+			&i.Amount,
+			&i.Type,
+			&i.Categoryid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLatestAccountBalancesByAccountIds = `-- name: ListLatestAccountBalancesByAccountIds :many
+SELECT DISTINCT ON (accountId) id, date, current, available, accountid, ownerid, syncitemid FROM account_balances
+WHERE ownerId = $1 AND accountId::varchar = ANY($2::varchar[])
+ORDER BY accountId, date DESC
+`
+
+type ListLatestAccountBalancesByAccountIdsParams struct {
+	Ownerid    uuid.UUID
+	Accountids []string
+}
+
+// Each account's latest balance. Accounts without a balance have no row
+func (q *Queries) ListLatestAccountBalancesByAccountIds(ctx context.Context, arg ListLatestAccountBalancesByAccountIdsParams) ([]AccountBalance, error) {
+	rows, err := q.db.QueryContext(ctx, listLatestAccountBalancesByAccountIds, arg.Ownerid, pq.Array(arg.Accountids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AccountBalance
+	for rows.Next() {
+		var i AccountBalance
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Current,
+			&i.Available,
+			&i.Accountid,
+			&i.Ownerid,
+			&i.Syncitemid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listManualAssetValuations = `-- name: ListManualAssetValuations :many
+SELECT id, date, value, assetid, ownerid FROM manual_asset_valuations
+WHERE assetId = $1
+ORDER BY date DESC
+`
+
+func (q *Queries) ListManualAssetValuations(ctx context.Context, assetid uuid.UUID) ([]ManualAssetValuation, error) {
+	rows, err := q.db.QueryContext(ctx, listManualAssetValuations, assetid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ManualAssetValuation
+	for rows.Next() {
+		var i ManualAssetValuation
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Value,
+			&i.Assetid,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listManualAssets = `-- name: ListManualAssets :many
+SELECT id, name, type, ownerid FROM manual_assets
+WHERE ownerId = $1
+ORDER BY name
+`
+
+func (q *Queries) ListManualAssets(ctx context.Context, ownerid uuid.UUID) ([]ManualAsset, error) {
+	rows, err := q.db.QueryContext(ctx, listManualAssets, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ManualAsset
+	for rows.Next() {
+		var i ManualAsset
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchantRuleCandidates = `-- name: ListMerchantRuleCandidates :many
+SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid, t.syncitemid, t.status, t.authorizeddate, t.categoryid, t.categorysource, t.categoryconfidence, t.needsreview, t.note, s.uploadSource FROM transactions AS t
+LEFT JOIN account_sync_items AS s ON t.syncItemId = s.id
+WHERE t.ownerId = $1
+ORDER BY t.date DESC
+`
+
+type ListMerchantRuleCandidatesRow struct {
+	Transaction  Transaction
+	Uploadsource NullUploadSource
+}
+
+// Every transaction with the source it was imported from, for testing a rule
+// against the user's history
+func (q *Queries) ListMerchantRuleCandidates(ctx context.Context, ownerid uuid.UUID) ([]ListMerchantRuleCandidatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantRuleCandidates, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMerchantRuleCandidatesRow
+	for rows.Next() {
+		var i ListMerchantRuleCandidatesRow
+		if err := rows.Scan(
+			&i.Transaction.ID,
+			&i.Transaction.Sourceid,
+			&i.Transaction.Amount,
+			&i.Transaction.Payeeid,
+			&i.Transaction.Payee,
+			&i.Transaction.Payeefull,
+			&i.Transaction.Isocurrencycode,
+			&i.Transaction.Date,
+			&i.Transaction.Description,
+			&i.Transaction.Type,
+			&i.Transaction.Checknumber,
+			&i.Transaction.Updated,
+			&i.Transaction.Merchantid,
+			&i.Transaction.Ownerid,
+			&i.Transaction.Accountid,
+			&i.Transaction.Syncitemid,
+			&i.Transaction.Status,
+			&i.Transaction.Authorizeddate,
+			&i.Transaction.Categoryid,
+			&i.Transaction.Categorysource,
+			&i.Transaction.Categoryconfidence,
+			&i.Transaction.Needsreview,
+			&i.Transaction.Note,
+			&i.Uploadsource,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchantRules = `-- name: ListMerchantRules :many
+SELECT id, priority, type, pattern, minamount, maxamount, uploadsource, merchantid, ownerid, created FROM merchant_rules
+WHERE ownerId = $1
+ORDER BY priority, created
+`
+
+func (q *Queries) ListMerchantRules(ctx context.Context, ownerid uuid.UUID) ([]MerchantRule, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantRules, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MerchantRule
+	for rows.Next() {
+		var i MerchantRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Priority,
+			&i.Type,
+			&i.Pattern,
+			&i.Minamount,
+			&i.Maxamount,
+			&i.Uploadsource,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchantTransactionsByIds = `-- name: ListMerchantTransactionsByIds :many
+SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid, t.syncitemid, t.status, t.authorizeddate, t.categoryid, t.categorysource, t.categoryconfidence, t.needsreview, t.note, s.uploadSource FROM transactions AS t
+LEFT JOIN account_sync_items AS s ON t.syncItemId = s.id
+WHERE t.ownerId = $1
+    AND t.merchantId = $2
+    AND t.id::varchar = ANY($3::varchar[])
+ORDER BY t.date DESC
+`
+
+type ListMerchantTransactionsByIdsParams struct {
+	Ownerid        uuid.UUID
+	Merchantid     uuid.UUID
+	Transactionids []string
+}
+
+type ListMerchantTransactionsByIdsRow struct {
+	Transaction  Transaction
+	Uploadsource NullUploadSource
+}
+
+func (q *Queries) ListMerchantTransactionsByIds(ctx context.Context, arg ListMerchantTransactionsByIdsParams) ([]ListMerchantTransactionsByIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantTransactionsByIds, arg.Ownerid, arg.Merchantid, pq.Array(arg.Transactionids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMerchantTransactionsByIdsRow
+	for rows.Next() {
+		var i ListMerchantTransactionsByIdsRow
+		if err := rows.Scan(
+			&i.Transaction.ID,
+			&i.Transaction.Sourceid,
+			&i.Transaction.Amount,
+			&i.Transaction.Payeeid,
+			&i.Transaction.Payee,
+			&i.Transaction.Payeefull,
+			&i.Transaction.Isocurrencycode,
+			&i.Transaction.Date,
+			&i.Transaction.Description,
+			&i.Transaction.Type,
+			&i.Transaction.Checknumber,
+			&i.Transaction.Updated,
+			&i.Transaction.Merchantid,
+			&i.Transaction.Ownerid,
+			&i.Transaction.Accountid,
+			&i.Transaction.Syncitemid,
+			&i.Transaction.Status,
+			&i.Transaction.Authorizeddate,
+			&i.Transaction.Categoryid,
+			&i.Transaction.Categorysource,
+			&i.Transaction.Categoryconfidence,
+			&i.Transaction.Needsreview,
+			&i.Transaction.Note,
+			&i.Uploadsource,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchants = `-- name: ListMerchants :many
+SELECT id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid FROM merchants
+WHERE ownerId = $1
+ORDER BY name
+LIMIT $2 OFFSET $3
+`
+
+type ListMerchantsParams struct {
+	Ownerid uuid.UUID
+	Limit   int32
+	Start   int32
+}
+
+func (q *Queries) ListMerchants(ctx context.Context, arg ListMerchantsParams) ([]Merchant, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchants, arg.Ownerid, arg.Limit, arg.Start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Merchant
+	for rows.Next() {
+		var i Merchant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Sourceid,
+			&i.Ownerid,
+			&i.Syncitemid,
+			&i.Website,
+			&i.Categoryid,
+			&i.Globalmerchantid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchantsByMerchantIds = `-- name: ListMerchantsByMerchantIds :many
+SELECT id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid FROM merchants
+WHERE ownerId = $1 AND id::varchar = ANY($2::varchar[])
+`
+
+type ListMerchantsByMerchantIdsParams struct {
+	Ownerid     uuid.UUID
+	Merchantids []string
+}
+
+func (q *Queries) ListMerchantsByMerchantIds(ctx context.Context, arg ListMerchantsByMerchantIdsParams) ([]Merchant, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantsByMerchantIds, arg.Ownerid, pq.Array(arg.Merchantids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Merchant
+	for rows.Next() {
+		var i Merchant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Sourceid,
+			&i.Ownerid,
+			&i.Syncitemid,
+			&i.Website,
+			&i.Categoryid,
+			&i.Globalmerchantid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMonths = `-- name: ListMonths :many
+
+SELECT EXTRACT(YEAR FROM t.date) AS year,
+       EXTRACT(MONTH FROM t.date) AS month,
+       COUNT(*) AS count
+FROM transactions AS t
+WHERE ownerId = $1
+GROUP BY EXTRACT(YEAR FROM t.date), EXTRACT(MONTH FROM t.date)
+ORDER BY year DESC, month DESC
+`
+
+type ListMonthsRow struct {
+	Year  string
+	Month string
+	Count int64
+}
+
+// MONTHS
+func (q *Queries) ListMonths(ctx context.Context, ownerid uuid.UUID) ([]ListMonthsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMonths, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMonthsRow
+	for rows.Next() {
+		var i ListMonthsRow
+		if err := rows.Scan(&i.Year, &i.Month, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetWorthAccountBalances = `-- name: ListNetWorthAccountBalances :many
+SELECT b.id, b.date, b.current, b.available, b.accountid, b.ownerid, b.syncitemid, a.type AS accountType FROM account_balances AS b
+JOIN accounts AS a ON b.accountId = a.id
+WHERE b.ownerId = $1 AND b.date <= $2
+ORDER BY b.date
+`
+
+type ListNetWorthAccountBalancesParams struct {
+	Ownerid uuid.UUID
+	Enddate time.Time
+}
+
+type ListNetWorthAccountBalancesRow struct {
+	ID          uuid.UUID
+	Date        time.Time
+	Current     int32
+	Available   sql.NullInt32
+	Accountid   uuid.UUID
+	Ownerid     uuid.UUID
+	Syncitemid  uuid.NullUUID
+	Accounttype AccountType
+}
+
+func (q *Queries) ListNetWorthAccountBalances(ctx context.Context, arg ListNetWorthAccountBalancesParams) ([]ListNetWorthAccountBalancesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNetWorthAccountBalances, arg.Ownerid, arg.Enddate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNetWorthAccountBalancesRow
+	for rows.Next() {
+		var i ListNetWorthAccountBalancesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Current,
+			&i.Available,
+			&i.Accountid,
+			&i.Ownerid,
+			&i.Syncitemid,
+			&i.Accounttype,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNetWorthAssetValuations = `-- name: ListNetWorthAssetValuations :many
+SELECT v.id, v.date, v.value, v.assetid, v.ownerid, m.type AS assetType FROM manual_asset_valuations AS v
+JOIN manual_assets AS m ON v.assetId = m.id
+WHERE v.ownerId = $1 AND v.date <= $2
+ORDER BY v.date
+`
+
+type ListNetWorthAssetValuationsParams struct {
+	Ownerid uuid.UUID
+	Enddate time.Time
+}
+
+type ListNetWorthAssetValuationsRow struct {
+	ID        uuid.UUID
+	Date      time.Time
+	Value     int32
+	Assetid   uuid.UUID
+	Ownerid   uuid.UUID
+	Assettype ManualAssetType
+}
+
+func (q *Queries) ListNetWorthAssetValuations(ctx context.Context, arg ListNetWorthAssetValuationsParams) ([]ListNetWorthAssetValuationsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listNetWorthAssetValuations, arg.Ownerid, arg.Enddate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListNetWorthAssetValuationsRow
+	for rows.Next() {
+		var i ListNetWorthAssetValuationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Value,
+			&i.Assetid,
+			&i.Ownerid,
+			&i.Assettype,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOfxConnections = `-- name: ListOfxConnections :many
+SELECT id, url, org, fid, appid, appversion, ofxversion, credentials, lastattempted, lastsynced, lasterror, created, accountid, ownerid FROM ofx_connections
+WHERE ownerId = $1
+ORDER BY created
+`
+
+func (q *Queries) ListOfxConnections(ctx context.Context, ownerid uuid.UUID) ([]OfxConnection, error) {
+	rows, err := q.db.QueryContext(ctx, listOfxConnections, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OfxConnection
+	for rows.Next() {
+		var i OfxConnection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Url,
+			&i.Org,
+			&i.Fid,
+			&i.Appid,
+			&i.Appversion,
+			&i.Ofxversion,
+			&i.Credentials,
+			&i.Lastattempted,
+			&i.Lastsynced,
+			&i.Lasterror,
+			&i.Created,
+			&i.Accountid,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingTransactions = `-- name: ListPendingTransactions :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE accountId = $1 AND status = 'PENDING' AND date BETWEEN $2 AND $3
+ORDER BY date, sourceId
+`
+
+type ListPendingTransactionsParams struct {
+	Accountid uuid.UUID
+	Startdate time.Time
+	Enddate   time.Time
+}
+
+func (q *Queries) ListPendingTransactions(ctx context.Context, arg ListPendingTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingTransactions, arg.Accountid, arg.Startdate, arg.Enddate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSavingsFunds = `-- name: ListSavingsFunds :many
+SELECT id, type, name, goal, startdate, enddate, ownerid FROM funds
+WHERE ownerId = $1 AND type = 'SAVINGS'
+ORDER BY name
+LIMIT $2 OFFSET $3
+`
+
+type ListSavingsFundsParams struct {
+	Ownerid uuid.UUID
+	Limit   int32
+	Start   int32
+}
+
+func (q *Queries) ListSavingsFunds(ctx context.Context, arg ListSavingsFundsParams) ([]Fund, error) {
+	rows, err := q.db.QueryContext(ctx, listSavingsFunds, arg.Ownerid, arg.Limit, arg.Start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Fund
+	for rows.Next() {
+		var i Fund
+		if err := rows.Scan(
+			&i.ID,
+			&i.Type,
+			&i.Name,
+			&i.Goal,
+			&i.Startdate,
+			&i.Enddate,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSpendingTransactions = `-- name: ListSpendingTransactions :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1
+    AND amount < 0 AND date BETWEEN $3 AND $4
+    AND ($5::boolean OR status = 'POSTED')
+ORDER BY date DESC
+LIMIT $2 OFFSET $6
+`
+
+type ListSpendingTransactionsParams struct {
+	Ownerid        uuid.UUID
+	Limit          int32
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+	Start          int32
+}
+
+func (q *Queries) ListSpendingTransactions(ctx context.Context, arg ListSpendingTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listSpendingTransactions,
+		arg.Ownerid,
+		arg.Limit,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+		arg.Start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSyncConnections = `-- name: ListSyncConnections :many
+SELECT id, provider, itemid, accesstoken, lastsynced, created, ownerid FROM sync_connections
+WHERE ownerId = $1
+ORDER BY created
+`
+
+func (q *Queries) ListSyncConnections(ctx context.Context, ownerid uuid.UUID) ([]SyncConnection, error) {
+	rows, err := q.db.QueryContext(ctx, listSyncConnections, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SyncConnection
+	for rows.Next() {
+		var i SyncConnection
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provider,
+			&i.Itemid,
+			&i.Accesstoken,
+			&i.Lastsynced,
+			&i.Created,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTags = `-- name: ListTags :many
+SELECT id, name, ownerid, created FROM tags
+WHERE ownerId = $1
+ORDER BY lower(name)
+`
+
+func (q *Queries) ListTags(ctx context.Context, ownerid uuid.UUID) ([]Tag, error) {
+	rows, err := q.db.QueryContext(ctx, listTags, ownerid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Ownerid,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTagsByTransactionIds = `-- name: ListTagsByTransactionIds :many
+SELECT tt.transactionId, tag.id, tag.name, tag.ownerid, tag.created FROM transaction_tags AS tt
+JOIN tags AS tag ON tt.tagId = tag.id
+WHERE tag.ownerId = $1 AND tt.transactionId::varchar = ANY($2::varchar[])
+ORDER BY lower(tag.name)
+`
+
+type ListTagsByTransactionIdsParams struct {
+	Ownerid        uuid.UUID
+	Transactionids []string
+}
+
+type ListTagsByTransactionIdsRow struct {
+	Transactionid uuid.UUID
+	Tag           Tag
+}
+
+func (q *Queries) ListTagsByTransactionIds(ctx context.Context, arg ListTagsByTransactionIdsParams) ([]ListTagsByTransactionIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTagsByTransactionIds, arg.Ownerid, pq.Array(arg.Transactionids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTagsByTransactionIdsRow
+	for rows.Next() {
+		var i ListTagsByTransactionIdsRow
+		if err := rows.Scan(
+			&i.Transactionid,
+			&i.Tag.ID,
+			&i.Tag.Name,
+			&i.Tag.Ownerid,
+			&i.Tag.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionSplitsByTransactionIds = `-- name: ListTransactionSplitsByTransactionIds :many
+SELECT id, transactionid, amount, categoryid, note, fundid, ownerid FROM transaction_splits
+WHERE ownerId = $1 AND transactionId::varchar = ANY($2::varchar[])
+ORDER BY transactionId, abs(amount) DESC, id
+`
+
+type ListTransactionSplitsByTransactionIdsParams struct {
+	Ownerid        uuid.UUID
+	Transactionids []string
+}
+
+func (q *Queries) ListTransactionSplitsByTransactionIds(ctx context.Context, arg ListTransactionSplitsByTransactionIdsParams) ([]TransactionSplit, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactionSplitsByTransactionIds, arg.Ownerid, pq.Array(arg.Transactionids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TransactionSplit
+	for rows.Next() {
+		var i TransactionSplit
+		if err := rows.Scan(
+			&i.ID,
+			&i.Transactionid,
+			&i.Amount,
+			&i.Categoryid,
+			&i.Note,
+			&i.Fundid,
+			&i.Ownerid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactions = `-- name: ListTransactions :many
+SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid, t.syncitemid, t.status, t.authorizeddate, t.categoryid, t.categorysource, t.categoryconfidence, t.needsreview, t.note FROM transactions AS t
+JOIN merchants AS m ON t.merchantId = m.id
+WHERE t.ownerId = $1 AND ($3::boolean OR t.status = 'POSTED')
+    AND ($4::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM categories AS c
+        WHERE (c.id = $4 OR c.parentId = $4)
+            AND c.id IN (
+                SELECT COALESCE(s.categoryId, t.categoryId, m.categoryId)
+                FROM transaction_splits AS s
+                WHERE s.transactionId = t.id
+                UNION ALL
+                SELECT COALESCE(t.categoryId, m.categoryId)
+                WHERE NOT EXISTS (SELECT 1 FROM transaction_splits AS s WHERE s.transactionId = t.id)
+            )
+    ))
+    AND ($5::boolean IS NULL OR t.needsReview = $5)
+    AND ($6::varchar[] IS NULL OR EXISTS (
+        SELECT 1 FROM transaction_tags AS tt
+        JOIN tags AS tag ON tt.tagId = tag.id
+        WHERE tt.transactionId = t.id AND lower(tag.name) = ANY($6::varchar[])
+    ))
+ORDER BY t.date DESC
+LIMIT $2 OFFSET $7
+`
+
+type ListTransactionsParams struct {
+	Ownerid        uuid.UUID
+	Limit          int32
+	Includepending bool
+	Categoryid     uuid.NullUUID
+	Needsreview    sql.NullBool
+	Tags           []string
+	Start          int32
+}
+
+// Transactions without a category have their merchant's category. Split
+// transactions only have their parts' categories, with parts without a
+// category in the transaction's category. Filtering by a top level category
+// includes its subcategories. Filtering by tags matches transactions with any
+// of the lowercase tag names
+func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactions,
+		arg.Ownerid,
+		arg.Limit,
+		arg.Includepending,
+		arg.Categoryid,
+		arg.Needsreview,
+		pq.Array(arg.Tags),
+		arg.Start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByAccountIds = `-- name: ListTransactionsByAccountIds :many
+SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid, t.syncitemid, t.status, t.authorizeddate, t.categoryid, t.categorysource, t.categoryconfidence, t.needsreview, t.note FROM transactions AS t, accounts AS a
+WHERE t.accountId = a.id
+    AND a.id::varchar = ANY($2::varchar[])
+    AND ($3::boolean OR t.status = 'POSTED')
+ORDER BY date DESC
+LIMIT $1 OFFSET $4
+`
+
+type ListTransactionsByAccountIdsParams struct {
+	Limit          int32
+	Accountids     []string
+	Includepending bool
+	Start          int32
+}
+
+func (q *Queries) ListTransactionsByAccountIds(ctx context.Context, arg ListTransactionsByAccountIdsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactionsByAccountIds,
+		arg.Limit,
+		pq.Array(arg.Accountids),
+		arg.Includepending,
+		arg.Start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByDates = `-- name: ListTransactionsByDates :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1 AND date BETWEEN $3 AND $4 AND ($5::boolean OR status = 'POSTED')
+ORDER BY date DESC
+LIMIT $2 OFFSET $6
+`
+
+type ListTransactionsByDatesParams struct {
+	Ownerid        uuid.UUID
+	Limit          int32
+	Startdate      time.Time
+	Enddate        time.Time
+	Includepending bool
+	Start          int32
+}
+
+func (q *Queries) ListTransactionsByDates(ctx context.Context, arg ListTransactionsByDatesParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactionsByDates,
+		arg.Ownerid,
+		arg.Limit,
+		arg.Startdate,
+		arg.Enddate,
+		arg.Includepending,
+		arg.Start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByIds = `-- name: ListTransactionsByIds :many
+SELECT id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note FROM transactions
+WHERE ownerId = $1 AND id::varchar = ANY($2::varchar[])
+ORDER BY date DESC
+`
+
+type ListTransactionsByIdsParams struct {
+	Ownerid        uuid.UUID
+	Transactionids []string
+}
+
+func (q *Queries) ListTransactionsByIds(ctx context.Context, arg ListTransactionsByIdsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactionsByIds, arg.Ownerid, pq.Array(arg.Transactionids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByMerchantIds = `-- name: ListTransactionsByMerchantIds :many
+SELECT t.id, t.sourceid, t.amount, t.payeeid, t.payee, t.payeefull, t.isocurrencycode, t.date, t.description, t.type, t.checknumber, t.updated, t.merchantid, t.ownerid, t.accountid, t.syncitemid, t.status, t.authorizeddate, t.categoryid, t.categorysource, t.categoryconfidence, t.needsreview, t.note FROM transactions AS t, merchants AS m
+WHERE t.ownerId = $2
+    AND t.merchantId = m.id
+    AND m.id::varchar = ANY($3::varchar[])
+    AND ($4::boolean OR t.status = 'POSTED')
+ORDER BY date DESC
+LIMIT $1 OFFSET $5
+`
+
+type ListTransactionsByMerchantIdsParams struct {
+	Limit          int32
+	Ownerid        uuid.UUID
+	Merchantids    []string
+	Includepending bool
+	Start          int32
+}
+
+func (q *Queries) ListTransactionsByMerchantIds(ctx context.Context, arg ListTransactionsByMerchantIdsParams) ([]Transaction, error) {
+	rows, err := q.db.QueryContext(ctx, listTransactionsByMerchantIds,
+		arg.Limit,
+		arg.Ownerid,
+		pq.Array(arg.Merchantids),
+		arg.Includepending,
+		arg.Start,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.Sourceid,
+			&i.Amount,
+			&i.Payeeid,
+			&i.Payee,
+			&i.Payeefull,
+			&i.Isocurrencycode,
+			&i.Date,
+			&i.Description,
+			&i.Type,
+			&i.Checknumber,
+			&i.Updated,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Accountid,
+			&i.Syncitemid,
+			&i.Status,
+			&i.Authorizeddate,
+			&i.Categoryid,
+			&i.Categorysource,
+			&i.Categoryconfidence,
+			&i.Needsreview,
+			&i.Note,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUploadSourceMerchantRules = `-- name: ListUploadSourceMerchantRules :many
+SELECT id, priority, type, pattern, minamount, maxamount, uploadsource, merchantid, ownerid, created FROM merchant_rules
+WHERE ownerId = $1 AND (uploadSource IS NULL OR uploadSource = $2)
+ORDER BY priority, created
+`
+
+type ListUploadSourceMerchantRulesParams struct {
+	Ownerid      uuid.UUID
+	Uploadsource NullUploadSource
+}
+
+// Rules without an upload source apply to every source
+func (q *Queries) ListUploadSourceMerchantRules(ctx context.Context, arg ListUploadSourceMerchantRulesParams) ([]MerchantRule, error) {
+	rows, err := q.db.QueryContext(ctx, listUploadSourceMerchantRules, arg.Ownerid, arg.Uploadsource)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MerchantRule
+	for rows.Next() {
+		var i MerchantRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Priority,
+			&i.Type,
+			&i.Pattern,
+			&i.Minamount,
+			&i.Maxamount,
+			&i.Uploadsource,
+			&i.Merchantid,
+			&i.Ownerid,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveMatchingMerchantKeys = `-- name: MoveMatchingMerchantKeys :exec
+UPDATE merchant_keys AS k
+SET merchantId = $1
+WHERE k.ownerId = $2
+    AND k.merchantId = $3
+    AND EXISTS (
+        SELECT 1 FROM transactions AS t
+        WHERE t.merchantId = $1
+            AND (upper(t.description) LIKE k.keymatch OR strpos(upper(t.description), k.keymatch) > 0)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM transactions AS t
+        WHERE t.merchantId = $3
+            AND (upper(t.description) LIKE k.keymatch OR strpos(upper(t.description), k.keymatch) > 0)
+    )
+`
+
+type MoveMatchingMerchantKeysParams struct {
+	Targetid   uuid.UUID
+	Ownerid    uuid.UUID
+	Merchantid uuid.UUID
+}
+
+// Moves the keys that match the target's transactions to the target, leaving
+// keys that still match one of the merchant's own transactions
+func (q *Queries) MoveMatchingMerchantKeys(ctx context.Context, arg MoveMatchingMerchantKeysParams) error {
+	_, err := q.db.ExecContext(ctx, moveMatchingMerchantKeys, arg.Targetid, arg.Ownerid, arg.Merchantid)
+	return err
+}
+
+const moveMerchantKeys = `-- name: MoveMerchantKeys :exec
+UPDATE merchant_keys
+SET merchantId = $1
+WHERE ownerId = $2 AND merchantId::varchar = ANY($3::varchar[])
+`
+
+type MoveMerchantKeysParams struct {
+	Targetid    uuid.UUID
+	Ownerid     uuid.UUID
+	Merchantids []string
+}
+
+func (q *Queries) MoveMerchantKeys(ctx context.Context, arg MoveMerchantKeysParams) error {
+	_, err := q.db.ExecContext(ctx, moveMerchantKeys, arg.Targetid, arg.Ownerid, pq.Array(arg.Merchantids))
+	return err
+}
+
+const moveMerchantRules = `-- name: MoveMerchantRules :exec
+UPDATE merchant_rules
+SET merchantId = $1
+WHERE ownerId = $2 AND merchantId::varchar = ANY($3::varchar[])
+`
+
+type MoveMerchantRulesParams struct {
+	Targetid    uuid.UUID
+	Ownerid     uuid.UUID
+	Merchantids []string
+}
+
+func (q *Queries) MoveMerchantRules(ctx context.Context, arg MoveMerchantRulesParams) error {
+	_, err := q.db.ExecContext(ctx, moveMerchantRules, arg.Targetid, arg.Ownerid, pq.Array(arg.Merchantids))
+	return err
+}
+
+const moveMerchantTransactions = `-- name: MoveMerchantTransactions :exec
+UPDATE transactions
+SET merchantId = $1
+WHERE ownerId = $2 AND merchantId::varchar = ANY($3::varchar[])
+`
+
+type MoveMerchantTransactionsParams struct {
+	Targetid    uuid.UUID
+	Ownerid     uuid.UUID
+	Merchantids []string
+}
+
+func (q *Queries) MoveMerchantTransactions(ctx context.Context, arg MoveMerchantTransactionsParams) error {
+	_, err := q.db.ExecContext(ctx, moveMerchantTransactions, arg.Targetid, arg.Ownerid, pq.Array(arg.Merchantids))
+	return err
+}
+
+const moveTransactionsToMerchant = `-- name: MoveTransactionsToMerchant :execrows
+UPDATE transactions
+SET merchantId = $1
+WHERE ownerId = $2
+    AND merchantId = $3
+    AND id::varchar = ANY($4::varchar[])
+`
+
+type MoveTransactionsToMerchantParams struct {
+	Targetid       uuid.UUID
+	Ownerid        uuid.UUID
+	Merchantid     uuid.UUID
+	Transactionids []string
+}
+
+func (q *Queries) MoveTransactionsToMerchant(ctx context.Context, arg MoveTransactionsToMerchantParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveTransactionsToMerchant,
+		arg.Targetid,
+		arg.Ownerid,
+		arg.Merchantid,
+		pq.Array(arg.Transactionids),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rebalanceTransactionSplits = `-- name: RebalanceTransactionSplits :exec
+UPDATE transaction_splits AS s
+SET amount = s.amount + t.amount - (
+    SELECT SUM(p.amount) FROM transaction_splits AS p WHERE p.transactionId = t.id
+)
+FROM transactions AS t
+WHERE s.transactionId = t.id AND t.ownerId = $1
+    AND ($2::uuid IS NULL OR t.id = $2)
+    AND s.id = (
+        SELECT p.id FROM transaction_splits AS p
+        WHERE p.transactionId = t.id
+        ORDER BY abs(p.amount) DESC, p.id
+        LIMIT 1
+    )
+`
+
+type RebalanceTransactionSplitsParams struct {
+	Ownerid       uuid.UUID
+	Transactionid uuid.NullUUID
+}
+
+// Adds the difference between split transactions' amounts and their parts to
+// their largest part, for when an import or revert changed the amount. Every
+// split transaction of the user is rebalanced when transactionId is null
+func (q *Queries) RebalanceTransactionSplits(ctx context.Context, arg RebalanceTransactionSplitsParams) error {
+	_, err := q.db.ExecContext(ctx, rebalanceTransactionSplits, arg.Ownerid, arg.Transactionid)
+	return err
+}
+
+const removeTransactionTags = `-- name: RemoveTransactionTags :execrows
+DELETE FROM transaction_tags AS tt
+USING transactions AS t, tags AS tag
+WHERE tt.transactionId = t.id AND tt.tagId = tag.id
+    AND t.ownerId = $1 AND t.id::varchar = ANY($2::varchar[])
+    AND lower(tag.name) = ANY($3::varchar[])
+`
+
+type RemoveTransactionTagsParams struct {
+	Ownerid        uuid.UUID
+	Transactionids []string
+	Names          []string
+}
+
+func (q *Queries) RemoveTransactionTags(ctx context.Context, arg RemoveTransactionTagsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeTransactionTags, arg.Ownerid, pq.Array(arg.Transactionids), pq.Array(arg.Names))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameMerchant = `-- name: RenameMerchant :one
+UPDATE merchants
+SET name = $3
+WHERE id = $1 AND ownerId = $2
+RETURNING id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid
+`
+
+type RenameMerchantParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+	Name    string
+}
+
+func (q *Queries) RenameMerchant(ctx context.Context, arg RenameMerchantParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, renameMerchant, arg.ID, arg.Ownerid, arg.Name)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
+	)
+	return i, err
+}
+
+const restoreTransactionRevisions = `-- name: RestoreTransactionRevisions :execrows
+UPDATE transactions AS t
+SET
+    amount = r.amount,
+    payeeId = r.payeeId,
+    payee = r.payee,
+    payeeFull = r.payeeFull,
+    isoCurrencyCode = r.isoCurrencyCode,
+    date = r.date,
+    description = r.description,
+    type = r.type,
+    checkNumber = r.checkNumber,
+    updated = r.updated,
+    sourceId = r.sourceId,
+    status = r.status,
+    authorizedDate = r.authorizedDate
+FROM transaction_revisions AS r
+WHERE r.transactionId = t.id AND r.syncItemId = $1
+`
+
+func (q *Queries) RestoreTransactionRevisions(ctx context.Context, syncitemid uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreTransactionRevisions, syncitemid)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const revertImportBatch = `-- name: RevertImportBatch :exec
+UPDATE import_batches AS b
+SET status = 'REVERTED'
+WHERE b.id = $1
+    AND NOT EXISTS (SELECT 1 FROM account_sync_items AS s WHERE s.importBatchId = b.id)
+`
+
+// Marks the batch reverted once none of its sync items are left
+func (q *Queries) RevertImportBatch(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revertImportBatch, id)
+	return err
+}
+
+const setSyncItemsImportBatch = `-- name: SetSyncItemsImportBatch :exec
+UPDATE account_sync_items
+SET importBatchId = $1
+WHERE id::varchar = ANY($2::varchar[])
+`
+
+type SetSyncItemsImportBatchParams struct {
+	Importbatchid uuid.NullUUID
+	Syncitemids   []string
+}
+
+func (q *Queries) SetSyncItemsImportBatch(ctx context.Context, arg SetSyncItemsImportBatchParams) error {
+	_, err := q.db.ExecContext(ctx, setSyncItemsImportBatch, arg.Importbatchid, pq.Array(arg.Syncitemids))
+	return err
+}
+
+const setTransactionCategory = `-- name: SetTransactionCategory :one
+UPDATE transactions
+SET
+    categoryId = $3,
+    categorySource = CASE WHEN $3::uuid IS NULL THEN NULL ELSE 'MANUAL'::CATEGORY_SOURCE END,
+    categoryConfidence = NULL,
+    needsReview = FALSE
+WHERE id = $1 AND ownerId = $2
+RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note
+`
+
+type SetTransactionCategoryParams struct {
+	ID         uuid.UUID
+	Ownerid    uuid.UUID
+	Categoryid uuid.NullUUID
+}
+
+// Categories set by the user are labels the categorizer learns from
+func (q *Queries) SetTransactionCategory(ctx context.Context, arg SetTransactionCategoryParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, setTransactionCategory, arg.ID, arg.Ownerid, arg.Categoryid)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Amount,
+		&i.Payeeid,
+		&i.Payee,
+		&i.Payeefull,
+		&i.Isocurrencycode,
+		&i.Date,
+		&i.Description,
+		&i.Type,
+		&i.Checknumber,
+		&i.Updated,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
+	)
+	return i, err
+}
+
+const setTransactionNote = `-- name: SetTransactionNote :one
+UPDATE transactions
+SET note = $3
+WHERE id = $1 AND ownerId = $2
+RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note
+`
+
+type SetTransactionNoteParams struct {
+	ID      uuid.UUID
+	Ownerid uuid.UUID
+	Note    string
+}
+
+func (q *Queries) SetTransactionNote(ctx context.Context, arg SetTransactionNoteParams) (Transaction, error) {
+	row := q.db.QueryRowContext(ctx, setTransactionNote, arg.ID, arg.Ownerid, arg.Note)
+	var i Transaction
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Amount,
+		&i.Payeeid,
+		&i.Payee,
+		&i.Payeefull,
+		&i.Isocurrencycode,
+		&i.Date,
+		&i.Description,
+		&i.Type,
+		&i.Checknumber,
+		&i.Updated,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
+	)
+	return i, err
+}
+
+const setTransactionPrediction = `-- name: SetTransactionPrediction :exec
+UPDATE transactions
+SET
+    categoryId = $3,
+    categorySource = 'CLASSIFIER',
+    categoryConfidence = $4,
+    needsReview = $5
+WHERE id = $1 AND ownerId = $2 AND categoryId IS NULL
+`
+
+type SetTransactionPredictionParams struct {
+	ID                 uuid.UUID
+	Ownerid            uuid.UUID
+	Categoryid         uuid.NullUUID
+	Categoryconfidence sql.NullFloat64
+	Needsreview        bool
+}
+
+// Predictions only categorize transactions without a category, so they never
+// replace the user's choice
+func (q *Queries) SetTransactionPrediction(ctx context.Context, arg SetTransactionPredictionParams) error {
+	_, err := q.db.ExecContext(ctx, setTransactionPrediction,
+		arg.ID,
+		arg.Ownerid,
+		arg.Categoryid,
+		arg.Categoryconfidence,
+		arg.Needsreview,
+	)
+	return err
+}
+
+const updateCategory = `-- name: UpdateCategory :one
+UPDATE categories
+SET
+    name = $1,
+    parentId = $2
+WHERE id = $3 AND ownerId = $4::uuid
+RETURNING id, name, parentid, ownerid, created
+`
+
+type UpdateCategoryParams struct {
+	Name     string
+	Parentid uuid.NullUUID
+	ID       uuid.UUID
+	Ownerid  uuid.UUID
+}
+
+// Default categories have no owner, so they can't be changed
+func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, updateCategory,
+		arg.Name,
+		arg.Parentid,
+		arg.ID,
+		arg.Ownerid,
+	)
+	var i Category
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Parentid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const updateCsvProfile = `-- name: UpdateCsvProfile :one
+UPDATE csv_profiles
+SET
+    name = $3,
+    institution = $4,
+    dateColumn = $5,
+    descriptionColumn = $6,
+    amountColumn = $7,
+    debitColumn = $8,
+    creditColumn = $9,
+    typeColumn = $10,
+    checkNumberColumn = $11,
+    dateFormat = $12,
+    signConvention = $13,
+    skipRows = $14,
+    hasHeader = $15,
+    balanceColumn = $16
+WHERE id = $1 AND ownerId = $2
+RETURNING id, name, institution, datecolumn, descriptioncolumn, amountcolumn, debitcolumn, creditcolumn, typecolumn, checknumbercolumn, dateformat, signconvention, skiprows, hasheader, ownerid, balancecolumn
+`
+
+type UpdateCsvProfileParams struct {
+	ID                uuid.UUID
+	Ownerid           uuid.UUID
+	Name              string
+	Institution       sql.NullString
+	Datecolumn        string
+	Descriptioncolumn string
+	Amountcolumn      sql.NullString
+	Debitcolumn       sql.NullString
+	Creditcolumn      sql.NullString
+	Typecolumn        sql.NullString
+	Checknumbercolumn sql.NullString
+	Dateformat        string
+	Signconvention    SignConvention
+	Skiprows          int32
+	Hasheader         bool
+	Balancecolumn     sql.NullString
+}
+
+func (q *Queries) UpdateCsvProfile(ctx context.Context, arg UpdateCsvProfileParams) (CsvProfile, error) {
+	row := q.db.QueryRowContext(ctx, updateCsvProfile,
+		arg.ID,
+		arg.Ownerid,
+		arg.Name,
+		arg.Institution,
+		arg.Datecolumn,
+		arg.Descriptioncolumn,
+		arg.Amountcolumn,
+		arg.Debitcolumn,
+		arg.Creditcolumn,
+		arg.Typecolumn,
+		arg.Checknumbercolumn,
+		arg.Dateformat,
+		arg.Signconvention,
+		arg.Skiprows,
+		arg.Hasheader,
+		arg.Balancecolumn,
+	)
+	var i CsvProfile
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Institution,
+		&i.Datecolumn,
+		&i.Descriptioncolumn,
+		&i.Amountcolumn,
+		&i.Debitcolumn,
+		&i.Creditcolumn,
+		&i.Typecolumn,
+		&i.Checknumbercolumn,
+		&i.Dateformat,
+		&i.Signconvention,
+		&i.Skiprows,
+		&i.Hasheader,
+		&i.Ownerid,
+		&i.Balancecolumn,
+	)
+	return i, err
+}
+
+const updateGlobalMerchant = `-- name: UpdateGlobalMerchant :one
+UPDATE global_merchants
+SET
+    name = $2,
+    website = $3,
+    categoryId = $4,
+    aliases = $5
+WHERE id = $1
+RETURNING id, name, website, categoryid, aliases, created
+`
+
+type UpdateGlobalMerchantParams struct {
+	ID         uuid.UUID
+	Name       string
+	Website    sql.NullString
+	Categoryid uuid.NullUUID
+	Aliases    []string
+}
+
+func (q *Queries) UpdateGlobalMerchant(ctx context.Context, arg UpdateGlobalMerchantParams) (GlobalMerchant, error) {
+	row := q.db.QueryRowContext(ctx, updateGlobalMerchant,
+		arg.ID,
+		arg.Name,
+		arg.Website,
+		arg.Categoryid,
+		pq.Array(arg.Aliases),
+	)
+	var i GlobalMerchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Website,
+		&i.Categoryid,
+		pq.Array(&i.Aliases),
+		&i.Created,
+	)
+	return i, err
+}
+
+const updateMerchantOverrides = `-- name: UpdateMerchantOverrides :one
+UPDATE merchants
+SET
+    website = $3,
+    categoryId = $4,
+    globalMerchantId = $5
+WHERE id = $1 AND ownerId = $2
+RETURNING id, name, sourceid, ownerid, syncitemid, website, categoryid, globalmerchantid
+`
+
+type UpdateMerchantOverridesParams struct {
+	ID               uuid.UUID
+	Ownerid          uuid.UUID
+	Website          sql.NullString
+	Categoryid       uuid.NullUUID
+	Globalmerchantid uuid.NullUUID
+}
+
+func (q *Queries) UpdateMerchantOverrides(ctx context.Context, arg UpdateMerchantOverridesParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantOverrides,
+		arg.ID,
+		arg.Ownerid,
+		arg.Website,
+		arg.Categoryid,
+		arg.Globalmerchantid,
+	)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Sourceid,
+		&i.Ownerid,
+		&i.Syncitemid,
+		&i.Website,
+		&i.Categoryid,
+		&i.Globalmerchantid,
+	)
+	return i, err
+}
+
+const updateMerchantRule = `-- name: UpdateMerchantRule :one
+UPDATE merchant_rules
+SET
+    priority = $3,
+    type = $4,
+    pattern = $5,
+    minAmount = $6,
+    maxAmount = $7,
+    uploadSource = $8,
+    merchantId = $9
+WHERE id = $1 AND ownerId = $2
+RETURNING id, priority, type, pattern, minamount, maxamount, uploadsource, merchantid, ownerid, created
+`
+
+type UpdateMerchantRuleParams struct {
+	ID           uuid.UUID
+	Ownerid      uuid.UUID
+	Priority     int32
+	Type         MerchantRuleType
+	Pattern      string
+	Minamount    sql.NullInt32
+	Maxamount    sql.NullInt32
+	Uploadsource NullUploadSource
+	Merchantid   uuid.UUID
+}
+
+func (q *Queries) UpdateMerchantRule(ctx context.Context, arg UpdateMerchantRuleParams) (MerchantRule, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantRule,
+		arg.ID,
+		arg.Ownerid,
+		arg.Priority,
+		arg.Type,
+		arg.Pattern,
+		arg.Minamount,
+		arg.Maxamount,
+		arg.Uploadsource,
+		arg.Merchantid,
+	)
+	var i MerchantRule
+	err := row.Scan(
+		&i.ID,
+		&i.Priority,
+		&i.Type,
+		&i.Pattern,
+		&i.Minamount,
+		&i.Maxamount,
+		&i.Uploadsource,
+		&i.Merchantid,
+		&i.Ownerid,
+		&i.Created,
+	)
+	return i, err
+}
+
+const updateOfxConnectionFailed = `-- name: UpdateOfxConnectionFailed :exec
+UPDATE ofx_connections
+SET lastAttempted = NOW(), lastError = $2
+WHERE id = $1
 `
 
-type ListTransactionsByMerchantIdsParams struct {
-	Limit       int32
-	Merchantids []string
-	Start       int32
+type UpdateOfxConnectionFailedParams struct {
+	ID        uuid.UUID
+	Lasterror sql.NullString
 }
 
-func (q *Queries) ListTransactionsByMerchantIds(ctx context.Context, arg ListTransactionsByMerchantIdsParams) ([]Transaction, error) {
-	rows, err := q.db.QueryContext(ctx, listTransactionsByMerchantIds, arg.Limit, pq.Array(arg.Merchantids), arg.Start)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Transaction
-	for rows.Next() {
-		var i Transaction
-		if err := rows.Scan(
-			&i.ID,
-			&i.Sourceid,
-			&i.Amount,
-			&i.Payeeid,
-			&i.Payee,
-			&i.Payeefull,
-			&i.Isocurrencycode,
-			&i.Date,
-			&i.Description,
-			&i.Type,
-			&i.Checknumber,
-			&i.Updated,
-			&i.Merchantid,
-			&i.Ownerid,
-			&i.Accountid,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) UpdateOfxConnectionFailed(ctx context.Context, arg UpdateOfxConnectionFailedParams) error {
+	_, err := q.db.ExecContext(ctx, updateOfxConnectionFailed, arg.ID, arg.Lasterror)
+	return err
+}
+
+const updateOfxConnectionSynced = `-- name: UpdateOfxConnectionSynced :exec
+UPDATE ofx_connections
+SET lastAttempted = NOW(), lastSynced = NOW(), lastError = NULL
+WHERE id = $1
+`
+
+func (q *Queries) UpdateOfxConnectionSynced(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, updateOfxConnectionSynced, id)
+	return err
+}
+
+const updateSyncConnectionCursor = `-- name: UpdateSyncConnectionCursor :exec
+UPDATE accounts
+SET syncCursor = $2
+WHERE syncConnectionId = $1
+`
+
+type UpdateSyncConnectionCursorParams struct {
+	Syncconnectionid uuid.NullUUID
+	Synccursor       sql.NullString
+}
+
+func (q *Queries) UpdateSyncConnectionCursor(ctx context.Context, arg UpdateSyncConnectionCursorParams) error {
+	_, err := q.db.ExecContext(ctx, updateSyncConnectionCursor, arg.Syncconnectionid, arg.Synccursor)
+	return err
+}
+
+const updateSyncConnectionSynced = `-- name: UpdateSyncConnectionSynced :exec
+UPDATE sync_connections
+SET lastSynced = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) UpdateSyncConnectionSynced(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, updateSyncConnectionSynced, id)
+	return err
 }
 
 const updateTransaction = `-- name: UpdateTransaction :one
 UPDATE transactions
 SET amount = $3
 WHERE id = $1 AND ownerId = $2
-RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid
+RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note
 `
 
 type UpdateTransactionParams struct {
@@ -1543,10 +5161,35 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		&i.Merchantid,
 		&i.Ownerid,
 		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
 	)
 	return i, err
 }
 
+const updateTransactionSourceId = `-- name: UpdateTransactionSourceId :exec
+UPDATE transactions
+SET sourceId = $1
+WHERE ownerId = $2 AND sourceId = $3
+`
+
+type UpdateTransactionSourceIdParams struct {
+	Newsourceid string
+	Ownerid     uuid.UUID
+	Sourceid    string
+}
+
+func (q *Queries) UpdateTransactionSourceId(ctx context.Context, arg UpdateTransactionSourceIdParams) error {
+	_, err := q.db.ExecContext(ctx, updateTransactionSourceId, arg.Newsourceid, arg.Ownerid, arg.Sourceid)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET username = $2, email = $3
@@ -1583,13 +5226,13 @@ INSERT INTO accounts (
     ownerId
 )
 VALUES ($1, $2, $3, $4, $5, $6)
-ON CONFLICT (sourceId) DO UPDATE
+ON CONFLICT (ownerId, sourceId) DO UPDATE
 SET
     type = $2,
     name = $3,
     routingNumber = $4,
     updated = $5
-RETURNING id, sourceid, type, name, routingnumber, updated, ownerid
+RETURNING id, sourceid, type, name, routingnumber, updated, ownerid, syncconnectionid, synccursor
 `
 
 type UpsertAccountParams struct {
@@ -1620,6 +5263,390 @@ func (q *Queries) UpsertAccount(ctx context.Context, arg UpsertAccountParams) (A
 		&i.Routingnumber,
 		&i.Updated,
 		&i.Ownerid,
+		&i.Syncconnectionid,
+		&i.Synccursor,
+	)
+	return i, err
+}
+
+const upsertAccountBalance = `-- name: UpsertAccountBalance :one
+INSERT INTO account_balances (
+    accountId,
+    date,
+    current,
+    available,
+    ownerId,
+    syncItemId
+)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (accountId, date) DO UPDATE
+SET
+    current = $3,
+    available = $4,
+    syncItemId = $6
+RETURNING id, date, current, available, accountid, ownerid, syncitemid
+`
+
+type UpsertAccountBalanceParams struct {
+	Accountid  uuid.UUID
+	Date       time.Time
+	Current    int32
+	Available  sql.NullInt32
+	Ownerid    uuid.UUID
+	Syncitemid uuid.NullUUID
+}
+
+func (q *Queries) UpsertAccountBalance(ctx context.Context, arg UpsertAccountBalanceParams) (AccountBalance, error) {
+	row := q.db.QueryRowContext(ctx, upsertAccountBalance,
+		arg.Accountid,
+		arg.Date,
+		arg.Current,
+		arg.Available,
+		arg.Ownerid,
+		arg.Syncitemid,
+	)
+	var i AccountBalance
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Current,
+		&i.Available,
+		&i.Accountid,
+		&i.Ownerid,
+		&i.Syncitemid,
+	)
+	return i, err
+}
+
+const upsertInvestmentTransaction = `-- name: UpsertInvestmentTransaction :one
+INSERT INTO investment_transactions (
+    sourceId,
+    type,
+    tradeDate,
+    settleDate,
+    units,
+    unitPrice,
+    amount,
+    fees,
+    isoCurrencyCode,
+    description,
+    securityId,
+    accountId,
+    ownerId,
+    syncItemId
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (accountId, sourceId) DO UPDATE
+SET
+    type = $2,
+    tradeDate = $3,
+    settleDate = $4,
+    units = $5,
+    unitPrice = $6,
+    amount = $7,
+    fees = $8,
+    isoCurrencyCode = $9,
+    description = $10,
+    securityId = $11,
+    syncItemId = $14
+RETURNING id, sourceid, type, tradedate, settledate, units, unitprice, amount, fees, isocurrencycode, description, securityid, accountid, ownerid, syncitemid
+`
+
+type UpsertInvestmentTransactionParams struct {
+	Sourceid        string
+	Type            InvestmentTransactionType
+	Tradedate       time.Time
+	Settledate      sql.NullTime
+	Units           float64
+	Unitprice       float64
+	Amount          int32
+	Fees            int32
+	Isocurrencycode string
+	Description     string
+	Securityid      uuid.NullUUID
+	Accountid       uuid.UUID
+	Ownerid         uuid.UUID
+	Syncitemid      uuid.NullUUID
+}
+
+func (q *Queries) UpsertInvestmentTransaction(ctx context.Context, arg UpsertInvestmentTransactionParams) (InvestmentTransaction, error) {
+	row := q.db.QueryRowContext(ctx, upsertInvestmentTransaction,
+		arg.Sourceid,
+		arg.Type,
+		arg.Tradedate,
+		arg.Settledate,
+		arg.Units,
+		arg.Unitprice,
+		arg.Amount,
+		arg.Fees,
+		arg.Isocurrencycode,
+		arg.Description,
+		arg.Securityid,
+		arg.Accountid,
+		arg.Ownerid,
+		arg.Syncitemid,
+	)
+	var i InvestmentTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.Sourceid,
+		&i.Type,
+		&i.Tradedate,
+		&i.Settledate,
+		&i.Units,
+		&i.Unitprice,
+		&i.Amount,
+		&i.Fees,
+		&i.Isocurrencycode,
+		&i.Description,
+		&i.Securityid,
+		&i.Accountid,
+		&i.Ownerid,
+		&i.Syncitemid,
+	)
+	return i, err
+}
+
+const upsertManualAssetValuation = `-- name: UpsertManualAssetValuation :one
+INSERT INTO manual_asset_valuations (assetId, date, value, ownerId)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (assetId, date) DO UPDATE
+SET value = $3
+RETURNING id, date, value, assetid, ownerid
+`
+
+type UpsertManualAssetValuationParams struct {
+	Assetid uuid.UUID
+	Date    time.Time
+	Value   int32
+	Ownerid uuid.UUID
+}
+
+func (q *Queries) UpsertManualAssetValuation(ctx context.Context, arg UpsertManualAssetValuationParams) (ManualAssetValuation, error) {
+	row := q.db.QueryRowContext(ctx, upsertManualAssetValuation,
+		arg.Assetid,
+		arg.Date,
+		arg.Value,
+		arg.Ownerid,
+	)
+	var i ManualAssetValuation
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Value,
+		&i.Assetid,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const upsertMerchantKey = `-- name: UpsertMerchantKey :one
+INSERT INTO merchant_keys (
+    keymatch,
+    uploadSource,
+    merchantId,
+    ownerId
+)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (ownerId, keymatch) DO UPDATE
+SET
+    uploadSource = EXCLUDED.uploadSource,
+    merchantId = EXCLUDED.merchantId
+RETURNING id, keymatch, uploadsource, merchantid, ownerid
+`
+
+type UpsertMerchantKeyParams struct {
+	Keymatch     string
+	Uploadsource UploadSource
+	Merchantid   uuid.UUID
+	Ownerid      uuid.UUID
+}
+
+func (q *Queries) UpsertMerchantKey(ctx context.Context, arg UpsertMerchantKeyParams) (MerchantKey, error) {
+	row := q.db.QueryRowContext(ctx, upsertMerchantKey,
+		arg.Keymatch,
+		arg.Uploadsource,
+		arg.Merchantid,
+		arg.Ownerid,
+	)
+	var i MerchantKey
+	err := row.Scan(
+		&i.ID,
+		&i.Keymatch,
+		&i.Uploadsource,
+		&i.Merchantid,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const upsertOfxConnection = `-- name: UpsertOfxConnection :one
+INSERT INTO ofx_connections (
+    url, org, fid, appId, appVersion, ofxVersion, credentials, accountId, ownerId
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (accountId) DO UPDATE
+SET url = EXCLUDED.url,
+    org = EXCLUDED.org,
+    fid = EXCLUDED.fid,
+    appId = EXCLUDED.appId,
+    appVersion = EXCLUDED.appVersion,
+    ofxVersion = EXCLUDED.ofxVersion,
+    credentials = EXCLUDED.credentials,
+    lastError = NULL
+WHERE ofx_connections.ownerId = EXCLUDED.ownerId
+RETURNING id, url, org, fid, appid, appversion, ofxversion, credentials, lastattempted, lastsynced, lasterror, created, accountid, ownerid
+`
+
+type UpsertOfxConnectionParams struct {
+	Url         string
+	Org         string
+	Fid         string
+	Appid       string
+	Appversion  string
+	Ofxversion  string
+	Credentials string
+	Accountid   uuid.UUID
+	Ownerid     uuid.UUID
+}
+
+func (q *Queries) UpsertOfxConnection(ctx context.Context, arg UpsertOfxConnectionParams) (OfxConnection, error) {
+	row := q.db.QueryRowContext(ctx, upsertOfxConnection,
+		arg.Url,
+		arg.Org,
+		arg.Fid,
+		arg.Appid,
+		arg.Appversion,
+		arg.Ofxversion,
+		arg.Credentials,
+		arg.Accountid,
+		arg.Ownerid,
+	)
+	var i OfxConnection
+	err := row.Scan(
+		&i.ID,
+		&i.Url,
+		&i.Org,
+		&i.Fid,
+		&i.Appid,
+		&i.Appversion,
+		&i.Ofxversion,
+		&i.Credentials,
+		&i.Lastattempted,
+		&i.Lastsynced,
+		&i.Lasterror,
+		&i.Created,
+		&i.Accountid,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const upsertSecurity = `-- name: UpsertSecurity :one
+INSERT INTO securities (
+    uniqueId, uniqueIdType, ticker, name, type
+) VALUES (
+    $1, $2, $3, $4, $5
+)
+ON CONFLICT (uniqueIdType, uniqueId) DO UPDATE
+SET ticker = COALESCE(securities.ticker, EXCLUDED.ticker),
+    name = CASE WHEN securities.name = securities.uniqueId THEN EXCLUDED.name ELSE securities.name END,
+    type = CASE WHEN securities.type = 'OTHER' THEN EXCLUDED.type ELSE securities.type END
+RETURNING id, uniqueid, uniqueidtype, ticker, name, type
+`
+
+type UpsertSecurityParams struct {
+	Uniqueid     string
+	Uniqueidtype string
+	Ticker       sql.NullString
+	Name         string
+	Type         SecurityType
+}
+
+// Securities are shared by every user's accounts, so statements only fill
+// in details a security is missing, e.g. one first saved by id
+func (q *Queries) UpsertSecurity(ctx context.Context, arg UpsertSecurityParams) (Security, error) {
+	row := q.db.QueryRowContext(ctx, upsertSecurity,
+		arg.Uniqueid,
+		arg.Uniqueidtype,
+		arg.Ticker,
+		arg.Name,
+		arg.Type,
+	)
+	var i Security
+	err := row.Scan(
+		&i.ID,
+		&i.Uniqueid,
+		&i.Uniqueidtype,
+		&i.Ticker,
+		&i.Name,
+		&i.Type,
+	)
+	return i, err
+}
+
+const upsertSyncConnection = `-- name: UpsertSyncConnection :one
+INSERT INTO sync_connections (
+    provider, itemId, accessToken, ownerId
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (provider, itemId) DO UPDATE
+SET accessToken = EXCLUDED.accessToken
+WHERE sync_connections.ownerId = EXCLUDED.ownerId
+RETURNING id, provider, itemid, accesstoken, lastsynced, created, ownerid
+`
+
+type UpsertSyncConnectionParams struct {
+	Provider    UploadSource
+	Itemid      string
+	Accesstoken string
+	Ownerid     uuid.UUID
+}
+
+func (q *Queries) UpsertSyncConnection(ctx context.Context, arg UpsertSyncConnectionParams) (SyncConnection, error) {
+	row := q.db.QueryRowContext(ctx, upsertSyncConnection,
+		arg.Provider,
+		arg.Itemid,
+		arg.Accesstoken,
+		arg.Ownerid,
+	)
+	var i SyncConnection
+	err := row.Scan(
+		&i.ID,
+		&i.Provider,
+		&i.Itemid,
+		&i.Accesstoken,
+		&i.Lastsynced,
+		&i.Created,
+		&i.Ownerid,
+	)
+	return i, err
+}
+
+const upsertTag = `-- name: UpsertTag :one
+INSERT INTO tags (name, ownerId)
+VALUES ($1, $2)
+ON CONFLICT (ownerId, lower(name)) DO UPDATE
+SET name = tags.name
+RETURNING id, name, ownerid, created
+`
+
+type UpsertTagParams struct {
+	Name    string
+	Ownerid uuid.UUID
+}
+
+// Finds a tag by name ignoring case, creating it if the user doesn't have it
+func (q *Queries) UpsertTag(ctx context.Context, arg UpsertTagParams) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, upsertTag, arg.Name, arg.Ownerid)
+	var i Tag
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Ownerid,
+		&i.Created,
 	)
 	return i, err
 }
@@ -1639,10 +5666,13 @@ INSERT INTO transactions (
     updated,
     ownerId,
     accountId,
-    merchantId
+    merchantId,
+    syncItemId,
+    status,
+    authorizedDate
 )
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-ON CONFLICT (sourceId) DO UPDATE
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+ON CONFLICT (ownerId, sourceId) DO UPDATE
 SET
     amount = $2,
     payeeId = $3,
@@ -1653,8 +5683,10 @@ SET
     description = $8,
     type = $9,
     checkNumber = $10,
-    updated = $11
-RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid
+    updated = $11,
+    status = CASE WHEN transactions.status = 'POSTED' THEN transactions.status ELSE $16 END,
+    authorizedDate = COALESCE($17, transactions.authorizedDate)
+RETURNING id, sourceid, amount, payeeid, payee, payeefull, isocurrencycode, date, description, type, checknumber, updated, merchantid, ownerid, accountid, syncitemid, status, authorizeddate, categoryid, categorysource, categoryconfidence, needsreview, note
 `
 
 type UpsertTransactionParams struct {
@@ -1672,8 +5704,12 @@ type UpsertTransactionParams struct {
 	Ownerid         uuid.UUID
 	Accountid       uuid.UUID
 	Merchantid      uuid.UUID
+	Syncitemid      uuid.NullUUID
+	Status          TransactionStatus
+	Authorizeddate  sql.NullTime
 }
 
+// A posted transaction is never set back to pending
 // WHERE ownerId = $13 -- HOW DO WE INCLUDE OWNER ID FOR UPDATE, NO VALIDATION
 func (q *Queries) UpsertTransaction(ctx context.Context, arg UpsertTransactionParams) (Transaction, error) {
 	row := q.db.QueryRowContext(ctx, upsertTransaction,
@@ -1691,6 +5727,9 @@ func (q *Queries) UpsertTransaction(ctx context.Context, arg UpsertTransactionPa
 		arg.Ownerid,
 		arg.Accountid,
 		arg.Merchantid,
+		arg.Syncitemid,
+		arg.Status,
+		arg.Authorizeddate,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -1709,6 +5748,14 @@ func (q *Queries) UpsertTransaction(ctx context.Context, arg UpsertTransactionPa
 		&i.Merchantid,
 		&i.Ownerid,
 		&i.Accountid,
+		&i.Syncitemid,
+		&i.Status,
+		&i.Authorizeddate,
+		&i.Categoryid,
+		&i.Categorysource,
+		&i.Categoryconfidence,
+		&i.Needsreview,
+		&i.Note,
 	)
 	return i, err
 }
@@ -3,13 +3,19 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 )
 
 type Repository interface {
+	// Database transactions
+	WithTx(ctx context.Context, txFn func(Repository) error) error
+
 	// Users
 	GetUser(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
@@ -18,6 +24,7 @@ type Repository interface {
 
 	// Accounts
 	GetAccount(ctx context.Context, arg GetAccountParams) (Account, error)
+	GetAccountBySourceId(ctx context.Context, arg GetAccountBySourceIdParams) (Account, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
 	UpsertAccount(ctx context.Context, arg UpsertAccountParams) (Account, error)
 	CountAccounts(ctx context.Context, ownerid uuid.UUID) (int64, error)
@@ -25,49 +32,117 @@ type Repository interface {
 	// Account Sync Item
 	GetLastSync(ctx context.Context, accountId uuid.UUID) (AccountSyncItem, error)
 	CreateAccountSyncItem(ctx context.Context, arg CreateAccountSyncItemParams) (AccountSyncItem, error)
+	GetAccountSyncItem(ctx context.Context, arg GetAccountSyncItemParams) (AccountSyncItem, error)
+	ListImportBatchSyncItems(ctx context.Context, importBatchId uuid.NullUUID) ([]AccountSyncItem, error)
+	SetSyncItemsImportBatch(ctx context.Context, arg SetSyncItemsImportBatchParams) error
+	RevertSync(ctx context.Context, arg RevertSyncParams) (RevertSyncResult, error)
+
+	// Account Balances
+	GetLatestAccountBalance(ctx context.Context, accountId uuid.UUID) (AccountBalance, error)
+	ListLatestAccountBalancesByAccountIds(ctx context.Context, arg ListLatestAccountBalancesByAccountIdsParams) ([]AccountBalance, error)
+	ListAccountBalances(ctx context.Context, arg ListAccountBalancesParams) ([]AccountBalance, error)
+	UpsertAccountBalance(ctx context.Context, arg UpsertAccountBalanceParams) (AccountBalance, error)
+	ListNetWorthAccountBalances(ctx context.Context, arg ListNetWorthAccountBalancesParams) ([]ListNetWorthAccountBalancesRow, error)
 
 	// Transactions
 	GetTransaction(ctx context.Context, arg GetTransactionParams) (Transaction, error)
+	GetTransactionBySourceId(ctx context.Context, arg GetTransactionBySourceIdParams) (Transaction, error)
 	ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error)
 	ListTransactionsByDates(ctx context.Context, arg ListTransactionsByDatesParams) ([]Transaction, error)
 	ListTransactionsByAccountIds(ctx context.Context, arg ListTransactionsByAccountIdsParams) ([]Transaction, error)
 	ListTransactionsByMerchantIds(ctx context.Context, arg ListTransactionsByMerchantIdsParams) ([]Transaction, error)
+	ListAccountTransactions(ctx context.Context, arg ListAccountTransactionsParams) ([]Transaction, error)
+	DeleteTransactionsBySourceIds(ctx context.Context, arg DeleteTransactionsBySourceIdsParams) (int64, error)
 	ListSpendingTransactions(ctx context.Context, arg ListSpendingTransactionsParams) ([]Transaction, error)
 	ListIncomeTransactions(ctx context.Context, arg ListIncomeTransactionsParams) ([]Transaction, error)
 	ListAccountSpendingTransactions(ctx context.Context, arg ListAccountSpendingTransactionsParams) ([]Transaction, error)
 	ListAccountIncomeTransactions(ctx context.Context, arg ListAccountIncomeTransactionsParams) ([]Transaction, error)
+	ListPendingTransactions(ctx context.Context, arg ListPendingTransactionsParams) ([]Transaction, error)
 	ListMonths(ctx context.Context, args uuid.UUID) ([]ListMonthsRow, error)
-	CountTransactions(ctx context.Context, ownerid uuid.UUID) (int64, error)
+	CountTransactions(ctx context.Context, arg CountTransactionsParams) (int64, error)
 	CountTransactionsByDates(ctx context.Context, arg CountTransactionsByDatesParams) (int64, error)
-	CountTransactionsByAccountIds(ctx context.Context, accountIds []string) ([]CountTransactionsByAccountIdsRow, error)
-	CountTransactionsByMerchantIds(ctx context.Context, merchantIds []string) ([]CountTransactionsByMerchantIdsRow, error)
+	CountTransactionsByAccountIds(ctx context.Context, arg CountTransactionsByAccountIdsParams) ([]CountTransactionsByAccountIdsRow, error)
+	CountTransactionsByMerchantIds(ctx context.Context, arg CountTransactionsByMerchantIdsParams) ([]CountTransactionsByMerchantIdsRow, error)
 	CountIncomeTransactions(ctx context.Context, arg CountIncomeTransactionsParams) (int64, error)
 	CountSpendingTransactions(ctx context.Context, arg CountSpendingTransactionsParams) (int64, error)
 	UpsertTransaction(ctx context.Context, arg UpsertTransactionParams) (Transaction, error)
+	SyncTransaction(ctx context.Context, arg UpsertTransactionParams) (Transaction, error)
+	ReconcileTransaction(ctx context.Context, arg ReconcileTransactionParams) (Transaction, error)
+	SetTransactionCategory(ctx context.Context, arg SetTransactionCategoryParams) (Transaction, error)
+	SetTransactionPrediction(ctx context.Context, arg SetTransactionPredictionParams) error
+	ListLabeledTransactions(ctx context.Context, ownerid uuid.UUID) ([]ListLabeledTransactionsRow, error)
+	ListTransactionsByIds(ctx context.Context, arg ListTransactionsByIdsParams) ([]Transaction, error)
+	SetTransactionNote(ctx context.Context, arg SetTransactionNoteParams) (Transaction, error)
 	DeleteTransaction(ctx context.Context, id uuid.UUID) (Transaction, error)
 
+	// Transaction splits
+	ListTransactionSplitsByTransactionIds(ctx context.Context, arg ListTransactionSplitsByTransactionIdsParams) ([]TransactionSplit, error)
+	SplitTransaction(ctx context.Context, arg SplitTransactionParams) (Transaction, error)
+
+	// Tags
+	ListTags(ctx context.Context, ownerid uuid.UUID) ([]Tag, error)
+	ListTagsByTransactionIds(ctx context.Context, arg ListTagsByTransactionIdsParams) ([]ListTagsByTransactionIdsRow, error)
+	DeleteTag(ctx context.Context, arg DeleteTagParams) (Tag, error)
+	TagTransactions(ctx context.Context, arg TagTransactionsParams) (int64, error)
+	RemoveTransactionTags(ctx context.Context, arg RemoveTransactionTagsParams) (int64, error)
+
 	// Merchants
 	GetMerchant(ctx context.Context, arg GetMerchantParams) (Merchant, error)
-	GetMerchantByName(ctx context.Context, name string) (Merchant, error)
-	GetMerchantBySourceId(ctx context.Context, sourceId sql.NullString) (Merchant, error)
+	GetMerchantByName(ctx context.Context, arg GetMerchantByNameParams) (Merchant, error)
+	GetMerchantBySourceId(ctx context.Context, arg GetMerchantBySourceIdParams) (Merchant, error)
+	GetMerchantByGlobalMerchantId(ctx context.Context, arg GetMerchantByGlobalMerchantIdParams) (Merchant, error)
 	GetMerchantByKey(ctx context.Context, arg GetMerchantByKeyParams) (Merchant, error)
 	ListMerchants(ctx context.Context, arg ListMerchantsParams) ([]Merchant, error)
-	ListMerchantsByMerchantIds(ctx context.Context, merchantIds []string) ([]Merchant, error)
+	ListMerchantsByMerchantIds(ctx context.Context, arg ListMerchantsByMerchantIdsParams) ([]Merchant, error)
 	CountMerchants(ctx context.Context, ownerid uuid.UUID) (int64, error)
 	CreateMerchant(ctx context.Context, arg CreateMerchantParams) (Merchant, error)
+	GetMerchantByKeyMatch(ctx context.Context, arg GetMerchantByKeyMatchParams) (Merchant, error)
+	ListMerchantTransactionsByIds(ctx context.Context, arg ListMerchantTransactionsByIdsParams) ([]ListMerchantTransactionsByIdsRow, error)
+	RenameMerchant(ctx context.Context, arg RenameMerchantParams) (Merchant, error)
+	UpdateMerchantOverrides(ctx context.Context, arg UpdateMerchantOverridesParams) (Merchant, error)
 	LinkMerchant(ctx context.Context, arg LinkMerchantParams) (*Merchant, error)
+	MergeMerchants(ctx context.Context, arg MergeMerchantsParams) (Merchant, error)
+	SplitMerchant(ctx context.Context, arg SplitMerchantParams) (Merchant, error)
+
+	// Global merchants
+	GetGlobalMerchant(ctx context.Context, id uuid.UUID) (GlobalMerchant, error)
+	FindGlobalMerchant(ctx context.Context, name string) (GlobalMerchant, error)
+	ListGlobalMerchants(ctx context.Context, arg ListGlobalMerchantsParams) ([]GlobalMerchant, error)
+	ListGlobalMerchantsByIds(ctx context.Context, ids []string) ([]GlobalMerchant, error)
+	CreateGlobalMerchant(ctx context.Context, arg CreateGlobalMerchantParams) (GlobalMerchant, error)
+	UpdateGlobalMerchant(ctx context.Context, arg UpdateGlobalMerchantParams) (GlobalMerchant, error)
+
+	// Categories
+	GetCategory(ctx context.Context, arg GetCategoryParams) (Category, error)
+	ListCategories(ctx context.Context, ownerid uuid.UUID) ([]Category, error)
+	CountSubcategories(ctx context.Context, parentid uuid.NullUUID) (int64, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	DeleteCategory(ctx context.Context, arg DeleteCategoryParams) (Category, error)
 
 	// Merchant keys
 	CreateMerchantKey(ctx context.Context, arg CreateMerchantKeyParams) (MerchantKey, error)
 
+	// Merchant rules
+	GetMerchantRule(ctx context.Context, arg GetMerchantRuleParams) (MerchantRule, error)
+	ListMerchantRules(ctx context.Context, ownerid uuid.UUID) ([]MerchantRule, error)
+	ListUploadSourceMerchantRules(ctx context.Context, arg ListUploadSourceMerchantRulesParams) ([]MerchantRule, error)
+	ListMerchantRuleCandidates(ctx context.Context, ownerid uuid.UUID) ([]ListMerchantRuleCandidatesRow, error)
+	CreateMerchantRule(ctx context.Context, arg CreateMerchantRuleParams) (MerchantRule, error)
+	UpdateMerchantRule(ctx context.Context, arg UpdateMerchantRuleParams) (MerchantRule, error)
+	DeleteMerchantRule(ctx context.Context, arg DeleteMerchantRuleParams) (MerchantRule, error)
+
 	// Stats
 	GetTotalSpending(ctx context.Context, arg GetTotalSpendingParams) (interface{}, error)
 	GetTotalIncome(ctx context.Context, arg GetTotalIncomeParams) (interface{}, error)
 	GetNetIncome(ctx context.Context, arg GetNetIncomeParams) (interface{}, error)
 	GetAccountSpending(ctx context.Context, arg GetAccountSpendingParams) (interface{}, error)
 	GetAccountIncome(ctx context.Context, arg GetAccountIncomeParams) (interface{}, error)
+	GetSpendingByCategory(ctx context.Context, arg GetSpendingByCategoryParams) ([]GetSpendingByCategoryRow, error)
+	GetTagTotals(ctx context.Context, arg GetTagTotalsParams) ([]GetTagTotalsRow, error)
 
 	// Funds
+	GetFund(ctx context.Context, arg GetFundParams) (Fund, error)
 	CreateFund(ctx context.Context, arg CreateFundParams) (Fund, error)
 	ListSavingsFunds(ctx context.Context, arg ListSavingsFundsParams) ([]Fund, error)
 	ListBudgetFunds(ctx context.Context, arg ListBudgetFundsParams) ([]Fund, error)
@@ -79,11 +154,70 @@ type Repository interface {
 	ListFundAllocationsByFundIds(ctx context.Context, arg ListFundAllocationsByFundIdsParams) ([]FundAllocation, error)
 	CountFundAllocationsByFundId(ctx context.Context, fundIds []string) ([]CountFundAllocationsByFundIdRow, error)
 	GetFundAllocationsStats(ctx context.Context, arg GetFundAllocationsStatsParams) (GetFundAllocationsStatsRow, error)
+
+	// Manual Assets
+	GetManualAsset(ctx context.Context, arg GetManualAssetParams) (ManualAsset, error)
+	ListManualAssets(ctx context.Context, ownerid uuid.UUID) ([]ManualAsset, error)
+	CreateManualAsset(ctx context.Context, arg CreateManualAssetParams) (ManualAsset, error)
+	DeleteManualAsset(ctx context.Context, arg DeleteManualAssetParams) (ManualAsset, error)
+	ListManualAssetValuations(ctx context.Context, assetId uuid.UUID) ([]ManualAssetValuation, error)
+	ListNetWorthAssetValuations(ctx context.Context, arg ListNetWorthAssetValuationsParams) ([]ListNetWorthAssetValuationsRow, error)
+	UpsertManualAssetValuation(ctx context.Context, arg UpsertManualAssetValuationParams) (ManualAssetValuation, error)
+	AddManualAsset(ctx context.Context, arg AddManualAssetParams) (*ManualAsset, error)
+
+	// CSV Profiles
+	GetCsvProfile(ctx context.Context, arg GetCsvProfileParams) (CsvProfile, error)
+	ListCsvProfiles(ctx context.Context, ownerid uuid.UUID) ([]CsvProfile, error)
+	CreateCsvProfile(ctx context.Context, arg CreateCsvProfileParams) (CsvProfile, error)
+	UpdateCsvProfile(ctx context.Context, arg UpdateCsvProfileParams) (CsvProfile, error)
+	DeleteCsvProfile(ctx context.Context, arg DeleteCsvProfileParams) (CsvProfile, error)
+
+	// Import Batches
+	GetCompletedImportBatch(ctx context.Context, arg GetCompletedImportBatchParams) (ImportBatch, error)
+	ListImportBatches(ctx context.Context, arg ListImportBatchesParams) ([]ImportBatch, error)
+	CreateImportBatch(ctx context.Context, arg CreateImportBatchParams) (ImportBatch, error)
+
+	// Sync Connections
+	GetSyncConnection(ctx context.Context, arg GetSyncConnectionParams) (SyncConnection, error)
+	ListSyncConnections(ctx context.Context, ownerid uuid.UUID) ([]SyncConnection, error)
+	UpsertSyncConnection(ctx context.Context, arg UpsertSyncConnectionParams) (SyncConnection, error)
+	UpdateSyncConnectionSynced(ctx context.Context, id uuid.UUID) error
+	GetSyncConnectionCursor(ctx context.Context, syncconnectionid uuid.NullUUID) (sql.NullString, error)
+	LinkSyncConnectionAccounts(ctx context.Context, arg LinkSyncConnectionAccountsParams) error
+	UpdateSyncConnectionCursor(ctx context.Context, arg UpdateSyncConnectionCursorParams) error
+
+	// OFX Connections
+	GetOfxConnection(ctx context.Context, arg GetOfxConnectionParams) (OfxConnection, error)
+	ListOfxConnections(ctx context.Context, ownerid uuid.UUID) ([]OfxConnection, error)
+	ListDueOfxConnections(ctx context.Context, attemptedbefore time.Time) ([]OfxConnection, error)
+	UpsertOfxConnection(ctx context.Context, arg UpsertOfxConnectionParams) (OfxConnection, error)
+	UpdateOfxConnectionSynced(ctx context.Context, id uuid.UUID) error
+	UpdateOfxConnectionFailed(ctx context.Context, arg UpdateOfxConnectionFailedParams) error
+	DeleteOfxConnection(ctx context.Context, arg DeleteOfxConnectionParams) (OfxConnection, error)
+
+	// Securities
+	UpsertSecurity(ctx context.Context, arg UpsertSecurityParams) (Security, error)
+	GetSecurity(ctx context.Context, id uuid.UUID) (Security, error)
+
+	// Holdings
+	DeleteAccountHoldings(ctx context.Context, arg DeleteAccountHoldingsParams) error
+	CreateHolding(ctx context.Context, arg CreateHoldingParams) (Holding, error)
+	ListAccountHoldings(ctx context.Context, arg ListAccountHoldingsParams) ([]Holding, error)
+
+	// Investment Transactions
+	UpsertInvestmentTransaction(ctx context.Context, arg UpsertInvestmentTransactionParams) (InvestmentTransaction, error)
+	ListAccountInvestmentTransactions(ctx context.Context, arg ListAccountInvestmentTransactionsParams) ([]InvestmentTransaction, error)
 }
 
+var ErrSyncOverwritten = errors.New("sync has been overwritten by a later upload")
+var ErrMerchantNotFound = errors.New("merchant not found")
+var ErrTransactionNotFound = errors.New("transaction not found")
+var ErrSplitAmount = errors.New("split amounts don't add up to the transaction amount")
+
 type repositoryService struct {
 	*Queries
 	db *sql.DB
+	tx *sql.Tx // set when bound to a transaction from WithTx
 }
 
 func NewRepository(db *sql.DB) Repository {
@@ -98,12 +232,38 @@ func Open(dataSourceName string) (*sql.DB, error) {
 }
 
 func (r repositoryService) withTx(ctx context.Context, txFn func(*Queries) error) error {
+	// Join the outer transaction so nested calls commit or roll back together
+	if r.tx != nil {
+		return txFn(r.Queries)
+	}
+
+	return r.runTx(ctx, func(tx *sql.Tx) error {
+		return txFn(New(tx))
+	})
+}
+
+// WithTx runs txFn with a Repository bound to a single database
+// transaction. The transaction commits only if txFn returns nil
+func (r *repositoryService) WithTx(ctx context.Context, txFn func(Repository) error) error {
+	if r.tx != nil {
+		return txFn(r)
+	}
+
+	return r.runTx(ctx, func(tx *sql.Tx) error {
+		return txFn(&repositoryService{
+			Queries: New(tx),
+			db:      r.db,
+			tx:      tx,
+		})
+	})
+}
+
+func (r repositoryService) runTx(ctx context.Context, txFn func(*sql.Tx) error) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	q := New(tx)
-	err = txFn(q)
+	err = txFn(tx)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			err = fmt.Errorf("tx failed: %v, unable to rollback: %v", err, rbErr)
@@ -115,11 +275,14 @@ func (r repositoryService) withTx(ctx context.Context, txFn func(*Queries) error
 }
 
 type LinkMerchantParams struct {
-	MerchantName string
-	KeyMatch     string
-	UploadSource UploadSource
-	SourceId     sql.NullString
-	UserId       uuid.UUID
+	MerchantName     string
+	KeyMatch         string
+	UploadSource     UploadSource
+	SourceId         sql.NullString
+	SyncItemId       uuid.NullUUID
+	GlobalMerchantId uuid.NullUUID
+	CategoryId       uuid.NullUUID
+	UserId           uuid.UUID
 }
 
 func (r *repositoryService) LinkMerchant(ctx context.Context, arg LinkMerchantParams) (*Merchant, error) {
@@ -127,9 +290,12 @@ func (r *repositoryService) LinkMerchant(ctx context.Context, arg LinkMerchantPa
 
 	err := r.withTx(ctx, func(q *Queries) error {
 		res, err := q.CreateMerchant(ctx, CreateMerchantParams{
-			Name:     arg.MerchantName,
-			Ownerid:  arg.UserId,
-			Sourceid: arg.SourceId,
+			Name:             arg.MerchantName,
+			Ownerid:          arg.UserId,
+			Sourceid:         arg.SourceId,
+			Syncitemid:       arg.SyncItemId,
+			Globalmerchantid: arg.GlobalMerchantId,
+			Categoryid:       arg.CategoryId,
 		})
 
 		if err != nil {
@@ -151,3 +317,560 @@ func (r *repositoryService) LinkMerchant(ctx context.Context, arg LinkMerchantPa
 	})
 	return merchant, err
 }
+
+type MergeMerchantsParams struct {
+	SourceIds []uuid.UUID
+	TargetId  uuid.UUID
+	UserId    uuid.UUID
+}
+
+// MergeMerchants moves the transactions, keys and rules of the source
+// merchants to the target and deletes the sources. Fails with
+// ErrMerchantNotFound if any merchant doesn't belong to the user
+func (r *repositoryService) MergeMerchants(ctx context.Context, arg MergeMerchantsParams) (Merchant, error) {
+	var merchant Merchant
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		target, err := q.GetMerchant(ctx, GetMerchantParams{
+			ID:      arg.TargetId,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return ErrMerchantNotFound
+		}
+
+		sourceIds := make([]string, len(arg.SourceIds))
+
+		for i, id := range arg.SourceIds {
+			sourceIds[i] = id.String()
+		}
+
+		err = q.MoveMerchantTransactions(ctx, MoveMerchantTransactionsParams{
+			Targetid:    target.ID,
+			Ownerid:     arg.UserId,
+			Merchantids: sourceIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		err = q.MoveMerchantKeys(ctx, MoveMerchantKeysParams{
+			Targetid:    target.ID,
+			Ownerid:     arg.UserId,
+			Merchantids: sourceIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		err = q.MoveMerchantRules(ctx, MoveMerchantRulesParams{
+			Targetid:    target.ID,
+			Ownerid:     arg.UserId,
+			Merchantids: sourceIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		deleted, err := q.DeleteMerchants(ctx, DeleteMerchantsParams{
+			Ownerid:     arg.UserId,
+			Merchantids: sourceIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if deleted != int64(len(sourceIds)) {
+			return ErrMerchantNotFound
+		}
+		merchant = target
+		return nil
+	})
+	return merchant, err
+}
+
+type SplitMerchantParams struct {
+	MerchantId     uuid.UUID
+	TransactionIds []uuid.UUID
+	Name           string
+	// Key learned for the new merchant. No key is saved when empty
+	KeyMatch     string
+	UploadSource UploadSource
+	UserId       uuid.UUID
+}
+
+// SplitMerchant moves some of a merchant's transactions to a new merchant.
+// Keys that only match the moved transactions move with them, so later
+// imports of those transactions are linked to the new merchant. Fails with
+// ErrTransactionNotFound if any transaction doesn't belong to the merchant
+func (r *repositoryService) SplitMerchant(ctx context.Context, arg SplitMerchantParams) (Merchant, error) {
+	var merchant Merchant
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		source, err := q.GetMerchant(ctx, GetMerchantParams{
+			ID:      arg.MerchantId,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return ErrMerchantNotFound
+		}
+
+		res, err := q.CreateMerchant(ctx, CreateMerchantParams{
+			Name:    arg.Name,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		transactionIds := make([]string, len(arg.TransactionIds))
+
+		for i, id := range arg.TransactionIds {
+			transactionIds[i] = id.String()
+		}
+
+		moved, err := q.MoveTransactionsToMerchant(ctx, MoveTransactionsToMerchantParams{
+			Targetid:       res.ID,
+			Ownerid:        arg.UserId,
+			Merchantid:     source.ID,
+			Transactionids: transactionIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if moved != int64(len(transactionIds)) {
+			return ErrTransactionNotFound
+		}
+
+		err = q.MoveMatchingMerchantKeys(ctx, MoveMatchingMerchantKeysParams{
+			Targetid:   res.ID,
+			Ownerid:    arg.UserId,
+			Merchantid: source.ID,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if arg.KeyMatch != "" {
+			_, err = q.UpsertMerchantKey(ctx, UpsertMerchantKeyParams{
+				Keymatch:     arg.KeyMatch,
+				Uploadsource: arg.UploadSource,
+				Merchantid:   res.ID,
+				Ownerid:      arg.UserId,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+		merchant = res
+		return nil
+	})
+	return merchant, err
+}
+
+type AddManualAssetParams struct {
+	Name   string
+	Type   ManualAssetType
+	Value  int32
+	Date   time.Time
+	UserId uuid.UUID
+}
+
+func (r *repositoryService) AddManualAsset(ctx context.Context, arg AddManualAssetParams) (*ManualAsset, error) {
+	asset := new(ManualAsset)
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		res, err := q.CreateManualAsset(ctx, CreateManualAssetParams{
+			Name:    arg.Name,
+			Type:    arg.Type,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		_, err = q.UpsertManualAssetValuation(ctx, UpsertManualAssetValuationParams{
+			Assetid: res.ID,
+			Date:    arg.Date,
+			Value:   arg.Value,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return err
+		}
+		asset = &res
+		return nil
+	})
+	return asset, err
+}
+
+type TransactionSplitPart struct {
+	Amount     int32
+	Categoryid uuid.NullUUID
+	Note       string
+	Fundid     uuid.NullUUID
+}
+
+type SplitTransactionParams struct {
+	TransactionId uuid.UUID
+	// Parts replacing the transaction's parts. No parts removes the split
+	Parts  []TransactionSplitPart
+	UserId uuid.UUID
+}
+
+// SplitTransaction replaces a transaction's parts. Fails with
+// ErrTransactionNotFound if the transaction doesn't belong to the user and
+// ErrSplitAmount if the parts don't add up to the transaction's amount
+func (r *repositoryService) SplitTransaction(ctx context.Context, arg SplitTransactionParams) (Transaction, error) {
+	var transaction Transaction
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		res, err := q.GetTransaction(ctx, GetTransactionParams{
+			ID:      arg.TransactionId,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return ErrTransactionNotFound
+		}
+
+		var total int64
+
+		for _, part := range arg.Parts {
+			total += int64(part.Amount)
+		}
+
+		if len(arg.Parts) > 0 && total != int64(res.Amount) {
+			return ErrSplitAmount
+		}
+
+		err = q.DeleteTransactionSplits(ctx, DeleteTransactionSplitsParams{
+			Transactionid: res.ID,
+			Ownerid:       arg.UserId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, part := range arg.Parts {
+			_, err = q.CreateTransactionSplit(ctx, CreateTransactionSplitParams{
+				Transactionid: res.ID,
+				Amount:        part.Amount,
+				Categoryid:    part.Categoryid,
+				Note:          part.Note,
+				Fundid:        part.Fundid,
+				Ownerid:       arg.UserId,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+		transaction = res
+		return nil
+	})
+	return transaction, err
+}
+
+type TagTransactionsParams struct {
+	TransactionIds []string
+	// Tag names, which are created when the user doesn't have them yet
+	Names  []string
+	UserId uuid.UUID
+}
+
+// TagTransactions adds tags to the user's transactions, returning how many
+// tags were added. Transactions that already have a tag are skipped
+func (r *repositoryService) TagTransactions(ctx context.Context, arg TagTransactionsParams) (int64, error) {
+	var added int64
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		names := make([]string, len(arg.Names))
+
+		for i, name := range arg.Names {
+			tag, err := q.UpsertTag(ctx, UpsertTagParams{
+				Name:    name,
+				Ownerid: arg.UserId,
+			})
+
+			if err != nil {
+				return err
+			}
+			names[i] = strings.ToLower(tag.Name)
+		}
+
+		res, err := q.AddTransactionTags(ctx, AddTransactionTagsParams{
+			Ownerid:        arg.UserId,
+			Transactionids: arg.TransactionIds,
+			Names:          names,
+		})
+
+		if err != nil {
+			return err
+		}
+		added = res
+		return nil
+	})
+	return added, err
+}
+
+// SyncTransaction upserts a transaction from an upload. When the upload has a
+// sync item and changes an existing transaction, the transaction is saved as a
+// revision first so the sync can be reverted. Unchanged transactions, e.g. from
+// overlapping statements, get no revision so they don't block reverting the
+// upload that created them. Splits of an existing transaction are kept and
+// rebalanced if its amount changed
+func (r *repositoryService) SyncTransaction(ctx context.Context, arg UpsertTransactionParams) (Transaction, error) {
+	var transaction Transaction
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		if arg.Syncitemid.Valid {
+			existing, err := q.GetTransactionBySourceId(ctx, GetTransactionBySourceIdParams{
+				Ownerid:  arg.Ownerid,
+				Sourceid: arg.Sourceid,
+			})
+
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			if err == nil && transactionChanged(existing, arg) {
+				err := q.CreateTransactionRevision(ctx, CreateTransactionRevisionParams{
+					Syncitemid: arg.Syncitemid.UUID,
+					Ownerid:    arg.Ownerid,
+					Sourceid:   arg.Sourceid,
+				})
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		res, err := q.UpsertTransaction(ctx, arg)
+
+		if err != nil {
+			return err
+		}
+
+		err = q.RebalanceTransactionSplits(ctx, RebalanceTransactionSplitsParams{
+			Ownerid:       res.Ownerid,
+			Transactionid: uuid.NullUUID{UUID: res.ID, Valid: true},
+		})
+
+		if err != nil {
+			return err
+		}
+		transaction = res
+		return nil
+	})
+	return transaction, err
+}
+
+// Reports whether upserting arg would change the saved transaction. The
+// updated date is ignored since every upload sets it
+func transactionChanged(existing Transaction, arg UpsertTransactionParams) bool {
+	status := arg.Status
+
+	// A posted transaction is never set back to pending
+	if existing.Status == TransactionStatusPOSTED {
+		status = existing.Status
+	}
+
+	authorizedDate := existing.Authorizeddate
+
+	if arg.Authorizeddate.Valid {
+		authorizedDate = arg.Authorizeddate
+	}
+
+	return existing.Amount != arg.Amount ||
+		existing.Payeeid != arg.Payeeid ||
+		existing.Payee != arg.Payee ||
+		existing.Payeefull != arg.Payeefull ||
+		existing.Isocurrencycode != arg.Isocurrencycode ||
+		!sameDay(existing.Date, arg.Date) ||
+		existing.Description != arg.Description ||
+		existing.Type != arg.Type ||
+		existing.Checknumber != arg.Checknumber ||
+		existing.Status != status ||
+		existing.Authorizeddate.Valid != authorizedDate.Valid ||
+		!sameDay(existing.Authorizeddate.Time, authorizedDate.Time)
+}
+
+// Transaction dates are stored without a time
+func sameDay(a time.Time, b time.Time) bool {
+	return a.Format(time.DateOnly) == b.Format(time.DateOnly)
+}
+
+type ReconcileTransactionParams struct {
+	// Source id of the pending transaction replaced by the posted transaction
+	PendingSourceId string
+	Transaction     UpsertTransactionParams
+}
+
+// ReconcileTransaction replaces a pending transaction with its posted
+// transaction, which usually has a different source id. The pending row is
+// updated in place so it keeps its id, merchant and splits, and is saved as a
+// revision first so the sync can be reverted
+func (r *repositoryService) ReconcileTransaction(ctx context.Context, arg ReconcileTransactionParams) (Transaction, error) {
+	var transaction Transaction
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		if arg.Transaction.Syncitemid.Valid {
+			err := q.CreateTransactionRevision(ctx, CreateTransactionRevisionParams{
+				Syncitemid: arg.Transaction.Syncitemid.UUID,
+				Ownerid:    arg.Transaction.Ownerid,
+				Sourceid:   arg.PendingSourceId,
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err := q.UpdateTransactionSourceId(ctx, UpdateTransactionSourceIdParams{
+			Newsourceid: arg.Transaction.Sourceid,
+			Ownerid:     arg.Transaction.Ownerid,
+			Sourceid:    arg.PendingSourceId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		res, err := q.UpsertTransaction(ctx, arg.Transaction)
+
+		if err != nil {
+			return err
+		}
+
+		err = q.RebalanceTransactionSplits(ctx, RebalanceTransactionSplitsParams{
+			Ownerid:       res.Ownerid,
+			Transactionid: uuid.NullUUID{UUID: res.ID, Valid: true},
+		})
+
+		if err != nil {
+			return err
+		}
+		transaction = res
+		return nil
+	})
+	return transaction, err
+}
+
+type RevertSyncParams struct {
+	SyncItemId uuid.UUID
+	UserId     uuid.UUID
+}
+
+type RevertSyncResult struct {
+	SyncItem             AccountSyncItem
+	TransactionsDeleted  int64
+	TransactionsRestored int64
+	MerchantsDeleted     int64
+	AccountDeleted       bool
+}
+
+// RevertSync undoes an upload. Transactions it created are deleted, transactions
+// it overwrote are restored from their revisions and merchants it created that
+// are no longer used are deleted. The account is deleted if nothing else
+// references it. Fails with ErrSyncOverwritten if a later upload changed the
+// same transactions
+func (r *repositoryService) RevertSync(ctx context.Context, arg RevertSyncParams) (RevertSyncResult, error) {
+	var result RevertSyncResult
+
+	err := r.withTx(ctx, func(q *Queries) error {
+		syncItem, err := q.GetAccountSyncItem(ctx, GetAccountSyncItemParams{
+			ID:      arg.SyncItemId,
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.SyncItem = syncItem
+		syncItemId := uuid.NullUUID{UUID: syncItem.ID, Valid: true}
+		later, err := q.CountLaterTransactionRevisions(ctx, syncItem.ID)
+
+		if err != nil {
+			return err
+		}
+
+		if later > 0 {
+			return ErrSyncOverwritten
+		}
+
+		result.TransactionsRestored, err = q.RestoreTransactionRevisions(ctx, syncItem.ID)
+
+		if err != nil {
+			return err
+		}
+
+		// Restored amounts may not match the splits made since the sync
+		err = q.RebalanceTransactionSplits(ctx, RebalanceTransactionSplitsParams{
+			Ownerid: arg.UserId,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.TransactionsDeleted, err = q.DeleteSyncItemTransactions(ctx, syncItemId)
+
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteSyncItemMerchantKeys(ctx, syncItemId)
+
+		if err != nil {
+			return err
+		}
+
+		result.MerchantsDeleted, err = q.DeleteSyncItemMerchants(ctx, syncItemId)
+
+		if err != nil {
+			return err
+		}
+
+		// Revisions, balances and investment data from the sync are deleted with it
+		err = q.DeleteAccountSyncItem(ctx, syncItem.ID)
+
+		if err != nil {
+			return err
+		}
+
+		// Allow the file to be uploaded again once every account from it is reverted
+		if syncItem.Importbatchid.Valid {
+			err = q.RevertImportBatch(ctx, syncItem.Importbatchid.UUID)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		deleted, err := q.DeleteEmptyAccount(ctx, syncItem.Accountid)
+
+		if err != nil {
+			return err
+		}
+		result.AccountDeleted = deleted > 0
+		return nil
+	})
+	return result, err
+}
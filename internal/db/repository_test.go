@@ -0,0 +1,106 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestTransactionChanged(t *testing.T) {
+	existing := Transaction{
+		Sourceid:        "1",
+		Amount:          -450,
+		Payee:           sql.NullString{String: "BLUE BOTTLE COFFEE", Valid: true},
+		Isocurrencycode: "USD",
+		Date:            time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC),
+		Description:     "BLUE BOTTLE COFFEE",
+		Type:            TransactionTypeDEBIT,
+		Updated:         time.Date(2024, time.January, 16, 0, 0, 0, 0, time.UTC),
+		Status:          TransactionStatusPOSTED,
+	}
+	unchanged := UpsertTransactionParams{
+		Sourceid:        "1",
+		Amount:          -450,
+		Payee:           sql.NullString{String: "BLUE BOTTLE COFFEE", Valid: true},
+		Isocurrencycode: "USD",
+		Date:            time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC),
+		Description:     "BLUE BOTTLE COFFEE",
+		Type:            TransactionTypeDEBIT,
+		Updated:         time.Now(),
+		Status:          TransactionStatusPOSTED,
+	}
+
+	tests := []struct {
+		name string
+		edit func(arg *UpsertTransactionParams)
+		want bool
+	}{
+		{
+			name: "same statement imported again",
+			edit: func(arg *UpsertTransactionParams) {},
+			want: false,
+		},
+		{
+			name: "pending status on a posted transaction is ignored",
+			edit: func(arg *UpsertTransactionParams) { arg.Status = TransactionStatusPENDING },
+			want: false,
+		},
+		{
+			name: "missing authorized date keeps the saved one",
+			edit: func(arg *UpsertTransactionParams) { arg.Authorizeddate = sql.NullTime{} },
+			want: false,
+		},
+		{
+			name: "amount",
+			edit: func(arg *UpsertTransactionParams) { arg.Amount = -575 },
+			want: true,
+		},
+		{
+			name: "date",
+			edit: func(arg *UpsertTransactionParams) { arg.Date = arg.Date.AddDate(0, 0, 1) },
+			want: true,
+		},
+		{
+			name: "description",
+			edit: func(arg *UpsertTransactionParams) { arg.Description = "BLUE BOTTLE COFFEE SF" },
+			want: true,
+		},
+		{
+			name: "type",
+			edit: func(arg *UpsertTransactionParams) { arg.Type = TransactionTypePOS },
+			want: true,
+		},
+		{
+			name: "check number",
+			edit: func(arg *UpsertTransactionParams) { arg.Checknumber = sql.NullString{String: "101", Valid: true} },
+			want: true,
+		},
+		{
+			name: "authorized date",
+			edit: func(arg *UpsertTransactionParams) {
+				arg.Authorizeddate = sql.NullTime{Time: time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC), Valid: true}
+			},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := unchanged
+			tt.edit(&arg)
+
+			if got := transactionChanged(existing, arg); got != tt.want {
+				t.Fatalf("transactionChanged() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("pending transaction posts", func(t *testing.T) {
+		pending := existing
+		pending.Status = TransactionStatusPENDING
+
+		if !transactionChanged(pending, unchanged) {
+			t.Fatalf("transactionChanged() = false, want true")
+		}
+	})
+}
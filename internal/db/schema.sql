@@ -1,19 +1,44 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS sync_connections CASCADE;
 DROP TABLE IF EXISTS accounts CASCADE;
+DROP TABLE IF EXISTS ofx_connections CASCADE;
 DROP TABLE IF EXISTS account_sync_items CASCADE;
+DROP TABLE IF EXISTS account_balances CASCADE;
 DROP TABLE IF EXISTS transactions CASCADE;
+DROP TABLE IF EXISTS transaction_revisions CASCADE;
+DROP TABLE IF EXISTS categories CASCADE;
+DROP TABLE IF EXISTS global_merchants CASCADE;
 DROP TABLE IF EXISTS merchants CASCADE;
 DROP TABLE IF EXISTS merchant_keys CASCADE;
+DROP TABLE IF EXISTS merchant_rules CASCADE;
 DROP TABLE IF EXISTS funds CASCADE;
 DROP TABLE IF EXISTS fund_allocations CASCADE;
+DROP TABLE IF EXISTS transaction_splits CASCADE;
+DROP TABLE IF EXISTS tags CASCADE;
+DROP TABLE IF EXISTS transaction_tags CASCADE;
+DROP TABLE IF EXISTS csv_profiles CASCADE;
+DROP TABLE IF EXISTS manual_assets CASCADE;
+DROP TABLE IF EXISTS manual_asset_valuations CASCADE;
+DROP TABLE IF EXISTS import_batches CASCADE;
+DROP TABLE IF EXISTS securities CASCADE;
+DROP TABLE IF EXISTS holdings CASCADE;
+DROP TABLE IF EXISTS investment_transactions CASCADE;
 
 DROP TYPE IF EXISTS ROLE;
 DROP TYPE IF EXISTS ACCOUNT_TYPE;
 DROP TYPE IF EXISTS UPLOAD_SOURCE;
 DROP TYPE IF EXISTS TRANSACTION_TYPE;
+DROP TYPE IF EXISTS TRANSACTION_STATUS;
 DROP TYPE IF EXISTS FUND_TYPE;
+DROP TYPE IF EXISTS SIGN_CONVENTION;
+DROP TYPE IF EXISTS MANUAL_ASSET_TYPE;
+DROP TYPE IF EXISTS IMPORT_STATUS;
+DROP TYPE IF EXISTS SECURITY_TYPE;
+DROP TYPE IF EXISTS INVESTMENT_TRANSACTION_TYPE;
+DROP TYPE IF EXISTS MERCHANT_RULE_TYPE;
+DROP TYPE IF EXISTS CATEGORY_SOURCE;
 
 CREATE TYPE ROLE AS ENUM (
   'USER',
@@ -26,13 +51,19 @@ CREATE TYPE ACCOUNT_TYPE AS ENUM (
     'SAVINGS',
     'MONEYMRKT',
     'CREDITLINE',
-    'CD'
+    'CD',
+    'INVESTMENT'
 );
 
 CREATE TYPE UPLOAD_SOURCE AS ENUM (
     'CHASE:CSV_UPLOAD',
     'CHASE:OFX_UPLOAD',
-    'PLAID'
+    'CSV_UPLOAD',
+    'QIF_UPLOAD',
+    'CAMT053_UPLOAD',
+    'MT940_UPLOAD',
+    'PLAID',
+    'OFX_DIRECT_CONNECT'
 );
 
 CREATE TYPE TRANSACTION_TYPE AS ENUM (
@@ -55,11 +86,70 @@ CREATE TYPE TRANSACTION_TYPE AS ENUM (
     'OTHER'
 );
 
+-- Pending transactions are authorized but not yet posted, and are replaced
+-- by their posted transaction on a later import
+CREATE TYPE TRANSACTION_STATUS AS ENUM (
+    'PENDING',
+    'POSTED'
+);
+
 CREATE TYPE FUND_TYPE AS ENUM (
     'SAVINGS',
     'BUDGET'
 );
 
+CREATE TYPE MANUAL_ASSET_TYPE AS ENUM (
+    'ASSET',
+    'LIABILITY'
+);
+
+CREATE TYPE SIGN_CONVENTION AS ENUM (
+    'DEBITS_NEGATIVE',
+    'DEBITS_POSITIVE'
+);
+
+CREATE TYPE IMPORT_STATUS AS ENUM (
+    'COMPLETED',
+    'FAILED',
+    'REVERTED'
+);
+
+CREATE TYPE SECURITY_TYPE AS ENUM (
+    'STOCK',
+    'MUTUALFUND',
+    'DEBT',
+    'OPTION',
+    'OTHER'
+);
+
+CREATE TYPE INVESTMENT_TRANSACTION_TYPE AS ENUM (
+    'BUY',
+    'SELL',
+    'DIVIDEND',
+    'INTEREST',
+    'CAPITAL_GAIN',
+    'REINVEST',
+    'RETURN_OF_CAPITAL',
+    'FEE',
+    'TRANSFER',
+    'SPLIT',
+    'OTHER'
+);
+
+CREATE TYPE MERCHANT_RULE_TYPE AS ENUM (
+    'PREFIX',
+    'CONTAINS',
+    'REGEX',
+    'AMOUNT_RANGE'
+);
+
+-- MANUAL categories were chosen by the user and train the categorizer.
+-- CLASSIFIER categories were predicted during import
+CREATE TYPE CATEGORY_SOURCE AS ENUM (
+    'MANUAL',
+    'CLASSIFIER'
+);
+
 CREATE TABLE users (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     role ROLE DEFAULT 'USER' NOT NULL,
@@ -68,13 +158,67 @@ CREATE TABLE users (
     passwordHash VARCHAR(255) NOT NULL
 );
 
+CREATE TABLE import_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    fileName VARCHAR(255) NOT NULL,
+    fileHash VARCHAR(64) NOT NULL,
+    uploadSource UPLOAD_SOURCE NOT NULL,
+    status IMPORT_STATUS NOT NULL,
+    accountsUpdated INT NOT NULL DEFAULT 0,
+    accountsFailed INT NOT NULL DEFAULT 0,
+    transactionsUpdated INT NOT NULL DEFAULT 0,
+    transactionsFailed INT NOT NULL DEFAULT 0,
+    errors TEXT[] NOT NULL DEFAULT '{}',
+    created TIMESTAMP NOT NULL DEFAULT NOW(),
+    ownerId UUID REFERENCES users (id) NOT NULL
+);
+
+-- A file can only be imported successfully once per user
+CREATE UNIQUE INDEX import_batches_completed_file
+    ON import_batches (ownerId, fileHash)
+    WHERE status = 'COMPLETED';
+
+-- Linked accounts at a sync provider such as Plaid, e.g. a Plaid item
+CREATE TABLE sync_connections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider UPLOAD_SOURCE NOT NULL,
+    itemId VARCHAR(255) NOT NULL,
+    accessToken VARCHAR(255) NOT NULL,
+    lastSynced TIMESTAMP,
+    created TIMESTAMP NOT NULL DEFAULT NOW(),
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    UNIQUE (provider, itemId)
+);
+
 CREATE TABLE accounts (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    sourceId VARCHAR(255) NOT NULL UNIQUE,
+    sourceId VARCHAR(255) NOT NULL,
     type ACCOUNT_TYPE NOT NULL,
     name VARCHAR(255) NOT NULL,
     routingNumber VARCHAR(255),
     updated DATE NOT NULL DEFAULT NOW(),
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    syncConnectionId UUID REFERENCES sync_connections (id) ON DELETE SET NULL,
+    syncCursor TEXT,
+    UNIQUE (ownerId, sourceId)
+);
+
+-- OFX Direct Connect login used to download statements for an account.
+-- credentials holds the encrypted username and password, see internal/secrets
+CREATE TABLE ofx_connections (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url VARCHAR(255) NOT NULL,
+    org VARCHAR(255) NOT NULL,
+    fid VARCHAR(255) NOT NULL,
+    appId VARCHAR(32) NOT NULL,
+    appVersion VARCHAR(32) NOT NULL,
+    ofxVersion VARCHAR(8) NOT NULL,
+    credentials TEXT NOT NULL,
+    lastAttempted TIMESTAMP,
+    lastSynced TIMESTAMP,
+    lastError TEXT,
+    created TIMESTAMP NOT NULL DEFAULT NOW(),
+    accountId UUID REFERENCES accounts (id) ON DELETE CASCADE NOT NULL UNIQUE,
     ownerId UUID REFERENCES users (id) NOT NULL
 );
 
@@ -82,14 +226,56 @@ CREATE TABLE account_sync_items (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     date DATE NOT NULL DEFAULT NOW(),
     uploadSource UPLOAD_SOURCE NOT NULL,
-    accountId UUID REFERENCES accounts (id) NOT NULL
+    accountId UUID REFERENCES accounts (id) NOT NULL,
+    importBatchId UUID REFERENCES import_batches (id)
+);
+
+CREATE TABLE account_balances (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    date DATE NOT NULL,
+    current INT NOT NULL,
+    available INT,
+    accountId UUID REFERENCES accounts (id) ON DELETE CASCADE NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    syncItemId UUID REFERENCES account_sync_items (id) ON DELETE CASCADE,
+    UNIQUE (accountId, date)
+);
+
+-- Two level category tree, e.g. Food > Groceries. Default categories have no
+-- owner and are shared by every user, who can add their own categories at
+-- either level
+CREATE TABLE categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    parentId UUID REFERENCES categories (id) ON DELETE CASCADE,
+    ownerId UUID REFERENCES users (id),
+    created TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX categories_name
+    ON categories (COALESCE(ownerId, uuid_nil()), COALESCE(parentId, uuid_nil()), upper(name));
+
+-- Curated merchants shared by every user. A user's merchant links to one to
+-- use its name, website and category unless the user overrides them
+CREATE TABLE global_merchants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    website VARCHAR(255),
+    categoryId UUID REFERENCES categories (id) ON DELETE SET NULL,
+    aliases VARCHAR(255)[] NOT NULL DEFAULT '{}',
+    created TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
 CREATE TABLE merchants (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(255) NOT NULL,
     sourceId VARCHAR(255),
-    ownerId UUID REFERENCES users (id) NOT NULL
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    syncItemId UUID REFERENCES account_sync_items (id) ON DELETE SET NULL,
+    website VARCHAR(255),
+    -- Category the merchant's transactions have unless they're categorized
+    categoryId UUID REFERENCES categories (id) ON DELETE SET NULL,
+    globalMerchantId UUID REFERENCES global_merchants (id) ON DELETE SET NULL
 );
 
 CREATE TABLE merchant_keys (
@@ -101,9 +287,24 @@ CREATE TABLE merchant_keys (
     UNIQUE (ownerId, keymatch)
 );
 
+-- Rules are checked in priority order before merchant keys and parsing the
+-- description. Amounts are in cents and compared to the absolute amount
+CREATE TABLE merchant_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    priority INT NOT NULL DEFAULT 0,
+    type MERCHANT_RULE_TYPE NOT NULL,
+    pattern VARCHAR(255) NOT NULL DEFAULT '',
+    minAmount INT,
+    maxAmount INT,
+    uploadSource UPLOAD_SOURCE,
+    merchantId UUID REFERENCES merchants (id) NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
 CREATE TABLE transactions (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    sourceId VARCHAR(255) NOT NULL UNIQUE,
+    sourceId VARCHAR(255) NOT NULL,
     amount INT NOT NULL,
     payeeId VARCHAR(255),
     payee VARCHAR(255),
@@ -116,7 +317,42 @@ CREATE TABLE transactions (
     updated DATE NOT NULL DEFAULT NOW(),
     merchantId UUID REFERENCES merchants (id) NOT NULL,
     ownerId UUID REFERENCES users (id) NOT NULL,
-    accountId UUID REFERENCES accounts (id) NOT NULL
+    accountId UUID REFERENCES accounts (id) NOT NULL,
+    syncItemId UUID REFERENCES account_sync_items (id) ON DELETE SET NULL,
+    status TRANSACTION_STATUS NOT NULL DEFAULT 'POSTED',
+    authorizedDate DATE,
+    -- Set when the transaction is categorized, otherwise it has its merchant's category
+    categoryId UUID REFERENCES categories (id) ON DELETE SET NULL,
+    categorySource CATEGORY_SOURCE,
+    -- Probability of a predicted category. Low confidence predictions need review
+    categoryConfidence DOUBLE PRECISION,
+    needsReview BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Markdown note written by the user
+    note TEXT NOT NULL DEFAULT '',
+    UNIQUE (ownerId, sourceId)
+);
+
+-- Copies of transactions taken before an upload overwrote them,
+-- used to restore the transaction when the upload is reverted
+CREATE TABLE transaction_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    amount INT NOT NULL,
+    payeeId VARCHAR(255),
+    payee VARCHAR(255),
+    payeeFull VARCHAR(255),
+    isoCurrencyCode VARCHAR(255) NOT NULL,
+    date DATE NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    type TRANSACTION_TYPE NOT NULL,
+    checkNumber VARCHAR(255),
+    updated DATE NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT NOW(),
+    transactionId UUID REFERENCES transactions (id) ON DELETE CASCADE NOT NULL,
+    syncItemId UUID REFERENCES account_sync_items (id) ON DELETE CASCADE NOT NULL,
+    sourceId VARCHAR(255) NOT NULL,
+    status TRANSACTION_STATUS NOT NULL,
+    authorizedDate DATE,
+    UNIQUE (syncItemId, transactionId)
 );
 
 CREATE TABLE funds (
@@ -137,3 +373,191 @@ CREATE TABLE fund_allocations (
     ownerId UUID REFERENCES users (id) NOT NULL,
     fundId UUID REFERENCES funds (id) NOT NULL
 );
+
+-- Parts of a split transaction, each with its own category and fund. The
+-- parts' amounts always add up to the transaction's amount, and stats use the
+-- parts instead of the transaction
+CREATE TABLE transaction_splits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transactionId UUID REFERENCES transactions (id) ON DELETE CASCADE NOT NULL,
+    amount INT NOT NULL,
+    categoryId UUID REFERENCES categories (id) ON DELETE SET NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    fundId UUID REFERENCES funds (id) ON DELETE SET NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL
+);
+
+CREATE INDEX transaction_splits_transaction ON transaction_splits (transactionId);
+
+-- User defined labels, e.g. vacation-2026. Names are unique ignoring case
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX tags_name ON tags (ownerId, lower(name));
+
+CREATE TABLE transaction_tags (
+    transactionId UUID REFERENCES transactions (id) ON DELETE CASCADE NOT NULL,
+    tagId UUID REFERENCES tags (id) ON DELETE CASCADE NOT NULL,
+    PRIMARY KEY (transactionId, tagId)
+);
+
+CREATE INDEX transaction_tags_tag ON transaction_tags (tagId);
+
+CREATE TABLE csv_profiles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    institution VARCHAR(255),
+    dateColumn VARCHAR(255) NOT NULL,
+    descriptionColumn VARCHAR(255) NOT NULL,
+    amountColumn VARCHAR(255),
+    debitColumn VARCHAR(255),
+    creditColumn VARCHAR(255),
+    typeColumn VARCHAR(255),
+    checkNumberColumn VARCHAR(255),
+    dateFormat VARCHAR(255) NOT NULL DEFAULT 'MM/DD/YYYY',
+    signConvention SIGN_CONVENTION NOT NULL DEFAULT 'DEBITS_NEGATIVE',
+    skipRows INT NOT NULL DEFAULT 0,
+    hasHeader BOOLEAN NOT NULL DEFAULT TRUE,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    balanceColumn VARCHAR(255),
+    UNIQUE (ownerId, name)
+);
+
+CREATE TABLE manual_assets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    type MANUAL_ASSET_TYPE NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL
+);
+
+CREATE TABLE manual_asset_valuations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    date DATE NOT NULL,
+    value INT NOT NULL,
+    assetId UUID REFERENCES manual_assets (id) ON DELETE CASCADE NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    UNIQUE (assetId, date)
+);
+
+-- Securities are shared by every user, identified by CUSIP or ISIN
+CREATE TABLE securities (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    uniqueId VARCHAR(32) NOT NULL,
+    uniqueIdType VARCHAR(16) NOT NULL,
+    ticker VARCHAR(32),
+    name VARCHAR(255) NOT NULL,
+    type SECURITY_TYPE NOT NULL,
+    UNIQUE (uniqueIdType, uniqueId)
+);
+
+-- Position snapshots from investment statements. Holdings on the latest
+-- date are the account's current positions
+CREATE TABLE holdings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    date DATE NOT NULL,
+    units DOUBLE PRECISION NOT NULL,
+    unitPrice DOUBLE PRECISION NOT NULL,
+    marketValue INT NOT NULL,
+    securityId UUID REFERENCES securities (id) NOT NULL,
+    accountId UUID REFERENCES accounts (id) ON DELETE CASCADE NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    syncItemId UUID REFERENCES account_sync_items (id) ON DELETE CASCADE,
+    UNIQUE (accountId, securityId, date)
+);
+
+-- Trades and income in investment accounts. Cash movements such as
+-- deposits are saved as regular transactions
+CREATE TABLE investment_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    sourceId VARCHAR(255) NOT NULL,
+    type INVESTMENT_TRANSACTION_TYPE NOT NULL,
+    tradeDate DATE NOT NULL,
+    settleDate DATE,
+    units DOUBLE PRECISION NOT NULL DEFAULT 0,
+    unitPrice DOUBLE PRECISION NOT NULL DEFAULT 0,
+    amount INT NOT NULL,
+    fees INT NOT NULL DEFAULT 0,
+    isoCurrencyCode VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    securityId UUID REFERENCES securities (id),
+    accountId UUID REFERENCES accounts (id) ON DELETE CASCADE NOT NULL,
+    ownerId UUID REFERENCES users (id) NOT NULL,
+    syncItemId UUID REFERENCES account_sync_items (id) ON DELETE CASCADE,
+    UNIQUE (accountId, sourceId)
+);
+
+-- Default categories
+INSERT INTO categories (name) VALUES
+    ('Food'),
+    ('Housing'),
+    ('Bills & Utilities'),
+    ('Transportation'),
+    ('Shopping'),
+    ('Entertainment'),
+    ('Health'),
+    ('Personal'),
+    ('Travel'),
+    ('Financial'),
+    ('Income'),
+    ('Transfers');
+
+INSERT INTO categories (name, parentId)
+SELECT child.name, parent.id
+FROM (VALUES
+    ('Food', 'Groceries'),
+    ('Food', 'Restaurants'),
+    ('Food', 'Fast Food'),
+    ('Food', 'Coffee Shops'),
+    ('Food', 'Bars'),
+    ('Housing', 'Rent'),
+    ('Housing', 'Mortgage'),
+    ('Housing', 'Home Improvement'),
+    ('Housing', 'Home Insurance'),
+    ('Bills & Utilities', 'Utilities'),
+    ('Bills & Utilities', 'Phone'),
+    ('Bills & Utilities', 'Internet'),
+    ('Bills & Utilities', 'Subscriptions'),
+    ('Transportation', 'Gas'),
+    ('Transportation', 'Parking'),
+    ('Transportation', 'Public Transit'),
+    ('Transportation', 'Rideshare'),
+    ('Transportation', 'Auto Maintenance'),
+    ('Transportation', 'Auto Insurance'),
+    ('Shopping', 'Household'),
+    ('Shopping', 'Clothing'),
+    ('Shopping', 'Electronics'),
+    ('Shopping', 'General Merchandise'),
+    ('Entertainment', 'Streaming'),
+    ('Entertainment', 'Games'),
+    ('Entertainment', 'Events'),
+    ('Entertainment', 'Hobbies'),
+    ('Health', 'Medical'),
+    ('Health', 'Pharmacy'),
+    ('Health', 'Fitness'),
+    ('Health', 'Health Insurance'),
+    ('Personal', 'Personal Care'),
+    ('Personal', 'Education'),
+    ('Personal', 'Gifts'),
+    ('Personal', 'Donations'),
+    ('Personal', 'Pets'),
+    ('Travel', 'Flights'),
+    ('Travel', 'Lodging'),
+    ('Travel', 'Rental Cars'),
+    ('Financial', 'Bank Fees'),
+    ('Financial', 'Interest Charges'),
+    ('Financial', 'Taxes'),
+    ('Financial', 'Loan Payments'),
+    ('Financial', 'Credit Card Payments'),
+    ('Income', 'Paycheck'),
+    ('Income', 'Interest Income'),
+    ('Income', 'Dividends'),
+    ('Income', 'Refunds'),
+    ('Transfers', 'Account Transfers'),
+    ('Transfers', 'Savings'),
+    ('Transfers', 'Investments')
+) AS child (parent, name)
+JOIN categories AS parent ON parent.name = child.parent AND parent.ownerId IS NULL;
@@ -0,0 +1,214 @@
+// Package directconnect downloads statements from a bank's OFX server
+// (OFX Direct Connect) instead of waiting for a file upload
+package directconnect
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aclindsa/ofxgo"
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Defaults match Quicken, since many servers only accept known clients
+const (
+	DefaultAppId      = "QWIN"
+	DefaultAppVersion = "2700"
+	DefaultOfxVersion = "102"
+)
+
+// Institution identifies a bank's OFX server
+type Institution struct {
+	URL        string
+	Org        string
+	Fid        string
+	AppId      string
+	AppVersion string
+	OfxVersion string
+}
+
+// Credentials are stored encrypted as JSON on the connection
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type Account struct {
+	// Routing number, only used for bank accounts
+	BankId    string
+	AccountId string
+	Type      db.AccountType
+}
+
+type Client struct {
+	httpClient *http.Client
+}
+
+func New(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &Client{httpClient: httpClient}
+}
+
+// Validate checks an institution before it is saved, so a bad URL or OFX
+// version is reported when the connection is created instead of on sync
+func Validate(institution Institution) error {
+	if institution.Org == "" || institution.Fid == "" {
+		return fmt.Errorf("OFX org and fid are required")
+	}
+
+	if _, err := ofxgo.NewOfxVersion(institution.OfxVersion); err != nil {
+		return fmt.Errorf("Invalid OFX version %s", institution.OfxVersion)
+	}
+
+	return checkURL(institution.URL)
+}
+
+// Download requests a statement for account with the transactions posted
+// between start and end. The response is parsed like an uploaded OFX file
+func (c *Client) Download(ctx context.Context, institution Institution, credentials Credentials, account Account, start time.Time, end time.Time) ([]chase.ChaseOFXResult, error) {
+	if err := checkURL(institution.URL); err != nil {
+		return nil, err
+	}
+
+	version, err := ofxgo.NewOfxVersion(institution.OfxVersion)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid OFX version %s", institution.OfxVersion)
+	}
+
+	request, err := newStatementRequest(institution, credentials, account, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := ofxgo.GetClient(institution.URL, &ofxgo.BasicClient{
+		SpecVersion: version,
+		AppID:       institution.AppId,
+		AppVer:      institution.AppVersion,
+	})
+	request.SetClientFields(client)
+	body, err := request.Marshal()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create OFX request: %w", err)
+	}
+
+	// ofxgo's RawRequest has no context or timeout, so the request is sent here
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, institution.URL, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest.Header.Set("Content-Type", "application/x-ofx")
+	httpRequest.Header.Set("Accept", "*/*, application/x-ofx")
+	res, err := c.httpClient.Do(httpRequest)
+
+	if err != nil {
+		return nil, fmt.Errorf("OFX request failed: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OFX request failed with status %d", res.StatusCode)
+	}
+
+	return chase.ParseChaseOFX(res.Body)
+}
+
+func newStatementRequest(institution Institution, credentials Credentials, account Account, start time.Time, end time.Time) (*ofxgo.Request, error) {
+	uid, err := ofxgo.RandomUID()
+
+	if err != nil {
+		return nil, err
+	}
+
+	request := &ofxgo.Request{
+		URL: institution.URL,
+		Signon: ofxgo.SignonRequest{
+			UserID:   ofxgo.String(credentials.Username),
+			UserPass: ofxgo.String(credentials.Password),
+			Org:      ofxgo.String(institution.Org),
+			Fid:      ofxgo.String(institution.Fid),
+		},
+	}
+
+	dtStart := &ofxgo.Date{Time: start}
+	dtEnd := &ofxgo.Date{Time: end}
+
+	if account.Type == db.AccountTypeCREDIT {
+		request.CreditCard = append(request.CreditCard, &ofxgo.CCStatementRequest{
+			TrnUID:     *uid,
+			CCAcctFrom: ofxgo.CCAcct{AcctID: ofxgo.String(account.AccountId)},
+			DtStart:    dtStart,
+			DtEnd:      dtEnd,
+			Include:    true,
+		})
+
+		return request, nil
+	}
+
+	accountType, err := ofxgo.NewAcctType(string(account.Type))
+
+	if err != nil {
+		return nil, fmt.Errorf("Unsupported account type %s", account.Type)
+	}
+
+	if account.BankId == "" {
+		return nil, fmt.Errorf("Routing number is required for bank accounts")
+	}
+
+	request.Bank = append(request.Bank, &ofxgo.StatementRequest{
+		TrnUID: *uid,
+		BankAcctFrom: ofxgo.BankAcct{
+			BankID:   ofxgo.String(account.BankId),
+			AcctID:   ofxgo.String(account.AccountId),
+			AcctType: accountType,
+		},
+		DtStart: dtStart,
+		DtEnd:   dtEnd,
+		Include: true,
+	})
+
+	return request, nil
+}
+
+// The request holds the password, so plain http is only allowed for a
+// local responder such as cmd/ofxmock
+func checkURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("Invalid OFX server URL")
+	}
+
+	if u.Scheme == "https" {
+		return nil
+	}
+
+	if u.Scheme == "http" && isLoopback(u.Hostname()) {
+		return nil
+	}
+
+	return fmt.Errorf("OFX server URL must use https")
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
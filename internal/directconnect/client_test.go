@@ -0,0 +1,272 @@
+package directconnect
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aclindsa/ofxgo"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/directconnect/ofxmock"
+)
+
+func mockInstitution(url string) Institution {
+	return Institution{
+		URL:        url,
+		Org:        ofxmock.Org,
+		Fid:        ofxmock.Fid,
+		AppId:      DefaultAppId,
+		AppVersion: DefaultAppVersion,
+		OfxVersion: DefaultOfxVersion,
+	}
+}
+
+func TestDownload(t *testing.T) {
+	server := httptest.NewServer(ofxmock.New())
+	defer server.Close()
+
+	credentials := Credentials{Username: ofxmock.Username, Password: ofxmock.Password}
+	checking := Account{BankId: ofxmock.BankId, AccountId: "1111111111", Type: db.AccountTypeCHECKING}
+	creditCard := Account{AccountId: "4444333322221111", Type: db.AccountTypeCREDIT}
+	now := time.Now()
+	start := now.AddDate(0, 0, -30)
+
+	tests := []struct {
+		name         string
+		institution  Institution
+		credentials  Credentials
+		account      Account
+		start        time.Time
+		wantType     db.AccountType
+		wantBalance  float32
+		transactions []string
+		wantErr      string
+	}{
+		{
+			name:         "checking",
+			institution:  mockInstitution(server.URL),
+			credentials:  credentials,
+			account:      checking,
+			start:        start,
+			wantType:     db.AccountTypeCHECKING,
+			wantBalance:  2150.42,
+			transactions: []string{"ACME CORP PAYROLL", "CITY POWER AND LIGHT", "ATM WITHDRAWAL MAIN ST BRANCH"},
+		},
+		{
+			name:         "credit card",
+			institution:  mockInstitution(server.URL),
+			credentials:  credentials,
+			account:      creditCard,
+			start:        start,
+			wantType:     db.AccountTypeCREDIT,
+			wantBalance:  -310.75,
+			transactions: []string{"STARBUCKS STORE 1458", "AMAZON MKTPLACE PMTS"},
+		},
+		{
+			name:         "transactions before the start are left out",
+			institution:  mockInstitution(server.URL),
+			credentials:  credentials,
+			account:      checking,
+			start:        now.Add(-36 * time.Hour),
+			wantType:     db.AccountTypeCHECKING,
+			wantBalance:  2150.42,
+			transactions: []string{"ACME CORP PAYROLL"},
+		},
+		{
+			name:         "OFX 2.0.3",
+			institution:  Institution{URL: server.URL, Org: ofxmock.Org, Fid: ofxmock.Fid, AppId: DefaultAppId, AppVersion: DefaultAppVersion, OfxVersion: "203"},
+			credentials:  credentials,
+			account:      creditCard,
+			start:        start,
+			wantType:     db.AccountTypeCREDIT,
+			wantBalance:  -310.75,
+			transactions: []string{"STARBUCKS STORE 1458", "AMAZON MKTPLACE PMTS"},
+		},
+		{
+			name:        "wrong password",
+			institution: mockInstitution(server.URL),
+			credentials: Credentials{Username: ofxmock.Username, Password: "wrong"},
+			account:     checking,
+			start:       start,
+			wantErr:     "15500",
+		},
+		{
+			name:        "unknown account",
+			institution: mockInstitution(server.URL),
+			credentials: credentials,
+			account:     Account{BankId: ofxmock.BankId, AccountId: "999", Type: db.AccountTypeCHECKING},
+			start:       start,
+			wantErr:     "2003",
+		},
+		{
+			name:        "plain http to another host",
+			institution: mockInstitution("http://ofx.example.com"),
+			credentials: credentials,
+			account:     checking,
+			start:       start,
+			wantErr:     "OFX server URL must use https",
+		},
+	}
+
+	client := New(server.Client())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := client.Download(context.Background(), tt.institution, tt.credentials, tt.account, tt.start, now)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Download() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Download() error = %v", err)
+			}
+
+			if len(results) != 1 {
+				t.Fatalf("Download() returned %d statements, want 1", len(results))
+			}
+
+			account := results[0].Account
+
+			if account.AccountId != tt.account.AccountId || account.Type != tt.wantType || account.CurrentBalance != tt.wantBalance {
+				t.Errorf("account = %s %s %.2f, want %s %s %.2f", account.AccountId, account.Type, account.CurrentBalance, tt.account.AccountId, tt.wantType, tt.wantBalance)
+			}
+
+			descriptions := []string{}
+
+			for _, tx := range results[0].Transactions {
+				descriptions = append(descriptions, tx.Description)
+			}
+
+			if strings.Join(descriptions, "|") != strings.Join(tt.transactions, "|") {
+				t.Errorf("transactions = %v, want %v", descriptions, tt.transactions)
+			}
+		})
+	}
+}
+
+func TestNewStatementRequest(t *testing.T) {
+	institution := mockInstitution("https://ofx.example.com")
+	credentials := Credentials{Username: "user", Password: "secret"}
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		account    Account
+		wantBank   string
+		wantCredit string
+		wantErr    string
+	}{
+		{
+			name:     "savings",
+			account:  Account{BankId: "021000021", AccountId: "123", Type: db.AccountTypeSAVINGS},
+			wantBank: "SAVINGS",
+		},
+		{
+			name:       "credit card",
+			account:    Account{AccountId: "4444", Type: db.AccountTypeCREDIT},
+			wantCredit: "4444",
+		},
+		{
+			name:    "bank account without a routing number",
+			account: Account{AccountId: "123", Type: db.AccountTypeCHECKING},
+			wantErr: "Routing number is required for bank accounts",
+		},
+		{
+			name:    "investment account",
+			account: Account{AccountId: "123", Type: db.AccountTypeINVESTMENT},
+			wantErr: "Unsupported account type INVESTMENT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := newStatementRequest(institution, credentials, tt.account, start, end)
+
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("newStatementRequest() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("newStatementRequest() error = %v", err)
+			}
+
+			if request.Signon.UserID != "user" || request.Signon.UserPass != "secret" || request.Signon.Org != ofxmock.Org || request.Signon.Fid != ofxmock.Fid {
+				t.Errorf("signon = %+v", request.Signon)
+			}
+
+			if tt.wantBank != "" {
+				if len(request.Bank) != 1 || len(request.CreditCard) != 0 {
+					t.Fatalf("request has %d bank and %d credit card statements, want 1 bank statement", len(request.Bank), len(request.CreditCard))
+				}
+
+				statement := request.Bank[0].(*ofxgo.StatementRequest)
+
+				if statement.BankAcctFrom.AcctType.String() != tt.wantBank || string(statement.BankAcctFrom.BankID) != tt.account.BankId {
+					t.Errorf("bank account = %+v", statement.BankAcctFrom)
+				}
+
+				if !statement.DtStart.Time.Equal(start) || !statement.DtEnd.Time.Equal(end) || !bool(statement.Include) {
+					t.Errorf("statement = %s to %s, include %v", statement.DtStart, statement.DtEnd, statement.Include)
+				}
+
+				return
+			}
+
+			if len(request.CreditCard) != 1 || len(request.Bank) != 0 {
+				t.Fatalf("request has %d bank and %d credit card statements, want 1 credit card statement", len(request.Bank), len(request.CreditCard))
+			}
+
+			statement := request.CreditCard[0].(*ofxgo.CCStatementRequest)
+
+			if string(statement.CCAcctFrom.AcctID) != tt.wantCredit {
+				t.Errorf("credit card = %+v", statement.CCAcctFrom)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr string
+	}{
+		{url: "https://ofx.example.com/ofx"},
+		{url: "http://localhost:8080"},
+		{url: "http://127.0.0.1:8080"},
+		{url: "http://[::1]:8080"},
+		{url: "http://ofx.example.com", wantErr: "OFX server URL must use https"},
+		{url: "ftp://ofx.example.com", wantErr: "OFX server URL must use https"},
+		{url: "ofx.example.com", wantErr: "Invalid OFX server URL"},
+		{url: "", wantErr: "Invalid OFX server URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := checkURL(tt.url)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkURL() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("checkURL() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+// Package ofxmock is a local OFX Direct Connect responder with in-memory
+// accounts, so scheduled downloads can run without a bank
+package ofxmock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aclindsa/ofxgo"
+)
+
+const (
+	Username = "mockuser"
+	Password = "mockpass"
+	Org      = "MOCKBANK"
+	Fid      = "1234"
+	// Routing number of the checking account created by New
+	BankId = "021000021"
+
+	// OFX signon status for an invalid username or password
+	statusInvalidLogin = 15500
+	// OFX status for an unknown account
+	statusInvalidAccount = 2003
+)
+
+var (
+	userIdPattern    = regexp.MustCompile(`<USERID>([^<\r\n]+)`)
+	userPassPattern  = regexp.MustCompile(`<USERPASS>([^<\r\n]+)`)
+	accountIdPattern = regexp.MustCompile(`<ACCTID>([^<\r\n]+)`)
+	dtStartPattern   = regexp.MustCompile(`<DTSTART>([^<\r\n]+)`)
+	dtEndPattern     = regexp.MustCompile(`<DTEND>([^<\r\n]+)`)
+)
+
+type Transaction struct {
+	Id     string
+	Type   string
+	Posted time.Time
+	Amount string
+	Name   string
+	Memo   string
+}
+
+type Account struct {
+	Id           string
+	CreditCard   bool
+	Type         string
+	Balance      string
+	Transactions []Transaction
+}
+
+type Server struct {
+	mu       sync.Mutex
+	accounts map[string]*Account
+	nextId   int
+}
+
+// New creates a server with a checking account (1111111111) and a credit
+// card (4444333322221111) with a few transactions
+func New() *Server {
+	s := &Server{accounts: map[string]*Account{}}
+	s.AddAccount(&Account{Id: "1111111111", Type: "CHECKING", Balance: "2150.42"})
+	s.AddAccount(&Account{Id: "4444333322221111", CreditCard: true, Balance: "-310.75"})
+
+	today := time.Now()
+
+	for i, tx := range []struct {
+		account string
+		tx      Transaction
+	}{
+		{"1111111111", Transaction{Type: "DIRECTDEP", Amount: "2500.00", Name: "ACME CORP PAYROLL"}},
+		{"1111111111", Transaction{Type: "DEBIT", Amount: "-89.40", Name: "CITY POWER AND LIGHT"}},
+		{"1111111111", Transaction{Type: "ATM", Amount: "-40.00", Name: "ATM WITHDRAWAL", Memo: "MAIN ST BRANCH"}},
+		{"4444333322221111", Transaction{Type: "DEBIT", Amount: "-12.75", Name: "STARBUCKS STORE 1458"}},
+		{"4444333322221111", Transaction{Type: "DEBIT", Amount: "-64.20", Name: "AMAZON MKTPLACE PMTS"}},
+	} {
+		tx.tx.Posted = today.AddDate(0, 0, -i-1)
+		s.AddTransaction(tx.account, tx.tx)
+	}
+
+	return s
+}
+
+func (s *Server) AddAccount(account *Account) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accounts[account.Id] = account
+}
+
+// AddTransaction adds a transaction to an account, generating its FITID
+// when empty
+func (s *Server) AddTransaction(accountId string, tx Transaction) Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tx.Id == "" {
+		s.nextId++
+		tx.Id = fmt.Sprintf("MOCK%06d", s.nextId)
+	}
+
+	if account, ok := s.accounts[accountId]; ok {
+		account.Transactions = append(account.Transactions, tx)
+	}
+
+	return tx
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Requests are not parsed by ofxgo, so the few fields used are matched
+	// directly in the SGML or XML body
+	body := string(payload)
+	version := ofxgo.OfxVersion203
+
+	if strings.HasPrefix(strings.TrimSpace(body), "OFXHEADER:100") {
+		version = ofxgo.OfxVersion102
+	}
+
+	response := &ofxgo.Response{
+		Version: version,
+		Signon: ofxgo.SignonResponse{
+			Status:   ofxgo.Status{Code: 0, Severity: "INFO"},
+			DtServer: ofxgo.Date{Time: time.Now()},
+			Language: "ENG",
+			Org:      Org,
+			Fid:      Fid,
+		},
+	}
+
+	if match(userIdPattern, body) != Username || match(userPassPattern, body) != Password {
+		response.Signon.Status = ofxgo.Status{Code: statusInvalidLogin, Severity: "ERROR", Message: "Invalid username or password"}
+		write(w, response)
+		return
+	}
+
+	start, _ := parseDate(match(dtStartPattern, body))
+	end, err := parseDate(match(dtEndPattern, body))
+
+	if err != nil {
+		end = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[match(accountIdPattern, body)]
+	creditCard := strings.Contains(body, "<CCSTMTRQ>")
+
+	if !ok || account.CreditCard != creditCard {
+		response.Signon.Status = ofxgo.Status{Code: statusInvalidAccount, Severity: "ERROR", Message: "Account not found"}
+		write(w, response)
+		return
+	}
+
+	if err := s.addStatement(response, account, start, end); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	write(w, response)
+}
+
+func (s *Server) addStatement(response *ofxgo.Response, account *Account, start time.Time, end time.Time) error {
+	list := &ofxgo.TransactionList{
+		DtStart: ofxgo.Date{Time: start},
+		DtEnd:   ofxgo.Date{Time: end},
+	}
+
+	for _, tx := range account.Transactions {
+		if tx.Posted.Before(start) || tx.Posted.After(end) {
+			continue
+		}
+
+		trnType, err := ofxgo.NewTrnType(tx.Type)
+
+		if err != nil {
+			return err
+		}
+
+		var amount ofxgo.Amount
+
+		if _, ok := amount.SetString(tx.Amount); !ok {
+			return fmt.Errorf("invalid amount %s", tx.Amount)
+		}
+
+		list.Transactions = append(list.Transactions, ofxgo.Transaction{
+			TrnType:  trnType,
+			DtPosted: ofxgo.Date{Time: tx.Posted},
+			TrnAmt:   amount,
+			FiTID:    ofxgo.String(tx.Id),
+			Name:     ofxgo.String(tx.Name),
+			Memo:     ofxgo.String(tx.Memo),
+		})
+	}
+
+	var balance ofxgo.Amount
+
+	if _, ok := balance.SetString(account.Balance); !ok {
+		return fmt.Errorf("invalid balance %s", account.Balance)
+	}
+
+	currency, _ := ofxgo.NewCurrSymbol("USD")
+	uid, err := ofxgo.RandomUID()
+
+	if err != nil {
+		return err
+	}
+
+	status := ofxgo.Status{Code: 0, Severity: "INFO"}
+	asOf := ofxgo.Date{Time: time.Now()}
+
+	if account.CreditCard {
+		response.CreditCard = append(response.CreditCard, &ofxgo.CCStatementResponse{
+			TrnUID:       *uid,
+			Status:       status,
+			CurDef:       *currency,
+			CCAcctFrom:   ofxgo.CCAcct{AcctID: ofxgo.String(account.Id)},
+			BankTranList: list,
+			BalAmt:       balance,
+			DtAsOf:       asOf,
+		})
+
+		return nil
+	}
+
+	accountType, err := ofxgo.NewAcctType(account.Type)
+
+	if err != nil {
+		return err
+	}
+
+	response.Bank = append(response.Bank, &ofxgo.StatementResponse{
+		TrnUID: *uid,
+		Status: status,
+		CurDef: *currency,
+		BankAcctFrom: ofxgo.BankAcct{
+			BankID:   BankId,
+			AcctID:   ofxgo.String(account.Id),
+			AcctType: accountType,
+		},
+		BankTranList: list,
+		BalAmt:       balance,
+		DtAsOf:       asOf,
+	})
+
+	return nil
+}
+
+func write(w http.ResponseWriter, response *ofxgo.Response) {
+	body, err := response.Marshal()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ofx")
+	w.Write(body.Bytes())
+}
+
+func match(pattern *regexp.Regexp, body string) string {
+	if m := pattern.FindStringSubmatch(body); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+
+	return ""
+}
+
+// OFX dates start with YYYYMMDD, optionally followed by a time and timezone
+func parseDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %s", value)
+	}
+
+	if len(value) >= 14 {
+		if date, err := time.Parse("20060102150405", value[:14]); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Parse("20060102", value[:8])
+}
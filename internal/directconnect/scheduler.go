@@ -0,0 +1,88 @@
+package directconnect
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+const (
+	// Statements overlap the last sync so transactions that post late are
+	// still downloaded. Downloaded transactions are matched by their FITID
+	overlap = 7 * 24 * time.Hour
+	// History downloaded for an account that has never been synced
+	initialHistory = 90 * 24 * time.Hour
+	// How often the scheduler looks for connections that are due
+	pollInterval = 15 * time.Minute
+)
+
+// SyncFunc downloads and saves the statement for a connection
+type SyncFunc func(ctx context.Context, connection db.OfxConnection) error
+
+// Scheduler syncs every connection that has not been attempted within the
+// interval. Failed connections also wait for the interval, so a wrong
+// password does not lock the account at the bank
+type Scheduler struct {
+	repo     db.Repository
+	interval time.Duration
+	sync     SyncFunc
+}
+
+func NewScheduler(repo db.Repository, interval time.Duration, sync SyncFunc) *Scheduler {
+	return &Scheduler{
+		repo:     repo,
+		interval: interval,
+		sync:     sync,
+	}
+}
+
+// Start checks for due connections now and then every poll interval until
+// ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(min(s.interval, pollInterval))
+		defer ticker.Stop()
+
+		for {
+			s.RunDue(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// RunDue syncs the due connections one at a time
+func (s *Scheduler) RunDue(ctx context.Context) {
+	connections, err := s.repo.ListDueOfxConnections(ctx, time.Now().Add(-s.interval))
+
+	if err != nil {
+		log.Printf("Failed to list OFX connections: %v", err)
+		return
+	}
+
+	for _, connection := range connections {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := s.sync(ctx, connection); err != nil {
+			log.Printf("OFX connection %s failed to sync: %v", connection.ID, err)
+		}
+	}
+}
+
+// StatementStart is the start of the date range to download, based on the
+// date of the account's last sync
+func StatementStart(lastSync time.Time, now time.Time) time.Time {
+	if lastSync.IsZero() {
+		return now.Add(-initialHistory)
+	}
+
+	return lastSync.Add(-overlap)
+}
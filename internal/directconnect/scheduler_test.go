@@ -0,0 +1,34 @@
+package directconnect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatementStart(t *testing.T) {
+	now := time.Date(2024, time.March, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		lastSync time.Time
+		want     time.Time
+	}{
+		{
+			name: "never synced downloads 90 days",
+			want: time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "overlaps the last sync by a week",
+			lastSync: time.Date(2024, time.March, 30, 6, 0, 0, 0, time.UTC),
+			want:     time.Date(2024, time.March, 23, 6, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatementStart(tt.lastSync, now); !got.Equal(tt.want) {
+				t.Fatalf("StatementStart() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
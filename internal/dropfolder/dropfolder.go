@@ -0,0 +1,255 @@
+// Package dropfolder imports statement files dropped into a folder. Each file
+// is moved to a done or failed folder next to it, with a JSON report of the
+// import. Files in a subfolder belong to the user the subfolder is named after
+package dropfolder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DoneDir   = "done"
+	FailedDir = "failed"
+	// Files modified more recently may still be being copied into the folder
+	settleTime = 2 * time.Second
+)
+
+// Extensions of the statement files that are imported. Other files are left alone
+var Extensions = []string{".ofx", ".qfx", ".qbo", ".csv"}
+
+// Result of importing a file
+type Result struct {
+	Duplicate           bool     `json:"duplicate"`
+	AccountsUpdated     int      `json:"accountsUpdated"`
+	AccountsFailed      int      `json:"accountsFailed"`
+	TransactionsUpdated int      `json:"transactionsUpdated"`
+	TransactionsFailed  int      `json:"transactionsFailed"`
+	Errors              []string `json:"errors"`
+}
+
+// Report is written next to each imported file
+type Report struct {
+	File     string    `json:"file"`
+	User     string    `json:"user"`
+	Success  bool      `json:"success"`
+	Error    string    `json:"error,omitempty"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Result
+}
+
+// ImportFunc imports the file at path for a user. user is the name of the
+// subfolder the file was dropped in, or empty for the top folder
+type ImportFunc func(ctx context.Context, user string, path string) (Result, error)
+
+// File waiting to be imported
+type File struct {
+	Path string
+	User string
+}
+
+type Folder struct {
+	dir        string
+	importFile ImportFunc
+}
+
+func New(dir string, importFile ImportFunc) *Folder {
+	return &Folder{
+		dir:        dir,
+		importFile: importFile,
+	}
+}
+
+// Pending lists the statement files in the folder and its user subfolders
+// that are ready to import
+func (f *Folder) Pending() ([]File, error) {
+	files, err := pendingFiles(f.dir, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(f.dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || isReserved(entry.Name()) {
+			continue
+		}
+
+		userFiles, err := pendingFiles(filepath.Join(f.dir, entry.Name()), entry.Name())
+
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, userFiles...)
+	}
+
+	return files, nil
+}
+
+// Import imports a file and moves it to the done or failed folder with its
+// report. An error is only returned if the file could not be moved
+func (f *Folder) Import(ctx context.Context, file File) (Report, error) {
+	report := Report{
+		File:    filepath.Base(file.Path),
+		User:    file.User,
+		Started: time.Now(),
+	}
+
+	result, err := f.importFile(ctx, file.User, file.Path)
+	report.Finished = time.Now()
+	report.Result = result
+	report.Success = err == nil
+
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	target := DoneDir
+
+	if !report.Success {
+		target = FailedDir
+	}
+
+	if err := moveFile(file.Path, target, report); err != nil {
+		return report, fmt.Errorf("Failed to move %s: %w", file.Path, err)
+	}
+
+	return report, nil
+}
+
+// RunOnce imports every pending file, calling onReport after each one
+func (f *Folder) RunOnce(ctx context.Context, onReport func(Report)) error {
+	files, err := f.Pending()
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		report, err := f.Import(ctx, file)
+
+		if err != nil {
+			return err
+		}
+
+		onReport(report)
+	}
+
+	return nil
+}
+
+// Watch checks the folder for new files every interval until ctx is cancelled
+func (f *Folder) Watch(ctx context.Context, interval time.Duration, onReport func(Report)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.RunOnce(ctx, onReport); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to import from %s: %v", f.dir, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsStatement reports whether the file has a supported extension
+func IsStatement(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+
+	for _, supported := range Extensions {
+		if extension == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+func pendingFiles(dir string, user string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := []File{}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !IsStatement(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if time.Since(info.ModTime()) < settleTime {
+			continue
+		}
+
+		files = append(files, File{
+			Path: filepath.Join(dir, entry.Name()),
+			User: user,
+		})
+	}
+
+	return files, nil
+}
+
+func isReserved(name string) bool {
+	return name == DoneDir || name == FailedDir || strings.HasPrefix(name, ".")
+}
+
+// Moves the file into the target folder beside it and writes its report. A
+// file with the same name from an earlier drop gets a timestamp added
+func moveFile(path string, target string, report Report) error {
+	dir := filepath.Join(filepath.Dir(path), target)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	destination := filepath.Join(dir, name)
+
+	if _, err := os.Stat(destination); err == nil {
+		extension := filepath.Ext(name)
+		stamp := report.Started.Format("20060102T150405")
+		destination = filepath.Join(dir, fmt.Sprintf("%s-%s%s", strings.TrimSuffix(name, extension), stamp, extension))
+	}
+
+	if err := os.Rename(path, destination); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(destination+".report.json", content, 0644)
+}
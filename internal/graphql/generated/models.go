@@ -3,6 +3,7 @@
 package gen
 
 import (
+	"github.com/google/uuid"
 	"github.com/proctorinc/banker/internal/db"
 	"github.com/proctorinc/banker/internal/graphql/paging"
 )
@@ -17,6 +18,62 @@ type AccountEdge struct {
 	Node   *db.Account `json:"node"`
 }
 
+type AccountPreview struct {
+	Name     string `json:"name"`
+	SourceID string `json:"sourceId"`
+	Type     string `json:"type"`
+	// exists is true when the upload would update an account already saved
+	Exists       bool                 `json:"exists"`
+	Transactions []TransactionPreview `json:"transactions"`
+}
+
+type AccountUploadStats struct {
+	Name         string              `json:"name"`
+	Account      *db.Account         `json:"account,omitempty"`
+	SyncItem     *db.AccountSyncItem `json:"syncItem,omitempty"`
+	Transactions *UploadStats        `json:"transactions"`
+	Errors       []string            `json:"errors"`
+}
+
+type CSVProfileInput struct {
+	Name        string  `json:"name"`
+	Institution *string `json:"institution,omitempty"`
+	// columns are matched by header name, or by zero-based index when numeric
+	DateColumn        string `json:"dateColumn"`
+	DescriptionColumn string `json:"descriptionColumn"`
+	// single signed amount column, or separate debit and credit columns
+	AmountColumn      *string `json:"amountColumn,omitempty"`
+	DebitColumn       *string `json:"debitColumn,omitempty"`
+	CreditColumn      *string `json:"creditColumn,omitempty"`
+	TypeColumn        *string `json:"typeColumn,omitempty"`
+	CheckNumberColumn *string `json:"checkNumberColumn,omitempty"`
+	// running balance after each row. The newest balance updates the account
+	BalanceColumn *string `json:"balanceColumn,omitempty"`
+	// date format using YYYY, YY, MMM, MM, M, DD and D. Defaults to MM/DD/YYYY
+	DateFormat *string `json:"dateFormat,omitempty"`
+	// DEBITS_NEGATIVE (default) or DEBITS_POSITIVE for files where spending is positive
+	SignConvention *string `json:"signConvention,omitempty"`
+	// number of rows to skip before the header row
+	SkipRows  *int  `json:"skipRows,omitempty"`
+	HasHeader *bool `json:"hasHeader,omitempty"`
+}
+
+type CategoryInput struct {
+	Name string `json:"name"`
+	// parentId makes the category a subcategory of a top level category
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+}
+
+type CategorySpending struct {
+	// category is null for spending without a category
+	Category *db.Category `json:"category,omitempty"`
+	Total    float64      `json:"total"`
+	Count    int          `json:"count"`
+	// subcategories break down a top level category's spending. Spending in the
+	// top level category itself is listed under the category
+	Subcategories []CategorySpending `json:"subcategories"`
+}
+
 type CreateFundInput struct {
 	Type string  `json:"type"`
 	Name string  `json:"name"`
@@ -60,6 +117,14 @@ type FundsStats struct {
 	Unallocated  float64 `json:"unallocated"`
 }
 
+type GlobalMerchantInput struct {
+	Name    string  `json:"name"`
+	Website *string `json:"website,omitempty"`
+	// categoryId must be a default category, since global merchants are shared
+	CategoryID *uuid.UUID `json:"categoryId,omitempty"`
+	Aliases    []string   `json:"aliases,omitempty"`
+}
+
 type IncomeStats struct {
 	Total        float64                `json:"total"`
 	Transactions *TransactionConnection `json:"transactions"`
@@ -70,6 +135,20 @@ type LoginInput struct {
 	Password string `json:"password"`
 }
 
+type ManualAssetInput struct {
+	Name string `json:"name"`
+	// ASSET (e.g. a house or car) or LIABILITY (e.g. a loan)
+	Type  string  `json:"type"`
+	Value float64 `json:"value"`
+	Date  string  `json:"date"`
+}
+
+type ManualAssetValuationInput struct {
+	AssetID uuid.UUID `json:"assetId"`
+	Value   float64   `json:"value"`
+	Date    string    `json:"date"`
+}
+
 type MerchantConnection struct {
 	Edges    []MerchantEdge   `json:"edges"`
 	PageInfo *paging.PageInfo `json:"pageInfo"`
@@ -80,6 +159,42 @@ type MerchantEdge struct {
 	Node   *db.Merchant `json:"node"`
 }
 
+type MerchantInput struct {
+	// website overrides the global merchant's website. Null clears the override
+	Website *string `json:"website,omitempty"`
+	// categoryId is the default category. Null uses the category of the global
+	// merchant passed in globalMerchantId, or clears the category without one
+	CategoryID *uuid.UUID `json:"categoryId,omitempty"`
+	// globalMerchantId links the merchant to a global merchant. Null unlinks it
+	GlobalMerchantID *uuid.UUID `json:"globalMerchantId,omitempty"`
+}
+
+type MerchantPreview struct {
+	Name string `json:"name"`
+	// isNew is true when the upload would create this merchant
+	IsNew    bool         `json:"isNew"`
+	Merchant *db.Merchant `json:"merchant,omitempty"`
+}
+
+type MerchantRuleInput struct {
+	Priority *int   `json:"priority,omitempty"`
+	Type     string `json:"type"`
+	// description pattern. Prefix and contains patterns ignore case. Optional for amount ranges
+	Pattern *string `json:"pattern,omitempty"`
+	// amounts are compared to the absolute transaction amount
+	MinAmount    *float64  `json:"minAmount,omitempty"`
+	MaxAmount    *float64  `json:"maxAmount,omitempty"`
+	UploadSource *string   `json:"uploadSource,omitempty"`
+	MerchantID   uuid.UUID `json:"merchantId"`
+}
+
+type MerchantRuleTest struct {
+	MatchCount int `json:"matchCount"`
+	// changedCount is the number of matches linked to a different merchant
+	ChangedCount int              `json:"changedCount"`
+	Transactions []db.Transaction `json:"transactions"`
+}
+
 type MonthItem struct {
 	ID    string `json:"id"`
 	Name  string `json:"name"`
@@ -96,6 +211,35 @@ type NetStats struct {
 	Transactions *TransactionConnection `json:"transactions"`
 }
 
+type NetWorthPoint struct {
+	Date        string  `json:"date"`
+	Assets      float64 `json:"assets"`
+	Liabilities float64 `json:"liabilities"`
+	Net         float64 `json:"net"`
+}
+
+type NetWorthStats struct {
+	Assets      float64         `json:"assets"`
+	Liabilities float64         `json:"liabilities"`
+	Net         float64         `json:"net"`
+	History     []NetWorthPoint `json:"history"`
+}
+
+type OFXConnectionInput struct {
+	// statements are requested for the account's source id and routing number
+	AccountID uuid.UUID `json:"accountId"`
+	URL       string    `json:"url"`
+	Org       string    `json:"org"`
+	Fid       string    `json:"fid"`
+	Username  string    `json:"username"`
+	Password  string    `json:"password"`
+	// client identification sent to the server. Defaults to Quicken: QWIN 2700
+	AppID      *string `json:"appId,omitempty"`
+	AppVersion *string `json:"appVersion,omitempty"`
+	// OFX version such as 102 or 203. Defaults to 102
+	OfxVersion *string `json:"ofxVersion,omitempty"`
+}
+
 type Query struct {
 }
 
@@ -105,6 +249,15 @@ type RegisterInput struct {
 	Password string `json:"password"`
 }
 
+type RevertSyncResponse struct {
+	SyncItem             *db.AccountSyncItem `json:"syncItem"`
+	TransactionsDeleted  int                 `json:"transactionsDeleted"`
+	TransactionsRestored int                 `json:"transactionsRestored"`
+	MerchantsDeleted     int                 `json:"merchantsDeleted"`
+	// accountDeleted is true when the reverted sync created the account
+	AccountDeleted bool `json:"accountDeleted"`
+}
+
 type SpendingStats struct {
 	Total        float64                `json:"total"`
 	Transactions *TransactionConnection `json:"transactions"`
@@ -117,7 +270,26 @@ type Stats struct {
 }
 
 type StatsInput struct {
-	Filter *DateFilter `json:"filter"`
+	Filter         *DateFilter `json:"filter"`
+	IncludePending *bool       `json:"includePending,omitempty"`
+}
+
+type Subscription struct {
+}
+
+type SyncConnectionResponse struct {
+	Connection          *db.SyncConnection `json:"connection"`
+	Upload              *UploadResponse    `json:"upload"`
+	TransactionsRemoved int                `json:"transactionsRemoved"`
+}
+
+// TagTotals add up a tag's transactions across accounts and categories
+type TagTotals struct {
+	Tag      *db.Tag `json:"tag"`
+	Spending float64 `json:"spending"`
+	Income   float64 `json:"income"`
+	Net      float64 `json:"net"`
+	Count    int     `json:"count"`
 }
 
 type TransactionConnection struct {
@@ -130,10 +302,44 @@ type TransactionEdge struct {
 	Node   *db.Transaction `json:"node"`
 }
 
+type TransactionPreview struct {
+	SourceID    string  `json:"sourceId"`
+	Date        *string `json:"date,omitempty"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Type        string  `json:"type"`
+	// action is INSERT, UPDATE or REJECT
+	Action string `json:"action"`
+	// reason explains why a transaction would be rejected
+	Reason *string `json:"reason,omitempty"`
+	// changedFields lists the fields an UPDATE would overwrite
+	ChangedFields []string         `json:"changedFields"`
+	Merchant      *MerchantPreview `json:"merchant,omitempty"`
+}
+
+type TransactionSplitInput struct {
+	Amount     float64    `json:"amount"`
+	CategoryID *uuid.UUID `json:"categoryId,omitempty"`
+	Note       *string    `json:"note,omitempty"`
+	FundID     *uuid.UUID `json:"fundId,omitempty"`
+}
+
+type UploadPreview struct {
+	Inserts  int              `json:"inserts"`
+	Updates  int              `json:"updates"`
+	Rejects  int              `json:"rejects"`
+	Accounts []AccountPreview `json:"accounts"`
+}
+
 type UploadResponse struct {
-	Success      bool         `json:"success"`
-	Accounts     *UploadStats `json:"accounts"`
-	Transactions *UploadStats `json:"transactions"`
+	Success bool `json:"success"`
+	// duplicate is true when the file was already imported and was skipped
+	Duplicate    bool                 `json:"duplicate"`
+	Accounts     *UploadStats         `json:"accounts"`
+	Transactions *UploadStats         `json:"transactions"`
+	AccountStats []AccountUploadStats `json:"accountStats"`
+	Errors       []string             `json:"errors"`
+	ImportBatch  *db.ImportBatch      `json:"importBatch,omitempty"`
 }
 
 type UploadStats struct {
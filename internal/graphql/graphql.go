@@ -1,44 +1,62 @@
 package graphql
 
 import (
+	"net/http"
+	"slices"
+	"time"
+
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gin-gonic/gin"
-	"github.com/proctorinc/banker/internal/auth"
-	"github.com/proctorinc/banker/internal/dataloaders"
-	"github.com/proctorinc/banker/internal/db"
+	"github.com/gorilla/websocket"
+	cors "github.com/proctorinc/banker/internal"
 	"github.com/proctorinc/banker/internal/graphql/directives"
 	gen "github.com/proctorinc/banker/internal/graphql/generated"
 	"github.com/proctorinc/banker/internal/graphql/resolvers"
 )
 
-func GraphqlHandler(repo db.Repository, loaders dataloaders.Retriever) gin.HandlerFunc {
+func GraphqlHandler(resolver *resolvers.Resolver) gin.HandlerFunc {
 	config := gen.Config{
-		Resolvers: &resolvers.Resolver{
-			Repository:  repo,
-			AuthService: *auth.NewAuthService(repo),
-			DataLoaders: loaders,
-		},
+		Resolvers: resolver,
 	}
 
 	// Add GraphQL directives
 	config.Directives.IsAuthenticated = directives.IsAuthenticated
 	config.Directives.IsAdmin = directives.IsAdmin
 
-	handler := handler.NewDefaultServer(
+	handler := handler.New(
 		gen.NewExecutableSchema(config),
 	)
 
-	// Limit queries to 5 levels of complexity
-	handler.Use(extension.FixedComplexityLimit(75))
-
+	// Subscriptions are served over websockets from the same origins as CORS
+	handler.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: 10 * time.Second,
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				return slices.Contains(cors.AllowedOrigins, r.Header.Get("Origin"))
+			},
+		},
+	})
+	handler.AddTransport(transport.Options{})
+	handler.AddTransport(transport.GET{})
+	handler.AddTransport(transport.POST{})
 	handler.AddTransport(transport.MultipartForm{
 		MaxUploadSize: 5 * 1_000_000, // 5 MB max
 		MaxMemory:     5 * 1_000_000, // 5 MB max
 	})
 
+	handler.SetQueryCache(lru.New(1000))
+	handler.Use(extension.Introspection{})
+	handler.Use(extension.AutomaticPersistedQuery{
+		Cache: lru.New(100),
+	})
+
+	// Limit queries to 5 levels of complexity
+	handler.Use(extension.FixedComplexityLimit(75))
+
 	return func(c *gin.Context) {
 		handler.ServeHTTP(c.Writer, c.Request)
 	}
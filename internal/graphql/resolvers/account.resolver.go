@@ -1,25 +1,22 @@
 package resolvers
 
 import (
-	"bytes"
 	"context"
-	"database/sql"
 	"fmt"
 	"io"
 	"log"
-	"regexp"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/google/uuid"
-	"github.com/jdkato/prose/v2"
 	"github.com/proctorinc/banker/internal/auth"
-	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/csvimport"
 	"github.com/proctorinc/banker/internal/db"
 	gen "github.com/proctorinc/banker/internal/graphql/generated"
 	"github.com/proctorinc/banker/internal/graphql/paging"
 	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/importer"
 )
 
 func (r *accountResolver) ID(ctx context.Context, account *db.Account) (uuid.UUID, error) {
@@ -54,8 +51,50 @@ func (r *accountResolver) LastSync(ctx context.Context, account *db.Account) (*d
 	return &sync, err
 }
 
-func (r *accountResolver) Transactions(ctx context.Context, account *db.Account, page *paging.PageArgs) (*gen.TransactionConnection, error) {
-	totalCount, err := r.DataLoaders.Retrieve(ctx).CountTransactionsByAccountId.Load(account.ID.String())
+func (r *accountResolver) CurrentBalance(ctx context.Context, account *db.Account) (*float64, error) {
+	balance, err := r.DataLoaders.Retrieve(ctx).LatestBalanceByAccountId.Load(account.ID.String())
+
+	if err != nil || balance == nil {
+		return nil, err
+	}
+
+	current := utils.FormatCurrencyFloat64(balance.Current)
+	return &current, nil
+}
+
+func (r *accountResolver) AvailableBalance(ctx context.Context, account *db.Account) (*float64, error) {
+	balance, err := r.DataLoaders.Retrieve(ctx).LatestBalanceByAccountId.Load(account.ID.String())
+
+	if err != nil || balance == nil || !balance.Available.Valid {
+		return nil, err
+	}
+
+	available := utils.FormatCurrencyFloat64(balance.Available.Int32)
+	return &available, nil
+}
+
+func (r *accountResolver) BalanceHistory(ctx context.Context, account *db.Account, filter gen.DateFilter) ([]db.AccountBalance, error) {
+	statsFilter, err := parseStatsFilter(&filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := r.Repository.ListAccountBalances(ctx, db.ListAccountBalancesParams{
+		Accountid: account.ID,
+		Startdate: statsFilter.StartDate,
+		Enddate:   statsFilter.EndDate,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func (r *accountResolver) Transactions(ctx context.Context, account *db.Account, page *paging.PageArgs, includePending *bool) (*gen.TransactionConnection, error) {
+	totalCount, err := r.DataLoaders.Retrieve(ctx).CountTransactionsByAccountId(withPending(includePending)).Load(account.ID.String())
 
 	if err != nil {
 		return &gen.TransactionConnection{
@@ -70,7 +109,7 @@ func (r *accountResolver) Transactions(ctx context.Context, account *db.Account,
 	start := int32(paginator.Offset)
 	limit := calculatePageLimit(page)
 
-	transactions, err := r.DataLoaders.Retrieve(ctx).TransactionsByAccountId(limit, start).Load(account.ID.String())
+	transactions, err := r.DataLoaders.Retrieve(ctx).TransactionsByAccountId(limit, start, withPending(includePending)).Load(account.ID.String())
 
 	for i, row := range transactions {
 		result.Edges = append(result.Edges, gen.TransactionEdge{
@@ -133,23 +172,19 @@ func (r *queryResolver) Accounts(ctx context.Context, page *paging.PageArgs) (*g
 // Mutations
 
 func (r *mutationResolver) ChaseOFXUpload(ctx context.Context, reader graphql.Upload) (*gen.UploadResponse, error) {
-	response := &gen.UploadResponse{
-		Success: false,
-		Accounts: &gen.UploadStats{
-			Updated: 0,
-			Failed:  0,
-		},
-		Transactions: &gen.UploadStats{
-			Updated: 0,
-			Failed:  0,
-		},
+	upload, err := r.chaseOFXUpload(ctx, reader)
+
+	if err != nil {
+		return newUploadResponse(), err
 	}
 
-	if !bytes.HasSuffix([]byte(reader.Filename), []byte(".ofx")) &&
-		!bytes.HasSuffix([]byte(reader.Filename), []byte(".QFX")) &&
-		!bytes.HasSuffix([]byte(reader.Filename), []byte(".")) {
+	return upload(ctx)
+}
+
+func (r *mutationResolver) chaseOFXUpload(ctx context.Context, reader graphql.Upload) (uploadFunc, error) {
+	if !isOFXFile(reader.Filename) {
 		log.Printf("Invalid extension: %s", reader.Filename)
-		return response, fmt.Errorf("Invalid file extension. .OFX/.QBX/.QBO required")
+		return nil, fmt.Errorf("Invalid file extension. .OFX/.QBX/.QBO required")
 	}
 
 	user := auth.GetCurrentUser(ctx)
@@ -162,104 +197,37 @@ func (r *mutationResolver) ChaseOFXUpload(ctx context.Context, reader graphql.Up
 	// 	return response, err
 	// }
 
-	ofxResult, err := chase.ParseChaseOFX(reader.File)
-
-	if err != nil {
-		return response, err
-	}
+	return r.importFile(user.ID, reader, db.UploadSourceCHASEOFXUPLOAD, importer.OFXParser)
+}
 
-	account, err := r.Repository.UpsertAccount(ctx, db.UpsertAccountParams{
-		Sourceid:      ofxResult.Account.AccountId,
-		Name:          ofxResult.Account.Name,
-		Type:          db.AccountType(ofxResult.Account.Type),
-		Routingnumber: sql.NullString{String: ofxResult.Account.BankId, Valid: len(ofxResult.Account.BankId) > 0},
-		Ownerid:       user.ID,
-	})
+func (r *mutationResolver) ChaseCSVUpload(ctx context.Context, accountId uuid.UUID, reader graphql.Upload) (*gen.UploadResponse, error) {
+	upload, err := r.chaseCSVUpload(ctx, accountId, reader)
 
 	if err != nil {
-		response.Accounts.Updated++
-		return response, err
+		return newUploadResponse(), err
 	}
 
-	_, err = r.Repository.CreateAccountSyncItem(ctx, db.CreateAccountSyncItemParams{
-		Accountid:    account.ID,
-		Uploadsource: db.UploadSourceCHASEOFXUPLOAD,
-	})
-
-	if err != nil {
-		return response, err
-	}
+	return upload(ctx)
+}
 
-	// Increment successful account upload
-	response.Accounts.Updated++
-
-	for _, tx := range ofxResult.Transactions {
-		merchant := new(db.Merchant)
-		merchantName := parseMerchantName(tx.Description)
-		merchantId, err := parseMerchantId(tx.Description)
-
-		if err != nil {
-			merchantId = strings.ToUpper(tx.Description)
-		}
-
-		res, err := r.Repository.GetMerchantBySourceId(ctx, sql.NullString{String: merchantId, Valid: merchantId != ""})
-
-		if err != nil {
-			res, err = r.Repository.GetMerchantByName(ctx, merchantName)
-
-			if err != nil {
-				res, err := r.Repository.LinkMerchant(ctx, db.LinkMerchantParams{
-					MerchantName: merchantName, //tx.Description,
-					KeyMatch:     merchantId,
-					UploadSource: db.UploadSourceCHASEOFXUPLOAD,
-					SourceId:     sql.NullString{String: merchantId, Valid: merchantId != ""},
-					UserId:       user.ID,
-				})
-
-				if err != nil {
-					log.Println(err)
-				}
-
-				merchant = res
-			} else {
-				merchant = &res
-			}
-		} else {
-			merchant = &res
-		}
-
-		if err != nil {
-			fmt.Println("Merchant unable to be found!")
-		}
-
-		_, err = r.Repository.UpsertTransaction(ctx, db.UpsertTransactionParams{
-			Ownerid:         user.ID,
-			Amount:          utils.FormatCurrencyInt(tx.Amount),
-			Payeeid:         sql.NullString{String: tx.PayeeId, Valid: len(tx.PayeeId) > 0},
-			Payee:           sql.NullString{String: tx.Payee, Valid: len(tx.Payee) > 0},
-			Payeefull:       sql.NullString{String: tx.PayeeFull, Valid: len(tx.PayeeFull) > 0},
-			Sourceid:        tx.Id,
-			Isocurrencycode: ofxResult.Account.IsoCurrencyCode,
-			Date:            tx.DatePosted,
-			Description:     tx.Description,
-			Type:            db.TransactionType(tx.Type),
-			Updated:         time.Now(),
-			Checknumber:     sql.NullString{String: tx.CheckNumber, Valid: len(tx.CheckNumber) > 0},
-			Accountid:       account.ID,
-			Merchantid:      merchant.ID,
-		})
+func (r *mutationResolver) chaseCSVUpload(ctx context.Context, accountId uuid.UUID, reader graphql.Upload) (uploadFunc, error) {
+	return r.csvProfileUpload(ctx, accountId, csvimport.ChaseProfile, db.UploadSourceCHASECSVUPLOAD, reader)
+}
 
-		// Increment successful transaction upload
-		if err != nil {
-			response.Transactions.Failed++
-		} else {
-			response.Transactions.Updated++
-		}
+func newUploadResponse() *gen.UploadResponse {
+	return &gen.UploadResponse{
+		Success: false,
+		Accounts: &gen.UploadStats{
+			Updated: 0,
+			Failed:  0,
+		},
+		Transactions: &gen.UploadStats{
+			Updated: 0,
+			Failed:  0,
+		},
+		AccountStats: []gen.AccountUploadStats{},
+		Errors:       []string{},
 	}
-
-	response.Success = true
-
-	return response, nil
 }
 
 func skipFirstLine(reader io.ReadSeeker) (io.ReadSeeker, error) {
@@ -273,40 +241,11 @@ func skipFirstLine(reader io.ReadSeeker) (io.ReadSeeker, error) {
 	return reader, nil
 }
 
-func parseMerchantName(description string) string {
-	doc, err := prose.NewDocument(description)
-
-	if err != nil {
-		return description
-	}
-
-	if len(doc.Entities()) > 0 {
-		fmt.Printf("Found merchant name?(entity) %s\n", doc.Entities()[0].Text)
-		return doc.Entities()[0].Text
-	}
-
-	for _, token := range doc.Tokens() {
-		if len(token.Tag) >= 2 && (token.Tag[0:2] == "NN" ||
-			token.Tag[0:2] == "VB" ||
-			token.Tag[0:2] == "MD" ||
-			token.Tag[0:2] == "RB") {
-			fmt.Printf("Found merchant name?(token) %s\n", token.Text)
-			return token.Text
-		}
-	}
-
-	return description
-}
-
-func parseMerchantId(description string) (string, error) {
-	expression := regexp.MustCompile(`\b\d{10}\b`)
-	matches := expression.FindAllString(description, -1)
+func isOFXFile(filename string) bool {
+	extension := strings.ToLower(filepath.Ext(filename))
 
-	if len(matches) > 0 {
-		// Get the last match
-		fmt.Printf("Found merchant id! %s\n", matches[len(matches)-1])
-		return matches[len(matches)-1], nil
-	} else {
-		return "", fmt.Errorf("no merchantId found")
-	}
+	return extension == ".ofx" ||
+		extension == ".qfx" ||
+		extension == ".qbo" ||
+		strings.HasSuffix(filename, ".")
 }
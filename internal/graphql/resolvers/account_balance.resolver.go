@@ -0,0 +1,26 @@
+package resolvers
+
+import (
+	"context"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+func (r *accountBalanceResolver) Date(ctx context.Context, balance *db.AccountBalance) (string, error) {
+	return balance.Date.Format(time.RFC3339), nil
+}
+
+func (r *accountBalanceResolver) Current(ctx context.Context, balance *db.AccountBalance) (float64, error) {
+	return utils.FormatCurrencyFloat64(balance.Current), nil
+}
+
+func (r *accountBalanceResolver) Available(ctx context.Context, balance *db.AccountBalance) (*float64, error) {
+	if balance.Available.Valid {
+		available := utils.FormatCurrencyFloat64(balance.Available.Int32)
+		return &available, nil
+	}
+
+	return nil, nil
+}
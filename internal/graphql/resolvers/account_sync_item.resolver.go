@@ -2,9 +2,15 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
 	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
 )
 
 func (r *accountSyncItemResolver) ID(ctx context.Context, syncItem *db.AccountSyncItem) (string, error) {
@@ -18,3 +24,33 @@ func (r *accountSyncItemResolver) Date(ctx context.Context, syncItem *db.Account
 func (r *accountSyncItemResolver) UploadSource(ctx context.Context, syncItem *db.AccountSyncItem) (string, error) {
 	return string(syncItem.Uploadsource), nil
 }
+
+// Mutations
+
+func (r *mutationResolver) RevertSync(ctx context.Context, id uuid.UUID) (*gen.RevertSyncResponse, error) {
+	user := auth.GetCurrentUser(ctx)
+	result, err := r.Repository.RevertSync(ctx, db.RevertSyncParams{
+		SyncItemId: id,
+		UserId:     user.ID,
+	})
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("Sync not found")
+	}
+
+	if errors.Is(err, db.ErrSyncOverwritten) {
+		return nil, fmt.Errorf("Sync has been overwritten by a later upload. Revert the later upload first")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &gen.RevertSyncResponse{
+		SyncItem:             &result.SyncItem,
+		TransactionsDeleted:  int(result.TransactionsDeleted),
+		TransactionsRestored: int(result.TransactionsRestored),
+		MerchantsDeleted:     int(result.MerchantsDeleted),
+		AccountDeleted:       result.AccountDeleted,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+// Mutations
+
+func (r *mutationResolver) Camt053Upload(ctx context.Context, reader graphql.Upload) (*gen.UploadResponse, error) {
+	upload, err := r.camt053Upload(ctx, reader)
+
+	if err != nil {
+		return newUploadResponse(), err
+	}
+
+	return upload(ctx)
+}
+
+func (r *mutationResolver) camt053Upload(ctx context.Context, reader graphql.Upload) (uploadFunc, error) {
+	if !strings.EqualFold(filepath.Ext(reader.Filename), ".xml") {
+		log.Printf("Invalid extension: %s", reader.Filename)
+		return nil, fmt.Errorf("Invalid file extension. .XML required")
+	}
+
+	return r.bankStatementUpload(ctx, reader, db.UploadSourceCAMT053UPLOAD, importer.Camt053Parser)
+}
+
+func (r *mutationResolver) Mt940Upload(ctx context.Context, reader graphql.Upload) (*gen.UploadResponse, error) {
+	upload, err := r.mt940Upload(ctx, reader)
+
+	if err != nil {
+		return newUploadResponse(), err
+	}
+
+	return upload(ctx)
+}
+
+func (r *mutationResolver) mt940Upload(ctx context.Context, reader graphql.Upload) (uploadFunc, error) {
+	switch strings.ToLower(filepath.Ext(reader.Filename)) {
+	case ".sta", ".mt940", ".940", ".txt":
+	default:
+		log.Printf("Invalid extension: %s", reader.Filename)
+		return nil, fmt.Errorf("Invalid file extension. .STA/.MT940/.940/.TXT required")
+	}
+
+	return r.bankStatementUpload(ctx, reader, db.UploadSourceMT940UPLOAD, importer.MT940Parser)
+}
+
+// Bank statements carry their own account details, so like OFX
+// uploads they create or update every account in the file
+func (r *mutationResolver) bankStatementUpload(ctx context.Context, reader graphql.Upload, uploadSource db.UploadSource, parser importer.Parser) (uploadFunc, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	return r.importFile(user.ID, reader, uploadSource, parser)
+}
@@ -0,0 +1,207 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+)
+
+func (r *categoryResolver) Parent(ctx context.Context, category *db.Category) (*db.Category, error) {
+	return r.category(ctx, currentUserId(ctx), category.Parentid)
+}
+
+func (r *categoryResolver) Subcategories(ctx context.Context, category *db.Category) ([]db.Category, error) {
+	categories, err := r.Repository.ListCategories(ctx, currentUserId(ctx))
+
+	if err != nil {
+		return nil, err
+	}
+
+	subcategories := []db.Category{}
+
+	for _, subcategory := range categories {
+		if subcategory.Parentid.Valid && subcategory.Parentid.UUID == category.ID {
+			subcategories = append(subcategories, subcategory)
+		}
+	}
+
+	return subcategories, nil
+}
+
+func (r *categoryResolver) IsDefault(ctx context.Context, category *db.Category) (bool, error) {
+	return !category.Ownerid.Valid, nil
+}
+
+// Queries
+
+// Lists the top level categories, which hold their subcategories
+func (r *queryResolver) Categories(ctx context.Context) ([]db.Category, error) {
+	user := auth.GetCurrentUser(ctx)
+	categories, err := r.Repository.ListCategories(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := []db.Category{}
+
+	for _, category := range categories {
+		if !category.Parentid.Valid {
+			result = append(result, category)
+		}
+	}
+
+	return result, nil
+}
+
+// Mutations
+
+func (r *mutationResolver) CreateCategory(ctx context.Context, data gen.CategoryInput) (*db.Category, error) {
+	user := auth.GetCurrentUser(ctx)
+	name, parentId, err := r.validateCategoryInput(ctx, user.ID, nil, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := r.Repository.CreateCategory(ctx, db.CreateCategoryParams{
+		Name:     name,
+		Parentid: parentId,
+		Ownerid:  user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Category already exists: %s", name)
+	}
+
+	return &category, nil
+}
+
+func (r *mutationResolver) UpdateCategory(ctx context.Context, id uuid.UUID, data gen.CategoryInput) (*db.Category, error) {
+	user := auth.GetCurrentUser(ctx)
+	name, parentId, err := r.validateCategoryInput(ctx, user.ID, &id, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := r.Repository.UpdateCategory(ctx, db.UpdateCategoryParams{
+		ID:       id,
+		Ownerid:  user.ID,
+		Name:     name,
+		Parentid: parentId,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Category not found or already exists: %s", name)
+	}
+
+	return &category, nil
+}
+
+// Deletes one of the user's categories and its subcategories. Their
+// transactions and merchants are left without a category
+func (r *mutationResolver) DeleteCategory(ctx context.Context, id uuid.UUID) (*db.Category, error) {
+	user := auth.GetCurrentUser(ctx)
+	category, err := r.Repository.DeleteCategory(ctx, db.DeleteCategoryParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Category not found")
+	}
+
+	return &category, nil
+}
+
+// Checks a category's name and parent, keeping the tree two levels deep
+func (r *mutationResolver) validateCategoryInput(ctx context.Context, userId uuid.UUID, id *uuid.UUID, data gen.CategoryInput) (string, uuid.NullUUID, error) {
+	name := strings.TrimSpace(data.Name)
+
+	if name == "" {
+		return "", uuid.NullUUID{}, fmt.Errorf("Category name is required")
+	}
+
+	if data.ParentID == nil {
+		return name, uuid.NullUUID{}, nil
+	}
+
+	if id != nil && *id == *data.ParentID {
+		return "", uuid.NullUUID{}, fmt.Errorf("Category can't be its own parent")
+	}
+
+	parent, err := r.Repository.GetCategory(ctx, db.GetCategoryParams{
+		ID:      *data.ParentID,
+		Ownerid: userId,
+	})
+
+	if err != nil {
+		return "", uuid.NullUUID{}, fmt.Errorf("Parent category not found")
+	}
+
+	if parent.Parentid.Valid {
+		return "", uuid.NullUUID{}, fmt.Errorf("Subcategories can't have subcategories")
+	}
+
+	if id != nil {
+		count, err := r.Repository.CountSubcategories(ctx, uuid.NullUUID{UUID: *id, Valid: true})
+
+		if err != nil {
+			return "", uuid.NullUUID{}, err
+		}
+
+		if count > 0 {
+			return "", uuid.NullUUID{}, fmt.Errorf("Categories with subcategories can't be moved into another category")
+		}
+	}
+
+	return name, uuid.NullUUID{UUID: parent.ID, Valid: true}, nil
+}
+
+// Finds a category the user can see, or nil for a null id
+func (r *Resolver) category(ctx context.Context, userId uuid.UUID, categoryId uuid.NullUUID) (*db.Category, error) {
+	if !categoryId.Valid {
+		return nil, nil
+	}
+
+	category, err := r.Repository.GetCategory(ctx, db.GetCategoryParams{
+		ID:      categoryId.UUID,
+		Ownerid: userId,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// Checks that a category id from an input is a default category or one of
+// the user's own. Null ids are valid and clear the category
+func (r *Resolver) userCategoryId(ctx context.Context, userId uuid.UUID, categoryId *uuid.UUID) (uuid.NullUUID, error) {
+	if categoryId == nil {
+		return uuid.NullUUID{}, nil
+	}
+
+	category, err := r.category(ctx, userId, uuid.NullUUID{UUID: *categoryId, Valid: true})
+
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("Category not found")
+	}
+
+	return uuid.NullUUID{UUID: category.ID, Valid: true}, nil
+}
+
+func currentUserId(ctx context.Context) uuid.UUID {
+	if user := auth.GetCurrentUser(ctx); user != nil {
+		return user.ID
+	}
+
+	return uuid.Nil
+}
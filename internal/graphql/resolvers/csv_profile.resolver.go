@@ -0,0 +1,226 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/csvimport"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+func (r *csvProfileResolver) Institution(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Institution), nil
+}
+
+func (r *csvProfileResolver) AmountColumn(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Amountcolumn), nil
+}
+
+func (r *csvProfileResolver) DebitColumn(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Debitcolumn), nil
+}
+
+func (r *csvProfileResolver) CreditColumn(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Creditcolumn), nil
+}
+
+func (r *csvProfileResolver) TypeColumn(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Typecolumn), nil
+}
+
+func (r *csvProfileResolver) CheckNumberColumn(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Checknumbercolumn), nil
+}
+
+func (r *csvProfileResolver) BalanceColumn(ctx context.Context, profile *db.CsvProfile) (*string, error) {
+	return nullStringPtr(profile.Balancecolumn), nil
+}
+
+func (r *csvProfileResolver) SignConvention(ctx context.Context, profile *db.CsvProfile) (string, error) {
+	return string(profile.Signconvention), nil
+}
+
+// Queries
+
+func (r *queryResolver) CSVProfiles(ctx context.Context) ([]db.CsvProfile, error) {
+	user := auth.GetCurrentUser(ctx)
+	profiles, err := r.Repository.ListCsvProfiles(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// Mutations
+
+func (r *mutationResolver) CreateCSVProfile(ctx context.Context, data gen.CSVProfileInput) (*db.CsvProfile, error) {
+	user := auth.GetCurrentUser(ctx)
+	params := parseCsvProfileInput(data)
+
+	if err := csvimport.ValidateProfile(params); err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Repository.CreateCsvProfile(ctx, db.CreateCsvProfileParams{
+		Name:              params.Name,
+		Institution:       params.Institution,
+		Datecolumn:        params.Datecolumn,
+		Descriptioncolumn: params.Descriptioncolumn,
+		Amountcolumn:      params.Amountcolumn,
+		Debitcolumn:       params.Debitcolumn,
+		Creditcolumn:      params.Creditcolumn,
+		Typecolumn:        params.Typecolumn,
+		Checknumbercolumn: params.Checknumbercolumn,
+		Dateformat:        params.Dateformat,
+		Signconvention:    params.Signconvention,
+		Skiprows:          params.Skiprows,
+		Hasheader:         params.Hasheader,
+		Ownerid:           user.ID,
+		Balancecolumn:     params.Balancecolumn,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+func (r *mutationResolver) UpdateCSVProfile(ctx context.Context, id uuid.UUID, data gen.CSVProfileInput) (*db.CsvProfile, error) {
+	user := auth.GetCurrentUser(ctx)
+	params := parseCsvProfileInput(data)
+
+	if err := csvimport.ValidateProfile(params); err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Repository.UpdateCsvProfile(ctx, db.UpdateCsvProfileParams{
+		ID:                id,
+		Ownerid:           user.ID,
+		Name:              params.Name,
+		Institution:       params.Institution,
+		Datecolumn:        params.Datecolumn,
+		Descriptioncolumn: params.Descriptioncolumn,
+		Amountcolumn:      params.Amountcolumn,
+		Debitcolumn:       params.Debitcolumn,
+		Creditcolumn:      params.Creditcolumn,
+		Typecolumn:        params.Typecolumn,
+		Checknumbercolumn: params.Checknumbercolumn,
+		Dateformat:        params.Dateformat,
+		Signconvention:    params.Signconvention,
+		Skiprows:          params.Skiprows,
+		Hasheader:         params.Hasheader,
+		Balancecolumn:     params.Balancecolumn,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+func (r *mutationResolver) DeleteCSVProfile(ctx context.Context, id uuid.UUID) (*db.CsvProfile, error) {
+	user := auth.GetCurrentUser(ctx)
+	profile, err := r.Repository.DeleteCsvProfile(ctx, db.DeleteCsvProfileParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+func (r *mutationResolver) CSVUpload(ctx context.Context, accountId uuid.UUID, profileId uuid.UUID, reader graphql.Upload) (*gen.UploadResponse, error) {
+	upload, err := r.csvUpload(ctx, accountId, profileId, reader)
+
+	if err != nil {
+		return newUploadResponse(), err
+	}
+
+	return upload(ctx)
+}
+
+func (r *mutationResolver) csvUpload(ctx context.Context, accountId uuid.UUID, profileId uuid.UUID, reader graphql.Upload) (uploadFunc, error) {
+	user := auth.GetCurrentUser(ctx)
+	profile, err := r.Repository.GetCsvProfile(ctx, db.GetCsvProfileParams{
+		ID:      profileId,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("CSV profile not found")
+	}
+
+	return r.csvProfileUpload(ctx, accountId, profile, db.UploadSourceCSVUPLOAD, reader)
+}
+
+// Imports a CSV file read with a saved or built-in profile. CSV files do not
+// include account details, so the account must already exist
+func (r *mutationResolver) csvProfileUpload(ctx context.Context, accountId uuid.UUID, profile db.CsvProfile, uploadSource db.UploadSource, reader graphql.Upload) (uploadFunc, error) {
+	if !strings.HasSuffix(strings.ToLower(reader.Filename), ".csv") {
+		log.Printf("Invalid extension: %s", reader.Filename)
+		return nil, fmt.Errorf("Invalid file extension. .CSV required")
+	}
+
+	user := auth.GetCurrentUser(ctx)
+	account, err := r.Repository.GetAccount(ctx, db.GetAccountParams{
+		ID:      accountId,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Account not found")
+	}
+
+	return r.importFile(user.ID, reader, uploadSource, importer.CSVParser(account, profile))
+}
+
+func parseCsvProfileInput(data gen.CSVProfileInput) db.CsvProfile {
+	profile := db.CsvProfile{
+		Name:              data.Name,
+		Institution:       optionalNullString(data.Institution),
+		Datecolumn:        data.DateColumn,
+		Descriptioncolumn: data.DescriptionColumn,
+		Amountcolumn:      optionalNullString(data.AmountColumn),
+		Debitcolumn:       optionalNullString(data.DebitColumn),
+		Creditcolumn:      optionalNullString(data.CreditColumn),
+		Typecolumn:        optionalNullString(data.TypeColumn),
+		Checknumbercolumn: optionalNullString(data.CheckNumberColumn),
+		Balancecolumn:     optionalNullString(data.BalanceColumn),
+		Dateformat:        csvimport.DefaultDateFormat,
+		Signconvention:    db.SignConventionDEBITSNEGATIVE,
+		Skiprows:          0,
+		Hasheader:         true,
+	}
+
+	if data.DateFormat != nil && len(*data.DateFormat) > 0 {
+		profile.Dateformat = *data.DateFormat
+	}
+
+	if data.SignConvention != nil {
+		profile.Signconvention = db.SignConvention(strings.ToUpper(*data.SignConvention))
+	}
+
+	if data.SkipRows != nil {
+		profile.Skiprows = int32(*data.SkipRows)
+	}
+
+	if data.HasHeader != nil {
+		profile.Hasheader = *data.HasHeader
+	}
+
+	return profile
+}
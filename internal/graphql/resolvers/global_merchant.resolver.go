@@ -0,0 +1,121 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/paging"
+)
+
+func (r *globalMerchantResolver) Website(ctx context.Context, merchant *db.GlobalMerchant) (*string, error) {
+	return nullStringPtr(merchant.Website), nil
+}
+
+// Global merchants only use default categories, which have no owner
+func (r *globalMerchantResolver) Category(ctx context.Context, merchant *db.GlobalMerchant) (*db.Category, error) {
+	return r.category(ctx, uuid.Nil, merchant.Categoryid)
+}
+
+// Queries
+
+func (r *queryResolver) GlobalMerchants(ctx context.Context, search *string, first *int) ([]db.GlobalMerchant, error) {
+	params := db.ListGlobalMerchantsParams{
+		Limit: calculatePageLimit(&paging.PageArgs{First: first}),
+	}
+
+	if search != nil {
+		params.Search = strings.TrimSpace(*search)
+	}
+
+	merchants, err := r.Repository.ListGlobalMerchants(ctx, params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return merchants, nil
+}
+
+// Mutations
+
+func (r *mutationResolver) CreateGlobalMerchant(ctx context.Context, data gen.GlobalMerchantInput) (*db.GlobalMerchant, error) {
+	name, aliases, err := parseGlobalMerchantInput(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	categoryId, err := r.userCategoryId(ctx, uuid.Nil, data.CategoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := r.Repository.CreateGlobalMerchant(ctx, db.CreateGlobalMerchantParams{
+		Name:       name,
+		Website:    optionalNullString(data.Website),
+		Categoryid: categoryId,
+		Aliases:    aliases,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Global merchant already exists: %s", name)
+	}
+
+	return &merchant, nil
+}
+
+func (r *mutationResolver) UpdateGlobalMerchant(ctx context.Context, id uuid.UUID, data gen.GlobalMerchantInput) (*db.GlobalMerchant, error) {
+	name, aliases, err := parseGlobalMerchantInput(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	categoryId, err := r.userCategoryId(ctx, uuid.Nil, data.CategoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := r.Repository.UpdateGlobalMerchant(ctx, db.UpdateGlobalMerchantParams{
+		ID:         id,
+		Name:       name,
+		Website:    optionalNullString(data.Website),
+		Categoryid: categoryId,
+		Aliases:    aliases,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Global merchant not found")
+	}
+
+	return &merchant, nil
+}
+
+// Aliases are matched against uppercase merchant names, so they're stored
+// uppercase without repeats
+func parseGlobalMerchantInput(data gen.GlobalMerchantInput) (string, []string, error) {
+	name := strings.TrimSpace(data.Name)
+	aliases := []string{}
+	seen := map[string]bool{}
+
+	if name == "" {
+		return "", nil, fmt.Errorf("Merchant name is required")
+	}
+
+	for _, alias := range data.Aliases {
+		alias = strings.ToUpper(strings.Join(strings.Fields(alias), " "))
+
+		if alias != "" && !seen[alias] {
+			seen[alias] = true
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return name, aliases, nil
+}
@@ -0,0 +1,111 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/paging"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+func (r *importBatchResolver) UploadSource(ctx context.Context, batch *db.ImportBatch) (string, error) {
+	return string(batch.Uploadsource), nil
+}
+
+func (r *importBatchResolver) Status(ctx context.Context, batch *db.ImportBatch) (string, error) {
+	return string(batch.Status), nil
+}
+
+func (r *importBatchResolver) Accounts(ctx context.Context, batch *db.ImportBatch) (*gen.UploadStats, error) {
+	return &gen.UploadStats{
+		Updated: int(batch.Accountsupdated),
+		Failed:  int(batch.Accountsfailed),
+	}, nil
+}
+
+func (r *importBatchResolver) Transactions(ctx context.Context, batch *db.ImportBatch) (*gen.UploadStats, error) {
+	return &gen.UploadStats{
+		Updated: int(batch.Transactionsupdated),
+		Failed:  int(batch.Transactionsfailed),
+	}, nil
+}
+
+func (r *importBatchResolver) Created(ctx context.Context, batch *db.ImportBatch) (string, error) {
+	return batch.Created.Format(time.RFC3339), nil
+}
+
+func (r *importBatchResolver) SyncItems(ctx context.Context, batch *db.ImportBatch) ([]db.AccountSyncItem, error) {
+	return r.Repository.ListImportBatchSyncItems(ctx, uuid.NullUUID{UUID: batch.ID, Valid: true})
+}
+
+// Queries
+
+func (r *queryResolver) ImportBatches(ctx context.Context, first *int) ([]db.ImportBatch, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	return r.Repository.ListImportBatches(ctx, db.ListImportBatchesParams{
+		Ownerid: user.ID,
+		Limit:   calculatePageLimit(&paging.PageArgs{First: first}),
+	})
+}
+
+// Imports a file for a user. Upload mutations build one so the import can
+// run during the request or be queued as a background job
+type uploadFunc func(ctx context.Context) (*gen.UploadResponse, error)
+
+// Reads an uploaded file and returns an uploadFunc that imports it with the
+// import service, which parses and saves the file in a single database
+// transaction and records the result as an import batch
+func (r *mutationResolver) importFile(userId uuid.UUID, reader graphql.Upload, uploadSource db.UploadSource, parser importer.Parser) (uploadFunc, error) {
+	content, err := io.ReadAll(reader.File)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read file: %w", err)
+	}
+
+	return func(ctx context.Context) (*gen.UploadResponse, error) {
+		result, err := r.Importer.ImportFile(ctx, userId, importer.File{
+			Name:         reader.Filename,
+			Content:      content,
+			UploadSource: uploadSource,
+			Parser:       parser,
+		})
+
+		return newImportResponse(result), err
+	}, nil
+}
+
+// Converts an import service result into the upload response
+func newImportResponse(result *importer.Result) *gen.UploadResponse {
+	response := newUploadResponse()
+	response.Success = result.Success
+	response.Duplicate = result.Duplicate
+	response.Accounts.Updated = result.Accounts.Updated
+	response.Accounts.Failed = result.Accounts.Failed
+	response.Transactions.Updated = result.Transactions.Updated
+	response.Transactions.Failed = result.Transactions.Failed
+	response.Errors = result.Errors
+	response.ImportBatch = result.ImportBatch
+
+	for _, statement := range result.Statements {
+		response.AccountStats = append(response.AccountStats, gen.AccountUploadStats{
+			Name:     statement.Name,
+			Account:  statement.Account,
+			SyncItem: statement.SyncItem,
+			Transactions: &gen.UploadStats{
+				Updated: statement.Transactions.Updated,
+				Failed:  statement.Transactions.Failed,
+			},
+			Errors: statement.Errors,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,154 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/jobs"
+)
+
+func (r *importJobResolver) Status(ctx context.Context, job *jobs.Snapshot) (string, error) {
+	return string(job.Status), nil
+}
+
+func (r *importJobResolver) Result(ctx context.Context, job *jobs.Snapshot) (*gen.UploadResponse, error) {
+	response, _ := job.Result.(*gen.UploadResponse)
+	return response, nil
+}
+
+func (r *importJobResolver) Error(ctx context.Context, job *jobs.Snapshot) (*string, error) {
+	if job.Err != nil {
+		message := job.Err.Error()
+		return &message, nil
+	}
+
+	return nil, nil
+}
+
+func (r *importJobResolver) Created(ctx context.Context, job *jobs.Snapshot) (string, error) {
+	return job.Created.Format(time.RFC3339), nil
+}
+
+func (r *importJobResolver) Finished(ctx context.Context, job *jobs.Snapshot) (*string, error) {
+	if !job.Finished.IsZero() {
+		formatted := job.Finished.Format(time.RFC3339)
+		return &formatted, nil
+	}
+
+	return nil, nil
+}
+
+// Queries
+
+func (r *queryResolver) ImportJob(ctx context.Context, id uuid.UUID) (*jobs.Snapshot, error) {
+	user := auth.GetCurrentUser(ctx)
+	job, ok := r.Jobs.Get(id, user.ID)
+
+	if !ok {
+		return nil, fmt.Errorf("Import job not found")
+	}
+
+	snapshot := job.Snapshot()
+	return &snapshot, nil
+}
+
+func (r *queryResolver) ImportJobs(ctx context.Context) ([]jobs.Snapshot, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	return r.Jobs.List(user.ID), nil
+}
+
+// Mutations
+
+func (r *mutationResolver) QueueChaseOFXUpload(ctx context.Context, reader graphql.Upload) (*jobs.Snapshot, error) {
+	upload, err := r.chaseOFXUpload(ctx, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queueUpload(ctx, reader.Filename, upload)
+}
+
+func (r *mutationResolver) QueueChaseCSVUpload(ctx context.Context, accountId uuid.UUID, reader graphql.Upload) (*jobs.Snapshot, error) {
+	upload, err := r.chaseCSVUpload(ctx, accountId, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queueUpload(ctx, reader.Filename, upload)
+}
+
+func (r *mutationResolver) QueueCSVUpload(ctx context.Context, accountId uuid.UUID, profileId uuid.UUID, reader graphql.Upload) (*jobs.Snapshot, error) {
+	upload, err := r.csvUpload(ctx, accountId, profileId, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queueUpload(ctx, reader.Filename, upload)
+}
+
+func (r *mutationResolver) QueueQIFUpload(ctx context.Context, accountId uuid.UUID, reader graphql.Upload) (*jobs.Snapshot, error) {
+	upload, err := r.qifUpload(ctx, accountId, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queueUpload(ctx, reader.Filename, upload)
+}
+
+func (r *mutationResolver) QueueCamt053Upload(ctx context.Context, reader graphql.Upload) (*jobs.Snapshot, error) {
+	upload, err := r.camt053Upload(ctx, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queueUpload(ctx, reader.Filename, upload)
+}
+
+func (r *mutationResolver) QueueMT940Upload(ctx context.Context, reader graphql.Upload) (*jobs.Snapshot, error) {
+	upload, err := r.mt940Upload(ctx, reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queueUpload(ctx, reader.Filename, upload)
+}
+
+// Queues an upload to run on the job pool instead of during the request
+func (r *mutationResolver) queueUpload(ctx context.Context, fileName string, upload uploadFunc) (*jobs.Snapshot, error) {
+	user := auth.GetCurrentUser(ctx)
+	job, err := r.Jobs.Submit(user.ID, fileName, func(ctx context.Context) (interface{}, error) {
+		return upload(ctx)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := job.Snapshot()
+	return &snapshot, nil
+}
+
+// Subscriptions
+
+func (r *subscriptionResolver) ImportJobProgress(ctx context.Context, id uuid.UUID) (<-chan *jobs.Event, error) {
+	user := auth.GetCurrentUser(ctx)
+	job, ok := r.Jobs.Get(id, user.ID)
+
+	if !ok {
+		return nil, fmt.Errorf("Import job not found")
+	}
+
+	return job.Subscribe(ctx), nil
+}
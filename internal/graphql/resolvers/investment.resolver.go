@@ -0,0 +1,117 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+func (r *accountResolver) Holdings(ctx context.Context, account *db.Account, date *string) ([]db.Holding, error) {
+	asOf := time.Now()
+
+	if date != nil {
+		parsed, err := time.Parse(time.RFC3339, *date)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid date format. RFC3339 required")
+		}
+
+		asOf = parsed
+	}
+
+	return r.Repository.ListAccountHoldings(ctx, db.ListAccountHoldingsParams{
+		Accountid: account.ID,
+		Asof:      asOf,
+	})
+}
+
+func (r *accountResolver) InvestmentTransactions(ctx context.Context, account *db.Account, filter *gen.DateFilter) ([]db.InvestmentTransaction, error) {
+	endDate := time.Now()
+	startDate := endDate.AddDate(-1, 0, 0)
+
+	if filter != nil {
+		statsFilter, err := parseStatsFilter(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		startDate = statsFilter.StartDate
+		endDate = statsFilter.EndDate
+	}
+
+	return r.Repository.ListAccountInvestmentTransactions(ctx, db.ListAccountInvestmentTransactionsParams{
+		Accountid: account.ID,
+		Startdate: startDate,
+		Enddate:   endDate,
+	})
+}
+
+func (r *securityResolver) Ticker(ctx context.Context, security *db.Security) (*string, error) {
+	return nullStringPtr(security.Ticker), nil
+}
+
+func (r *securityResolver) Type(ctx context.Context, security *db.Security) (string, error) {
+	return string(security.Type), nil
+}
+
+func (r *holdingResolver) Date(ctx context.Context, holding *db.Holding) (string, error) {
+	return holding.Date.Format(time.RFC3339), nil
+}
+
+func (r *holdingResolver) Security(ctx context.Context, holding *db.Holding) (*db.Security, error) {
+	security, err := r.Repository.GetSecurity(ctx, holding.Securityid)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &security, nil
+}
+
+func (r *holdingResolver) MarketValue(ctx context.Context, holding *db.Holding) (float64, error) {
+	return utils.FormatCurrencyFloat64(holding.Marketvalue), nil
+}
+
+func (r *investmentTransactionResolver) Type(ctx context.Context, transaction *db.InvestmentTransaction) (string, error) {
+	return string(transaction.Type), nil
+}
+
+func (r *investmentTransactionResolver) TradeDate(ctx context.Context, transaction *db.InvestmentTransaction) (string, error) {
+	return transaction.Tradedate.Format(time.RFC3339), nil
+}
+
+func (r *investmentTransactionResolver) SettleDate(ctx context.Context, transaction *db.InvestmentTransaction) (*string, error) {
+	if !transaction.Settledate.Valid {
+		return nil, nil
+	}
+
+	settleDate := transaction.Settledate.Time.Format(time.RFC3339)
+	return &settleDate, nil
+}
+
+func (r *investmentTransactionResolver) Security(ctx context.Context, transaction *db.InvestmentTransaction) (*db.Security, error) {
+	if !transaction.Securityid.Valid {
+		return nil, nil
+	}
+
+	security, err := r.Repository.GetSecurity(ctx, transaction.Securityid.UUID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &security, nil
+}
+
+func (r *investmentTransactionResolver) Amount(ctx context.Context, transaction *db.InvestmentTransaction) (float64, error) {
+	return utils.FormatCurrencyFloat64(transaction.Amount), nil
+}
+
+func (r *investmentTransactionResolver) Fees(ctx context.Context, transaction *db.InvestmentTransaction) (float64, error) {
+	return utils.FormatCurrencyFloat64(transaction.Fees), nil
+}
@@ -0,0 +1,124 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+func (r *manualAssetResolver) Type(ctx context.Context, asset *db.ManualAsset) (string, error) {
+	return string(asset.Type), nil
+}
+
+func (r *manualAssetResolver) CurrentValue(ctx context.Context, asset *db.ManualAsset) (*float64, error) {
+	valuations, err := r.Repository.ListManualAssetValuations(ctx, asset.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(valuations) == 0 {
+		return nil, nil
+	}
+
+	// Valuations are ordered newest first
+	value := utils.FormatCurrencyFloat64(valuations[0].Value)
+	return &value, nil
+}
+
+func (r *manualAssetResolver) Valuations(ctx context.Context, asset *db.ManualAsset) ([]db.ManualAssetValuation, error) {
+	return r.Repository.ListManualAssetValuations(ctx, asset.ID)
+}
+
+func (r *manualAssetValuationResolver) Date(ctx context.Context, valuation *db.ManualAssetValuation) (string, error) {
+	return valuation.Date.Format(time.RFC3339), nil
+}
+
+func (r *manualAssetValuationResolver) Value(ctx context.Context, valuation *db.ManualAssetValuation) (float64, error) {
+	return utils.FormatCurrencyFloat64(valuation.Value), nil
+}
+
+// Queries
+
+func (r *queryResolver) ManualAssets(ctx context.Context) ([]db.ManualAsset, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	return r.Repository.ListManualAssets(ctx, user.ID)
+}
+
+// Mutations
+
+func (r *mutationResolver) CreateManualAsset(ctx context.Context, data gen.ManualAssetInput) (*db.ManualAsset, error) {
+	user := auth.GetCurrentUser(ctx)
+	assetType := db.ManualAssetType(strings.ToUpper(data.Type))
+
+	if assetType != db.ManualAssetTypeASSET && assetType != db.ManualAssetTypeLIABILITY {
+		return nil, fmt.Errorf("Invalid type. ASSET or LIABILITY required")
+	}
+
+	date, err := time.Parse(time.RFC3339, data.Date)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid date format. RFC3339 required")
+	}
+
+	return r.Repository.AddManualAsset(ctx, db.AddManualAssetParams{
+		Name:   data.Name,
+		Type:   assetType,
+		Value:  utils.FormatCurrencyInt(float32(data.Value)),
+		Date:   date,
+		UserId: user.ID,
+	})
+}
+
+func (r *mutationResolver) AddManualAssetValuation(ctx context.Context, data gen.ManualAssetValuationInput) (*db.ManualAssetValuation, error) {
+	user := auth.GetCurrentUser(ctx)
+	asset, err := r.Repository.GetManualAsset(ctx, db.GetManualAssetParams{
+		ID:      data.AssetID,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Asset not found")
+	}
+
+	date, err := time.Parse(time.RFC3339, data.Date)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid date format. RFC3339 required")
+	}
+
+	valuation, err := r.Repository.UpsertManualAssetValuation(ctx, db.UpsertManualAssetValuationParams{
+		Assetid: asset.ID,
+		Date:    date,
+		Value:   utils.FormatCurrencyInt(float32(data.Value)),
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &valuation, nil
+}
+
+func (r *mutationResolver) DeleteManualAsset(ctx context.Context, id uuid.UUID) (*db.ManualAsset, error) {
+	user := auth.GetCurrentUser(ctx)
+	asset, err := r.Repository.DeleteManualAsset(ctx, db.DeleteManualAssetParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
@@ -2,12 +2,16 @@ package resolvers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/proctorinc/banker/internal/auth"
 	"github.com/proctorinc/banker/internal/db"
 	gen "github.com/proctorinc/banker/internal/graphql/generated"
 	"github.com/proctorinc/banker/internal/graphql/paging"
+	"github.com/proctorinc/banker/internal/importer"
 )
 
 func (r *merchantResolver) ID(ctx context.Context, merchant *db.Merchant) (uuid.UUID, error) {
@@ -30,8 +34,44 @@ func (r *merchantResolver) OwnerId(ctx context.Context, merchant *db.Merchant) (
 	return merchant.Ownerid.String(), nil
 }
 
-func (r *merchantResolver) Transactions(ctx context.Context, merchant *db.Merchant, page *paging.PageArgs) (*gen.TransactionConnection, error) {
-	totalCount, err := r.DataLoaders.Retrieve(ctx).CountTransactionsByMerchantId.Load(merchant.ID.String())
+func (r *merchantResolver) Website(ctx context.Context, merchant *db.Merchant) (*string, error) {
+	if merchant.Website.Valid {
+		return &merchant.Website.String, nil
+	}
+
+	global, err := r.globalMerchant(ctx, merchant)
+
+	if err != nil || global == nil {
+		return nil, err
+	}
+
+	return nullStringPtr(global.Website), nil
+}
+
+func (r *merchantResolver) Category(ctx context.Context, merchant *db.Merchant) (*db.Category, error) {
+	return r.category(ctx, merchant.Ownerid, merchant.Categoryid)
+}
+
+func (r *merchantResolver) GlobalMerchant(ctx context.Context, merchant *db.Merchant) (*db.GlobalMerchant, error) {
+	return r.globalMerchant(ctx, merchant)
+}
+
+func (r *merchantResolver) globalMerchant(ctx context.Context, merchant *db.Merchant) (*db.GlobalMerchant, error) {
+	if !merchant.Globalmerchantid.Valid {
+		return nil, nil
+	}
+
+	global, err := r.DataLoaders.Retrieve(ctx).GlobalMerchantById.Load(merchant.Globalmerchantid.UUID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &global, nil
+}
+
+func (r *merchantResolver) Transactions(ctx context.Context, merchant *db.Merchant, page *paging.PageArgs, includePending *bool) (*gen.TransactionConnection, error) {
+	totalCount, err := r.DataLoaders.Retrieve(ctx).CountTransactionsByMerchantId(withPending(includePending)).Load(merchant.ID.String())
 
 	if err != nil {
 		return &gen.TransactionConnection{
@@ -46,7 +86,7 @@ func (r *merchantResolver) Transactions(ctx context.Context, merchant *db.Mercha
 	start := int32(paginator.Offset)
 	limit := calculatePageLimit(page)
 
-	transactions, err := r.DataLoaders.Retrieve(ctx).TransactionsByMerchantId(int32(limit), start).Load(merchant.ID.String())
+	transactions, err := r.DataLoaders.Retrieve(ctx).TransactionsByMerchantId(int32(limit), start, withPending(includePending)).Load(merchant.ID.String())
 
 	for i, row := range transactions {
 		result.Edges = append(result.Edges, gen.TransactionEdge{
@@ -105,3 +145,178 @@ func (r *queryResolver) Merchants(ctx context.Context, page *paging.PageArgs) (*
 
 	return result, err
 }
+
+// Mutations
+
+func (r *mutationResolver) RenameMerchant(ctx context.Context, id uuid.UUID, name string) (*db.Merchant, error) {
+	user := auth.GetCurrentUser(ctx)
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return nil, fmt.Errorf("Merchant name is required")
+	}
+
+	merchant, err := r.Repository.RenameMerchant(ctx, db.RenameMerchantParams{
+		ID:      id,
+		Ownerid: user.ID,
+		Name:    name,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Merchant not found")
+	}
+
+	return &merchant, nil
+}
+
+// Sets the merchant's global merchant, default category and its own website,
+// which overrides the global merchant's
+func (r *mutationResolver) UpdateMerchant(ctx context.Context, id uuid.UUID, data gen.MerchantInput) (*db.Merchant, error) {
+	user := auth.GetCurrentUser(ctx)
+	categoryId, err := r.userCategoryId(ctx, user.ID, data.CategoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	params := db.UpdateMerchantOverridesParams{
+		ID:         id,
+		Ownerid:    user.ID,
+		Website:    optionalNullString(data.Website),
+		Categoryid: categoryId,
+	}
+
+	if data.GlobalMerchantID != nil {
+		global, err := r.Repository.GetGlobalMerchant(ctx, *data.GlobalMerchantID)
+
+		if err != nil {
+			return nil, fmt.Errorf("Global merchant not found")
+		}
+
+		params.Globalmerchantid = uuid.NullUUID{UUID: global.ID, Valid: true}
+
+		if !params.Categoryid.Valid {
+			params.Categoryid = global.Categoryid
+		}
+	}
+
+	merchant, err := r.Repository.UpdateMerchantOverrides(ctx, params)
+
+	if err != nil {
+		return nil, fmt.Errorf("Merchant not found")
+	}
+
+	return &merchant, nil
+}
+
+// Merges duplicate merchants into the target, which keeps its name
+func (r *mutationResolver) MergeMerchants(ctx context.Context, sourceIds []uuid.UUID, targetId uuid.UUID) (*db.Merchant, error) {
+	user := auth.GetCurrentUser(ctx)
+	ids := uniqueIds(sourceIds)
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Select merchants to merge")
+	}
+
+	for _, id := range ids {
+		if id == targetId {
+			return nil, fmt.Errorf("Merchant can't be merged into itself")
+		}
+	}
+
+	merchant, err := r.Repository.MergeMerchants(ctx, db.MergeMerchantsParams{
+		SourceIds: ids,
+		TargetId:  targetId,
+		UserId:    user.ID,
+	})
+
+	if errors.Is(err, db.ErrMerchantNotFound) {
+		return nil, fmt.Errorf("Merchant not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchant, nil
+}
+
+// Moves transactions to a new merchant and learns a key from their
+// descriptions so later imports of them are linked to the new merchant
+func (r *mutationResolver) SplitMerchant(ctx context.Context, id uuid.UUID, transactionIds []uuid.UUID, name string) (*db.Merchant, error) {
+	user := auth.GetCurrentUser(ctx)
+	ids := uniqueIds(transactionIds)
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return nil, fmt.Errorf("Merchant name is required")
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Select transactions to move")
+	}
+
+	idStrings := make([]string, len(ids))
+
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+
+	rows, err := r.Repository.ListMerchantTransactionsByIds(ctx, db.ListMerchantTransactionsByIdsParams{
+		Ownerid:        user.ID,
+		Merchantid:     id,
+		Transactionids: idStrings,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) != len(ids) {
+		return nil, fmt.Errorf("Transaction not found for merchant")
+	}
+
+	descriptions := make([]string, len(rows))
+	sources := map[db.UploadSource]int{}
+	params := db.SplitMerchantParams{
+		MerchantId:     id,
+		TransactionIds: ids,
+		Name:           name,
+		UserId:         user.ID,
+	}
+
+	for i, row := range rows {
+		descriptions[i] = row.Transaction.Description
+
+		if row.Uploadsource.Valid {
+			sources[row.Uploadsource.UploadSource]++
+		}
+	}
+
+	// Keys belong to one upload source, so learn it for the most common one
+	for source, count := range sources {
+		if count > sources[params.UploadSource] {
+			params.UploadSource = source
+		}
+	}
+
+	if keyMatch, ok := importer.LearnMerchantKey(descriptions); ok && params.UploadSource != "" {
+		params.KeyMatch = keyMatch
+	}
+
+	merchant, err := r.Repository.SplitMerchant(ctx, params)
+
+	if errors.Is(err, db.ErrMerchantNotFound) {
+		return nil, fmt.Errorf("Merchant not found")
+	}
+
+	if errors.Is(err, db.ErrTransactionNotFound) {
+		return nil, fmt.Errorf("Transaction not found for merchant")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchant, nil
+}
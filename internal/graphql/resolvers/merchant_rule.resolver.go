@@ -0,0 +1,260 @@
+package resolvers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/paging"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/merchantrules"
+)
+
+func (r *merchantRuleResolver) Type(ctx context.Context, rule *db.MerchantRule) (string, error) {
+	return string(rule.Type), nil
+}
+
+func (r *merchantRuleResolver) MinAmount(ctx context.Context, rule *db.MerchantRule) (*float64, error) {
+	if rule.Minamount.Valid {
+		amount := utils.FormatCurrencyFloat64(rule.Minamount.Int32)
+		return &amount, nil
+	}
+
+	return nil, nil
+}
+
+func (r *merchantRuleResolver) MaxAmount(ctx context.Context, rule *db.MerchantRule) (*float64, error) {
+	if rule.Maxamount.Valid {
+		amount := utils.FormatCurrencyFloat64(rule.Maxamount.Int32)
+		return &amount, nil
+	}
+
+	return nil, nil
+}
+
+func (r *merchantRuleResolver) UploadSource(ctx context.Context, rule *db.MerchantRule) (*string, error) {
+	if rule.Uploadsource.Valid {
+		uploadSource := string(rule.Uploadsource.UploadSource)
+		return &uploadSource, nil
+	}
+
+	return nil, nil
+}
+
+func (r *merchantRuleResolver) Merchant(ctx context.Context, rule *db.MerchantRule) (*db.Merchant, error) {
+	merchant, err := r.Repository.GetMerchant(ctx, db.GetMerchantParams{
+		ID:      rule.Merchantid,
+		Ownerid: rule.Ownerid,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &merchant, nil
+}
+
+func (r *merchantRuleResolver) Created(ctx context.Context, rule *db.MerchantRule) (string, error) {
+	return rule.Created.Format(time.RFC3339), nil
+}
+
+// Queries
+
+func (r *queryResolver) MerchantRules(ctx context.Context) ([]db.MerchantRule, error) {
+	user := auth.GetCurrentUser(ctx)
+	rules, err := r.Repository.ListMerchantRules(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Runs a rule against every transaction the user has imported without
+// saving it, so a rule can be checked before it changes future imports
+func (r *queryResolver) TestMerchantRule(ctx context.Context, data gen.MerchantRuleInput, first *int) (*gen.MerchantRuleTest, error) {
+	user := auth.GetCurrentUser(ctx)
+	rule, err := parseMerchantRuleInput(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compiled, err := merchantrules.Compile(rule)
+
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := r.Repository.ListMerchantRuleCandidates(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(calculatePageLimit(&paging.PageArgs{First: first}))
+	result := &gen.MerchantRuleTest{
+		Transactions: []db.Transaction{},
+	}
+
+	for _, candidate := range candidates {
+		matched := compiled.Matches(merchantrules.Transaction{
+			Description:  candidate.Transaction.Description,
+			Amount:       candidate.Transaction.Amount,
+			UploadSource: candidate.Uploadsource,
+		})
+
+		if !matched {
+			continue
+		}
+
+		result.MatchCount++
+
+		if candidate.Transaction.Merchantid != rule.Merchantid {
+			result.ChangedCount++
+		}
+
+		if len(result.Transactions) < limit {
+			result.Transactions = append(result.Transactions, candidate.Transaction)
+		}
+	}
+
+	return result, nil
+}
+
+// Mutations
+
+func (r *mutationResolver) CreateMerchantRule(ctx context.Context, data gen.MerchantRuleInput) (*db.MerchantRule, error) {
+	user := auth.GetCurrentUser(ctx)
+	params, err := r.validateMerchantRuleInput(ctx, user.ID, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := r.Repository.CreateMerchantRule(ctx, db.CreateMerchantRuleParams{
+		Priority:     params.Priority,
+		Type:         params.Type,
+		Pattern:      params.Pattern,
+		Minamount:    params.Minamount,
+		Maxamount:    params.Maxamount,
+		Uploadsource: params.Uploadsource,
+		Merchantid:   params.Merchantid,
+		Ownerid:      user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *mutationResolver) UpdateMerchantRule(ctx context.Context, id uuid.UUID, data gen.MerchantRuleInput) (*db.MerchantRule, error) {
+	user := auth.GetCurrentUser(ctx)
+	params, err := r.validateMerchantRuleInput(ctx, user.ID, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := r.Repository.UpdateMerchantRule(ctx, db.UpdateMerchantRuleParams{
+		ID:           id,
+		Ownerid:      user.ID,
+		Priority:     params.Priority,
+		Type:         params.Type,
+		Pattern:      params.Pattern,
+		Minamount:    params.Minamount,
+		Maxamount:    params.Maxamount,
+		Uploadsource: params.Uploadsource,
+		Merchantid:   params.Merchantid,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func (r *mutationResolver) DeleteMerchantRule(ctx context.Context, id uuid.UUID) (*db.MerchantRule, error) {
+	user := auth.GetCurrentUser(ctx)
+	rule, err := r.Repository.DeleteMerchantRule(ctx, db.DeleteMerchantRuleParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// Parses and validates a rule, checking that its merchant belongs to the user
+func (r *mutationResolver) validateMerchantRuleInput(ctx context.Context, userId uuid.UUID, data gen.MerchantRuleInput) (db.MerchantRule, error) {
+	rule, err := parseMerchantRuleInput(data)
+
+	if err != nil {
+		return rule, err
+	}
+
+	if err := merchantrules.Validate(rule); err != nil {
+		return rule, err
+	}
+
+	_, err = r.Repository.GetMerchant(ctx, db.GetMerchantParams{
+		ID:      rule.Merchantid,
+		Ownerid: userId,
+	})
+
+	if err != nil {
+		return rule, fmt.Errorf("Merchant not found")
+	}
+
+	return rule, nil
+}
+
+func parseMerchantRuleInput(data gen.MerchantRuleInput) (db.MerchantRule, error) {
+	rule := db.MerchantRule{
+		Type:       db.MerchantRuleType(strings.ToUpper(data.Type)),
+		Minamount:  optionalCurrency(data.MinAmount),
+		Maxamount:  optionalCurrency(data.MaxAmount),
+		Merchantid: data.MerchantID,
+	}
+
+	if data.Priority != nil {
+		rule.Priority = int32(*data.Priority)
+	}
+
+	if data.Pattern != nil {
+		rule.Pattern = strings.TrimSpace(*data.Pattern)
+	}
+
+	if data.UploadSource != nil && len(*data.UploadSource) > 0 {
+		uploadSource := db.UploadSource(strings.ToUpper(*data.UploadSource))
+
+		if !uploadSource.Valid() {
+			return rule, fmt.Errorf("Invalid upload source: %s", *data.UploadSource)
+		}
+
+		rule.Uploadsource = db.NullUploadSource{UploadSource: uploadSource, Valid: true}
+	}
+
+	return rule, nil
+}
+
+func optionalCurrency(amount *float64) sql.NullInt32 {
+	if amount == nil {
+		return sql.NullInt32{}
+	}
+
+	return sql.NullInt32{Int32: utils.FormatCurrencyInt(float32(*amount)), Valid: true}
+}
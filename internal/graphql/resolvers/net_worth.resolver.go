@@ -0,0 +1,129 @@
+package resolvers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+// Account types that represent money owed rather than money held
+var liabilityAccountTypes = map[db.AccountType]bool{
+	db.AccountTypeCREDIT:     true,
+	db.AccountTypeCREDITLINE: true,
+}
+
+// Queries
+
+func (r *queryResolver) NetWorth(ctx context.Context, filter *gen.DateFilter) (*gen.NetWorthStats, error) {
+	user := auth.GetCurrentUser(ctx)
+	endDate := time.Now()
+	startDate := endDate.AddDate(-1, 0, 0)
+
+	if filter != nil {
+		statsFilter, err := parseStatsFilter(filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		startDate = statsFilter.StartDate
+		endDate = statsFilter.EndDate
+	}
+
+	balances, err := r.Repository.ListNetWorthAccountBalances(ctx, db.ListNetWorthAccountBalancesParams{
+		Ownerid: user.ID,
+		Enddate: endDate,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	valuations, err := r.Repository.ListNetWorthAssetValuations(ctx, db.ListNetWorthAssetValuationsParams{
+		Ownerid: user.ID,
+		Enddate: endDate,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &gen.NetWorthStats{
+		History: []gen.NetWorthPoint{},
+	}
+
+	// Balances and valuations are ordered by date, so the latest
+	// value for each account or asset is carried forward to each point
+	latestBalances := map[uuid.UUID]db.ListNetWorthAccountBalancesRow{}
+	latestValuations := map[uuid.UUID]db.ListNetWorthAssetValuationsRow{}
+	balanceIndex := 0
+	valuationIndex := 0
+
+	for _, date := range netWorthDates(startDate, endDate) {
+		for ; balanceIndex < len(balances) && !balances[balanceIndex].Date.After(date); balanceIndex++ {
+			latestBalances[balances[balanceIndex].Accountid] = balances[balanceIndex]
+		}
+
+		for ; valuationIndex < len(valuations) && !valuations[valuationIndex].Date.After(date); valuationIndex++ {
+			latestValuations[valuations[valuationIndex].Assetid] = valuations[valuationIndex]
+		}
+
+		var assets, liabilities int64
+
+		for _, balance := range latestBalances {
+			// Credit balances are negative when owed, so an overpaid
+			// card reduces liabilities
+			if liabilityAccountTypes[balance.Accounttype] {
+				liabilities -= int64(balance.Current)
+			} else {
+				assets += int64(balance.Current)
+			}
+		}
+
+		for _, valuation := range latestValuations {
+			if valuation.Assettype == db.ManualAssetTypeLIABILITY {
+				liabilities += int64(abs(valuation.Value))
+			} else {
+				assets += int64(valuation.Value)
+			}
+		}
+
+		result.Assets = utils.FormatCurrencyFloat64FromInt64(assets)
+		result.Liabilities = utils.FormatCurrencyFloat64FromInt64(liabilities)
+		result.Net = utils.FormatCurrencyFloat64FromInt64(assets - liabilities)
+		result.History = append(result.History, gen.NetWorthPoint{
+			Date:        date.Format(time.RFC3339),
+			Assets:      result.Assets,
+			Liabilities: result.Liabilities,
+			Net:         result.Net,
+		})
+	}
+
+	return result, nil
+}
+
+// Returns the last day of each month in the range, ending on the end date
+func netWorthDates(startDate time.Time, endDate time.Time) []time.Time {
+	dates := []time.Time{}
+	monthEnd := time.Date(startDate.Year(), startDate.Month()+1, 0, 0, 0, 0, 0, startDate.Location())
+
+	for monthEnd.Before(endDate) {
+		dates = append(dates, monthEnd)
+		monthEnd = time.Date(monthEnd.Year(), monthEnd.Month()+2, 0, 0, 0, 0, 0, monthEnd.Location())
+	}
+
+	return append(dates, endDate)
+}
+
+func abs(value int32) int32 {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,286 @@
+package resolvers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/directconnect"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+var errDirectConnectDisabled = fmt.Errorf("OFX Direct Connect is not configured")
+
+func (r *oFXConnectionResolver) Account(ctx context.Context, connection *db.OfxConnection) (*db.Account, error) {
+	account, err := r.Repository.GetAccount(ctx, db.GetAccountParams{
+		ID:      connection.Accountid,
+		Ownerid: connection.Ownerid,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *oFXConnectionResolver) LastAttempted(ctx context.Context, connection *db.OfxConnection) (*string, error) {
+	if !connection.Lastattempted.Valid {
+		return nil, nil
+	}
+
+	lastAttempted := connection.Lastattempted.Time.Format(time.RFC3339)
+	return &lastAttempted, nil
+}
+
+func (r *oFXConnectionResolver) LastSynced(ctx context.Context, connection *db.OfxConnection) (*string, error) {
+	if !connection.Lastsynced.Valid {
+		return nil, nil
+	}
+
+	lastSynced := connection.Lastsynced.Time.Format(time.RFC3339)
+	return &lastSynced, nil
+}
+
+func (r *oFXConnectionResolver) LastError(ctx context.Context, connection *db.OfxConnection) (*string, error) {
+	if !connection.Lasterror.Valid {
+		return nil, nil
+	}
+
+	return &connection.Lasterror.String, nil
+}
+
+func (r *oFXConnectionResolver) Created(ctx context.Context, connection *db.OfxConnection) (string, error) {
+	return connection.Created.Format(time.RFC3339), nil
+}
+
+// Queries
+
+func (r *queryResolver) OfxConnections(ctx context.Context) ([]db.OfxConnection, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	return r.Repository.ListOfxConnections(ctx, user.ID)
+}
+
+// Mutations
+
+func (r *mutationResolver) SaveOFXConnection(ctx context.Context, data gen.OFXConnectionInput) (*db.OfxConnection, error) {
+	if r.Secrets == nil {
+		return nil, errDirectConnectDisabled
+	}
+
+	user := auth.GetCurrentUser(ctx)
+	account, err := r.Repository.GetAccount(ctx, db.GetAccountParams{
+		ID:      data.AccountID,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Account not found")
+	}
+
+	institution := directconnect.Institution{
+		URL:        data.URL,
+		Org:        data.Org,
+		Fid:        data.Fid,
+		AppId:      valueOrDefault(data.AppID, directconnect.DefaultAppId),
+		AppVersion: valueOrDefault(data.AppVersion, directconnect.DefaultAppVersion),
+		OfxVersion: valueOrDefault(data.OfxVersion, directconnect.DefaultOfxVersion),
+	}
+
+	if err := directconnect.Validate(institution); err != nil {
+		return nil, err
+	}
+
+	if data.Username == "" || data.Password == "" {
+		return nil, fmt.Errorf("Username and password are required")
+	}
+
+	credentials, err := json.Marshal(directconnect.Credentials{
+		Username: data.Username,
+		Password: data.Password,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := r.Secrets.Encrypt(credentials)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := r.Repository.UpsertOfxConnection(ctx, db.UpsertOfxConnectionParams{
+		Url:         institution.URL,
+		Org:         institution.Org,
+		Fid:         institution.Fid,
+		Appid:       institution.AppId,
+		Appversion:  institution.AppVersion,
+		Ofxversion:  institution.OfxVersion,
+		Credentials: encrypted,
+		Accountid:   account.ID,
+		Ownerid:     user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection, nil
+}
+
+func (r *mutationResolver) DeleteOFXConnection(ctx context.Context, id uuid.UUID) (*db.OfxConnection, error) {
+	user := auth.GetCurrentUser(ctx)
+	connection, err := r.Repository.DeleteOfxConnection(ctx, db.DeleteOfxConnectionParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &connection, nil
+}
+
+func (r *mutationResolver) SyncOFXConnection(ctx context.Context, id uuid.UUID) (*gen.UploadResponse, error) {
+	user := auth.GetCurrentUser(ctx)
+	connection, err := r.Repository.GetOfxConnection(ctx, db.GetOfxConnectionParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return newUploadResponse(), fmt.Errorf("OFX connection not found")
+	}
+
+	return r.syncOFXConnection(ctx, connection)
+}
+
+// SyncOFXConnection is used by the Direct Connect scheduler, which syncs
+// connections outside of a request
+func (r *Resolver) SyncOFXConnection(ctx context.Context, connection db.OfxConnection) error {
+	_, err := (&mutationResolver{r}).syncOFXConnection(ctx, connection)
+	return err
+}
+
+// Downloads and saves a connection's statement, recording the attempt and
+// any error on the connection
+func (r *mutationResolver) syncOFXConnection(ctx context.Context, connection db.OfxConnection) (*gen.UploadResponse, error) {
+	response, err := r.downloadOFXConnection(ctx, connection)
+
+	if err != nil {
+		updateErr := r.Repository.UpdateOfxConnectionFailed(ctx, db.UpdateOfxConnectionFailedParams{
+			ID:        connection.ID,
+			Lasterror: sql.NullString{String: err.Error(), Valid: true},
+		})
+
+		if updateErr != nil {
+			log.Printf("Failed to record OFX connection error: %v", updateErr)
+		}
+
+		return response, err
+	}
+
+	return response, r.Repository.UpdateOfxConnectionSynced(ctx, connection.ID)
+}
+
+func (r *mutationResolver) downloadOFXConnection(ctx context.Context, connection db.OfxConnection) (*gen.UploadResponse, error) {
+	response := newUploadResponse()
+
+	if r.Secrets == nil || r.DirectConnect == nil {
+		return response, errDirectConnectDisabled
+	}
+
+	account, err := r.Repository.GetAccount(ctx, db.GetAccountParams{
+		ID:      connection.Accountid,
+		Ownerid: connection.Ownerid,
+	})
+
+	if err != nil {
+		return response, err
+	}
+
+	plaintext, err := r.Secrets.Decrypt(connection.Credentials)
+
+	if err != nil {
+		return response, err
+	}
+
+	var credentials directconnect.Credentials
+
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return response, fmt.Errorf("Invalid OFX credentials")
+	}
+
+	// Download everything since the account was last synced by any source
+	lastSync, err := r.Repository.GetLastSync(ctx, account.ID)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return response, err
+	}
+
+	now := time.Now()
+	institution := directconnect.Institution{
+		URL:        connection.Url,
+		Org:        connection.Org,
+		Fid:        connection.Fid,
+		AppId:      connection.Appid,
+		AppVersion: connection.Appversion,
+		OfxVersion: connection.Ofxversion,
+	}
+	statements, err := r.DirectConnect.Download(ctx, institution, credentials, directconnect.Account{
+		BankId:    account.Routingnumber.String,
+		AccountId: account.Sourceid,
+		Type:      account.Type,
+	}, directconnect.StatementStart(lastSync.Date, now), now)
+
+	if err != nil {
+		return response, err
+	}
+
+	// Only the connection's account is saved, even if the server returns others
+	var accountStatements []chase.ChaseOFXResult
+
+	for _, statement := range statements {
+		if statement.Account.AccountId == account.Sourceid {
+			accountStatements = append(accountStatements, statement)
+		}
+	}
+
+	if len(accountStatements) == 0 {
+		return response, fmt.Errorf("OFX server returned no statement for the account")
+	}
+
+	err = r.Repository.WithTx(ctx, func(repo db.Repository) error {
+		result, err := r.Importer.Import(ctx, repo, connection.Ownerid, importer.FromChaseOFX(accountStatements), db.UploadSourceOFXDIRECTCONNECT)
+		response = newImportResponse(result)
+		return err
+	})
+
+	if err != nil {
+		return response, err
+	}
+
+	response.Success = true
+
+	return response, nil
+}
+
+func valueOrDefault(value *string, defaultValue string) string {
+	if value == nil || *value == "" {
+		return defaultValue
+	}
+
+	return *value
+}
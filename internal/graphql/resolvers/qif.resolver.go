@@ -0,0 +1,153 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+// Queries
+
+func (r *queryResolver) ExportQif(ctx context.Context, accountId uuid.UUID) (string, error) {
+	user := auth.GetCurrentUser(ctx)
+	account, err := r.Repository.GetAccount(ctx, db.GetAccountParams{
+		ID:      accountId,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("Account not found")
+	}
+
+	transactions, err := r.Repository.ListAccountTransactions(ctx, db.ListAccountTransactionsParams{
+		Accountid: account.ID,
+		Ownerid:   user.ID,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	categories, err := r.Repository.ListCategories(ctx, user.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	transactionIds := make([]string, len(transactions))
+
+	for i, tx := range transactions {
+		transactionIds[i] = tx.ID.String()
+	}
+
+	splits, err := r.Repository.ListTransactionSplitsByTransactionIds(ctx, db.ListTransactionSplitsByTransactionIdsParams{
+		Ownerid:        user.ID,
+		Transactionids: transactionIds,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	splitsByTransactionId := map[uuid.UUID][]db.TransactionSplit{}
+
+	for _, split := range splits {
+		splitsByTransactionId[split.Transactionid] = append(splitsByTransactionId[split.Transactionid], split)
+	}
+
+	categoryPaths := importer.CategoryPaths(categories)
+	qifTransactions := make([]chase.QIFTransaction, len(transactions))
+
+	for i, tx := range transactions {
+		qifTransactions[i] = newQIFTransaction(tx, splitsByTransactionId[tx.ID], categoryPaths)
+	}
+
+	var export strings.Builder
+
+	if err := chase.WriteQIF(&export, account.Type, qifTransactions); err != nil {
+		return "", err
+	}
+
+	return export.String(), nil
+}
+
+// Mutations
+
+func (r *mutationResolver) QifUpload(ctx context.Context, accountId uuid.UUID, reader graphql.Upload) (*gen.UploadResponse, error) {
+	upload, err := r.qifUpload(ctx, accountId, reader)
+
+	if err != nil {
+		return newUploadResponse(), err
+	}
+
+	return upload(ctx)
+}
+
+func (r *mutationResolver) qifUpload(ctx context.Context, accountId uuid.UUID, reader graphql.Upload) (uploadFunc, error) {
+	if !strings.HasSuffix(strings.ToLower(reader.Filename), ".qif") {
+		log.Printf("Invalid extension: %s", reader.Filename)
+		return nil, fmt.Errorf("Invalid file extension. .QIF required")
+	}
+
+	user := auth.GetCurrentUser(ctx)
+
+	// QIF files do not include account numbers, so the
+	// account must already exist from a previous upload
+	account, err := r.Repository.GetAccount(ctx, db.GetAccountParams{
+		ID:      accountId,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Account not found")
+	}
+
+	return r.importFile(user.ID, reader, db.UploadSourceQIFUPLOAD, importer.QIFParser(account))
+}
+
+// Categories are written by path, e.g. Food:Groceries, which is how QIF
+// imports match them to the user's categories
+func newQIFTransaction(tx db.Transaction, splits []db.TransactionSplit, categoryPaths map[uuid.UUID]string) chase.QIFTransaction {
+	qifTransaction := chase.QIFTransaction{
+		Date:        tx.Date,
+		Amount:      utils.FormatCurrencyFloat32(tx.Amount),
+		Payee:       tx.Description,
+		CheckNumber: tx.Checknumber.String,
+	}
+
+	if tx.Categoryid.Valid {
+		qifTransaction.Category = categoryPaths[tx.Categoryid.UUID]
+	}
+
+	for _, split := range splits {
+		qifSplit := chase.QIFSplit{
+			Memo:   split.Note,
+			Amount: utils.FormatCurrencyFloat32(split.Amount),
+		}
+
+		if split.Categoryid.Valid {
+			qifSplit.Category = categoryPaths[split.Categoryid.UUID]
+		}
+
+		qifTransaction.Splits = append(qifTransaction.Splits, qifSplit)
+	}
+
+	// Split out the payee when the description starts with it, so
+	// importing the export again rebuilds the same description
+	if tx.Payee.Valid && tx.Payee.String != "" && strings.HasPrefix(tx.Description, tx.Payee.String) {
+		qifTransaction.Payee = tx.Payee.String
+		qifTransaction.Memo = strings.TrimSpace(strings.TrimPrefix(tx.Description, tx.Payee.String))
+	}
+
+	return qifTransaction
+}
@@ -4,36 +4,67 @@ import (
 	"github.com/proctorinc/banker/internal/auth"
 	"github.com/proctorinc/banker/internal/dataloaders"
 	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/directconnect"
 	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/importer"
+	"github.com/proctorinc/banker/internal/jobs"
+	"github.com/proctorinc/banker/internal/secrets"
+	"github.com/proctorinc/banker/internal/syncprovider"
 )
 
 type Resolver struct {
 	Repository  db.Repository
 	AuthService auth.AuthService
 	DataLoaders dataloaders.Retriever
+	Importer    *importer.Service
+	Jobs        *jobs.Pool
+	Providers   syncprovider.Providers
+	// Secrets and DirectConnect are nil when no encryption key is set
+	Secrets       *secrets.Cipher
+	DirectConnect *directconnect.Client
 }
 
 // Base resolvers
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
 type pageInfoResolver struct{ *Resolver }
 
 // Models
 type userResolver struct{ *Resolver }
 type accountResolver struct{ *Resolver }
 type accountSyncItemResolver struct{ *Resolver }
+type accountBalanceResolver struct{ *Resolver }
 type transactionResolver struct{ *Resolver }
+type transactionSplitResolver struct{ *Resolver }
 type merchantResolver struct{ *Resolver }
+type merchantRuleResolver struct{ *Resolver }
+type globalMerchantResolver struct{ *Resolver }
+type categoryResolver struct{ *Resolver }
 type fundResolver struct{ *Resolver }
 type fundAllocationResolver struct{ *Resolver }
 type statsResolver struct{ *Resolver }
 type monthsResolver struct{ *Resolver }
 type fundsResponseResolver struct{ *Resolver }
+type csvProfileResolver struct{ *Resolver }
+type manualAssetResolver struct{ *Resolver }
+type manualAssetValuationResolver struct{ *Resolver }
+type importBatchResolver struct{ *Resolver }
+type importJobResolver struct{ *Resolver }
+type syncConnectionResolver struct{ *Resolver }
+type oFXConnectionResolver struct{ *Resolver }
+type securityResolver struct{ *Resolver }
+type holdingResolver struct{ *Resolver }
+type investmentTransactionResolver struct{ *Resolver }
 
 func (r *Resolver) Mutation() gen.MutationResolver {
 	return &mutationResolver{r}
 }
 
+func (r *Resolver) Subscription() gen.SubscriptionResolver {
+	return &subscriptionResolver{r}
+}
+
 func (r *Resolver) Query() gen.QueryResolver {
 	return &queryResolver{r}
 }
@@ -50,14 +81,34 @@ func (r *Resolver) AccountSyncItem() gen.AccountSyncItemResolver {
 	return &accountSyncItemResolver{r}
 }
 
+func (r *Resolver) AccountBalance() gen.AccountBalanceResolver {
+	return &accountBalanceResolver{r}
+}
+
 func (r *Resolver) Transaction() gen.TransactionResolver {
 	return &transactionResolver{r}
 }
 
+func (r *Resolver) TransactionSplit() gen.TransactionSplitResolver {
+	return &transactionSplitResolver{r}
+}
+
 func (r *Resolver) Merchant() gen.MerchantResolver {
 	return &merchantResolver{r}
 }
 
+func (r *Resolver) MerchantRule() gen.MerchantRuleResolver {
+	return &merchantRuleResolver{r}
+}
+
+func (r *Resolver) GlobalMerchant() gen.GlobalMerchantResolver {
+	return &globalMerchantResolver{r}
+}
+
+func (r *Resolver) Category() gen.CategoryResolver {
+	return &categoryResolver{r}
+}
+
 func (r *Resolver) Fund() gen.FundResolver {
 	return &fundResolver{r}
 }
@@ -73,3 +124,43 @@ func (r *Resolver) PageInfo() gen.PageInfoResolver {
 func (r *Resolver) FundsResponse() gen.FundsResponseResolver {
 	return &fundsResponseResolver{r}
 }
+
+func (r *Resolver) CSVProfile() gen.CSVProfileResolver {
+	return &csvProfileResolver{r}
+}
+
+func (r *Resolver) ManualAsset() gen.ManualAssetResolver {
+	return &manualAssetResolver{r}
+}
+
+func (r *Resolver) ManualAssetValuation() gen.ManualAssetValuationResolver {
+	return &manualAssetValuationResolver{r}
+}
+
+func (r *Resolver) ImportBatch() gen.ImportBatchResolver {
+	return &importBatchResolver{r}
+}
+
+func (r *Resolver) ImportJob() gen.ImportJobResolver {
+	return &importJobResolver{r}
+}
+
+func (r *Resolver) SyncConnection() gen.SyncConnectionResolver {
+	return &syncConnectionResolver{r}
+}
+
+func (r *Resolver) OFXConnection() gen.OFXConnectionResolver {
+	return &oFXConnectionResolver{r}
+}
+
+func (r *Resolver) Security() gen.SecurityResolver {
+	return &securityResolver{r}
+}
+
+func (r *Resolver) Holding() gen.HoldingResolver {
+	return &holdingResolver{r}
+}
+
+func (r *Resolver) InvestmentTransaction() gen.InvestmentTransactionResolver {
+	return &investmentTransactionResolver{r}
+}
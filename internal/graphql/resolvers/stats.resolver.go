@@ -2,7 +2,9 @@ package resolvers
 
 import (
 	"context"
+	"sort"
 
+	"github.com/google/uuid"
 	"github.com/proctorinc/banker/internal/auth"
 	"github.com/proctorinc/banker/internal/db"
 	gen "github.com/proctorinc/banker/internal/graphql/generated"
@@ -37,9 +39,10 @@ func (r *queryResolver) Spending(ctx context.Context, input gen.StatsInput) (*ge
 	}
 
 	incomeTotal, err := r.Repository.GetTotalSpending(ctx, db.GetTotalSpendingParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
 	})
 
 	if err != nil {
@@ -51,9 +54,10 @@ func (r *queryResolver) Spending(ctx context.Context, input gen.StatsInput) (*ge
 	}
 
 	totalCount, err := r.Repository.CountSpendingTransactions(ctx, db.CountSpendingTransactionsParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
 	})
 
 	if err != nil {
@@ -67,19 +71,20 @@ func (r *queryResolver) Spending(ctx context.Context, input gen.StatsInput) (*ge
 	start := int32(paginator.Offset)
 	limit := calculatePageLimit(pageArgs)
 
-	incomeTransactions, err := r.Repository.ListIncomeTransactions(ctx, db.ListIncomeTransactionsParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
-		Limit:     limit,
-		Start:     start,
+	spendingTransactions, err := r.Repository.ListSpendingTransactions(ctx, db.ListSpendingTransactionsParams{
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Limit:          limit,
+		Includepending: withPending(input.IncludePending),
+		Start:          start,
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	for i, row := range incomeTransactions {
+	for i, row := range spendingTransactions {
 		transactionsResult.Edges = append(transactionsResult.Edges, gen.TransactionEdge{
 			Cursor: paging.EncodeOffsetCursor(paginator.Offset + i + 1),
 			Node:   &row,
@@ -91,6 +96,36 @@ func (r *queryResolver) Spending(ctx context.Context, input gen.StatsInput) (*ge
 	return result, err
 }
 
+// Breaks spending down by top level category and their subcategories.
+// Transactions without a category count towards their merchant's category
+func (r *queryResolver) SpendingByCategory(ctx context.Context, input gen.StatsInput) ([]gen.CategorySpending, error) {
+	user := auth.GetCurrentUser(ctx)
+	filter, err := parseStatsFilter(input.Filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Repository.GetSpendingByCategory(ctx, db.GetSpendingByCategoryParams{
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := r.Repository.ListCategories(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return groupCategorySpending(rows, categories), nil
+}
+
 func (r *queryResolver) Income(ctx context.Context, input gen.StatsInput) (*gen.IncomeStats, error) {
 	user := auth.GetCurrentUser(ctx)
 	pageArgs := getPageArgs(ctx, "transactions")
@@ -101,9 +136,10 @@ func (r *queryResolver) Income(ctx context.Context, input gen.StatsInput) (*gen.
 	}
 
 	incomeTotal, err := r.Repository.GetTotalIncome(ctx, db.GetTotalIncomeParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
 	})
 
 	if err != nil {
@@ -115,9 +151,10 @@ func (r *queryResolver) Income(ctx context.Context, input gen.StatsInput) (*gen.
 	}
 
 	totalCount, err := r.Repository.CountIncomeTransactions(ctx, db.CountIncomeTransactionsParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
 	})
 
 	if err != nil {
@@ -132,11 +169,12 @@ func (r *queryResolver) Income(ctx context.Context, input gen.StatsInput) (*gen.
 	limit := calculatePageLimit(pageArgs)
 
 	incomeTransactions, err := r.Repository.ListIncomeTransactions(ctx, db.ListIncomeTransactionsParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
-		Limit:     limit,
-		Start:     start,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Limit:          limit,
+		Includepending: withPending(input.IncludePending),
+		Start:          start,
 	})
 
 	if err != nil {
@@ -165,9 +203,10 @@ func (r *queryResolver) Net(ctx context.Context, input gen.StatsInput) (*gen.Net
 	}
 
 	incomeTotal, err := r.Repository.GetNetIncome(ctx, db.GetNetIncomeParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
 	})
 
 	if err != nil {
@@ -179,9 +218,10 @@ func (r *queryResolver) Net(ctx context.Context, input gen.StatsInput) (*gen.Net
 	}
 
 	totalCount, err := r.Repository.CountTransactionsByDates(ctx, db.CountTransactionsByDatesParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
 	})
 
 	if err != nil {
@@ -196,11 +236,12 @@ func (r *queryResolver) Net(ctx context.Context, input gen.StatsInput) (*gen.Net
 	limit := calculatePageLimit(pageArgs)
 
 	incomeTransactions, err := r.Repository.ListTransactionsByDates(ctx, db.ListTransactionsByDatesParams{
-		Ownerid:   user.ID,
-		Startdate: filter.StartDate,
-		Enddate:   filter.EndDate,
-		Limit:     limit,
-		Start:     start,
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Limit:          limit,
+		Includepending: withPending(input.IncludePending),
+		Start:          start,
 	})
 
 	if err != nil {
@@ -218,3 +259,98 @@ func (r *queryResolver) Net(ctx context.Context, input gen.StatsInput) (*gen.Net
 
 	return result, err
 }
+
+type categoryTotal struct {
+	category      *db.Category
+	cents         int64
+	count         int64
+	subcategories map[uuid.UUID]*categoryTotal
+}
+
+func (t *categoryTotal) add(row db.GetSpendingByCategoryRow) {
+	t.cents += row.Total
+	t.count += row.Count
+}
+
+func (t *categoryTotal) spending() gen.CategorySpending {
+	result := gen.CategorySpending{
+		Category:      t.category,
+		Total:         utils.FormatCurrencyFloat64FromInt64(t.cents),
+		Count:         int(t.count),
+		Subcategories: []gen.CategorySpending{},
+	}
+
+	for _, subcategory := range t.subcategories {
+		result.Subcategories = append(result.Subcategories, subcategory.spending())
+	}
+
+	sortCategorySpending(result.Subcategories)
+
+	return result
+}
+
+// Rolls the spending of each category up into its top level category.
+// Spending without a category is listed last with a null category
+func groupCategorySpending(rows []db.GetSpendingByCategoryRow, categories []db.Category) []gen.CategorySpending {
+	categoriesById := make(map[uuid.UUID]db.Category, len(categories))
+
+	for _, category := range categories {
+		categoriesById[category.ID] = category
+	}
+
+	totals := map[uuid.UUID]*categoryTotal{}
+	uncategorized := &categoryTotal{}
+
+	for _, row := range rows {
+		category, ok := categoriesById[row.Categoryid.UUID]
+
+		if !row.Categoryid.Valid || !ok {
+			uncategorized.add(row)
+			continue
+		}
+
+		parent := category
+
+		if p, ok := categoriesById[category.Parentid.UUID]; category.Parentid.Valid && ok {
+			parent = p
+		}
+
+		total, ok := totals[parent.ID]
+
+		if !ok {
+			total = &categoryTotal{category: &parent, subcategories: map[uuid.UUID]*categoryTotal{}}
+			totals[parent.ID] = total
+		}
+
+		subcategory, ok := total.subcategories[category.ID]
+
+		if !ok {
+			subcategory = &categoryTotal{category: &category}
+			total.subcategories[category.ID] = subcategory
+		}
+
+		total.add(row)
+		subcategory.add(row)
+	}
+
+	result := []gen.CategorySpending{}
+
+	for _, total := range totals {
+		result = append(result, total.spending())
+	}
+
+	sortCategorySpending(result)
+
+	if uncategorized.count > 0 {
+		result = append(result, uncategorized.spending())
+	}
+
+	return result
+}
+
+// Spending is negative, so the largest spending comes first
+func sortCategorySpending(spending []gen.CategorySpending) {
+	sort.SliceStable(spending, func(i, j int) bool {
+		return spending[i].Total < spending[j].Total
+	})
+}
@@ -0,0 +1,172 @@
+package resolvers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+func (r *syncConnectionResolver) Provider(ctx context.Context, connection *db.SyncConnection) (string, error) {
+	return string(connection.Provider), nil
+}
+
+func (r *syncConnectionResolver) LastSynced(ctx context.Context, connection *db.SyncConnection) (*string, error) {
+	if !connection.Lastsynced.Valid {
+		return nil, nil
+	}
+
+	lastSynced := connection.Lastsynced.Time.Format(time.RFC3339)
+	return &lastSynced, nil
+}
+
+func (r *syncConnectionResolver) Created(ctx context.Context, connection *db.SyncConnection) (string, error) {
+	return connection.Created.Format(time.RFC3339), nil
+}
+
+// Queries
+
+func (r *queryResolver) SyncConnections(ctx context.Context) ([]db.SyncConnection, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	return r.Repository.ListSyncConnections(ctx, user.ID)
+}
+
+// Mutations
+
+func (r *mutationResolver) LinkSyncConnection(ctx context.Context, provider string, publicToken string) (*db.SyncConnection, error) {
+	user := auth.GetCurrentUser(ctx)
+	syncProvider, err := r.Providers.Get(db.UploadSource(provider))
+
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := syncProvider.Link(ctx, publicToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := r.Repository.UpsertSyncConnection(ctx, db.UpsertSyncConnectionParams{
+		Provider:    syncProvider.Source(),
+		Itemid:      link.ItemId,
+		Accesstoken: link.AccessToken,
+		Ownerid:     user.ID,
+	})
+
+	// The item is already linked by another user
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("Sync connection already linked")
+	}
+
+	return &connection, err
+}
+
+func (r *mutationResolver) SyncConnection(ctx context.Context, id uuid.UUID) (*gen.SyncConnectionResponse, error) {
+	user := auth.GetCurrentUser(ctx)
+	connection, err := r.Repository.GetSyncConnection(ctx, db.GetSyncConnectionParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Sync connection not found")
+	}
+
+	return r.syncConnection(ctx, connection)
+}
+
+// Fetches the changes since the cursor stored on the connection's accounts
+// and saves them in a single database transaction. The cursor only moves
+// forward when everything saved, so a failed sync retries the same changes
+func (r *mutationResolver) syncConnection(ctx context.Context, connection db.SyncConnection) (*gen.SyncConnectionResponse, error) {
+	provider, err := r.Providers.Get(connection.Provider)
+
+	if err != nil {
+		return nil, err
+	}
+
+	connectionId := uuid.NullUUID{UUID: connection.ID, Valid: true}
+	cursor, err := r.Repository.GetSyncConnectionCursor(ctx, connectionId)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	result, err := provider.Sync(ctx, connection.Accesstoken, cursor.String)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := &gen.SyncConnectionResponse{
+		Connection: &connection,
+		Upload:     newUploadResponse(),
+	}
+
+	err = r.Repository.WithTx(ctx, func(repo db.Repository) error {
+		upload, err := r.Importer.Import(ctx, repo, connection.Ownerid, importer.FromChaseOFX(result.Statements), connection.Provider)
+		response.Upload = newImportResponse(upload)
+
+		if err != nil {
+			return err
+		}
+
+		if len(result.Removed) > 0 {
+			removed, err := repo.DeleteTransactionsBySourceIds(ctx, db.DeleteTransactionsBySourceIdsParams{
+				Ownerid:   connection.Ownerid,
+				Sourceids: result.Removed,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			response.TransactionsRemoved = int(removed)
+		}
+
+		accountIds := []string{}
+
+		for _, statement := range result.Statements {
+			accountIds = append(accountIds, statement.Account.AccountId)
+		}
+
+		err = repo.LinkSyncConnectionAccounts(ctx, db.LinkSyncConnectionAccountsParams{
+			Syncconnectionid: connectionId,
+			Ownerid:          connection.Ownerid,
+			Sourceids:        accountIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		err = repo.UpdateSyncConnectionCursor(ctx, db.UpdateSyncConnectionCursorParams{
+			Syncconnectionid: connectionId,
+			Synccursor:       sql.NullString{String: result.Cursor, Valid: true},
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return repo.UpdateSyncConnectionSynced(ctx, connection.ID)
+	})
+
+	if err != nil {
+		return response, err
+	}
+
+	response.Upload.Success = true
+	response.Connection.Lastsynced = sql.NullTime{Time: time.Now(), Valid: true}
+
+	return response, nil
+}
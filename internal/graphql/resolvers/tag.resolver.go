@@ -0,0 +1,222 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+// Queries
+
+func (r *queryResolver) Tags(ctx context.Context) ([]db.Tag, error) {
+	user := auth.GetCurrentUser(ctx)
+	tags, err := r.Repository.ListTags(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// Totals of each tag's transactions, with the most spending first
+func (r *queryResolver) TagTotals(ctx context.Context, input gen.StatsInput) ([]gen.TagTotals, error) {
+	user := auth.GetCurrentUser(ctx)
+	filter, err := parseStatsFilter(input.Filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.Repository.GetTagTotals(ctx, db.GetTagTotalsParams{
+		Ownerid:        user.ID,
+		Startdate:      filter.StartDate,
+		Enddate:        filter.EndDate,
+		Includepending: withPending(input.IncludePending),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := r.Repository.ListTags(ctx, user.ID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tagsById := make(map[uuid.UUID]db.Tag, len(tags))
+
+	for _, tag := range tags {
+		tagsById[tag.ID] = tag
+	}
+
+	result := []gen.TagTotals{}
+
+	for _, row := range rows {
+		tag, ok := tagsById[row.Tagid]
+
+		if !ok {
+			continue
+		}
+
+		result = append(result, gen.TagTotals{
+			Tag:      &tag,
+			Spending: utils.FormatCurrencyFloat64FromInt64(row.Spending),
+			Income:   utils.FormatCurrencyFloat64FromInt64(row.Income),
+			Net:      utils.FormatCurrencyFloat64FromInt64(row.Spending + row.Income),
+			Count:    int(row.Count),
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Spending != result[j].Spending {
+			return result[i].Spending < result[j].Spending
+		}
+
+		return strings.ToLower(result[i].Tag.Name) < strings.ToLower(result[j].Tag.Name)
+	})
+
+	return result, nil
+}
+
+// Mutations
+
+// Adds tags to transactions, creating the tags the user doesn't have yet
+func (r *mutationResolver) AddTransactionTags(ctx context.Context, transactionIds []uuid.UUID, tags []string) ([]db.Transaction, error) {
+	user := auth.GetCurrentUser(ctx)
+	names, err := parseTagNames(tags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := uuidStrings(uniqueIds(transactionIds))
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Select transactions")
+	}
+
+	_, err = r.Repository.TagTransactions(ctx, db.TagTransactionsParams{
+		TransactionIds: ids,
+		Names:          names,
+		UserId:         user.ID,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.taggedTransactions(ctx, user.ID, ids)
+}
+
+// Removes tags from transactions. The tags are kept for other transactions
+func (r *mutationResolver) RemoveTransactionTags(ctx context.Context, transactionIds []uuid.UUID, tags []string) ([]db.Transaction, error) {
+	user := auth.GetCurrentUser(ctx)
+	names, err := parseTagNames(tags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := uuidStrings(uniqueIds(transactionIds))
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Select transactions")
+	}
+
+	_, err = r.Repository.RemoveTransactionTags(ctx, db.RemoveTransactionTagsParams{
+		Ownerid:        user.ID,
+		Transactionids: ids,
+		Names:          tagFilter(names),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.taggedTransactions(ctx, user.ID, ids)
+}
+
+// Deletes a tag and removes it from every transaction
+func (r *mutationResolver) DeleteTag(ctx context.Context, id uuid.UUID) (*db.Tag, error) {
+	user := auth.GetCurrentUser(ctx)
+	tag, err := r.Repository.DeleteTag(ctx, db.DeleteTagParams{
+		ID:      id,
+		Ownerid: user.ID,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Tag not found")
+	}
+
+	return &tag, nil
+}
+
+func (r *mutationResolver) taggedTransactions(ctx context.Context, userId uuid.UUID, ids []string) ([]db.Transaction, error) {
+	transactions, err := r.Repository.ListTransactionsByIds(ctx, db.ListTransactionsByIdsParams{
+		Ownerid:        userId,
+		Transactionids: ids,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if transactions == nil {
+		return []db.Transaction{}, nil
+	}
+
+	return transactions, nil
+}
+
+// Trims tag names and removes repeated names, ignoring case
+func parseTagNames(tags []string) ([]string, error) {
+	seen := map[string]bool{}
+	names := []string{}
+
+	for _, tag := range tags {
+		name := strings.TrimSpace(tag)
+
+		if name == "" {
+			return nil, fmt.Errorf("Tag name is required")
+		}
+
+		if len(name) > 255 {
+			return nil, fmt.Errorf("Tag name is too long: %s", name)
+		}
+
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("Select tags")
+	}
+
+	return names, nil
+}
+
+// Lowercase tag names to filter by, or nil to not filter by tags
+func tagFilter(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(tags))
+
+	for i, tag := range tags {
+		names[i] = strings.ToLower(strings.TrimSpace(tag))
+	}
+
+	return names
+}
@@ -2,6 +2,8 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -88,6 +90,19 @@ func (r *transactionResolver) Updated(ctx context.Context, transaction *db.Trans
 	return transaction.Updated.Format(time.RFC3339), nil
 }
 
+func (r *transactionResolver) Status(ctx context.Context, transaction *db.Transaction) (string, error) {
+	return string(transaction.Status), nil
+}
+
+func (r *transactionResolver) AuthorizedDate(ctx context.Context, transaction *db.Transaction) (*string, error) {
+	if !transaction.Authorizeddate.Valid {
+		return nil, nil
+	}
+
+	authorizedDate := transaction.Authorizeddate.Time.Format(time.RFC3339)
+	return &authorizedDate, nil
+}
+
 func (r *transactionResolver) Merchant(ctx context.Context, transaction *db.Transaction) (*db.Merchant, error) {
 	merchant, err := r.DataLoaders.Retrieve(ctx).MerchantByTransactionId.Load(transaction.Merchantid.String())
 
@@ -95,9 +110,73 @@ func (r *transactionResolver) Merchant(ctx context.Context, transaction *db.Tran
 		return nil, err
 	}
 
+	// The loader only returns the user's own merchants
+	if merchant.ID == uuid.Nil {
+		return nil, fmt.Errorf("Merchant not found")
+	}
+
 	return &merchant, nil
 }
 
+func (r *transactionResolver) Category(ctx context.Context, transaction *db.Transaction) (*db.Category, error) {
+	if transaction.Categoryid.Valid {
+		return r.category(ctx, transaction.Ownerid, transaction.Categoryid)
+	}
+
+	merchant, err := r.Merchant(ctx, transaction)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.category(ctx, transaction.Ownerid, merchant.Categoryid)
+}
+
+func (r *transactionResolver) CategorySource(ctx context.Context, transaction *db.Transaction) (*string, error) {
+	if transaction.Categorysource.Valid {
+		source := string(transaction.Categorysource.CategorySource)
+		return &source, nil
+	}
+
+	return nil, nil
+}
+
+func (r *transactionResolver) CategoryConfidence(ctx context.Context, transaction *db.Transaction) (*float64, error) {
+	if transaction.Categoryconfidence.Valid {
+		return &transaction.Categoryconfidence.Float64, nil
+	}
+
+	return nil, nil
+}
+
+func (r *transactionResolver) Splits(ctx context.Context, transaction *db.Transaction) ([]db.TransactionSplit, error) {
+	splits, err := r.DataLoaders.Retrieve(ctx).SplitsByTransactionId.Load(transaction.ID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if splits == nil {
+		return []db.TransactionSplit{}, nil
+	}
+
+	return splits, nil
+}
+
+func (r *transactionResolver) Tags(ctx context.Context, transaction *db.Transaction) ([]db.Tag, error) {
+	tags, err := r.DataLoaders.Retrieve(ctx).TagsByTransactionId.Load(transaction.ID.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tags == nil {
+		return []db.Tag{}, nil
+	}
+
+	return tags, nil
+}
+
 // Queries
 
 func (r *queryResolver) Transaction(ctx context.Context, transactionId uuid.UUID) (*db.Transaction, error) {
@@ -114,9 +193,18 @@ func (r *queryResolver) Transaction(ctx context.Context, transactionId uuid.UUID
 	return &transaction, nil
 }
 
-func (r *queryResolver) Transactions(ctx context.Context, page *paging.PageArgs) (*gen.TransactionConnection, error) {
+func (r *queryResolver) Transactions(ctx context.Context, page *paging.PageArgs, includePending *bool, categoryId *uuid.UUID, needsReview *bool, tags []string) (*gen.TransactionConnection, error) {
 	user := auth.GetCurrentUser(ctx)
-	totalCount, err := r.Repository.CountTransactions(ctx, user.ID)
+	category := optionalId(categoryId)
+	review := optionalBool(needsReview)
+	tagNames := tagFilter(tags)
+	totalCount, err := r.Repository.CountTransactions(ctx, db.CountTransactionsParams{
+		Ownerid:        user.ID,
+		Includepending: withPending(includePending),
+		Categoryid:     category,
+		Needsreview:    review,
+		Tags:           tagNames,
+	})
 
 	if err != nil {
 		return &gen.TransactionConnection{
@@ -132,9 +220,13 @@ func (r *queryResolver) Transactions(ctx context.Context, page *paging.PageArgs)
 	limit := calculatePageLimit(page)
 
 	transactions, err := r.Repository.ListTransactions(ctx, db.ListTransactionsParams{
-		Ownerid: user.ID,
-		Limit:   limit,
-		Start:   start,
+		Ownerid:        user.ID,
+		Limit:          limit,
+		Includepending: withPending(includePending),
+		Categoryid:     category,
+		Needsreview:    review,
+		Tags:           tagNames,
+		Start:          start,
 	})
 
 	for i, row := range transactions {
@@ -149,6 +241,45 @@ func (r *queryResolver) Transactions(ctx context.Context, page *paging.PageArgs)
 
 // Mutations
 
+// Sets a transaction's category, which clears its review flag and teaches the
+// categorizer. A null category gives the transaction its merchant's category
+// again
+func (r *mutationResolver) CategorizeTransaction(ctx context.Context, id uuid.UUID, categoryId *uuid.UUID) (*db.Transaction, error) {
+	user := auth.GetCurrentUser(ctx)
+	category, err := r.userCategoryId(ctx, user.ID, categoryId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := r.Repository.SetTransactionCategory(ctx, db.SetTransactionCategoryParams{
+		ID:         id,
+		Ownerid:    user.ID,
+		Categoryid: category,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Transaction not found")
+	}
+
+	return &transaction, nil
+}
+
+func (r *mutationResolver) SetTransactionNote(ctx context.Context, id uuid.UUID, note string) (*db.Transaction, error) {
+	user := auth.GetCurrentUser(ctx)
+	transaction, err := r.Repository.SetTransactionNote(ctx, db.SetTransactionNoteParams{
+		ID:      id,
+		Ownerid: user.ID,
+		Note:    strings.TrimSpace(note),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("Transaction not found")
+	}
+
+	return &transaction, nil
+}
+
 func (r *mutationResolver) DeleteTransaction(ctx context.Context, id uuid.UUID) (*db.Transaction, error) {
 	transaction, err := r.Repository.DeleteTransaction(ctx, id)
 
@@ -0,0 +1,131 @@
+package resolvers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+func (r *transactionSplitResolver) Amount(ctx context.Context, split *db.TransactionSplit) (float64, error) {
+	return utils.FormatCurrencyFloat64(split.Amount), nil
+}
+
+func (r *transactionSplitResolver) Category(ctx context.Context, split *db.TransactionSplit) (*db.Category, error) {
+	if split.Categoryid.Valid {
+		return r.category(ctx, split.Ownerid, split.Categoryid)
+	}
+
+	transaction, err := r.Repository.GetTransaction(ctx, db.GetTransactionParams{
+		ID:      split.Transactionid,
+		Ownerid: split.Ownerid,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Transaction().Category(ctx, &transaction)
+}
+
+func (r *transactionSplitResolver) Fund(ctx context.Context, split *db.TransactionSplit) (*db.Fund, error) {
+	if !split.Fundid.Valid {
+		return nil, nil
+	}
+
+	fund, err := r.Repository.GetFund(ctx, db.GetFundParams{
+		ID:      split.Fundid.UUID,
+		Ownerid: split.Ownerid,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &fund, nil
+}
+
+// Mutations
+
+// Splits a transaction into parts that add up to its amount, replacing any
+// earlier split. No parts removes the split
+func (r *mutationResolver) SplitTransaction(ctx context.Context, id uuid.UUID, parts []gen.TransactionSplitInput) (*db.Transaction, error) {
+	user := auth.GetCurrentUser(ctx)
+
+	if len(parts) == 1 {
+		return nil, fmt.Errorf("Transaction must be split into at least two parts")
+	}
+
+	params := db.SplitTransactionParams{
+		TransactionId: id,
+		UserId:        user.ID,
+	}
+
+	for _, part := range parts {
+		amount := utils.RoundCurrencyInt(part.Amount)
+
+		if amount == 0 {
+			return nil, fmt.Errorf("Split amounts can't be zero")
+		}
+
+		category, err := r.userCategoryId(ctx, user.ID, part.CategoryID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		fund, err := r.userFundId(ctx, user.ID, part.FundID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		params.Parts = append(params.Parts, db.TransactionSplitPart{
+			Amount:     amount,
+			Categoryid: category,
+			Note:       strings.TrimSpace(optionalNullString(part.Note).String),
+			Fundid:     fund,
+		})
+	}
+
+	transaction, err := r.Repository.SplitTransaction(ctx, params)
+
+	if errors.Is(err, db.ErrTransactionNotFound) {
+		return nil, fmt.Errorf("Transaction not found")
+	}
+
+	if errors.Is(err, db.ErrSplitAmount) {
+		return nil, fmt.Errorf("Split amounts must add up to the transaction amount")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// Checks that a fund id from an input is one of the user's funds. Null ids
+// are valid
+func (r *Resolver) userFundId(ctx context.Context, userId uuid.UUID, fundId *uuid.UUID) (uuid.NullUUID, error) {
+	if fundId == nil {
+		return uuid.NullUUID{}, nil
+	}
+
+	fund, err := r.Repository.GetFund(ctx, db.GetFundParams{
+		ID:      *fundId,
+		Ownerid: userId,
+	})
+
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("Fund not found")
+	}
+
+	return uuid.NullUUID{UUID: fund.ID, Valid: true}, nil
+}
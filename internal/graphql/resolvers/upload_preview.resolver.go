@@ -0,0 +1,188 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/auth"
+	"github.com/proctorinc/banker/internal/db"
+	gen "github.com/proctorinc/banker/internal/graphql/generated"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+const (
+	PreviewActionInsert = "INSERT"
+	PreviewActionUpdate = "UPDATE"
+	PreviewActionReject = "REJECT"
+)
+
+// Mutations
+
+// PreviewUpload reports what ChaseOFXUpload would do with a file
+// without writing anything. Upload the same file to confirm
+func (r *mutationResolver) PreviewUpload(ctx context.Context, reader graphql.Upload) (*gen.UploadPreview, error) {
+	if !isOFXFile(reader.Filename) {
+		return nil, fmt.Errorf("Invalid file extension. .OFX/.QBX/.QBO required")
+	}
+
+	user := auth.GetCurrentUser(ctx)
+	statements, err := importer.OFXParser.Parse(reader.File)
+
+	if err != nil {
+		return nil, err
+	}
+
+	merchants, err := r.Importer.ImportMerchants(ctx, r.Repository, user.ID, db.UploadSourceCHASEOFXUPLOAD)
+
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &gen.UploadPreview{
+		Accounts: []gen.AccountPreview{},
+	}
+
+	for _, statement := range statements {
+		accountPreview := gen.AccountPreview{
+			Name:         statement.Account.Name,
+			SourceID:     utils.MaskData(statement.Account.SourceId),
+			Type:         string(statement.Account.Type),
+			Transactions: []gen.TransactionPreview{},
+		}
+
+		var account db.Account
+		accountErr := importer.CheckStatementOwner(ctx, r.Repository, user.ID, statement)
+
+		if accountErr == nil {
+			existing, err := r.Repository.GetAccountBySourceId(ctx, db.GetAccountBySourceIdParams{
+				Ownerid:  user.ID,
+				Sourceid: statement.Account.SourceId,
+			})
+
+			if err == nil {
+				account = existing
+				accountPreview.Exists = true
+			}
+		}
+
+		if !statement.Account.Type.Valid() {
+			accountErr = fmt.Errorf("Unsupported account type: %s", statement.Account.Type)
+		}
+
+		seen := map[string]bool{}
+
+		for _, tx := range statement.Transactions {
+			txPreview := r.previewOFXTransaction(ctx, merchants, user.ID, account, tx, seen)
+
+			if accountErr != nil {
+				reason := accountErr.Error()
+				txPreview.Action = PreviewActionReject
+				txPreview.Reason = &reason
+				txPreview.ChangedFields = []string{}
+				txPreview.Merchant = nil
+			}
+
+			switch txPreview.Action {
+			case PreviewActionInsert:
+				preview.Inserts++
+			case PreviewActionUpdate:
+				preview.Updates++
+			default:
+				preview.Rejects++
+			}
+
+			accountPreview.Transactions = append(accountPreview.Transactions, txPreview)
+		}
+
+		preview.Accounts = append(preview.Accounts, accountPreview)
+	}
+
+	return preview, nil
+}
+
+func (r *mutationResolver) previewOFXTransaction(ctx context.Context, merchants importer.MerchantResolver, userId uuid.UUID, account db.Account, tx importer.NormalizedTransaction, seen map[string]bool) gen.TransactionPreview {
+	preview := gen.TransactionPreview{
+		SourceID:      tx.SourceId,
+		Description:   tx.Description,
+		Amount:        float64(tx.Amount),
+		Type:          string(tx.Type),
+		Action:        PreviewActionInsert,
+		ChangedFields: []string{},
+	}
+
+	if !tx.Date.IsZero() {
+		date := tx.Date.Format(time.RFC3339)
+		preview.Date = &date
+	}
+
+	if err := importer.ValidateTransaction(tx, seen); err != nil {
+		reason := err.Error()
+		preview.Action = PreviewActionReject
+		preview.Reason = &reason
+		return preview
+	}
+
+	existing, err := r.Repository.GetTransactionBySourceId(ctx, db.GetTransactionBySourceIdParams{
+		Ownerid:  userId,
+		Sourceid: tx.SourceId,
+	})
+
+	// Transactions of other accounts are rejected with the statement
+	if err == nil && existing.Accountid == account.ID {
+		preview.Action = PreviewActionUpdate
+		preview.ChangedFields = changedTransactionFields(existing, tx)
+	}
+
+	match, err := merchants.FindMerchant(ctx, r.Repository, userId, db.UploadSourceCHASEOFXUPLOAD, tx)
+
+	if err != nil {
+		return preview
+	}
+
+	preview.Merchant = &gen.MerchantPreview{
+		Name:     match.Name,
+		IsNew:    match.Merchant == nil,
+		Merchant: match.Merchant,
+	}
+
+	if match.Merchant != nil {
+		preview.Merchant.Name = match.Merchant.Name
+	}
+
+	return preview
+}
+
+// Lists the fields an upsert would overwrite on an existing transaction
+func changedTransactionFields(existing db.Transaction, tx importer.NormalizedTransaction) []string {
+	changed := []string{}
+
+	if existing.Amount != utils.FormatCurrencyInt(tx.Amount) {
+		changed = append(changed, "amount")
+	}
+
+	if !existing.Date.Equal(tx.Date.Truncate(24 * time.Hour)) {
+		changed = append(changed, "date")
+	}
+
+	if existing.Description != tx.Description {
+		changed = append(changed, "description")
+	}
+
+	if existing.Type != tx.Type {
+		changed = append(changed, "type")
+	}
+
+	if existing.Payee.String != tx.Payee {
+		changed = append(changed, "payee")
+	}
+
+	if existing.Checknumber.String != tx.CheckNumber {
+		changed = append(changed, "checkNumber")
+	}
+
+	return changed
+}
@@ -51,8 +51,11 @@ func (r *userResolver) Accounts(ctx context.Context, user *db.User, page *paging
 	return result, err
 }
 
-func (r *userResolver) Transactions(ctx context.Context, user *db.User, page *paging.PageArgs) (*gen.TransactionConnection, error) {
-	totalCount, err := r.Repository.CountTransactions(ctx, user.ID)
+func (r *userResolver) Transactions(ctx context.Context, user *db.User, page *paging.PageArgs, includePending *bool) (*gen.TransactionConnection, error) {
+	totalCount, err := r.Repository.CountTransactions(ctx, db.CountTransactionsParams{
+		Ownerid:        user.ID,
+		Includepending: withPending(includePending),
+	})
 
 	if err != nil {
 		return &gen.TransactionConnection{
@@ -68,9 +71,10 @@ func (r *userResolver) Transactions(ctx context.Context, user *db.User, page *pa
 	limit := calculatePageLimit(page)
 
 	transactions, err := r.Repository.ListTransactions(ctx, db.ListTransactionsParams{
-		Ownerid: user.ID,
-		Limit:   limit,
-		Start:   start,
+		Ownerid:        user.ID,
+		Limit:          limit,
+		Includepending: withPending(includePending),
+		Start:          start,
 	})
 
 	for i, row := range transactions {
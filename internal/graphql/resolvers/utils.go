@@ -2,11 +2,14 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
 	gen "github.com/proctorinc/banker/internal/graphql/generated"
 	"github.com/proctorinc/banker/internal/graphql/paging"
 )
@@ -73,3 +76,65 @@ func getPageArgs(ctx context.Context, fieldName string) *paging.PageArgs {
 
 	return pageArgs
 }
+
+func nullStringPtr(value sql.NullString) *string {
+	if value.Valid {
+		return &value.String
+	}
+
+	return nil
+}
+
+func optionalNullString(value *string) sql.NullString {
+	if value == nil || len(strings.TrimSpace(*value)) == 0 {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: strings.TrimSpace(*value), Valid: true}
+}
+
+func optionalId(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
+
+func optionalBool(value *bool) sql.NullBool {
+	if value == nil {
+		return sql.NullBool{}
+	}
+
+	return sql.NullBool{Bool: *value, Valid: true}
+}
+
+// Pending transactions are included unless the caller excludes them
+func withPending(includePending *bool) bool {
+	return includePending == nil || *includePending
+}
+
+// Removes repeated ids, keeping the first of each
+func uniqueIds(ids []uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	unique := []uuid.UUID{}
+
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	result := make([]string, len(ids))
+
+	for i, id := range ids {
+		result[i] = id.String()
+	}
+
+	return result
+}
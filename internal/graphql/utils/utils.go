@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"math"
 	"strings"
 )
 
@@ -31,6 +32,18 @@ func FormatCurrencyFloat64(amount int32) float64 {
 	return float64(amount) / 100
 }
 
+// FormatCurrencyFloat64FromInt64 converts a sum of amounts in cents, which
+// can be larger than an int32
+func FormatCurrencyFloat64FromInt64(amount int64) float64 {
+	return float64(amount) / 100
+}
+
 func FormatCurrencyInt(amount float32) int32 {
 	return int32(amount * 100)
 }
+
+// RoundCurrencyInt converts an amount to cents, rounding to the nearest cent
+// so amounts like 19.99 aren't truncated
+func RoundCurrencyInt(amount float64) int32 {
+	return int32(math.Round(amount * 100))
+}
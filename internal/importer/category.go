@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/categorizer"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+)
+
+// DefaultReviewThreshold is the confidence below which predicted categories
+// are flagged for review
+const DefaultReviewThreshold = 0.8
+
+// Categories categorizes imported transactions with a model trained on the
+// transactions the user categorized. The model is trained at the start of
+// each import, so every category the user sets improves the next import
+type Categories struct {
+	// Predictions below this confidence need review. DefaultReviewThreshold
+	// is used when zero
+	ReviewThreshold float64
+}
+
+// Trains a model for the user, or returns nil when they haven't categorized
+// any transactions yet
+func (c *Categories) train(ctx context.Context, repo db.Repository, userId uuid.UUID) (*categorizer.Model, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	rows, err := repo.ListLabeledTransactions(ctx, userId)
+
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	examples := make([]categorizer.Example, len(rows))
+
+	for i, row := range rows {
+		examples[i] = categorizer.Example{
+			Transaction: categorizer.Transaction{
+				Description: row.Description,
+				MerchantId:  row.Merchantid,
+				Amount:      row.Amount,
+				Type:        row.Type,
+			},
+			CategoryId: row.Categoryid.UUID,
+		}
+	}
+
+	return categorizer.Train(examples), nil
+}
+
+// Predicts a category for a saved transaction without one. Confident
+// predictions are saved as they are. Unsure ones are flagged for review, unless
+// the merchant's category already covers the transaction
+func (c *Categories) categorize(ctx context.Context, repo db.Repository, model *categorizer.Model, transaction db.Transaction, merchant *db.Merchant) error {
+	if model == nil || transaction.Categoryid.Valid {
+		return nil
+	}
+
+	prediction, ok := model.Predict(categorizer.Transaction{
+		Description: transaction.Description,
+		MerchantId:  transaction.Merchantid,
+		Amount:      transaction.Amount,
+		Type:        transaction.Type,
+	})
+
+	if !ok {
+		return nil
+	}
+
+	threshold := c.ReviewThreshold
+
+	if threshold == 0 {
+		threshold = DefaultReviewThreshold
+	}
+
+	needsReview := prediction.Confidence < threshold
+
+	if needsReview && merchant.Categoryid.Valid {
+		return nil
+	}
+
+	return repo.SetTransactionPrediction(ctx, db.SetTransactionPredictionParams{
+		ID:                 transaction.ID,
+		Ownerid:            transaction.Ownerid,
+		Categoryid:         uuid.NullUUID{UUID: prediction.CategoryId, Valid: true},
+		Categoryconfidence: sql.NullFloat64{Float64: prediction.Confidence, Valid: true},
+		Needsreview:        needsReview,
+	})
+}
+
+// CategoryPaths names each category by its path from the top level category,
+// e.g. Food:Groceries, the way QIF files name categories
+func CategoryPaths(categories []db.Category) map[uuid.UUID]string {
+	byId := make(map[uuid.UUID]db.Category, len(categories))
+
+	for _, category := range categories {
+		byId[category.ID] = category
+	}
+
+	paths := make(map[uuid.UUID]string, len(categories))
+
+	for _, category := range categories {
+		path := category.Name
+		parent := category.Parentid
+
+		// Depth is capped in case of a cycle
+		for depth := 0; parent.Valid && depth < len(categories); depth++ {
+			next, ok := byId[parent.UUID]
+
+			if !ok {
+				break
+			}
+
+			path = next.Name + ":" + path
+			parent = next.Parentid
+		}
+
+		paths[category.ID] = path
+	}
+
+	return paths
+}
+
+// Maps the user's category paths in uppercase onto their ids. The user's
+// own categories take the place of default categories with the same path
+func listCategoryIds(ctx context.Context, repo db.Repository, userId uuid.UUID) (map[string]uuid.UUID, error) {
+	categories, err := repo.ListCategories(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uuid.UUID, len(categories))
+	paths := CategoryPaths(categories)
+
+	for _, category := range categories {
+		path := strings.ToUpper(paths[category.ID])
+
+		if _, ok := ids[path]; !ok || category.Ownerid.Valid {
+			ids[path] = category.ID
+		}
+	}
+
+	return ids, nil
+}
+
+// Saves the category and parts a file gives a transaction, e.g. QIF L and S
+// lines. Categories the user doesn't have are left empty. Transactions the
+// user already categorized or split keep their category and parts
+func applyFileCategories(ctx context.Context, repo db.Repository, transaction db.Transaction, tx NormalizedTransaction, categoryIds map[string]uuid.UUID) (db.Transaction, error) {
+	categoryId := func(path string) uuid.NullUUID {
+		id, ok := categoryIds[strings.ToUpper(path)]
+		return uuid.NullUUID{UUID: id, Valid: ok}
+	}
+
+	if len(tx.Splits) > 0 {
+		existing, err := repo.ListTransactionSplitsByTransactionIds(ctx, db.ListTransactionSplitsByTransactionIdsParams{
+			Ownerid:        transaction.Ownerid,
+			Transactionids: []string{transaction.ID.String()},
+		})
+
+		if err != nil || len(existing) > 0 {
+			return transaction, err
+		}
+
+		parts := make([]db.TransactionSplitPart, len(tx.Splits))
+		remaining := transaction.Amount
+
+		for i, split := range tx.Splits {
+			parts[i] = db.TransactionSplitPart{
+				Amount:     utils.FormatCurrencyInt(split.Amount),
+				Categoryid: categoryId(split.Category),
+				Note:       split.Memo,
+			}
+			remaining -= parts[i].Amount
+		}
+
+		// Parts are rounded to cents on their own, so the last part
+		// takes up any rounding difference
+		parts[len(parts)-1].Amount += remaining
+
+		return repo.SplitTransaction(ctx, db.SplitTransactionParams{
+			TransactionId: transaction.ID,
+			Parts:         parts,
+			UserId:        transaction.Ownerid,
+		})
+	}
+
+	if id := categoryId(tx.Category); id.Valid && !transaction.Categoryid.Valid {
+		return repo.SetTransactionCategory(ctx, db.SetTransactionCategoryParams{
+			ID:         transaction.ID,
+			Ownerid:    transaction.Ownerid,
+			Categoryid: id,
+		})
+	}
+
+	return transaction, nil
+}
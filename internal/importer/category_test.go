@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+type fileCategoryRepository struct {
+	db.Repository
+	categories []db.Category
+	splits     []db.TransactionSplit
+	parts      []db.TransactionSplitPart
+	categoryId uuid.NullUUID
+}
+
+func (r *fileCategoryRepository) ListCategories(ctx context.Context, ownerid uuid.UUID) ([]db.Category, error) {
+	return r.categories, nil
+}
+
+func (r *fileCategoryRepository) ListTransactionSplitsByTransactionIds(ctx context.Context, arg db.ListTransactionSplitsByTransactionIdsParams) ([]db.TransactionSplit, error) {
+	return r.splits, nil
+}
+
+func (r *fileCategoryRepository) SplitTransaction(ctx context.Context, arg db.SplitTransactionParams) (db.Transaction, error) {
+	r.parts = arg.Parts
+	return db.Transaction{ID: arg.TransactionId, Ownerid: arg.UserId}, nil
+}
+
+func (r *fileCategoryRepository) SetTransactionCategory(ctx context.Context, arg db.SetTransactionCategoryParams) (db.Transaction, error) {
+	r.categoryId = arg.Categoryid
+	return db.Transaction{ID: arg.ID, Ownerid: arg.Ownerid, Categoryid: arg.Categoryid}, nil
+}
+
+func TestCategoryPaths(t *testing.T) {
+	food := db.Category{ID: uuid.New(), Name: "Food"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries", Parentid: uuid.NullUUID{UUID: food.ID, Valid: true}}
+	orphan := db.Category{ID: uuid.New(), Name: "Orphan", Parentid: uuid.NullUUID{UUID: uuid.New(), Valid: true}}
+
+	got := CategoryPaths([]db.Category{groceries, food, orphan})
+	want := map[uuid.UUID]string{
+		food.ID:      "Food",
+		groceries.ID: "Food:Groceries",
+		orphan.ID:    "Orphan",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CategoryPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestApplyFileCategories(t *testing.T) {
+	userId := uuid.New()
+	food := db.Category{ID: uuid.New(), Name: "Food"}
+	groceries := db.Category{ID: uuid.New(), Name: "Groceries", Parentid: uuid.NullUUID{UUID: food.ID, Valid: true}}
+	household := db.Category{ID: uuid.New(), Name: "Household"}
+	// The user's own category replaces the default with the same name
+	userHousehold := db.Category{ID: uuid.New(), Name: "household", Ownerid: uuid.NullUUID{UUID: userId, Valid: true}}
+	categories := []db.Category{food, groceries, household, userHousehold}
+	id := func(category db.Category) uuid.NullUUID {
+		return uuid.NullUUID{UUID: category.ID, Valid: true}
+	}
+
+	tests := []struct {
+		name         string
+		transaction  db.Transaction
+		tx           NormalizedTransaction
+		splits       []db.TransactionSplit
+		wantCategory uuid.NullUUID
+		wantParts    []db.TransactionSplitPart
+	}{
+		{
+			name:         "category",
+			transaction:  db.Transaction{Amount: -450},
+			tx:           NormalizedTransaction{Category: "food:groceries"},
+			wantCategory: id(groceries),
+		},
+		{
+			name:        "unknown category",
+			transaction: db.Transaction{Amount: -450},
+			tx:          NormalizedTransaction{Category: "Travel"},
+		},
+		{
+			name:        "already categorized",
+			transaction: db.Transaction{Amount: -450, Categoryid: id(food)},
+			tx:          NormalizedTransaction{Category: "Food:Groceries"},
+		},
+		{
+			name:        "splits",
+			transaction: db.Transaction{Amount: -12000},
+			tx: NormalizedTransaction{
+				Category: "Food:Groceries",
+				Splits: []NormalizedSplit{
+					{Category: "Food:Groceries", Memo: "Weekly shop", Amount: -80},
+					{Category: "Household", Amount: -40},
+					{Category: "Travel", Memo: "Snacks", Amount: 0},
+				},
+			},
+			wantParts: []db.TransactionSplitPart{
+				{Amount: -8000, Categoryid: id(groceries), Note: "Weekly shop"},
+				{Amount: -4000, Categoryid: id(userHousehold)},
+				{Amount: 0, Note: "Snacks"},
+			},
+		},
+		{
+			name:        "rounding goes to the last part",
+			transaction: db.Transaction{Amount: -1000},
+			tx: NormalizedTransaction{
+				Splits: []NormalizedSplit{
+					{Category: "Food", Amount: -3.333},
+					{Category: "Food", Amount: -3.333},
+					{Category: "Food", Amount: -3.334},
+				},
+			},
+			wantParts: []db.TransactionSplitPart{
+				{Amount: -333, Categoryid: id(food)},
+				{Amount: -333, Categoryid: id(food)},
+				{Amount: -334, Categoryid: id(food)},
+			},
+		},
+		{
+			name:        "already split",
+			transaction: db.Transaction{Amount: -12000},
+			tx: NormalizedTransaction{
+				Splits: []NormalizedSplit{{Category: "Household", Amount: -120}},
+			},
+			splits: []db.TransactionSplit{{Amount: -12000}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fileCategoryRepository{categories: categories, splits: tt.splits}
+			categoryIds, err := listCategoryIds(context.Background(), repo, userId)
+
+			if err != nil {
+				t.Fatalf("listCategoryIds() error = %v", err)
+			}
+
+			tt.transaction.ID = uuid.New()
+			tt.transaction.Ownerid = userId
+
+			if _, err := applyFileCategories(context.Background(), repo, tt.transaction, tt.tx, categoryIds); err != nil {
+				t.Fatalf("applyFileCategories() error = %v", err)
+			}
+
+			if repo.categoryId != tt.wantCategory {
+				t.Errorf("category = %v, want %v", repo.categoryId, tt.wantCategory)
+			}
+
+			if !reflect.DeepEqual(repo.parts, tt.wantParts) {
+				t.Errorf("parts = %+v, want %+v", repo.parts, tt.wantParts)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package importer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jdkato/prose/v2"
+)
+
+// MerchantExtractor names the merchant in a transaction description.
+// Extractors return false when they can't name the merchant, so the next
+// extractor in a chain can try
+type MerchantExtractor interface {
+	ExtractMerchant(description string) (string, bool)
+}
+
+// MerchantExtractorFunc lets a function be used as a MerchantExtractor
+type MerchantExtractorFunc func(description string) (string, bool)
+
+func (f MerchantExtractorFunc) ExtractMerchant(description string) (string, bool) {
+	return f(description)
+}
+
+// ExtractorChain removes processor noise from a description with Cleaner,
+// then tries each extractor in order with the cleaned description
+type ExtractorChain struct {
+	Cleaner    *NoiseCleaner // nil leaves descriptions as they are
+	Extractors []MerchantExtractor
+}
+
+func (c ExtractorChain) ExtractMerchant(description string) (string, bool) {
+	if c.Cleaner != nil {
+		description = c.Cleaner.Clean(description)
+	}
+
+	for _, extractor := range c.Extractors {
+		if name, ok := extractor.ExtractMerchant(description); ok {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// DefaultMerchantExtractor cleans the description, looks it up in the
+// merchant dictionary and falls back to NLP, then to the cleaned description
+var DefaultMerchantExtractor MerchantExtractor = ExtractorChain{
+	Cleaner: DefaultNoiseCleaner,
+	Extractors: []MerchantExtractor{
+		DefaultMerchantDictionary,
+		ProseExtractor{},
+		CleanedDescription{},
+	},
+}
+
+type noisePattern struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// NoiseCleaner removes the parts of a description added by banks and card
+// processors, like ACH ids, store numbers, dates and locations
+type NoiseCleaner struct {
+	patterns []noisePattern
+}
+
+// NewNoiseCleaner builds a cleaner from case-insensitive patterns that are
+// removed in order
+func NewNoiseCleaner(patterns ...string) *NoiseCleaner {
+	cleaner := &NoiseCleaner{}
+
+	for _, pattern := range patterns {
+		cleaner.patterns = append(cleaner.patterns, noisePattern{
+			pattern:     regexp.MustCompile("(?i)" + pattern),
+			replacement: " ",
+		})
+	}
+
+	return cleaner
+}
+
+func (c *NoiseCleaner) Clean(description string) string {
+	for _, noise := range c.patterns {
+		description = noise.pattern.ReplaceAllString(description, noise.replacement)
+	}
+
+	return strings.Trim(strings.Join(strings.Fields(description), " "), " -*#,.")
+}
+
+var usStates = `AL|AK|AZ|AR|CA|CO|CT|DC|DE|FL|GA|HI|ID|IL|IN|IA|KS|KY|LA|ME|MD|MA|MI|MN|MS|MO|MT|NE|NV|NH|NJ|NM|NY|NC|ND|OH|OK|OR|PA|RI|SC|SD|TN|TX|UT|VT|VA|WA|WV|WI|WY`
+
+var multiWordCities = `SAN FRANCISCO|SAN JOSE|SAN DIEGO|LOS ANGELES|NEW YORK|LAS VEGAS|SALT LAKE CITY|LAKE OSWEGO|WEST LINN|OREGON CITY`
+
+// DefaultNoiseCleaner removes the noise seen in US bank statements
+var DefaultNoiseCleaner = NewNoiseCleaner(
+	// ACH and card processor ids, e.g. PPD ID: 6371542226
+	`\b(PPD|WEB|CCD|TEL|ARC|BOC|POP|IAT|CTX)\s+ID:?\s*\S+`,
+	`\b(TRANSACTION|CONF|REF)\s*#?:\s*\S+`,
+	// Payment processors and peer to peer prefixes
+	`^(SQ|TST|SP|PAYPAL|PY|IN|CKE|GOOGLE|APPLE PAY)\s*\*`,
+	`^(POS|DEBIT CARD PURCHASE|CHECKCARD|PURCHASE AUTHORIZED ON|RECURRING PAYMENT AUTHORIZED ON)\b`,
+	`^ZELLE PAYMENT (TO|FROM)\b`,
+	// ACH entry descriptions, which follow the company name
+	`\s(ACH PMT|MOBILE PMT|ONLINE PMT|WEB PMTS?|E-?PAYMENT|AUTOPAY|DIR(ECT)? DEP|PAYROLL|SALARY|USATAXPYMT|PAYMENTS?|PMT|ACH)\b.*$`,
+	`\bENDING IN \d+`,
+	// Store numbers and everything after them, which is the store's location
+	`\s(#|STORE\s*#?)\s*\d+.*$`,
+	// Dates and phone numbers
+	`\b\d{1,2}/\d{1,2}(/\d{2,4})?\b`,
+	`\b\d{3}-\d{3}-\d{4}\b`,
+	`\b\d{3}-\d{7}\b`,
+	// Website domains, keeping the name, e.g. NETFLIX.COM
+	`\.(COM|NET|ORG|CO)\b(/\S*)?`,
+	`\S+/\S*`,
+	// Order numbers after a star, e.g. AMZN Mktp US*2K4L81Q30
+	`\*`,
+	// Ids, store numbers and amounts: tokens with three or more digits
+	`\b[A-Z]*\d[A-Z\d]*\d[A-Z\d]*\d[A-Z\d]*\b`,
+	// City and state at the end of card purchases
+	`\s(`+multiWordCities+`)\s(`+usStates+`)$`,
+	`\s[A-Z][A-Z.']+\s(`+usStates+`)$`,
+	`\s(`+usStates+`)$`,
+)
+
+// CleanedDescription names the merchant with the description as it is,
+// which after cleanup is usually close to the merchant's name
+type CleanedDescription struct{}
+
+func (CleanedDescription) ExtractMerchant(description string) (string, bool) {
+	description = strings.TrimSpace(description)
+	return description, description != ""
+}
+
+// MerchantDictionary names well known merchants by the words their
+// descriptions start with or contain. Aliases are uppercase
+type MerchantDictionary struct {
+	aliases []string
+	names   map[string]string
+}
+
+// NewMerchantDictionary builds a dictionary from aliases mapped to the
+// merchant's name
+func NewMerchantDictionary(names map[string]string) *MerchantDictionary {
+	dictionary := &MerchantDictionary{names: map[string]string{}}
+
+	for alias, name := range names {
+		alias = strings.Join(strings.Fields(strings.ToUpper(alias)), " ")
+		dictionary.aliases = append(dictionary.aliases, alias)
+		dictionary.names[alias] = name
+	}
+
+	// Longer aliases are more specific, e.g. UBER EATS before UBER
+	sort.Slice(dictionary.aliases, func(i, j int) bool {
+		if len(dictionary.aliases[i]) != len(dictionary.aliases[j]) {
+			return len(dictionary.aliases[i]) > len(dictionary.aliases[j])
+		}
+
+		return dictionary.aliases[i] < dictionary.aliases[j]
+	})
+
+	return dictionary
+}
+
+func (d *MerchantDictionary) ExtractMerchant(description string) (string, bool) {
+	words := " " + strings.Join(strings.Fields(strings.ToUpper(description)), " ") + " "
+
+	for _, alias := range d.aliases {
+		if strings.HasPrefix(words, " "+alias+" ") {
+			return d.names[alias], true
+		}
+	}
+
+	for _, alias := range d.aliases {
+		if strings.Contains(words, " "+alias+" ") {
+			return d.names[alias], true
+		}
+	}
+
+	return "", false
+}
+
+// DefaultMerchantDictionary holds merchants whose descriptions are
+// abbreviated or vary between stores
+var DefaultMerchantDictionary = NewMerchantDictionary(map[string]string{
+	"AMZN":                  "Amazon",
+	"AMZN MKTP":             "Amazon",
+	"AMAZON":                "Amazon",
+	"AMAZON PRIME":          "Amazon Prime",
+	"AMEX":                  "American Express",
+	"AMERICAN EXPRESS":      "American Express",
+	"APPLE":                 "Apple",
+	"ATT":                   "AT&T",
+	"AT&T":                  "AT&T",
+	"BEST BUY":              "Best Buy",
+	"CAPITAL ONE":           "Capital One",
+	"CHASE CARD":            "Chase Card",
+	"PAYMENT TO CHASE CARD": "Chase Card",
+	"CITI":                  "Citi",
+	"COMCAST":               "Comcast",
+	"COSTCO GAS":            "Costco Gas",
+	"COSTCO":                "Costco",
+	"COSTCO WHSE":           "Costco",
+	"DISCOVER":              "Discover",
+	"DOORDASH":              "DoorDash",
+	"FRED-MEYER":            "Fred Meyer",
+	"FRED MEYER":            "Fred Meyer",
+	"GEICO":                 "GEICO",
+	"HOME DEPOT":            "The Home Depot",
+	"THE HOME DEPOT":        "The Home Depot",
+	"LOWES":                 "Lowe's",
+	"LYFT":                  "Lyft",
+	"MCDONALD'S":            "McDonald's",
+	"MCDONALDS":             "McDonald's",
+	"NETFLIX":               "Netflix",
+	"NEWREZ-SHELLPOIN":      "NewRez",
+	"NW NATURAL":            "NW Natural",
+	"PGE":                   "Portland General Electric",
+	"SCHWAB":                "Charles Schwab",
+	"SCHWAB BROKERAGE":      "Charles Schwab",
+	"SHELL OIL":             "Shell",
+	"SPOTIFY":               "Spotify",
+	"STARBUCKS":             "Starbucks",
+	"STATE FARM":            "State Farm",
+	"STEAM GAMES":           "Steam",
+	"T-MOBILE":              "T-Mobile",
+	"TARGET":                "Target",
+	"TRADER JOE S":          "Trader Joe's",
+	"TRADER JOES":           "Trader Joe's",
+	"UBER":                  "Uber",
+	"UBER EATS":             "Uber Eats",
+	"VENMO":                 "Venmo",
+	"VERIZON":               "Verizon",
+	"WAL-MART":              "Walmart",
+	"WALMART":               "Walmart",
+	"WM SUPERCENTER":        "Walmart",
+	"WHOLEFDS":              "Whole Foods",
+	"WHOLE FOODS":           "Whole Foods",
+	"WINCO":                 "WinCo Foods",
+	"YOUTUBE TV":            "YouTube TV",
+	"YOUTUBE":               "YouTube",
+})
+
+// ProseExtractor names the merchant with the first named entity prose finds
+// in the description
+type ProseExtractor struct{}
+
+func (ProseExtractor) ExtractMerchant(description string) (string, bool) {
+	doc, err := prose.NewDocument(description, prose.WithSegmentation(false))
+
+	if err != nil || len(doc.Entities()) == 0 {
+		return "", false
+	}
+
+	return doc.Entities()[0].Text, true
+}
+
+// TokenExtractor names the merchant with the first noun, verb, modal or
+// adverb prose tags in the description, which was how merchants were named
+// before extractor chains
+type TokenExtractor struct{}
+
+func (TokenExtractor) ExtractMerchant(description string) (string, bool) {
+	doc, err := prose.NewDocument(description, prose.WithSegmentation(false))
+
+	if err != nil {
+		return "", false
+	}
+
+	if len(doc.Entities()) > 0 {
+		return doc.Entities()[0].Text, true
+	}
+
+	for _, token := range doc.Tokens() {
+		if len(token.Tag) >= 2 && (token.Tag[0:2] == "NN" ||
+			token.Tag[0:2] == "VB" ||
+			token.Tag[0:2] == "MD" ||
+			token.Tag[0:2] == "RB") {
+			return token.Text, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,137 @@
+package importer
+
+import "testing"
+
+func TestNoiseCleanerClean(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{
+			name:        "ACH id",
+			description: "COMCAST CABLE PPD ID: 0000123456",
+			want:        "COMCAST CABLE",
+		},
+		{
+			name:        "ACH entry description",
+			description: "NEWREZ-SHELLPOIN ACH PMT 240115 WEB ID: 1234567",
+			want:        "NEWREZ-SHELLPOIN",
+		},
+		{
+			name:        "payment processor prefix",
+			description: "SQ *BLUE STAR DONUTS",
+			want:        "BLUE STAR DONUTS",
+		},
+		{
+			name:        "store number and location",
+			description: "SAFEWAY #1234 PORTLAND OR",
+			want:        "SAFEWAY",
+		},
+		{
+			name:        "city and state",
+			description: "POWELLS BOOKS PORTLAND OR",
+			want:        "POWELLS BOOKS",
+		},
+		{
+			name:        "multi word city",
+			description: "TARTINE BAKERY SAN FRANCISCO CA",
+			want:        "TARTINE BAKERY",
+		},
+		{
+			name:        "website and order number",
+			description: "AMZN Mktp US*2K4L81Q30 Amzn.com/bill WA",
+			want:        "AMZN Mktp US Amzn",
+		},
+		{
+			name:        "date and phone number",
+			description: "PURCHASE AUTHORIZED ON 01/15 NETFLIX.COM 866-579-7172 CA",
+			want:        "NETFLIX",
+		},
+		{
+			name:        "nothing to clean",
+			description: "  Corner   Bakery  ",
+			want:        "Corner Bakery",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultNoiseCleaner.Clean(tt.description); got != tt.want {
+				t.Errorf("Clean(%q) = %q, want %q", tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNoiseCleaner(t *testing.T) {
+	cleaner := NewNoiseCleaner(`\bREF\d+`, `^ONLINE\s`)
+
+	if got, want := cleaner.Clean("online transfer ref12345 to savings."), "transfer to savings"; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
+
+func TestMerchantDictionaryExtractMerchant(t *testing.T) {
+	dictionary := NewMerchantDictionary(map[string]string{
+		"UBER":      "Uber",
+		"uber eats": "Uber Eats",
+		"EATS":      "Eats",
+		"TARGET":    "Target",
+	})
+
+	tests := []struct {
+		name        string
+		description string
+		want        string
+		wantOk      bool
+	}{
+		{name: "alias", description: "TARGET", want: "Target", wantOk: true},
+		{name: "ignores case and spacing", description: "uber   eats  pending", want: "Uber Eats", wantOk: true},
+		{name: "longer alias first", description: "UBER EATS", want: "Uber Eats", wantOk: true},
+		{name: "prefix before contained alias", description: "EATS BY UBER", want: "Eats", wantOk: true},
+		{name: "contained alias", description: "PAYPAL TARGET", want: "Target", wantOk: true},
+		{name: "whole words only", description: "UBERRIDE TARGETED", wantOk: false},
+		{name: "unknown", description: "CORNER BAKERY", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dictionary.ExtractMerchant(tt.description)
+
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ExtractMerchant(%q) = %q, %v, want %q, %v", tt.description, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestExtractorChain(t *testing.T) {
+	chain := ExtractorChain{
+		Cleaner: DefaultNoiseCleaner,
+		Extractors: []MerchantExtractor{
+			DefaultMerchantDictionary,
+			CleanedDescription{},
+		},
+	}
+
+	tests := []struct {
+		description string
+		want        string
+		wantOk      bool
+	}{
+		{description: "WHOLEFDS PDX 10234 PORTLAND OR", want: "Whole Foods", wantOk: true},
+		{description: "SQ *BLUE STAR DONUTS", want: "BLUE STAR DONUTS", wantOk: true},
+		{description: "#1234", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			got, ok := chain.ExtractMerchant(tt.description)
+
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ExtractMerchant(%q) = %q, %v, want %q, %v", tt.description, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+// Package importer saves parsed statements. Every upload, sync provider and
+// import command parses its source into NormalizedStatements and imports
+// them through a Service, so accounts, balances, transactions and merchants
+// are saved the same way whatever the source
+package importer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/categorizer"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/jobs"
+)
+
+type Service struct {
+	Repository db.Repository
+	// Finds the merchant for each imported transaction
+	Merchants MerchantResolver
+	// Predicts categories for imported transactions. Nil leaves them
+	// uncategorized
+	Categories *Categories
+}
+
+func New(repo db.Repository) *Service {
+	return &Service{
+		Repository: repo,
+		Merchants:  RuleMerchants{Fallback: DescriptionMerchants{}},
+		Categories: &Categories{},
+	}
+}
+
+type Stats struct {
+	Updated int
+	Failed  int
+}
+
+// AccountResult is the outcome of importing one statement
+type AccountResult struct {
+	Name         string
+	Account      *db.Account
+	SyncItem     *db.AccountSyncItem
+	Transactions Stats
+	Errors       []string
+}
+
+// Result is the outcome of an import. Rejected transactions are counted as
+// failed with their reason in Errors, without failing the import
+type Result struct {
+	Success bool
+	// The file was already imported and was skipped
+	Duplicate    bool
+	Accounts     Stats
+	Transactions Stats
+	Statements   []AccountResult
+	Errors       []string
+	ImportBatch  *db.ImportBatch
+}
+
+func NewResult() *Result {
+	return &Result{
+		Statements: []AccountResult{},
+		Errors:     []string{},
+	}
+}
+
+// File to import for a user
+type File struct {
+	Name         string
+	Content      []byte
+	UploadSource db.UploadSource
+	Parser       Parser
+}
+
+// ImportFile parses and imports a file inside a single database transaction,
+// recording the result as an import batch. A file the user already imported
+// is skipped. If anything fails the whole import is rolled back
+func (s *Service) ImportFile(ctx context.Context, userId uuid.UUID, file File) (*Result, error) {
+	result := NewResult()
+	fileHash := hashFile(file.Content)
+	batch, err := s.Repository.GetCompletedImportBatch(ctx, db.GetCompletedImportBatchParams{
+		Ownerid:  userId,
+		Filehash: fileHash,
+	})
+
+	if err == nil {
+		log.Printf("Skipping duplicate upload: %s", file.Name)
+		result.Success = true
+		result.Duplicate = true
+		result.ImportBatch = &batch
+		return result, nil
+	}
+
+	// Only a missing batch means the file wasn't imported yet
+	if !errors.Is(err, sql.ErrNoRows) {
+		return result, fmt.Errorf("Failed to check for a previous upload: %w", err)
+	}
+
+	err = s.Repository.WithTx(ctx, func(repo db.Repository) error {
+		statements, err := file.Parser.Parse(bytes.NewReader(file.Content))
+
+		if err != nil {
+			return err
+		}
+
+		if err := s.importStatements(ctx, repo, userId, statements, file.UploadSource, result); err != nil {
+			return err
+		}
+
+		batch, err := repo.CreateImportBatch(ctx, newImportBatchParams(file.Name, fileHash, file.UploadSource, db.ImportStatusCOMPLETED, result, userId))
+
+		if err != nil {
+			return err
+		}
+
+		syncItemIds := []string{}
+
+		for _, statement := range result.Statements {
+			if statement.SyncItem != nil {
+				syncItemIds = append(syncItemIds, statement.SyncItem.ID.String())
+			}
+		}
+
+		err = repo.SetSyncItemsImportBatch(ctx, db.SetSyncItemsImportBatchParams{
+			Importbatchid: uuid.NullUUID{UUID: batch.ID, Valid: true},
+			Syncitemids:   syncItemIds,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.ImportBatch = &batch
+		return nil
+	})
+
+	if err == nil {
+		result.Success = true
+		return result, nil
+	}
+
+	// Nothing from the import was saved, so report the counts as failed
+	failed := NewResult()
+	failed.Accounts.Failed = result.Accounts.Updated + result.Accounts.Failed
+	failed.Transactions.Failed = result.Transactions.Updated + result.Transactions.Failed
+	failed.Errors = append(result.Errors, err.Error())
+
+	batch, batchErr := s.Repository.CreateImportBatch(ctx, newImportBatchParams(file.Name, fileHash, file.UploadSource, db.ImportStatusFAILED, failed, userId))
+
+	if batchErr != nil {
+		log.Printf("Failed to record import batch: %v", batchErr)
+	} else {
+		failed.ImportBatch = &batch
+	}
+
+	return failed, err
+}
+
+// Import saves statements with repo, for callers that manage their own
+// database transaction such as sync providers. The import stops at the first
+// statement that fails to save. Success is left for the caller to set once
+// its transaction commits
+func (s *Service) Import(ctx context.Context, repo db.Repository, userId uuid.UUID, statements []NormalizedStatement, uploadSource db.UploadSource) (*Result, error) {
+	result := NewResult()
+	err := s.importStatements(ctx, repo, userId, statements, uploadSource, result)
+
+	return result, err
+}
+
+func (s *Service) importStatements(ctx context.Context, repo db.Repository, userId uuid.UUID, statements []NormalizedStatement, uploadSource db.UploadSource, result *Result) error {
+	model, err := s.Categories.train(ctx, repo, userId)
+
+	if err != nil {
+		return err
+	}
+
+	merchants, err := s.ImportMerchants(ctx, repo, userId, uploadSource)
+
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range statements {
+		stats, err := s.importStatement(ctx, repo, userId, statement, uploadSource, model, merchants)
+		result.Statements = append(result.Statements, stats)
+		result.Transactions.Updated += stats.Transactions.Updated
+		result.Transactions.Failed += stats.Transactions.Failed
+		result.Errors = append(result.Errors, stats.Errors...)
+
+		if err != nil {
+			result.Accounts.Failed++
+			return fmt.Errorf("Failed to upload account %s: %w", utils.MaskData(statement.Account.SourceId), err)
+		}
+
+		// Increment successful account upload
+		result.Accounts.Updated++
+	}
+
+	return nil
+}
+
+// Upserts a single statement's account and transactions. Statements
+// from formats without a balance have a zero BalanceDate
+func (s *Service) importStatement(ctx context.Context, repo db.Repository, userId uuid.UUID, statement NormalizedStatement, uploadSource db.UploadSource, model *categorizer.Model, merchants MerchantResolver) (AccountResult, error) {
+	stats := AccountResult{
+		Name:   statement.Account.Name,
+		Errors: []string{},
+	}
+
+	if err := CheckStatementOwner(ctx, repo, userId, statement); err != nil {
+		return stats, err
+	}
+
+	account, err := repo.UpsertAccount(ctx, db.UpsertAccountParams{
+		Sourceid:      statement.Account.SourceId,
+		Name:          statement.Account.Name,
+		Type:          statement.Account.Type,
+		Routingnumber: sql.NullString{String: statement.Account.BankId, Valid: len(statement.Account.BankId) > 0},
+		Updated:       time.Now(),
+		Ownerid:       userId,
+	})
+
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Account = &account
+
+	syncItem, err := repo.CreateAccountSyncItem(ctx, db.CreateAccountSyncItemParams{
+		Accountid:    account.ID,
+		Uploadsource: uploadSource,
+	})
+
+	if err != nil {
+		return stats, err
+	}
+
+	stats.SyncItem = &syncItem
+
+	if !statement.Account.BalanceDate.IsZero() {
+		_, err = repo.UpsertAccountBalance(ctx, db.UpsertAccountBalanceParams{
+			Accountid:  account.ID,
+			Date:       statement.Account.BalanceDate,
+			Current:    utils.FormatCurrencyInt(statement.Account.CurrentBalance),
+			Available:  nullCurrency(statement.Account.AvailableBalance),
+			Ownerid:    userId,
+			Syncitemid: uuid.NullUUID{UUID: syncItem.ID, Valid: true},
+		})
+
+		if err != nil {
+			return stats, err
+		}
+	}
+
+	pending, err := listReconcilableTransactions(ctx, repo, account.ID, statement.Transactions)
+
+	if err != nil {
+		return stats, err
+	}
+
+	// Loaded with the first transaction the file categorizes
+	var categoryIds map[string]uuid.UUID
+	seen := map[string]bool{}
+	job := jobs.FromContext(ctx)
+	job.AddTotal(len(statement.Transactions))
+
+	for _, tx := range statement.Transactions {
+		if err := ValidateTransaction(tx, seen); err != nil {
+			stats.Transactions.Failed++
+			stats.Errors = append(stats.Errors, fmt.Sprintf("Transaction %s: %v", tx.SourceId, err))
+			job.Advance(fmt.Sprintf("Rejected transaction %s: %v", tx.SourceId, err))
+			continue
+		}
+
+		merchant, err := s.linkMerchant(ctx, repo, merchants, userId, tx, syncItem)
+
+		if err != nil {
+			stats.Transactions.Failed++
+			return stats, err
+		}
+
+		params := db.UpsertTransactionParams{
+			Ownerid:         userId,
+			Amount:          utils.FormatCurrencyInt(tx.Amount),
+			Payeeid:         sql.NullString{String: tx.PayeeId, Valid: len(tx.PayeeId) > 0},
+			Payee:           sql.NullString{String: tx.Payee, Valid: len(tx.Payee) > 0},
+			Payeefull:       sql.NullString{String: tx.PayeeFull, Valid: len(tx.PayeeFull) > 0},
+			Sourceid:        tx.SourceId,
+			Isocurrencycode: statement.Account.IsoCurrencyCode,
+			Date:            tx.Date,
+			Description:     tx.Description,
+			Type:            tx.Type,
+			Updated:         time.Now(),
+			Checknumber:     sql.NullString{String: tx.CheckNumber, Valid: len(tx.CheckNumber) > 0},
+			Accountid:       account.ID,
+			Merchantid:      merchant.ID,
+			Syncitemid:      uuid.NullUUID{UUID: syncItem.ID, Valid: true},
+			Status:          transactionStatus(tx),
+			Authorizeddate:  sql.NullTime{Time: tx.AuthorizedDate, Valid: !tx.AuthorizedDate.IsZero()},
+		}
+
+		i, ok, err := matchPendingTransaction(ctx, repo, tx, params, pending)
+
+		if err != nil {
+			stats.Transactions.Failed++
+			return stats, fmt.Errorf("Transaction %s: %w", tx.SourceId, err)
+		}
+
+		var transaction db.Transaction
+
+		if ok {
+			transaction, err = repo.ReconcileTransaction(ctx, db.ReconcileTransactionParams{
+				PendingSourceId: pending[i].Sourceid,
+				Transaction:     params,
+			})
+			pending = append(pending[:i], pending[i+1:]...)
+		} else {
+			transaction, err = repo.SyncTransaction(ctx, params)
+		}
+
+		if err == nil && categoryIds == nil && (tx.Category != "" || len(tx.Splits) > 0) {
+			categoryIds, err = listCategoryIds(ctx, repo, userId)
+		}
+
+		if err == nil && categoryIds != nil {
+			transaction, err = applyFileCategories(ctx, repo, transaction, tx, categoryIds)
+		}
+
+		if err == nil {
+			err = s.Categories.categorize(ctx, repo, model, transaction, merchant)
+		}
+
+		if err != nil {
+			stats.Transactions.Failed++
+			return stats, fmt.Errorf("Transaction %s: %w", tx.SourceId, err)
+		}
+
+		// Increment successful transaction upload
+		stats.Transactions.Updated++
+		job.Advance(fmt.Sprintf("Imported transaction %s", tx.SourceId))
+	}
+
+	if statement.Investment != nil {
+		if err := importInvestment(ctx, repo, userId, account, syncItem, statement, &stats); err != nil {
+			return stats, err
+		}
+	}
+
+	return stats, nil
+}
+
+func newImportBatchParams(fileName string, fileHash string, uploadSource db.UploadSource, status db.ImportStatus, result *Result, userId uuid.UUID) db.CreateImportBatchParams {
+	return db.CreateImportBatchParams{
+		Filename:            fileName,
+		Filehash:            fileHash,
+		Uploadsource:        uploadSource,
+		Status:              status,
+		Accountsupdated:     int32(result.Accounts.Updated),
+		Accountsfailed:      int32(result.Accounts.Failed),
+		Transactionsupdated: int32(result.Transactions.Updated),
+		Transactionsfailed:  int32(result.Transactions.Failed),
+		Errors:              result.Errors,
+		Ownerid:             userId,
+	}
+}
+
+func hashFile(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func nullCurrency(amount *float32) sql.NullInt32 {
+	if amount == nil {
+		return sql.NullInt32{}
+	}
+
+	return sql.NullInt32{Int32: utils.FormatCurrencyInt(*amount), Valid: true}
+}
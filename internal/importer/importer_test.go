@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Repository that fails imports, so only the duplicate check is exercised
+type duplicateRepository struct {
+	db.Repository
+	batch    db.ImportBatch
+	batchErr error
+	imported bool
+}
+
+func (r *duplicateRepository) GetCompletedImportBatch(ctx context.Context, arg db.GetCompletedImportBatchParams) (db.ImportBatch, error) {
+	return r.batch, r.batchErr
+}
+
+func (r *duplicateRepository) WithTx(ctx context.Context, fn func(db.Repository) error) error {
+	r.imported = true
+	return errors.New("import failed")
+}
+
+func (r *duplicateRepository) CreateImportBatch(ctx context.Context, arg db.CreateImportBatchParams) (db.ImportBatch, error) {
+	return db.ImportBatch{Status: arg.Status}, nil
+}
+
+func TestImportFileDuplicateCheck(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchErr      error
+		wantDuplicate bool
+		wantImported  bool
+		wantErr       string
+	}{
+		{
+			name:          "already imported",
+			wantDuplicate: true,
+		},
+		{
+			name:         "not imported yet",
+			batchErr:     sql.ErrNoRows,
+			wantImported: true,
+			wantErr:      "import failed",
+		},
+		{
+			name:     "lookup fails",
+			batchErr: errors.New("connection reset"),
+			wantErr:  "Failed to check for a previous upload: connection reset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &duplicateRepository{batch: db.ImportBatch{ID: uuid.New()}, batchErr: tt.batchErr}
+			result, err := New(repo).ImportFile(context.Background(), uuid.New(), File{Name: "statement.ofx"})
+
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ImportFile() error = %v, want %q", err, tt.wantErr)
+			}
+
+			if result.Duplicate != tt.wantDuplicate {
+				t.Errorf("Duplicate = %v, want %v", result.Duplicate, tt.wantDuplicate)
+			}
+
+			if repo.imported != tt.wantImported {
+				t.Errorf("imported = %v, want %v", repo.imported, tt.wantImported)
+			}
+		})
+	}
+}
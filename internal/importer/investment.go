@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/jobs"
+)
+
+// Saves the securities, position snapshot and investment transactions of an
+// investment statement. Investment transactions are counted with the
+// statement's transactions
+func importInvestment(ctx context.Context, repo db.Repository, userId uuid.UUID, account db.Account, syncItem db.AccountSyncItem, statement NormalizedStatement, stats *AccountResult) error {
+	investment := statement.Investment
+	securityIds := map[chase.ChaseOFXSecurityId]uuid.UUID{}
+
+	for _, security := range investment.Securities {
+		if security.Id.UniqueId == "" {
+			continue
+		}
+
+		saved, err := repo.UpsertSecurity(ctx, db.UpsertSecurityParams{
+			Uniqueid:     security.Id.UniqueId,
+			Uniqueidtype: security.Id.UniqueIdType,
+			Ticker:       sql.NullString{String: security.Ticker, Valid: len(security.Ticker) > 0},
+			Name:         security.Name,
+			Type:         security.Type,
+		})
+
+		if err != nil {
+			return fmt.Errorf("Security %s: %w", security.Id.UniqueId, err)
+		}
+
+		securityIds[security.Id] = saved.ID
+	}
+
+	syncItemId := uuid.NullUUID{UUID: syncItem.ID, Valid: true}
+
+	// The statement's positions replace any snapshot already saved for its date
+	if !statement.Account.BalanceDate.IsZero() {
+		err := repo.DeleteAccountHoldings(ctx, db.DeleteAccountHoldingsParams{
+			Accountid: account.ID,
+			Date:      statement.Account.BalanceDate,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, holding := range investment.Holdings {
+			securityId, ok := securityIds[holding.Security]
+
+			if !ok {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("Holding %s: Missing security", holding.Security.UniqueId))
+				continue
+			}
+
+			_, err := repo.CreateHolding(ctx, db.CreateHoldingParams{
+				Date:        statement.Account.BalanceDate,
+				Units:       holding.Units,
+				Unitprice:   holding.UnitPrice,
+				Marketvalue: utils.FormatCurrencyInt(holding.MarketValue),
+				Securityid:  securityId,
+				Accountid:   account.ID,
+				Ownerid:     userId,
+				Syncitemid:  syncItemId,
+			})
+
+			if err != nil {
+				return fmt.Errorf("Holding %s: %w", holding.Security.UniqueId, err)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	job := jobs.FromContext(ctx)
+	job.AddTotal(len(investment.Transactions))
+
+	for _, tx := range investment.Transactions {
+		if err := validateInvestmentTransaction(tx, seen); err != nil {
+			stats.Transactions.Failed++
+			stats.Errors = append(stats.Errors, fmt.Sprintf("Investment transaction %s: %v", tx.Id, err))
+			job.Advance(fmt.Sprintf("Rejected investment transaction %s: %v", tx.Id, err))
+			continue
+		}
+
+		securityId := uuid.NullUUID{}
+
+		if tx.Security != nil {
+			securityId.UUID, securityId.Valid = securityIds[*tx.Security]
+		}
+
+		_, err := repo.UpsertInvestmentTransaction(ctx, db.UpsertInvestmentTransactionParams{
+			Sourceid:        tx.Id,
+			Type:            tx.Type,
+			Tradedate:       tx.TradeDate,
+			Settledate:      sql.NullTime{Time: tx.SettleDate, Valid: !tx.SettleDate.IsZero()},
+			Units:           tx.Units,
+			Unitprice:       tx.UnitPrice,
+			Amount:          utils.FormatCurrencyInt(tx.Amount),
+			Fees:            utils.FormatCurrencyInt(tx.Fees),
+			Isocurrencycode: statement.Account.IsoCurrencyCode,
+			Description:     tx.Description,
+			Securityid:      securityId,
+			Accountid:       account.ID,
+			Ownerid:         userId,
+			Syncitemid:      syncItemId,
+		})
+
+		if err != nil {
+			stats.Transactions.Failed++
+			return fmt.Errorf("Investment transaction %s: %w", tx.Id, err)
+		}
+
+		stats.Transactions.Updated++
+		job.Advance(fmt.Sprintf("Imported investment transaction %s", tx.Id))
+	}
+
+	return nil
+}
+
+func validateInvestmentTransaction(tx chase.ChaseOFXInvestmentTransaction, seen map[string]bool) error {
+	if tx.Id == "" {
+		return fmt.Errorf("Missing transaction id")
+	}
+
+	if seen[tx.Id] {
+		return fmt.Errorf("Duplicate transaction id in file")
+	}
+
+	seen[tx.Id] = true
+
+	if tx.TradeDate.IsZero() {
+		return fmt.Errorf("Missing trade date")
+	}
+
+	if !tx.Type.Valid() {
+		return fmt.Errorf("Unsupported transaction type: %s", tx.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// MerchantMatch is the merchant found for a transaction. Name and KeyMatch
+// are used to create the merchant when none exists yet
+type MerchantMatch struct {
+	Name     string
+	KeyMatch string
+	Merchant *db.Merchant // nil when no merchant exists yet
+	// Global merchant the new merchant is linked to, if the name is in the
+	// global directory
+	GlobalMerchant *db.GlobalMerchant
+}
+
+// MerchantResolver finds the merchant for a transaction imported from
+// uploadSource without creating anything. The service creates and links a
+// new merchant when none is found
+type MerchantResolver interface {
+	FindMerchant(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource, tx NormalizedTransaction) (MerchantMatch, error)
+}
+
+// importMerchantResolver is a MerchantResolver with data to load once per
+// import rather than for every transaction
+type importMerchantResolver interface {
+	MerchantResolver
+	ForImport(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource) (MerchantResolver, error)
+}
+
+// ImportMerchants returns the merchant resolver to use for every transaction
+// of one import from uploadSource
+func (s *Service) ImportMerchants(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource) (MerchantResolver, error) {
+	return forImport(ctx, s.Merchants, repo, userId, uploadSource)
+}
+
+func forImport(ctx context.Context, merchants MerchantResolver, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource) (MerchantResolver, error) {
+	if m, ok := merchants.(importMerchantResolver); ok {
+		return m.ForImport(ctx, repo, userId, uploadSource)
+	}
+
+	return merchants, nil
+}
+
+// MerchantResolverFunc lets a function be used as a MerchantResolver
+type MerchantResolverFunc func(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource, tx NormalizedTransaction) (MerchantMatch, error)
+
+func (f MerchantResolverFunc) FindMerchant(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource, tx NormalizedTransaction) (MerchantMatch, error) {
+	return f(ctx, repo, userId, uploadSource, tx)
+}
+
+// DescriptionMerchants finds merchants by the merchant id or name parsed
+// from the transaction description
+type DescriptionMerchants struct {
+	// Names merchants. DefaultMerchantExtractor is used when nil
+	Extractor MerchantExtractor
+}
+
+func (m DescriptionMerchants) FindMerchant(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource, tx NormalizedTransaction) (MerchantMatch, error) {
+	match := MerchantMatch{
+		Name: m.merchantName(tx.Description),
+	}
+	merchantId, err := ParseMerchantId(tx.Description)
+
+	if err != nil {
+		merchantId = strings.ToUpper(tx.Description)
+	}
+
+	match.KeyMatch = merchantId
+	merchant, err := repo.GetMerchantBySourceId(ctx, db.GetMerchantBySourceIdParams{
+		Ownerid:  userId,
+		Sourceid: sql.NullString{String: merchantId, Valid: merchantId != ""},
+	})
+
+	if err == nil {
+		match.Merchant = &merchant
+		return match, nil
+	}
+
+	// Names in the global directory use the curated name, so every spelling
+	// of a merchant links to the user's single merchant for it
+	global, err := repo.FindGlobalMerchant(ctx, match.Name)
+
+	if err == nil {
+		merchant, err := repo.GetMerchantByGlobalMerchantId(ctx, db.GetMerchantByGlobalMerchantIdParams{
+			Ownerid:          userId,
+			Globalmerchantid: uuid.NullUUID{UUID: global.ID, Valid: true},
+		})
+
+		if err == nil {
+			match.Merchant = &merchant
+			return match, nil
+		}
+
+		match.Name = global.Name
+		match.GlobalMerchant = &global
+	}
+
+	merchant, err = repo.GetMerchantByName(ctx, db.GetMerchantByNameParams{
+		Ownerid: userId,
+		Name:    match.Name,
+	})
+
+	if err == nil {
+		match.Merchant = &merchant
+	}
+
+	return match, nil
+}
+
+func (m DescriptionMerchants) merchantName(description string) string {
+	if m.Extractor == nil {
+		return ParseMerchantName(description)
+	}
+
+	if name, ok := m.Extractor.ExtractMerchant(description); ok {
+		return name
+	}
+
+	return description
+}
+
+// Finds the merchant for a transaction, creating and linking a
+// new merchant to the sync item if none exist
+func (s *Service) linkMerchant(ctx context.Context, repo db.Repository, merchants MerchantResolver, userId uuid.UUID, tx NormalizedTransaction, syncItem db.AccountSyncItem) (*db.Merchant, error) {
+	match, err := merchants.FindMerchant(ctx, repo, userId, syncItem.Uploadsource, tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if match.Merchant != nil {
+		return match.Merchant, nil
+	}
+
+	// Keys are unique per user, and a merged or split merchant can own a key
+	// that the resolver didn't match
+	if match.KeyMatch != "" {
+		merchant, err := repo.GetMerchantByKeyMatch(ctx, db.GetMerchantByKeyMatchParams{
+			Ownerid:  userId,
+			Keymatch: match.KeyMatch,
+		})
+
+		if err == nil {
+			return &merchant, nil
+		}
+	}
+
+	params := db.LinkMerchantParams{
+		MerchantName: match.Name,
+		KeyMatch:     match.KeyMatch,
+		UploadSource: syncItem.Uploadsource,
+		SourceId:     sql.NullString{String: match.KeyMatch, Valid: match.KeyMatch != ""},
+		SyncItemId:   uuid.NullUUID{UUID: syncItem.ID, Valid: true},
+		UserId:       userId,
+	}
+
+	if match.GlobalMerchant != nil {
+		params.GlobalMerchantId = uuid.NullUUID{UUID: match.GlobalMerchant.ID, Valid: true}
+
+		// The global merchant's category is the new merchant's default category
+		params.CategoryId = match.GlobalMerchant.Categoryid
+	}
+
+	linked, err := repo.LinkMerchant(ctx, params)
+
+	if err != nil {
+		log.Println(err)
+		return nil, fmt.Errorf("Merchant unable to be found: %w", err)
+	}
+
+	return linked, nil
+}
+
+// ParseMerchantName names the merchant in a description with the default
+// extractor chain, falling back to the whole description
+func ParseMerchantName(description string) string {
+	if name, ok := DefaultMerchantExtractor.ExtractMerchant(description); ok {
+		return name
+	}
+
+	return description
+}
+
+func ParseMerchantId(description string) (string, error) {
+	expression := regexp.MustCompile(`\b\d{10}\b`)
+	matches := expression.FindAllString(description, -1)
+
+	if len(matches) > 0 {
+		// Get the last match
+		fmt.Printf("Found merchant id! %s\n", matches[len(matches)-1])
+		return matches[len(matches)-1], nil
+	} else {
+		return "", fmt.Errorf("no merchantId found")
+	}
+}
+
+// LearnMerchantKey returns a key matching every description, for merchants
+// created from a set of transactions. Identical descriptions are matched
+// exactly, otherwise by their common leading words. Returns false when the
+// descriptions have too little in common for a useful key
+func LearnMerchantKey(descriptions []string) (string, bool) {
+	if len(descriptions) == 0 {
+		return "", false
+	}
+
+	normalized := make([]string, len(descriptions))
+
+	for i, description := range descriptions {
+		normalized[i] = strings.Join(strings.Fields(strings.ToUpper(description)), " ")
+	}
+
+	prefix := normalized[0]
+	exact := true
+
+	for _, description := range normalized[1:] {
+		if description != prefix {
+			exact = false
+		}
+
+		for !strings.HasPrefix(description, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	if exact {
+		return escapeKeyMatch(prefix), prefix != ""
+	}
+
+	// Only keep whole words so "WINCO #014" and "WINCO #022" learn "WINCO"
+	for _, description := range normalized {
+		if len(description) > len(prefix) && description[len(prefix)] != ' ' {
+			prefix = prefix[:strings.LastIndex(prefix, " ")+1]
+			break
+		}
+	}
+
+	prefix = strings.TrimSpace(prefix)
+
+	if len(prefix) < minKeyLength {
+		return "", false
+	}
+
+	return escapeKeyMatch(prefix) + "%", true
+}
+
+// Shortest common prefix that is learned as a key
+const minKeyLength = 3
+
+// Escapes LIKE wildcards so descriptions only match literally
+func escapeKeyMatch(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
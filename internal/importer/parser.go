@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/csvimport"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Parser reads a file into statements
+type Parser interface {
+	Parse(reader io.Reader) ([]NormalizedStatement, error)
+}
+
+// ParserFunc lets a function be used as a Parser
+type ParserFunc func(reader io.Reader) ([]NormalizedStatement, error)
+
+func (f ParserFunc) Parse(reader io.Reader) ([]NormalizedStatement, error) {
+	return f(reader)
+}
+
+// Parsers for formats that carry their own account details. Every account in
+// the file is created or updated
+var (
+	OFXParser     = chaseParser(chase.ParseChaseOFX)
+	Camt053Parser = chaseParser(chase.ParseCamt053)
+	MT940Parser   = chaseParser(chase.ParseMT940)
+)
+
+func chaseParser(parse func(io.Reader) ([]chase.ChaseOFXResult, error)) Parser {
+	return ParserFunc(func(reader io.Reader) ([]NormalizedStatement, error) {
+		results, err := parse(reader)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return FromChaseOFX(results), nil
+	})
+}
+
+// CSVParser reads a CSV file into an existing account using the columns
+// mapped by a saved or built-in profile
+func CSVParser(account db.Account, profile db.CsvProfile) Parser {
+	return ParserFunc(func(reader io.Reader) ([]NormalizedStatement, error) {
+		csvTransactions, err := csvimport.Parse(reader, profile)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse CSV file: %w", err)
+		}
+
+		sourceIds := csvimport.SourceIds(account.Sourceid, csvTransactions)
+		transactions := make([]NormalizedTransaction, len(csvTransactions))
+
+		for i, tx := range csvTransactions {
+			transactions[i] = NormalizedTransaction{
+				SourceId:    sourceIds[i],
+				Type:        tx.Type,
+				Date:        tx.Date,
+				Amount:      tx.Amount,
+				CheckNumber: tx.CheckNumber,
+				Description: tx.Description,
+			}
+		}
+
+		statement := newAccountStatement(account, transactions)
+
+		// Files with a running balance column update the account's balance
+		if latest, ok := csvimport.LatestBalance(csvTransactions); ok {
+			statement.Account.CurrentBalance = *latest.Balance
+			statement.Account.BalanceDate = latest.Date
+		}
+
+		return []NormalizedStatement{statement}, nil
+	})
+}
+
+// QIFParser reads a single account QIF file into an existing account
+func QIFParser(account db.Account) Parser {
+	return ParserFunc(func(reader io.Reader) ([]NormalizedStatement, error) {
+		results, err := chase.ParseQIF(reader)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse QIF file: %w", err)
+		}
+
+		if len(results) != 1 {
+			return nil, fmt.Errorf("QIF file contains %d accounts. Upload one account at a time", len(results))
+		}
+
+		sourceIds := chase.QIFSourceIds(account.Sourceid, results[0].Transactions)
+		transactions := make([]NormalizedTransaction, len(results[0].Transactions))
+
+		for i, tx := range results[0].Transactions {
+			transactions[i] = NormalizedTransaction{
+				SourceId:    sourceIds[i],
+				Type:        tx.TransactionType(),
+				Date:        tx.Date,
+				Amount:      tx.Amount,
+				Payee:       tx.Payee,
+				CheckNumber: tx.CheckNumber,
+				Description: tx.Description(),
+				Category:    chase.QIFCategoryName(tx.Category),
+			}
+
+			for _, split := range tx.Splits {
+				transactions[i].Splits = append(transactions[i].Splits, NormalizedSplit{
+					Category: chase.QIFCategoryName(split.Category),
+					Memo:     split.Memo,
+					Amount:   split.Amount,
+				})
+			}
+		}
+
+		return []NormalizedStatement{newAccountStatement(account, transactions)}, nil
+	})
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/proctorinc/banker/internal/csvimport"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/testutil"
+)
+
+func TestParsers(t *testing.T) {
+	account := db.Account{
+		Sourceid: "000123456789",
+		Name:     "Checking",
+		Type:     db.AccountTypeCHECKING,
+	}
+	ofx, err := os.ReadFile("testdata/checking.ofx")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		sourceId     string
+		accountType  db.AccountType
+		balance      float32
+		balanceDate  time.Time
+		transactions []NormalizedTransaction
+	}
+
+	tests := []struct {
+		name    string
+		parser  Parser
+		file    string
+		want    want
+		wantErr string
+	}{
+		{
+			name:   "ofx",
+			parser: OFXParser,
+			file:   string(ofx),
+			want: want{
+				sourceId:    "000123456789",
+				accountType: db.AccountTypeCHECKING,
+				balance:     1234.56,
+				balanceDate: time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC),
+				transactions: []NormalizedTransaction{
+					{SourceId: "202401150", Type: db.TransactionTypeDEBIT, Date: time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC), Amount: -4.5, Payee: "BLUE BOTTLE COFFEE", Description: "BLUE BOTTLE COFFEE SAN FRANCISCO CA"},
+					{SourceId: "202401310", Type: db.TransactionTypeDIRECTDEP, Date: time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC), Amount: 2500, Payee: "ACME CORP PAYROLL", Description: "ACME CORP PAYROLL"},
+				},
+			},
+		},
+		{
+			name:   "chase csv",
+			parser: CSVParser(account, csvimport.ChaseProfile),
+			file: "Details,Posting Date,Description,Amount,Type,Balance,Check or Slip #\n" +
+				"CREDIT,01/31/2024,ACME CORP PAYROLL,2500.00,ACH_CREDIT,3734.56,,\n" +
+				"DEBIT,01/15/2024,BLUE BOTTLE COFFEE,-4.50,DEBIT_CARD,1234.56,,\n",
+			want: want{
+				sourceId:    "000123456789",
+				accountType: db.AccountTypeCHECKING,
+				balance:     3734.56,
+				balanceDate: testutil.Date("2024-01-31"),
+				transactions: []NormalizedTransaction{
+					{Type: db.TransactionTypeDIRECTDEP, Date: testutil.Date("2024-01-31"), Amount: 2500, Description: "ACME CORP PAYROLL"},
+					{Type: db.TransactionTypePOS, Date: testutil.Date("2024-01-15"), Amount: -4.5, Description: "BLUE BOTTLE COFFEE"},
+				},
+			},
+		},
+		{
+			name:    "invalid csv",
+			parser:  CSVParser(account, csvimport.ChaseProfile),
+			file:    "Details,Posting Date,Description,Amount,Type,Balance,Check or Slip #\nDEBIT,01/15/2024,COFFEE,free,DEBIT_CARD,,,\n",
+			wantErr: "Failed to parse CSV file: Row 2: invalid amount",
+		},
+		{
+			name:   "qif",
+			parser: QIFParser(account),
+			file:   "!Type:Bank\nD01/15/2024\nT-4.50\nPBLUE BOTTLE COFFEE\n^\nD01/20/2024\nT-100.00\nN101\nPLANDLORD\nMJanuary rent\n^\n",
+			want: want{
+				sourceId:    "000123456789",
+				accountType: db.AccountTypeCHECKING,
+				transactions: []NormalizedTransaction{
+					{Type: db.TransactionTypeDEBIT, Date: testutil.Date("2024-01-15"), Amount: -4.5, Payee: "BLUE BOTTLE COFFEE", Description: "BLUE BOTTLE COFFEE"},
+					{Type: db.TransactionTypeCHECK, Date: testutil.Date("2024-01-20"), Amount: -100, Payee: "LANDLORD", CheckNumber: "101", Description: "LANDLORD January rent"},
+				},
+			},
+		},
+		{
+			name:   "qif categories and splits",
+			parser: QIFParser(account),
+			file:   "!Type:Bank\nD01/15/2024\nT-4.50\nPBLUE BOTTLE COFFEE\nLFood:Coffee/Work\n^\nD01/16/2024\nT-500.00\nPTRANSFER\nL[Savings]\n^\nD01/20/2024\nT-120.00\nPCOSTCO\nLFood:Groceries\nSFood:Groceries\nEWeekly shop\n$-80.00\nSHousehold\n$-40.00\n^\n",
+			want: want{
+				sourceId:    "000123456789",
+				accountType: db.AccountTypeCHECKING,
+				transactions: []NormalizedTransaction{
+					{Type: db.TransactionTypeDEBIT, Date: testutil.Date("2024-01-15"), Amount: -4.5, Payee: "BLUE BOTTLE COFFEE", Description: "BLUE BOTTLE COFFEE", Category: "Food:Coffee"},
+					{Type: db.TransactionTypeDEBIT, Date: testutil.Date("2024-01-16"), Amount: -500, Payee: "TRANSFER", Description: "TRANSFER"},
+					{
+						Type:        db.TransactionTypeDEBIT,
+						Date:        testutil.Date("2024-01-20"),
+						Amount:      -120,
+						Payee:       "COSTCO",
+						Description: "COSTCO",
+						Category:    "Food:Groceries",
+						Splits: []NormalizedSplit{
+							{Category: "Food:Groceries", Memo: "Weekly shop", Amount: -80},
+							{Category: "Household", Amount: -40},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "qif with several accounts",
+			parser:  QIFParser(account),
+			file:    "!Type:Bank\nD01/15/2024\nT-4.50\n^\n!Type:CCard\nD01/16/2024\nT-9.99\n^\n",
+			wantErr: "QIF file contains 2 accounts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements, err := tt.parser.Parse(strings.NewReader(tt.file))
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if len(statements) != 1 {
+				t.Fatalf("Parse() returned %d statements, want 1", len(statements))
+			}
+
+			statement := statements[0]
+
+			if statement.Account.SourceId != tt.want.sourceId || statement.Account.Type != tt.want.accountType {
+				t.Errorf("account = %s %s, want %s %s", statement.Account.SourceId, statement.Account.Type, tt.want.sourceId, tt.want.accountType)
+			}
+
+			if statement.Account.CurrentBalance != tt.want.balance || !statement.Account.BalanceDate.Equal(tt.want.balanceDate) {
+				t.Errorf("balance = %.2f on %s, want %.2f on %s", statement.Account.CurrentBalance, statement.Account.BalanceDate, tt.want.balance, tt.want.balanceDate)
+			}
+
+			if len(statement.Transactions) != len(tt.want.transactions) {
+				t.Fatalf("Parse() returned %d transactions, want %d", len(statement.Transactions), len(tt.want.transactions))
+			}
+
+			seen := map[string]bool{}
+
+			for i, want := range tt.want.transactions {
+				got := statement.Transactions[i]
+
+				if err := ValidateTransaction(got, seen); err != nil {
+					t.Errorf("transaction %d is invalid: %v", i, err)
+				}
+
+				// Files without transaction ids get generated ones
+				if want.SourceId == "" {
+					want.SourceId = got.SourceId
+				}
+
+				// Dates are compared in UTC, as OFX dates have a time zone
+				got.Date, got.AuthorizedDate = got.Date.UTC(), got.AuthorizedDate.UTC()
+
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("transaction %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
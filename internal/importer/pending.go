@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/reconcile"
+)
+
+// Lists the account's pending transactions that the statement's posted
+// transactions could replace. Transactions in the statement with the same
+// source id are updated instead, so they are left out
+func listReconcilableTransactions(ctx context.Context, repo db.Repository, accountId uuid.UUID, transactions []NormalizedTransaction) ([]db.Transaction, error) {
+	var startDate, endDate time.Time
+	sourceIds := map[string]bool{}
+
+	for _, tx := range transactions {
+		sourceIds[tx.SourceId] = true
+
+		if tx.Pending || tx.Date.IsZero() {
+			continue
+		}
+
+		if startDate.IsZero() || tx.Date.Before(startDate) {
+			startDate = tx.Date
+		}
+
+		if tx.Date.After(endDate) {
+			endDate = tx.Date
+		}
+	}
+
+	if startDate.IsZero() {
+		return nil, nil
+	}
+
+	pending, err := repo.ListPendingTransactions(ctx, db.ListPendingTransactionsParams{
+		Accountid: accountId,
+		Startdate: startDate.AddDate(0, 0, -reconcile.MaxPendingDays),
+		Enddate:   endDate.AddDate(0, 0, 1),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reconcilable []db.Transaction
+
+	for _, tx := range pending {
+		if !sourceIds[tx.Sourceid] {
+			reconcilable = append(reconcilable, tx)
+		}
+	}
+
+	return reconcilable, nil
+}
+
+// Finds the pending transaction replaced by a posted transaction. Posted
+// transactions that were already imported keep their own row
+func matchPendingTransaction(ctx context.Context, repo db.Repository, tx NormalizedTransaction, params db.UpsertTransactionParams, pending []db.Transaction) (int, bool, error) {
+	if tx.Pending || len(pending) == 0 {
+		return 0, false, nil
+	}
+
+	i, ok := reconcile.Match(reconcile.Posted{
+		Amount:      params.Amount,
+		Date:        params.Date,
+		Description: params.Description,
+		PendingId:   tx.PendingId,
+	}, pending)
+
+	if !ok {
+		return 0, false, nil
+	}
+
+	_, err := repo.GetTransactionBySourceId(ctx, db.GetTransactionBySourceIdParams{
+		Ownerid:  params.Ownerid,
+		Sourceid: tx.SourceId,
+	})
+
+	if err == nil {
+		return 0, false, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, err
+	}
+
+	return i, true, nil
+}
@@ -0,0 +1,184 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/testutil"
+)
+
+// Repository with the pending transaction queries used by the importer.
+// Calling any other query panics
+type pendingRepository struct {
+	db.Repository
+	pending   []db.Transaction
+	imported  map[string]db.Transaction
+	lookupErr error
+	listed    *db.ListPendingTransactionsParams
+}
+
+func (r *pendingRepository) ListPendingTransactions(ctx context.Context, arg db.ListPendingTransactionsParams) ([]db.Transaction, error) {
+	r.listed = &arg
+	return r.pending, nil
+}
+
+func (r *pendingRepository) GetTransactionBySourceId(ctx context.Context, arg db.GetTransactionBySourceIdParams) (db.Transaction, error) {
+	if r.lookupErr != nil {
+		return db.Transaction{}, r.lookupErr
+	}
+
+	if tx, ok := r.imported[arg.Sourceid]; ok {
+		return tx, nil
+	}
+
+	return db.Transaction{}, sql.ErrNoRows
+}
+
+func TestListReconcilableTransactions(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []NormalizedTransaction
+		pending      []db.Transaction
+		want         []string
+		wantStart    string
+		wantEnd      string
+	}{
+		{
+			name: "window covers the posted dates",
+			transactions: []NormalizedTransaction{
+				{SourceId: "p1", Date: testutil.Date("2024-01-20")},
+				{SourceId: "p2", Date: testutil.Date("2024-01-15")},
+				{SourceId: "p3", Date: testutil.Date("2024-01-30"), Pending: true},
+			},
+			pending: []db.Transaction{
+				{Sourceid: "a", Date: testutil.Date("2024-01-14")},
+				{Sourceid: "b", Date: testutil.Date("2024-01-18")},
+			},
+			want:      []string{"a", "b"},
+			wantStart: "2024-01-01",
+			wantEnd:   "2024-01-21",
+		},
+		{
+			name: "pending transactions in the statement are updated instead",
+			transactions: []NormalizedTransaction{
+				{SourceId: "p1", Date: testutil.Date("2024-01-20")},
+				{SourceId: "a", Date: testutil.Date("2024-01-19"), Pending: true},
+			},
+			pending: []db.Transaction{
+				{Sourceid: "a", Date: testutil.Date("2024-01-19")},
+				{Sourceid: "b", Date: testutil.Date("2024-01-18")},
+			},
+			want:      []string{"b"},
+			wantStart: "2024-01-06",
+			wantEnd:   "2024-01-21",
+		},
+		{
+			name: "only pending transactions",
+			transactions: []NormalizedTransaction{
+				{SourceId: "a", Date: testutil.Date("2024-01-19"), Pending: true},
+			},
+			pending: []db.Transaction{{Sourceid: "b", Date: testutil.Date("2024-01-18")}},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &pendingRepository{pending: tt.pending}
+			got, err := listReconcilableTransactions(context.Background(), repo, uuid.New(), tt.transactions)
+
+			if err != nil {
+				t.Fatalf("listReconcilableTransactions() error = %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("listReconcilableTransactions() returned %d transactions, want %d", len(got), len(tt.want))
+			}
+
+			for i, sourceId := range tt.want {
+				if got[i].Sourceid != sourceId {
+					t.Errorf("transaction %d = %s, want %s", i, got[i].Sourceid, sourceId)
+				}
+			}
+
+			if tt.wantStart == "" {
+				if repo.listed != nil {
+					t.Fatalf("listed pending transactions without posted transactions")
+				}
+
+				return
+			}
+
+			if !repo.listed.Startdate.Equal(testutil.Date(tt.wantStart)) || !repo.listed.Enddate.Equal(testutil.Date(tt.wantEnd)) {
+				t.Errorf("listed %s to %s, want %s to %s", repo.listed.Startdate, repo.listed.Enddate, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// Matching itself is covered by the reconcile package. These cases check the
+// importer only replaces pending transactions with posted transactions it
+// hasn't saved yet
+func TestMatchPendingTransaction(t *testing.T) {
+	pending := []db.Transaction{{
+		Sourceid:    "a",
+		Amount:      -450,
+		Date:        testutil.Date("2024-01-15"),
+		Description: "BLUE BOTTLE COFFEE",
+		Status:      db.TransactionStatusPENDING,
+	}}
+	params := db.UpsertTransactionParams{Amount: -450, Date: testutil.Date("2024-01-16"), Description: "BLUE BOTTLE COFFEE"}
+	lookupErr := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		tx        NormalizedTransaction
+		imported  map[string]db.Transaction
+		lookupErr error
+		wantMatch bool
+		wantErr   error
+	}{
+		{
+			name:      "new posted transaction replaces its pending transaction",
+			tx:        NormalizedTransaction{SourceId: "p1"},
+			wantMatch: true,
+		},
+		{
+			name:      "pending transactions are not matched",
+			tx:        NormalizedTransaction{SourceId: "p1", Pending: true},
+			wantMatch: false,
+		},
+		{
+			name:      "already imported posted transaction keeps its row",
+			tx:        NormalizedTransaction{SourceId: "p1"},
+			imported:  map[string]db.Transaction{"p1": {Sourceid: "p1"}},
+			wantMatch: false,
+		},
+		{
+			name:      "lookup error",
+			tx:        NormalizedTransaction{SourceId: "p1"},
+			lookupErr: lookupErr,
+			wantMatch: false,
+			wantErr:   lookupErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &pendingRepository{imported: tt.imported, lookupErr: tt.lookupErr}
+			got, ok, err := matchPendingTransaction(context.Background(), repo, tt.tx, params, pending)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("matchPendingTransaction() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if ok != tt.wantMatch || got != 0 {
+				t.Fatalf("matchPendingTransaction() = %d, %v, want match %v", got, ok, tt.wantMatch)
+			}
+		})
+	}
+}
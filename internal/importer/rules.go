@@ -0,0 +1,151 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/graphql/utils"
+	"github.com/proctorinc/banker/internal/merchantrules"
+)
+
+// RuleMerchants finds merchants with the user's merchant rules first, then
+// the merchant keys learned from earlier imports, then the source's built in
+// key patterns. Transactions that match none of them are passed to Fallback
+type RuleMerchants struct {
+	Fallback MerchantResolver
+	// Compiled rules of one import, see ForImport. Until they are loaded the
+	// rules are loaded for each transaction
+	rules  []merchantrules.Rule
+	loaded bool
+}
+
+// ForImport loads and compiles the user's rules for uploadSource once, for
+// every transaction of one import
+func (m RuleMerchants) ForImport(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource) (MerchantResolver, error) {
+	rules, err := loadMerchantRules(ctx, repo, userId, uploadSource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	fallback, err := forImport(ctx, m.Fallback, repo, userId, uploadSource)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return RuleMerchants{Fallback: fallback, rules: rules, loaded: true}, nil
+}
+
+func (m RuleMerchants) FindMerchant(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource, tx NormalizedTransaction) (MerchantMatch, error) {
+	rules := m.rules
+
+	if !m.loaded {
+		var err error
+		rules, err = loadMerchantRules(ctx, repo, userId, uploadSource)
+
+		if err != nil {
+			return MerchantMatch{}, err
+		}
+	}
+
+	rule, ok := merchantrules.Match(rules, merchantrules.Transaction{
+		Description:  tx.Description,
+		Amount:       utils.FormatCurrencyInt(tx.Amount),
+		UploadSource: db.NullUploadSource{UploadSource: uploadSource, Valid: true},
+	})
+
+	if ok {
+		merchant, err := repo.GetMerchant(ctx, db.GetMerchantParams{
+			ID:      rule.Merchantid,
+			Ownerid: userId,
+		})
+
+		if err != nil {
+			return MerchantMatch{}, err
+		}
+
+		return MerchantMatch{Name: merchant.Name, Merchant: &merchant}, nil
+	}
+
+	merchant, err := repo.GetMerchantByKey(ctx, db.GetMerchantByKeyParams{
+		Ownerid:      userId,
+		Uploadsource: uploadSource,
+		Description:  tx.Description,
+	})
+
+	if err == nil {
+		return MerchantMatch{Name: merchant.Name, Merchant: &merchant}, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return MerchantMatch{}, err
+	}
+
+	if match, ok := matchSourceKey(uploadSource, tx.Description); ok {
+		return match, nil
+	}
+
+	if m.Fallback == nil {
+		return MerchantMatch{Name: tx.Description, KeyMatch: strings.ToUpper(tx.Description)}, nil
+	}
+
+	return m.Fallback.FindMerchant(ctx, repo, userId, uploadSource, tx)
+}
+
+func loadMerchantRules(ctx context.Context, repo db.Repository, userId uuid.UUID, uploadSource db.UploadSource) ([]merchantrules.Rule, error) {
+	rules, err := repo.ListUploadSourceMerchantRules(ctx, db.ListUploadSourceMerchantRulesParams{
+		Ownerid:      userId,
+		Uploadsource: db.NullUploadSource{UploadSource: uploadSource, Valid: true},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return merchantrules.CompileAll(rules)
+}
+
+// Key patterns built in for each source. A merchant created from one of them
+// gets the pattern as its key, so every later match is linked to it
+func sourceKeyMatchers(uploadSource db.UploadSource) []string {
+	switch uploadSource {
+	case db.UploadSourceCHASECSVUPLOAD, db.UploadSourceCHASEOFXUPLOAD:
+		return chase.ChaseKeyMatchers()
+	}
+
+	return nil
+}
+
+func matchSourceKey(uploadSource db.UploadSource, description string) (MerchantMatch, bool) {
+	description = strings.ToUpper(strings.TrimSpace(description))
+
+	for _, pattern := range sourceKeyMatchers(uploadSource) {
+		prefix := strings.TrimSuffix(pattern, "%")
+
+		if strings.HasPrefix(description, prefix) {
+			return MerchantMatch{
+				Name:     keyMatcherName(prefix),
+				KeyMatch: pattern,
+			}, true
+		}
+	}
+
+	return MerchantMatch{}, false
+}
+
+// Names a merchant after a key pattern, e.g. CASH REDEMPTION -> Cash Redemption
+func keyMatcherName(prefix string) string {
+	words := strings.Fields(strings.ToLower(prefix))
+
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Repository with one merchant rule that counts how often rules are listed
+type ruleRepository struct {
+	db.Repository
+	merchant db.Merchant
+	listed   int
+}
+
+func (r *ruleRepository) ListUploadSourceMerchantRules(ctx context.Context, arg db.ListUploadSourceMerchantRulesParams) ([]db.MerchantRule, error) {
+	r.listed++
+
+	return []db.MerchantRule{{
+		ID:         uuid.New(),
+		Type:       db.MerchantRuleTypePREFIX,
+		Pattern:    "BLUE BOTTLE",
+		Merchantid: r.merchant.ID,
+	}}, nil
+}
+
+func (r *ruleRepository) GetMerchant(ctx context.Context, arg db.GetMerchantParams) (db.Merchant, error) {
+	return r.merchant, nil
+}
+
+func TestRuleMerchantsForImport(t *testing.T) {
+	ctx := context.Background()
+	repo := &ruleRepository{merchant: db.Merchant{ID: uuid.New(), Name: "Blue Bottle"}}
+	userId := uuid.New()
+	service := New(repo)
+
+	merchants, err := service.ImportMerchants(ctx, repo, userId, db.UploadSourceCHASEOFXUPLOAD)
+
+	if err != nil {
+		t.Fatalf("ImportMerchants() error = %v", err)
+	}
+
+	for _, description := range []string{"BLUE BOTTLE COFFEE #12", "BLUE BOTTLE COFFEE #40"} {
+		match, err := merchants.FindMerchant(ctx, repo, userId, db.UploadSourceCHASEOFXUPLOAD, NormalizedTransaction{Description: description})
+
+		if err != nil {
+			t.Fatalf("FindMerchant() error = %v", err)
+		}
+
+		if match.Merchant == nil || match.Merchant.ID != repo.merchant.ID {
+			t.Fatalf("FindMerchant(%q) = %+v, want the rule's merchant", description, match)
+		}
+	}
+
+	if repo.listed != 1 {
+		t.Fatalf("listed merchant rules %d times, want once per import", repo.listed)
+	}
+}
@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// NormalizedStatement is one account's statement from any source. Parsers
+// convert their format into statements so every source is saved the same way
+type NormalizedStatement struct {
+	Account      NormalizedAccount
+	Transactions []NormalizedTransaction
+	// Set for investment statements
+	Investment *chase.ChaseOFXInvestment
+}
+
+type NormalizedAccount struct {
+	// Account number at the bank, used to find the account on later imports
+	SourceId         string
+	BankId           string
+	Name             string
+	Type             db.AccountType
+	IsoCurrencyCode  string
+	CurrentBalance   float32
+	AvailableBalance *float32
+	// Zero for sources without a balance
+	BalanceDate time.Time
+}
+
+type NormalizedTransaction struct {
+	// Stable id used to update the transaction on later imports
+	SourceId    string
+	Type        db.TransactionType
+	Date        time.Time
+	Amount      float32
+	PayeeId     string
+	Payee       string
+	PayeeFull   string
+	CheckNumber string
+	Description string
+	// Pending transactions are replaced by their posted transaction on a
+	// later import
+	Pending        bool
+	AuthorizedDate time.Time
+	// Source id of the pending transaction this posted transaction replaces,
+	// for sources that link them
+	PendingId string
+	// Category path from the file, e.g. Food:Groceries
+	Category string
+	// Parts from the file, which add up to the amount
+	Splits []NormalizedSplit
+}
+
+type NormalizedSplit struct {
+	Category string
+	Memo     string
+	Amount   float32
+}
+
+// FromChaseOFX converts parsed OFX statements. Bank statement, QIF and sync
+// provider parsers produce the same structures
+func FromChaseOFX(results []chase.ChaseOFXResult) []NormalizedStatement {
+	statements := make([]NormalizedStatement, len(results))
+
+	for i, result := range results {
+		statements[i] = NormalizedStatement{
+			Account: NormalizedAccount{
+				SourceId:         result.Account.AccountId,
+				BankId:           result.Account.BankId,
+				Name:             result.Account.Name,
+				Type:             result.Account.Type,
+				IsoCurrencyCode:  result.Account.IsoCurrencyCode,
+				CurrentBalance:   result.Account.CurrentBalance,
+				AvailableBalance: result.Account.AvailableBalance,
+				BalanceDate:      result.Account.BalanceDate,
+			},
+			Transactions: make([]NormalizedTransaction, len(result.Transactions)),
+			Investment:   result.Investment,
+		}
+
+		for j, tx := range result.Transactions {
+			statements[i].Transactions[j] = NormalizedTransaction{
+				SourceId:       tx.Id,
+				Type:           db.TransactionType(tx.Type),
+				Date:           tx.DatePosted,
+				Amount:         tx.Amount,
+				PayeeId:        tx.PayeeId,
+				Payee:          tx.Payee,
+				PayeeFull:      tx.PayeeFull,
+				CheckNumber:    tx.CheckNumber,
+				Description:    tx.Description,
+				Pending:        tx.Pending,
+				AuthorizedDate: tx.AuthorizedDate,
+				PendingId:      tx.PendingId,
+			}
+		}
+	}
+
+	return statements
+}
+
+// Statement for a file without account details, e.g. CSV and QIF. The
+// account must already exist from a previous upload
+func newAccountStatement(account db.Account, transactions []NormalizedTransaction) NormalizedStatement {
+	return NormalizedStatement{
+		Account: NormalizedAccount{
+			SourceId:        account.Sourceid,
+			BankId:          account.Routingnumber.String,
+			Name:            account.Name,
+			Type:            account.Type,
+			IsoCurrencyCode: "USD",
+		},
+		Transactions: transactions,
+	}
+}
+
+// ValidateTransaction checks a transaction can be saved. seen holds the
+// transaction ids already checked from the same statement
+func ValidateTransaction(tx NormalizedTransaction, seen map[string]bool) error {
+	if tx.SourceId == "" {
+		return fmt.Errorf("Missing transaction id")
+	}
+
+	if seen[tx.SourceId] {
+		return fmt.Errorf("Duplicate transaction id in file")
+	}
+
+	seen[tx.SourceId] = true
+
+	if tx.Date.IsZero() {
+		return fmt.Errorf("Missing posted date")
+	}
+
+	if !tx.Type.Valid() {
+		return fmt.Errorf("Unsupported transaction type: %s", tx.Type)
+	}
+
+	if len(tx.Description) > 255 {
+		return fmt.Errorf("Description longer than 255 characters")
+	}
+
+	for _, split := range tx.Splits {
+		if len(split.Memo) > 255 {
+			return fmt.Errorf("Split memo longer than 255 characters")
+		}
+	}
+
+	return nil
+}
+
+// CheckStatementOwner checks the statement would only update its own
+// account's transactions. Source ids are unique per user, so other users'
+// accounts and transactions are never matched
+func CheckStatementOwner(ctx context.Context, repo db.Repository, userId uuid.UUID, statement NormalizedStatement) error {
+	account, err := repo.GetAccountBySourceId(ctx, db.GetAccountBySourceIdParams{
+		Ownerid:  userId,
+		Sourceid: statement.Account.SourceId,
+	})
+	exists := err == nil
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	for _, tx := range statement.Transactions {
+		if tx.SourceId == "" {
+			continue
+		}
+
+		existing, err := repo.GetTransactionBySourceId(ctx, db.GetTransactionBySourceIdParams{
+			Ownerid:  userId,
+			Sourceid: tx.SourceId,
+		})
+
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if !exists || existing.Accountid != account.ID {
+			return fmt.Errorf("Transaction %s belongs to another account", tx.SourceId)
+		}
+	}
+
+	return nil
+}
+
+func transactionStatus(tx NormalizedTransaction) db.TransactionStatus {
+	if tx.Pending {
+		return db.TransactionStatusPENDING
+	}
+
+	return db.TransactionStatusPOSTED
+}
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/testutil"
+)
+
+func TestValidateTransaction(t *testing.T) {
+	valid := NormalizedTransaction{
+		SourceId:    "1",
+		Type:        db.TransactionTypeDEBIT,
+		Date:        testutil.Date("2024-01-15"),
+		Amount:      -4.5,
+		Description: "BLUE BOTTLE COFFEE",
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(tx *NormalizedTransaction)
+		seen    map[string]bool
+		wantErr string
+	}{
+		{
+			name: "valid",
+			edit: func(tx *NormalizedTransaction) {},
+		},
+		{
+			name:    "missing id",
+			edit:    func(tx *NormalizedTransaction) { tx.SourceId = "" },
+			wantErr: "Missing transaction id",
+		},
+		{
+			name:    "duplicate id",
+			edit:    func(tx *NormalizedTransaction) {},
+			seen:    map[string]bool{"1": true},
+			wantErr: "Duplicate transaction id in file",
+		},
+		{
+			name:    "missing date",
+			edit:    func(tx *NormalizedTransaction) { tx.Date = testutil.Date("0001-01-01") },
+			wantErr: "Missing posted date",
+		},
+		{
+			name:    "unsupported type",
+			edit:    func(tx *NormalizedTransaction) { tx.Type = "BARTER" },
+			wantErr: "Unsupported transaction type: BARTER",
+		},
+		{
+			name:    "long description",
+			edit:    func(tx *NormalizedTransaction) { tx.Description = strings.Repeat("A", 256) },
+			wantErr: "Description longer than 255 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := valid
+			tt.edit(&tx)
+			seen := tt.seen
+
+			if seen == nil {
+				seen = map[string]bool{}
+			}
+
+			err := ValidateTransaction(tx, seen)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateTransaction() error = %v", err)
+				}
+
+				if !seen[tx.SourceId] {
+					t.Fatalf("ValidateTransaction() did not mark %s as seen", tx.SourceId)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("ValidateTransaction() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Repository with saved accounts and transactions looked up by owner and
+// source id
+type ownerRepository struct {
+	db.Repository
+	accounts     map[string]db.Account
+	transactions map[string]db.Transaction
+}
+
+func (r *ownerRepository) GetAccountBySourceId(ctx context.Context, arg db.GetAccountBySourceIdParams) (db.Account, error) {
+	if account, ok := r.accounts[arg.Sourceid]; ok && account.Ownerid == arg.Ownerid {
+		return account, nil
+	}
+
+	return db.Account{}, sql.ErrNoRows
+}
+
+func (r *ownerRepository) GetTransactionBySourceId(ctx context.Context, arg db.GetTransactionBySourceIdParams) (db.Transaction, error) {
+	if tx, ok := r.transactions[arg.Sourceid]; ok && tx.Ownerid == arg.Ownerid {
+		return tx, nil
+	}
+
+	return db.Transaction{}, sql.ErrNoRows
+}
+
+func TestCheckStatementOwner(t *testing.T) {
+	user := uuid.New()
+	other := uuid.New()
+	checking := db.Account{ID: uuid.New(), Sourceid: "111", Ownerid: user}
+	savings := db.Account{ID: uuid.New(), Sourceid: "222", Ownerid: user}
+	repo := &ownerRepository{
+		accounts: map[string]db.Account{
+			"111": checking,
+			"222": savings,
+			"999": {ID: uuid.New(), Sourceid: "999", Ownerid: other},
+		},
+		transactions: map[string]db.Transaction{
+			"c1": {Sourceid: "c1", Ownerid: user, Accountid: checking.ID},
+			"s1": {Sourceid: "s1", Ownerid: user, Accountid: savings.ID},
+			"o1": {Sourceid: "o1", Ownerid: other, Accountid: uuid.New()},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		accountId      string
+		transactionIds []string
+		wantErr        string
+	}{
+		{
+			name:           "own account",
+			accountId:      "111",
+			transactionIds: []string{"c1", "new"},
+		},
+		{
+			name:           "new account",
+			accountId:      "333",
+			transactionIds: []string{"new"},
+		},
+		{
+			name:           "another user's account is a new account",
+			accountId:      "999",
+			transactionIds: []string{"o1"},
+		},
+		{
+			name:           "transaction of another account",
+			accountId:      "111",
+			transactionIds: []string{"c1", "s1"},
+			wantErr:        "Transaction s1 belongs to another account",
+		},
+		{
+			name:           "transaction of another account in a new account",
+			accountId:      "333",
+			transactionIds: []string{"c1"},
+			wantErr:        "Transaction c1 belongs to another account",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statement := NormalizedStatement{Account: NormalizedAccount{SourceId: tt.accountId}}
+
+			for _, sourceId := range tt.transactionIds {
+				statement.Transactions = append(statement.Transactions, NormalizedTransaction{SourceId: sourceId})
+			}
+
+			err := CheckStatementOwner(context.Background(), repo, user, statement)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("CheckStatementOwner() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("CheckStatementOwner() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Status string
+
+const (
+	StatusQueued    Status = "QUEUED"
+	StatusRunning   Status = "RUNNING"
+	StatusCompleted Status = "COMPLETED"
+	StatusFailed    Status = "FAILED"
+)
+
+// Finished jobs are kept for this long so their status can still be queried
+const retention = 24 * time.Hour
+
+// Size of each subscriber's event buffer. When a subscriber falls behind,
+// the oldest events are dropped so the final event is always delivered
+const subscriberBuffer = 100
+
+// RunFunc does the work for a job. Progress is reported through the job
+// stored in ctx, see FromContext
+type RunFunc func(ctx context.Context) (interface{}, error)
+
+type Event struct {
+	Job     Snapshot
+	Message string
+}
+
+type Job struct {
+	id       uuid.UUID
+	ownerId  uuid.UUID
+	fileName string
+	created  time.Time
+	run      RunFunc
+
+	mu          sync.RWMutex
+	status      Status
+	processed   int
+	total       int
+	result      interface{}
+	err         error
+	finished    time.Time
+	subscribers map[chan *Event]bool
+}
+
+// Snapshot is a copy of a job's state at a point in time
+type Snapshot struct {
+	ID        uuid.UUID
+	FileName  string
+	Status    Status
+	Processed int
+	Total     int
+	Result    interface{} // value returned by the job's RunFunc
+	Err       error
+	Created   time.Time
+	Finished  time.Time // zero until the job completes or fails
+}
+
+func (j *Job) Snapshot() Snapshot {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.snapshot()
+}
+
+// Must be called with j.mu held
+func (j *Job) snapshot() Snapshot {
+	return Snapshot{
+		ID:        j.id,
+		FileName:  j.fileName,
+		Status:    j.status,
+		Processed: j.processed,
+		Total:     j.total,
+		Result:    j.result,
+		Err:       j.err,
+		Created:   j.created,
+		Finished:  j.finished,
+	}
+}
+
+func (j *Job) isDone() bool {
+	return j.status == StatusCompleted || j.status == StatusFailed
+}
+
+// AddTotal increases the number of rows the job expects to process.
+// It is safe to call on a nil job
+func (j *Job) AddTotal(rows int) {
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.total += rows
+	j.publish(fmt.Sprintf("Found %d rows", rows))
+}
+
+// Advance marks one more row as processed. It is safe to call on a nil job
+func (j *Job) Advance(message string) {
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.processed++
+	j.publish(message)
+}
+
+func (j *Job) setStatus(status Status, message string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+	j.publish(message)
+}
+
+func (j *Job) finish(result interface{}, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.result = result
+	j.err = err
+	j.finished = time.Now()
+	j.status = StatusCompleted
+	message := "Import completed"
+
+	if err != nil {
+		j.status = StatusFailed
+		message = err.Error()
+	}
+
+	j.publish(message)
+
+	for ch := range j.subscribers {
+		close(ch)
+	}
+	j.subscribers = nil
+}
+
+// Sends an event to every subscriber. Must be called with j.mu held
+func (j *Job) publish(message string) {
+	event := &Event{Job: j.snapshot(), Message: message}
+
+	for ch := range j.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Drop the oldest event to make room
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- event
+		}
+	}
+}
+
+// Subscribe streams the job's events until it finishes or ctx is done.
+// The channel is closed after the final event
+func (j *Job) Subscribe(ctx context.Context) <-chan *Event {
+	ch := make(chan *Event, subscriberBuffer)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// Send the current state so late subscribers see where the job is
+	ch <- &Event{Job: j.snapshot(), Message: string(j.status)}
+
+	if j.isDone() {
+		close(ch)
+		return ch
+	}
+
+	if j.subscribers == nil {
+		j.subscribers = map[chan *Event]bool{}
+	}
+	j.subscribers[ch] = true
+
+	go func() {
+		<-ctx.Done()
+		j.mu.Lock()
+		defer j.mu.Unlock()
+
+		if j.subscribers[ch] {
+			delete(j.subscribers, ch)
+			close(ch)
+		}
+	}()
+
+	return ch
+}
+
+type contextKey struct {
+	name string
+}
+
+var jobContextKey = &contextKey{"job"}
+
+// FromContext returns the job running in ctx, or nil outside of a job
+func FromContext(ctx context.Context) *Job {
+	job, _ := ctx.Value(jobContextKey).(*Job)
+	return job
+}
+
+// Pool runs queued jobs on a fixed number of worker goroutines
+type Pool struct {
+	workers int
+	queue   chan *Job
+
+	mu   sync.RWMutex
+	jobs map[uuid.UUID]*Job
+}
+
+func NewPool(workers int, queueSize int) *Pool {
+	return &Pool{
+		workers: workers,
+		queue:   make(chan *Job, queueSize),
+		jobs:    map[uuid.UUID]*Job{},
+	}
+}
+
+// Start launches the workers. They stop once ctx is done
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.work(ctx)
+	}
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-p.queue:
+			p.runJob(ctx, job)
+		}
+	}
+}
+
+func (p *Pool) runJob(ctx context.Context, job *Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s panicked: %v", job.id, r)
+			job.finish(nil, fmt.Errorf("Job failed unexpectedly"))
+		}
+	}()
+
+	job.setStatus(StatusRunning, "Import started")
+	result, err := job.run(context.WithValue(ctx, jobContextKey, job))
+	job.finish(result, err)
+}
+
+// Submit queues a job for ownerId. Fails if the queue is full
+func (p *Pool) Submit(ownerId uuid.UUID, fileName string, run RunFunc) (*Job, error) {
+	job := &Job{
+		id:       uuid.New(),
+		ownerId:  ownerId,
+		fileName: fileName,
+		created:  time.Now(),
+		run:      run,
+		status:   StatusQueued,
+	}
+
+	p.mu.Lock()
+	p.prune()
+	p.jobs[job.id] = job
+	p.mu.Unlock()
+
+	select {
+	case p.queue <- job:
+		return job, nil
+	default:
+		p.mu.Lock()
+		delete(p.jobs, job.id)
+		p.mu.Unlock()
+		return nil, fmt.Errorf("Too many queued jobs. Try again later")
+	}
+}
+
+// Get returns the job with id if it belongs to ownerId
+func (p *Pool) Get(id uuid.UUID, ownerId uuid.UUID) (*Job, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	job, ok := p.jobs[id]
+
+	if !ok || job.ownerId != ownerId {
+		return nil, false
+	}
+
+	return job, true
+}
+
+// List returns snapshots of ownerId's jobs, newest first
+func (p *Pool) List(ownerId uuid.UUID) []Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	jobs := []Snapshot{}
+
+	for _, job := range p.jobs {
+		if job.ownerId == ownerId {
+			jobs = append(jobs, job.Snapshot())
+		}
+	}
+
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].Created.After(jobs[k].Created)
+	})
+
+	return jobs
+}
+
+// Removes finished jobs past retention. Must be called with p.mu held
+func (p *Pool) prune() {
+	for id, job := range p.jobs {
+		finished := job.Snapshot().Finished
+
+		if !finished.IsZero() && time.Since(finished) > retention {
+			delete(p.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package merchantrules matches transactions to merchants with rules a user
+// defines. Rules are checked in priority order and the first match wins, so
+// specific rules should have a lower priority than broad ones
+package merchantrules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Transaction is what a rule is matched against
+type Transaction struct {
+	Description string
+	// Amount in cents. Rules compare the absolute amount
+	Amount       int32
+	UploadSource db.NullUploadSource
+}
+
+// Rule is a merchant rule ready to be matched
+type Rule struct {
+	db.MerchantRule
+	pattern string
+	regex   *regexp.Regexp
+}
+
+// Validate checks that a rule can be compiled and is able to match
+func Validate(rule db.MerchantRule) error {
+	if !rule.Type.Valid() {
+		return fmt.Errorf("Invalid rule type: %s", rule.Type)
+	}
+
+	if rule.Uploadsource.Valid && !rule.Uploadsource.UploadSource.Valid() {
+		return fmt.Errorf("Invalid upload source: %s", rule.Uploadsource.UploadSource)
+	}
+
+	if rule.Type != db.MerchantRuleTypeAMOUNTRANGE && strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("Pattern is required for %s rules", rule.Type)
+	}
+
+	if rule.Type == db.MerchantRuleTypeAMOUNTRANGE && !rule.Minamount.Valid && !rule.Maxamount.Valid {
+		return fmt.Errorf("Amount range rules need a min or max amount")
+	}
+
+	if (rule.Minamount.Valid && rule.Minamount.Int32 < 0) || (rule.Maxamount.Valid && rule.Maxamount.Int32 < 0) {
+		return fmt.Errorf("Amounts are compared to the absolute amount and can't be negative")
+	}
+
+	if rule.Minamount.Valid && rule.Maxamount.Valid && rule.Minamount.Int32 > rule.Maxamount.Int32 {
+		return fmt.Errorf("Min amount is greater than max amount")
+	}
+
+	if rule.Type == db.MerchantRuleTypeREGEX {
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("Invalid regex: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Compile validates a rule and prepares its pattern
+func Compile(rule db.MerchantRule) (Rule, error) {
+	if err := Validate(rule); err != nil {
+		return Rule{}, err
+	}
+
+	compiled := Rule{
+		MerchantRule: rule,
+		pattern:      strings.ToUpper(strings.TrimSpace(rule.Pattern)),
+	}
+
+	if rule.Type == db.MerchantRuleTypeREGEX {
+		// Descriptions vary in case between sources, so regexes ignore it
+		compiled.regex = regexp.MustCompile("(?i)" + rule.Pattern)
+	}
+
+	return compiled, nil
+}
+
+// CompileAll compiles rules, keeping their order
+func CompileAll(rules []db.MerchantRule) ([]Rule, error) {
+	compiled := make([]Rule, len(rules))
+
+	for i, rule := range rules {
+		r, err := Compile(rule)
+
+		if err != nil {
+			return nil, fmt.Errorf("Merchant rule %s: %w", rule.ID, err)
+		}
+
+		compiled[i] = r
+	}
+
+	return compiled, nil
+}
+
+// Matches reports whether the rule applies to tx. Min and max amounts limit
+// every rule type, not only amount ranges
+func (r Rule) Matches(tx Transaction) bool {
+	if r.Uploadsource.Valid && (!tx.UploadSource.Valid || tx.UploadSource.UploadSource != r.Uploadsource.UploadSource) {
+		return false
+	}
+
+	amount := tx.Amount
+
+	if amount < 0 {
+		amount = -amount
+	}
+
+	if r.Minamount.Valid && amount < r.Minamount.Int32 {
+		return false
+	}
+
+	if r.Maxamount.Valid && amount > r.Maxamount.Int32 {
+		return false
+	}
+
+	description := strings.ToUpper(strings.TrimSpace(tx.Description))
+
+	switch r.Type {
+	case db.MerchantRuleTypePREFIX:
+		return strings.HasPrefix(description, r.pattern)
+	case db.MerchantRuleTypeCONTAINS:
+		return strings.Contains(description, r.pattern)
+	case db.MerchantRuleTypeREGEX:
+		return r.regex.MatchString(tx.Description)
+	case db.MerchantRuleTypeAMOUNTRANGE:
+		// Amount ranges can optionally be narrowed by the description
+		return r.pattern == "" || strings.Contains(description, r.pattern)
+	}
+
+	return false
+}
+
+// Match returns the first rule that matches tx
+func Match(rules []Rule, tx Transaction) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.Matches(tx) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
@@ -0,0 +1,240 @@
+package merchantrules
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+func amount(cents int32) sql.NullInt32 {
+	return sql.NullInt32{Int32: cents, Valid: true}
+}
+
+func uploadSource(source db.UploadSource) db.NullUploadSource {
+	return db.NullUploadSource{UploadSource: source, Valid: true}
+}
+
+func TestMatches(t *testing.T) {
+	coffee := Transaction{Description: "Blue Bottle Coffee #12", Amount: -450}
+
+	tests := []struct {
+		name string
+		rule db.MerchantRule
+		tx   Transaction
+		want bool
+	}{
+		{
+			name: "prefix ignores case",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: "blue bottle"},
+			tx:   coffee,
+			want: true,
+		},
+		{
+			name: "prefix only matches the start",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: "COFFEE"},
+			tx:   coffee,
+			want: false,
+		},
+		{
+			name: "contains",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeCONTAINS, Pattern: " coffee "},
+			tx:   coffee,
+			want: true,
+		},
+		{
+			name: "contains without the pattern",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeCONTAINS, Pattern: "PEET'S"},
+			tx:   coffee,
+			want: false,
+		},
+		{
+			name: "regex ignores case",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeREGEX, Pattern: `^blue bottle.*#\d+$`},
+			tx:   coffee,
+			want: true,
+		},
+		{
+			name: "regex without a match",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeREGEX, Pattern: `#\d{3}`},
+			tx:   coffee,
+			want: false,
+		},
+		{
+			name: "amount range compares the absolute amount",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Minamount: amount(400), Maxamount: amount(500)},
+			tx:   coffee,
+			want: true,
+		},
+		{
+			name: "amount range bounds are inclusive",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Minamount: amount(450)},
+			tx:   coffee,
+			want: true,
+		},
+		{
+			name: "amount above the range",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Maxamount: amount(449)},
+			tx:   coffee,
+			want: false,
+		},
+		{
+			name: "amount range narrowed by a description",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Pattern: "PEET'S", Maxamount: amount(1000)},
+			tx:   coffee,
+			want: false,
+		},
+		{
+			name: "amounts limit other rule types",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: "BLUE BOTTLE", Minamount: amount(1000)},
+			tx:   coffee,
+			want: false,
+		},
+		{
+			name: "rule for the transaction's upload source",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: "BLUE", Uploadsource: uploadSource(db.UploadSourceCHASEOFXUPLOAD)},
+			tx:   Transaction{Description: coffee.Description, UploadSource: uploadSource(db.UploadSourceCHASEOFXUPLOAD)},
+			want: true,
+		},
+		{
+			name: "rule for another upload source",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: "BLUE", Uploadsource: uploadSource(db.UploadSourceCHASECSVUPLOAD)},
+			tx:   Transaction{Description: coffee.Description, UploadSource: uploadSource(db.UploadSourceCHASEOFXUPLOAD)},
+			want: false,
+		},
+		{
+			name: "rule for an upload source and a transaction without one",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: "BLUE", Uploadsource: uploadSource(db.UploadSourceCHASECSVUPLOAD)},
+			tx:   coffee,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Compile(tt.rule)
+
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			if got := rule.Matches(tt.tx); got != tt.want {
+				t.Fatalf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	coffee := uuid.New()
+	cafe := uuid.New()
+	rules, err := CompileAll([]db.MerchantRule{
+		{Type: db.MerchantRuleTypePREFIX, Pattern: "BLUE BOTTLE", Merchantid: coffee},
+		{Type: db.MerchantRuleTypeCONTAINS, Pattern: "COFFEE", Merchantid: cafe},
+	})
+
+	if err != nil {
+		t.Fatalf("CompileAll() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tx     Transaction
+		want   uuid.UUID
+		wantOk bool
+	}{
+		{
+			name:   "first matching rule wins",
+			tx:     Transaction{Description: "BLUE BOTTLE COFFEE"},
+			want:   coffee,
+			wantOk: true,
+		},
+		{
+			name:   "later rule",
+			tx:     Transaction{Description: "PHILZ COFFEE"},
+			want:   cafe,
+			wantOk: true,
+		},
+		{
+			name:   "no rule",
+			tx:     Transaction{Description: "SAFEWAY"},
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := Match(rules, tt.tx)
+
+			if ok != tt.wantOk || rule.Merchantid != tt.want {
+				t.Fatalf("Match() = %s, %v, want %s, %v", rule.Merchantid, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    db.MerchantRule
+		wantErr string
+	}{
+		{
+			name: "valid",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeCONTAINS, Pattern: "COFFEE"},
+		},
+		{
+			name: "amount range without a pattern",
+			rule: db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Maxamount: amount(500)},
+		},
+		{
+			name:    "unknown type",
+			rule:    db.MerchantRule{Type: "SUFFIX", Pattern: "COFFEE"},
+			wantErr: "Invalid rule type: SUFFIX",
+		},
+		{
+			name:    "missing pattern",
+			rule:    db.MerchantRule{Type: db.MerchantRuleTypePREFIX, Pattern: " "},
+			wantErr: "Pattern is required for PREFIX rules",
+		},
+		{
+			name:    "amount range without amounts",
+			rule:    db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE},
+			wantErr: "Amount range rules need a min or max amount",
+		},
+		{
+			name:    "negative amount",
+			rule:    db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Minamount: amount(-100)},
+			wantErr: "Amounts are compared to the absolute amount and can't be negative",
+		},
+		{
+			name:    "min above max",
+			rule:    db.MerchantRule{Type: db.MerchantRuleTypeAMOUNTRANGE, Minamount: amount(500), Maxamount: amount(100)},
+			wantErr: "Min amount is greater than max amount",
+		},
+		{
+			name:    "invalid regex",
+			rule:    db.MerchantRule{Type: db.MerchantRuleTypeREGEX, Pattern: "("},
+			wantErr: "Invalid regex: error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rule)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
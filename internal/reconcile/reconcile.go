@@ -0,0 +1,157 @@
+// Package reconcile matches posted transactions to the pending transactions
+// they replace. Banks usually give the posted transaction a new id, and the
+// amount can change when a tip or exchange rate is added
+package reconcile
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+const (
+	// How long a transaction can stay pending before it posts
+	MaxPendingDays = 14
+	// Posted amounts can differ from the authorization, e.g. restaurant tips
+	amountTolerance = 0.2
+	// Description similarity required when the amount matches exactly
+	minSimilarity = 0.3
+	// Description similarity required when the amount changed
+	minChangedSimilarity = 0.6
+)
+
+// Posted is the posted transaction being imported
+type Posted struct {
+	Amount      int32
+	Date        time.Time
+	Description string
+	// Source id of the pending transaction, when the source links them
+	PendingId string
+}
+
+// Match returns the index of the pending transaction that best matches
+// posted. Pending transactions must be from the same account
+func Match(posted Posted, pending []db.Transaction) (int, bool) {
+	if posted.PendingId != "" {
+		for i, tx := range pending {
+			if tx.Sourceid == posted.PendingId {
+				return i, true
+			}
+		}
+	}
+
+	best := -1
+	bestScore := 0.0
+
+	for i, tx := range pending {
+		if !inWindow(tx.Date, posted.Date) || !sameSign(tx.Amount, posted.Amount) {
+			continue
+		}
+
+		amount := amountSimilarity(tx.Amount, posted.Amount)
+
+		if amount < 1-amountTolerance {
+			continue
+		}
+
+		description := Similarity(tx.Description, posted.Description)
+
+		if description < minSimilarity || (amount < 1 && description < minChangedSimilarity) {
+			continue
+		}
+
+		// Closer dates break ties between otherwise equal matches, e.g. two
+		// pending coffees for the same amount
+		days := posted.Date.Sub(tx.Date).Hours() / 24
+		score := amount + description - days/(MaxPendingDays*10)
+
+		if best == -1 || score > bestScore {
+			best = i
+			bestScore = score
+		}
+	}
+
+	return best, best != -1
+}
+
+// Similarity compares two descriptions by their character trigrams, from 0
+// for nothing in common to 1 for the same normalized text
+func Similarity(a string, b string) float64 {
+	aGrams := trigrams(normalize(a))
+	bGrams := trigrams(normalize(b))
+
+	if len(aGrams) == 0 || len(bGrams) == 0 {
+		return 0
+	}
+
+	shared := 0
+
+	for gram := range aGrams {
+		if bGrams[gram] {
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(aGrams)+len(bGrams))
+}
+
+// Transactions post on or after the day they are authorized
+func inWindow(pendingDate time.Time, postedDate time.Time) bool {
+	earliest := postedDate.AddDate(0, 0, -MaxPendingDays)
+	latest := postedDate.AddDate(0, 0, 1)
+
+	return !pendingDate.Before(earliest) && !pendingDate.After(latest)
+}
+
+func sameSign(a int32, b int32) bool {
+	return (a < 0) == (b < 0)
+}
+
+func amountSimilarity(a int32, b int32) float64 {
+	if a == b {
+		return 1
+	}
+
+	larger := max(abs(a), abs(b))
+	return 1 - float64(abs(a-b))/float64(larger)
+}
+
+func abs(value int32) int32 {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
+
+// Lowercases letters and drops digits and punctuation, since store numbers and
+// reference codes often differ between the pending and posted descriptions
+func normalize(value string) string {
+	var b strings.Builder
+	space := true
+
+	for _, r := range strings.ToLower(value) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func trigrams(value string) map[string]bool {
+	grams := map[string]bool{}
+	padded := []rune(" " + value + " ")
+
+	for i := 0; i+3 <= len(padded); i++ {
+		grams[string(padded[i:i+3])] = true
+	}
+
+	return grams
+}
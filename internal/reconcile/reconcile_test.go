@@ -0,0 +1,166 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/proctorinc/banker/internal/db"
+)
+
+func day(n int) time.Time {
+	return time.Date(2024, time.January, n, 0, 0, 0, 0, time.UTC)
+}
+
+func pending(sourceId string, amount int32, date time.Time, description string) db.Transaction {
+	return db.Transaction{
+		Sourceid:    sourceId,
+		Amount:      amount,
+		Date:        date,
+		Description: description,
+		Status:      db.TransactionStatusPENDING,
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		posted  Posted
+		pending []db.Transaction
+		want    int
+	}{
+		{
+			name:   "same amount and description",
+			posted: Posted{Amount: -450, Date: day(12), Description: "BLUE BOTTLE COFFEE"},
+			pending: []db.Transaction{
+				pending("a", -1200, day(10), "GROCERY OUTLET"),
+				pending("b", -450, day(10), "BLUE BOTTLE COFFEE"),
+			},
+			want: 1,
+		},
+		{
+			name:   "linked pending id wins over a better match",
+			posted: Posted{Amount: -450, Date: day(12), Description: "BLUE BOTTLE COFFEE", PendingId: "a"},
+			pending: []db.Transaction{
+				pending("a", -2000, day(1), "SOMETHING ELSE"),
+				pending("b", -450, day(12), "BLUE BOTTLE COFFEE"),
+			},
+			want: 0,
+		},
+		{
+			name:   "tip added to a restaurant charge",
+			posted: Posted{Amount: -5750, Date: day(12), Description: "SQ *NOPA RESTAURANT"},
+			pending: []db.Transaction{
+				pending("a", -5000, day(11), "SQ *NOPA RESTAURANT PENDING"),
+			},
+			want: 0,
+		},
+		{
+			name:   "changed amount needs a closer description",
+			posted: Posted{Amount: -5750, Date: day(12), Description: "NOPA"},
+			pending: []db.Transaction{
+				pending("a", -5000, day(11), "NOPA RESTAURANT SAN FRANCISCO"),
+			},
+			want: -1,
+		},
+		{
+			name:   "amount changed too much",
+			posted: Posted{Amount: -10000, Date: day(12), Description: "HOTEL"},
+			pending: []db.Transaction{
+				pending("a", -5000, day(11), "HOTEL"),
+			},
+			want: -1,
+		},
+		{
+			name:   "opposite sign",
+			posted: Posted{Amount: 450, Date: day(12), Description: "BLUE BOTTLE COFFEE"},
+			pending: []db.Transaction{
+				pending("a", -450, day(11), "BLUE BOTTLE COFFEE"),
+			},
+			want: -1,
+		},
+		{
+			name:   "pending too long ago",
+			posted: Posted{Amount: -450, Date: day(20), Description: "BLUE BOTTLE COFFEE"},
+			pending: []db.Transaction{
+				pending("a", -450, day(5), "BLUE BOTTLE COFFEE"),
+			},
+			want: -1,
+		},
+		{
+			name:   "pending after the posted date",
+			posted: Posted{Amount: -450, Date: day(10), Description: "BLUE BOTTLE COFFEE"},
+			pending: []db.Transaction{
+				pending("a", -450, day(12), "BLUE BOTTLE COFFEE"),
+			},
+			want: -1,
+		},
+		{
+			name:   "closer date breaks a tie",
+			posted: Posted{Amount: -450, Date: day(12), Description: "BLUE BOTTLE COFFEE"},
+			pending: []db.Transaction{
+				pending("a", -450, day(8), "BLUE BOTTLE COFFEE"),
+				pending("b", -450, day(11), "BLUE BOTTLE COFFEE"),
+			},
+			want: 1,
+		},
+		{
+			name:   "different merchant",
+			posted: Posted{Amount: -450, Date: day(12), Description: "BLUE BOTTLE COFFEE"},
+			pending: []db.Transaction{
+				pending("a", -450, day(11), "PARKING METER"),
+			},
+			want: -1,
+		},
+		{
+			name:    "nothing pending",
+			posted:  Posted{Amount: -450, Date: day(12), Description: "BLUE BOTTLE COFFEE"},
+			pending: nil,
+			want:    -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Match(tt.posted, tt.pending)
+
+			if tt.want == -1 {
+				if ok {
+					t.Fatalf("Match() = %d, want no match", got)
+				}
+
+				return
+			}
+
+			if !ok || got != tt.want {
+				t.Fatalf("Match() = %d, %v, want %d", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		min  float64
+		max  float64
+	}{
+		{"same text", "Blue Bottle Coffee", "BLUE BOTTLE COFFEE", 1, 1},
+		{"store numbers ignored", "STARBUCKS #1234", "STARBUCKS 5678", 1, 1},
+		{"nothing in common", "STARBUCKS", "SHELL OIL", 0, 0.1},
+		{"partial match", "SQ *NOPA RESTAURANT", "NOPA", 0.3, 0.6},
+		{"empty", "", "STARBUCKS", 0, 0},
+		{"only digits", "1234", "1234", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+
+			if got < tt.min || got > tt.max {
+				t.Fatalf("Similarity(%q, %q) = %.2f, want between %.2f and %.2f", tt.a, tt.b, got, tt.min, tt.max)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package secrets encrypts credentials stored in the database, such as OFX
+// Direct Connect logins, with AES-256-GCM
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+const KeySize = 32
+
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a base64 encoded 32 byte key, e.g. from
+// `openssl rand -base64 32`
+func NewCipher(encodedKey string) (*Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid encryption key: %w", err)
+	}
+
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("Invalid encryption key: expected %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext
+func (c *Cipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *Cipher) Decrypt(encoded string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt secret: %w", err)
+	}
+
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("Failed to decrypt secret: ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+
+	if err != nil {
+		// Wrong key or tampered ciphertext
+		return nil, fmt.Errorf("Failed to decrypt secret")
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,31 @@
+// Package sourceid derives stable source ids for transactions from files
+// that carry no ids of their own, e.g. CSV and QIF exports
+package sourceid
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Hasher hashes transaction keys into source ids. Identical transactions,
+// e.g. two equal purchases on the same day, are told apart by their order
+type Hasher struct {
+	prefix      string
+	occurrences map[string]int
+}
+
+func NewHasher(prefix string) *Hasher {
+	return &Hasher{
+		prefix:      prefix,
+		occurrences: map[string]int{},
+	}
+}
+
+// Id returns the source id for the next transaction with the key
+func (h *Hasher) Id(key string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, h.occurrences[key])))
+	h.occurrences[key]++
+
+	return h.prefix + ":" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,34 @@
+package sourceid
+
+import "testing"
+
+func TestHasherId(t *testing.T) {
+	hasher := NewHasher("CSV")
+
+	first := hasher.Id("acct|2024-01-15|-4.50|COFFEE")
+	second := hasher.Id("acct|2024-01-15|-4.50|COFFEE")
+	other := hasher.Id("acct|2024-01-16|-4.50|COFFEE")
+
+	// Ids are stored, so the hash must never change
+	if want := "CSV:4f15df2991963d9048994a4549e52521"; first != want {
+		t.Errorf("Id() = %q, want %q", first, want)
+	}
+
+	if first == second {
+		t.Errorf("Id() returned %q for both occurrences of the key", first)
+	}
+
+	if first == other || second == other {
+		t.Errorf("Id() returned %q for a different key", other)
+	}
+
+	again := NewHasher("CSV")
+
+	if id := again.Id("acct|2024-01-15|-4.50|COFFEE"); id != first {
+		t.Errorf("Id() = %q on a new hasher, want %q", id, first)
+	}
+
+	if id := again.Id("acct|2024-01-15|-4.50|COFFEE"); id != second {
+		t.Errorf("Id() = %q for the second occurrence on a new hasher, want %q", id, second)
+	}
+}
@@ -0,0 +1,353 @@
+package plaid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/syncprovider"
+)
+
+const (
+	SandboxURL    = "https://sandbox.plaid.com"
+	ProductionURL = "https://production.plaid.com"
+
+	// Largest page /transactions/sync allows
+	pageSize = 500
+	// Restarts allowed when transactions change while paging
+	maxRestarts = 3
+)
+
+var errMutationDuringPagination = errors.New("TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION")
+
+type Config struct {
+	ClientId string
+	Secret   string
+	// BaseURL defaults to the sandbox. Point it at a local mock server
+	// to run without Plaid
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// Client syncs transactions using the Plaid /transactions/sync API
+type Client struct {
+	config Config
+}
+
+func New(config Config) *Client {
+	if config.BaseURL == "" {
+		config.BaseURL = SandboxURL
+	}
+
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+	return &Client{config: config}
+}
+
+func (c *Client) Source() db.UploadSource {
+	return db.UploadSourcePLAID
+}
+
+func (c *Client) Link(ctx context.Context, publicToken string) (*syncprovider.Connection, error) {
+	var response ExchangeResponse
+	err := c.post(ctx, "/item/public_token/exchange", ExchangeRequest{
+		ClientId:    c.config.ClientId,
+		Secret:      c.config.Secret,
+		PublicToken: publicToken,
+	}, &response)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &syncprovider.Connection{
+		ItemId:      response.ItemId,
+		AccessToken: response.AccessToken,
+	}, nil
+}
+
+// Sync pages through /transactions/sync until has_more is false. If
+// transactions change while paging, the sync restarts from cursor
+func (c *Client) Sync(ctx context.Context, accessToken string, cursor string) (*syncprovider.SyncResult, error) {
+	for restarts := 0; ; restarts++ {
+		result, err := c.sync(ctx, accessToken, cursor)
+
+		if errors.Is(err, errMutationDuringPagination) && restarts < maxRestarts {
+			continue
+		}
+
+		return result, err
+	}
+}
+
+func (c *Client) sync(ctx context.Context, accessToken string, cursor string) (*syncprovider.SyncResult, error) {
+	accounts := map[string]Account{}
+	var accountIds []string
+	var transactionIds []string
+	transactions := map[string]Transaction{}
+	removed := map[string]bool{}
+
+	for {
+		var page SyncResponse
+		err := c.post(ctx, "/transactions/sync", SyncRequest{
+			ClientId:    c.config.ClientId,
+			Secret:      c.config.Secret,
+			AccessToken: accessToken,
+			Cursor:      cursor,
+			Count:       pageSize,
+		}, &page)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, account := range page.Accounts {
+			if _, ok := accounts[account.AccountId]; !ok {
+				accountIds = append(accountIds, account.AccountId)
+			}
+
+			accounts[account.AccountId] = account
+		}
+
+		// Modified transactions replace earlier versions from previous pages
+		for _, tx := range append(page.Added, page.Modified...) {
+			if _, ok := transactions[tx.TransactionId]; !ok {
+				transactionIds = append(transactionIds, tx.TransactionId)
+			}
+
+			transactions[tx.TransactionId] = tx
+			delete(removed, tx.TransactionId)
+		}
+
+		for _, tx := range page.Removed {
+			removed[tx.TransactionId] = true
+		}
+
+		cursor = page.NextCursor
+
+		if !page.HasMore {
+			break
+		}
+	}
+
+	result := &syncprovider.SyncResult{Cursor: cursor}
+	statements := map[string]int{}
+
+	for _, id := range accountIds {
+		account, ok := parseAccount(accounts[id])
+
+		if !ok {
+			continue
+		}
+
+		statements[id] = len(result.Statements)
+		result.Statements = append(result.Statements, chase.ChaseOFXResult{Account: account})
+	}
+
+	for _, id := range transactionIds {
+		tx := transactions[id]
+		index, ok := statements[tx.AccountId]
+
+		if !ok || removed[id] {
+			continue
+		}
+
+		statement := &result.Statements[index]
+		statement.Transactions = append(statement.Transactions, parseTransaction(tx))
+	}
+
+	for id := range removed {
+		result.Removed = append(result.Removed, id)
+	}
+
+	return result, nil
+}
+
+func (c *Client) post(ctx context.Context, path string, body interface{}, response interface{}) error {
+	payload, err := json.Marshal(body)
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.BaseURL+path, bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	res, err := c.config.HTTPClient.Do(request)
+
+	if err != nil {
+		return fmt.Errorf("Plaid request failed: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var plaidErr Error
+
+		if err := json.NewDecoder(res.Body).Decode(&plaidErr); err != nil || plaidErr.ErrorCode == "" {
+			return fmt.Errorf("Plaid request failed with status %d", res.StatusCode)
+		}
+
+		if plaidErr.ErrorCode == errMutationDuringPagination.Error() {
+			return errMutationDuringPagination
+		}
+
+		return plaidErr
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return fmt.Errorf("Invalid Plaid response: %w", err)
+	}
+
+	return nil
+}
+
+// Plaid amounts are positive when money leaves the account, and credit
+// balances are positive when money is owed, so both are negated
+func parseAccount(account Account) (chase.ChaseOFXAccount, bool) {
+	accountType, ok := accountType(account.Type, account.Subtype)
+
+	if !ok {
+		return chase.ChaseOFXAccount{}, false
+	}
+
+	sign := float32(1)
+
+	if account.Type == "credit" || account.Type == "loan" {
+		sign = -1
+	}
+
+	var current float32
+	var available *float32
+
+	if account.Balances.Current != nil {
+		current = sign * float32(*account.Balances.Current)
+	}
+
+	if account.Balances.Available != nil {
+		value := sign * float32(*account.Balances.Available)
+		available = &value
+	}
+
+	name := account.OfficialName
+
+	if name == "" {
+		name = account.Name
+	}
+
+	if account.Mask != "" {
+		name = fmt.Sprintf("%s %s", name, account.Mask)
+	}
+
+	currency := account.Balances.IsoCurrencyCode
+
+	if currency == "" {
+		currency = "USD"
+	}
+
+	return chase.ChaseOFXAccount{
+		AccountId:        account.AccountId,
+		IsoCurrencyCode:  currency,
+		Type:             accountType,
+		CurrentBalance:   current,
+		AvailableBalance: available,
+		BalanceDate:      time.Now(),
+		Name:             name,
+	}, true
+}
+
+func parseTransaction(tx Transaction) chase.ChaseOFXTransaction {
+	date, _ := time.Parse("2006-01-02", tx.Date)
+	authorizedDate, _ := time.Parse("2006-01-02", tx.AuthorizedDate)
+
+	return chase.ChaseOFXTransaction{
+		Id:             tx.TransactionId,
+		Type:           string(transactionType(tx)),
+		DatePosted:     date,
+		Amount:         -float32(tx.Amount),
+		PayeeId:        tx.MerchantEntityId,
+		Payee:          tx.MerchantName,
+		CheckNumber:    tx.CheckNumber,
+		Description:    tx.Name,
+		Pending:        tx.Pending,
+		AuthorizedDate: authorizedDate,
+		PendingId:      tx.PendingTransactionId,
+	}
+}
+
+// Investment accounts are not supported yet
+func accountType(plaidType string, subtype string) (db.AccountType, bool) {
+	switch plaidType {
+	case "credit":
+		return db.AccountTypeCREDIT, true
+	case "loan":
+		return db.AccountTypeCREDITLINE, true
+	case "depository":
+		switch subtype {
+		case "savings":
+			return db.AccountTypeSAVINGS, true
+		case "money market":
+			return db.AccountTypeMONEYMRKT, true
+		case "cd":
+			return db.AccountTypeCD, true
+		}
+
+		return db.AccountTypeCHECKING, true
+	}
+
+	return "", false
+}
+
+func transactionType(tx Transaction) db.TransactionType {
+	if tx.CheckNumber != "" {
+		return db.TransactionTypeCHECK
+	}
+
+	switch tx.TransactionCode {
+	case "atm":
+		return db.TransactionTypeATM
+	case "bank charge":
+		return db.TransactionTypeSRVCHG
+	case "bill payment":
+		return db.TransactionTypePAYMENT
+	case "cash":
+		return db.TransactionTypeCASH
+	case "cheque":
+		return db.TransactionTypeCHECK
+	case "direct debit":
+		return db.TransactionTypeDIRECTDEBIT
+	case "interest":
+		return db.TransactionTypeINT
+	case "purchase":
+		return db.TransactionTypePOS
+	case "standing order":
+		return db.TransactionTypeREPEATPMT
+	case "transfer":
+		return db.TransactionTypeXFER
+	}
+
+	if tx.PaymentChannel == "in store" {
+		return db.TransactionTypePOS
+	}
+
+	if tx.Amount > 0 {
+		return db.TransactionTypeDEBIT
+	}
+
+	return db.TransactionTypeCREDIT
+}
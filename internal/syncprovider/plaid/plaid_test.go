@@ -0,0 +1,364 @@
+package plaid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/proctorinc/banker/internal/db"
+	"github.com/proctorinc/banker/internal/syncprovider"
+	"github.com/proctorinc/banker/internal/syncprovider/plaid"
+	"github.com/proctorinc/banker/internal/syncprovider/plaid/plaidmock"
+)
+
+// Counts requests to the mock and optionally fails the first request for a
+// later page, like Plaid does when transactions change while paging
+type testServer struct {
+	*plaidmock.Server
+	mu              sync.Mutex
+	requests        int
+	failLaterPage   bool
+	failedLaterPage bool
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var request plaid.SyncRequest
+	json.Unmarshal(body, &request)
+
+	s.mu.Lock()
+	s.requests++
+	fail := s.failLaterPage && !s.failedLaterPage && request.Cursor != ""
+
+	if fail {
+		s.failedLaterPage = true
+	}
+
+	s.mu.Unlock()
+
+	if fail {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(plaid.Error{
+			ErrorType:    "TRANSACTIONS_ERROR",
+			ErrorCode:    "TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION",
+			ErrorMessage: "underlying transaction data changed since last page was fetched",
+		})
+		return
+	}
+
+	s.Server.ServeHTTP(w, r)
+}
+
+func newTestClient(t *testing.T, server *testServer) *plaid.Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	return plaid.New(plaid.Config{
+		ClientId: plaidmock.ClientId,
+		Secret:   plaidmock.Secret,
+		BaseURL:  httpServer.URL,
+	})
+}
+
+// Creates an item with a checking account and returns its access token
+func newItem(server *plaidmock.Server) string {
+	current := 100.0
+
+	return server.CreateItem("public-test-token", []plaid.Account{{
+		AccountId: "checking",
+		Name:      "Checking",
+		Type:      "depository",
+		Subtype:   "checking",
+		Balances:  plaid.Balances{Current: &current},
+	}})
+}
+
+func addTransactions(server *plaidmock.Server, accessToken string, count int) []plaid.Transaction {
+	transactions := make([]plaid.Transaction, count)
+
+	for i := range transactions {
+		transactions[i] = server.AddTransaction(accessToken, plaid.Transaction{
+			AccountId: "checking",
+			Amount:    float64(i + 1),
+			Date:      "2024-01-15",
+			Name:      fmt.Sprintf("PURCHASE %d", i+1),
+		})
+	}
+
+	return transactions
+}
+
+func transactionIds(result *syncprovider.SyncResult) []string {
+	ids := []string{}
+
+	for _, statement := range result.Statements {
+		for _, tx := range statement.Transactions {
+			ids = append(ids, tx.Id)
+		}
+	}
+
+	return ids
+}
+
+func TestLink(t *testing.T) {
+	client := newTestClient(t, &testServer{Server: plaidmock.New()})
+	connection, err := client.Link(context.Background(), plaidmock.PublicToken)
+
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	if connection.AccessToken == "" || connection.ItemId == "" {
+		t.Fatalf("Link() = %+v, want an access token and item id", connection)
+	}
+
+	if _, err := client.Link(context.Background(), "public-unknown"); err == nil {
+		t.Fatalf("Link() with an unknown public token succeeded")
+	}
+}
+
+func TestSyncAccounts(t *testing.T) {
+	client := newTestClient(t, &testServer{Server: plaidmock.New()})
+	connection, err := client.Link(context.Background(), plaidmock.PublicToken)
+
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+
+	result, err := client.Sync(context.Background(), connection.AccessToken, "")
+
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		accountType  db.AccountType
+		current      float32
+		available    float32
+		transactions int
+	}{
+		{
+			name:         "Plaid Checking 0000",
+			accountType:  db.AccountTypeCHECKING,
+			current:      1250.32,
+			available:    1200.32,
+			transactions: 3,
+		},
+		{
+			// Plaid reports owed credit balances as positive amounts
+			name:         "Plaid Credit Card 3333",
+			accountType:  db.AccountTypeCREDIT,
+			current:      -410.5,
+			available:    -4589.5,
+			transactions: 3,
+		},
+	}
+
+	if len(result.Statements) != len(tests) {
+		t.Fatalf("Sync() returned %d statements, want %d", len(result.Statements), len(tests))
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := result.Statements[i].Account
+
+			if account.Name != tt.name || account.Type != tt.accountType {
+				t.Errorf("account = %s %s, want %s %s", account.Name, account.Type, tt.name, tt.accountType)
+			}
+
+			if account.CurrentBalance != tt.current || account.AvailableBalance == nil || *account.AvailableBalance != tt.available {
+				t.Errorf("balances = %.2f, %v, want %.2f, %.2f", account.CurrentBalance, account.AvailableBalance, tt.current, tt.available)
+			}
+
+			if len(result.Statements[i].Transactions) != tt.transactions {
+				t.Errorf("%d transactions, want %d", len(result.Statements[i].Transactions), tt.transactions)
+			}
+		})
+	}
+
+	// Money leaving the account is positive in Plaid
+	payroll := result.Statements[0].Transactions[0]
+
+	if payroll.Description != "ACME CORP PAYROLL" || payroll.Amount != 2500 {
+		t.Errorf("payroll = %s %.2f, want ACME CORP PAYROLL 2500.00", payroll.Description, payroll.Amount)
+	}
+}
+
+func TestSyncPaging(t *testing.T) {
+	server := &testServer{Server: plaidmock.New()}
+	accessToken := newItem(server.Server)
+	added := addTransactions(server.Server, accessToken, 1200)
+	client := newTestClient(t, server)
+
+	result, err := client.Sync(context.Background(), accessToken, "")
+
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// Pages hold up to 500 transactions
+	if server.requests != 3 {
+		t.Errorf("made %d requests, want 3", server.requests)
+	}
+
+	ids := transactionIds(result)
+
+	if len(ids) != len(added) {
+		t.Fatalf("Sync() returned %d transactions, want %d", len(ids), len(added))
+	}
+
+	for i, tx := range added {
+		if ids[i] != tx.TransactionId {
+			t.Fatalf("transaction %d = %s, want %s", i, ids[i], tx.TransactionId)
+		}
+	}
+
+	next, err := client.Sync(context.Background(), accessToken, result.Cursor)
+
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if ids := transactionIds(next); len(ids) != 0 {
+		t.Fatalf("Sync() from the cursor returned %d transactions, want none", len(ids))
+	}
+}
+
+func TestSyncChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		// Changes made after the first sync, given the transactions it added
+		change      func(server *plaidmock.Server, accessToken string, added []plaid.Transaction)
+		syncBetween bool
+		want        []string
+		wantRemoved []int
+	}{
+		{
+			name: "modified transaction replaces the added one",
+			change: func(server *plaidmock.Server, accessToken string, added []plaid.Transaction) {
+				tx := added[0]
+				tx.Name = "PURCHASE 1 CORRECTED"
+				server.ModifyTransaction(accessToken, tx)
+			},
+			want: []string{"PURCHASE 1 CORRECTED", "PURCHASE 2"},
+		},
+		{
+			name: "modified transaction from an earlier sync",
+			change: func(server *plaidmock.Server, accessToken string, added []plaid.Transaction) {
+				tx := added[1]
+				tx.Name = "PURCHASE 2 CORRECTED"
+				server.ModifyTransaction(accessToken, tx)
+			},
+			syncBetween: true,
+			want:        []string{"PURCHASE 2 CORRECTED"},
+		},
+		{
+			name: "removed transaction is left out",
+			change: func(server *plaidmock.Server, accessToken string, added []plaid.Transaction) {
+				server.RemoveTransaction(accessToken, added[0].TransactionId)
+			},
+			want:        []string{"PURCHASE 2"},
+			wantRemoved: []int{0},
+		},
+		{
+			name: "removed transaction from an earlier sync",
+			change: func(server *plaidmock.Server, accessToken string, added []plaid.Transaction) {
+				server.RemoveTransaction(accessToken, added[1].TransactionId)
+			},
+			syncBetween: true,
+			want:        []string{},
+			wantRemoved: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &testServer{Server: plaidmock.New()}
+			accessToken := newItem(server.Server)
+			added := addTransactions(server.Server, accessToken, 2)
+			client := newTestClient(t, server)
+			cursor := ""
+
+			if tt.syncBetween {
+				result, err := client.Sync(context.Background(), accessToken, cursor)
+
+				if err != nil {
+					t.Fatalf("Sync() error = %v", err)
+				}
+
+				cursor = result.Cursor
+			}
+
+			tt.change(server.Server, accessToken, added)
+			result, err := client.Sync(context.Background(), accessToken, cursor)
+
+			if err != nil {
+				t.Fatalf("Sync() error = %v", err)
+			}
+
+			descriptions := []string{}
+
+			for _, tx := range result.Statements[0].Transactions {
+				descriptions = append(descriptions, tx.Description)
+			}
+
+			if fmt.Sprint(descriptions) != fmt.Sprint(tt.want) {
+				t.Errorf("transactions = %v, want %v", descriptions, tt.want)
+			}
+
+			wantRemoved := []string{}
+
+			for _, i := range tt.wantRemoved {
+				wantRemoved = append(wantRemoved, added[i].TransactionId)
+			}
+
+			removed := append([]string{}, result.Removed...)
+			sort.Strings(removed)
+
+			if fmt.Sprint(removed) != fmt.Sprint(wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, wantRemoved)
+			}
+		})
+	}
+}
+
+func TestSyncRestartsAfterMutationDuringPagination(t *testing.T) {
+	server := &testServer{Server: plaidmock.New(), failLaterPage: true}
+	accessToken := newItem(server.Server)
+	added := addTransactions(server.Server, accessToken, 600)
+	client := newTestClient(t, server)
+
+	result, err := client.Sync(context.Background(), accessToken, "")
+
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	if !server.failedLaterPage {
+		t.Fatalf("the second page never failed")
+	}
+
+	// First page, failed second page, then both pages again
+	if server.requests != 4 {
+		t.Errorf("made %d requests, want 4", server.requests)
+	}
+
+	if ids := transactionIds(result); len(ids) != len(added) {
+		t.Fatalf("Sync() returned %d transactions, want %d", len(ids), len(added))
+	}
+
+	if result.Cursor != "600" {
+		t.Errorf("cursor = %s, want 600", result.Cursor)
+	}
+}
@@ -0,0 +1,298 @@
+// Package plaidmock is an in-memory fake of the Plaid endpoints used by the
+// plaid sync provider, so syncs can run locally and in tests without Plaid
+package plaidmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/proctorinc/banker/internal/syncprovider/plaid"
+)
+
+const (
+	ClientId = "mock-client-id"
+	Secret   = "mock-secret"
+	// Public token for the item created by New
+	PublicToken = "public-mock-token"
+
+	defaultCount = 100
+	maxCount     = 500
+)
+
+type changeKind int
+
+const (
+	changeAdded changeKind = iota
+	changeModified
+	changeRemoved
+)
+
+type change struct {
+	kind        changeKind
+	transaction plaid.Transaction
+}
+
+// Changes are kept as a log, and a cursor is an offset into the log
+type item struct {
+	id       string
+	accounts []plaid.Account
+	changes  []change
+}
+
+type Server struct {
+	mu           sync.Mutex
+	items        map[string]*item // by access token
+	publicTokens map[string]string
+	nextId       int
+}
+
+// New creates a server with one item that has a checking account and a
+// credit card with a few transactions, linked with PublicToken
+func New() *Server {
+	s := &Server{
+		items:        map[string]*item{},
+		publicTokens: map[string]string{},
+	}
+
+	accessToken := s.CreateItem(PublicToken, []plaid.Account{
+		newAccount("mock-checking", "Plaid Checking", "0000", "depository", "checking", 1250.32, 1200.32),
+		newAccount("mock-credit", "Plaid Credit Card", "3333", "credit", "credit card", 410.50, 4589.50),
+	})
+
+	today := time.Now()
+
+	for i, tx := range []plaid.Transaction{
+		{AccountId: "mock-checking", Amount: -2500, Name: "ACME CORP PAYROLL", TransactionCode: "transfer"},
+		{AccountId: "mock-checking", Amount: 89.40, Name: "CITY POWER AND LIGHT", MerchantName: "City Power", TransactionCode: "bill payment"},
+		{AccountId: "mock-checking", Amount: 40, Name: "ATM WITHDRAWAL 1234", TransactionCode: "atm"},
+		{AccountId: "mock-credit", Amount: 12.75, Name: "STARBUCKS STORE 1458", MerchantName: "Starbucks", PaymentChannel: "in store"},
+		{AccountId: "mock-credit", Amount: 64.20, Name: "AMAZON MKTPLACE PMTS", MerchantName: "Amazon", PaymentChannel: "online"},
+		{AccountId: "mock-credit", Amount: 23.10, Name: "UBER *TRIP HELP.UBER.COM", MerchantName: "Uber", PaymentChannel: "online", Pending: true},
+	} {
+		tx.Date = today.AddDate(0, 0, -i-1).Format("2006-01-02")
+		s.AddTransaction(accessToken, tx)
+	}
+
+	return s
+}
+
+// CreateItem adds an item linked with publicToken, returning its access token
+func (s *Server) CreateItem(publicToken string, accounts []plaid.Account) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextId++
+	accessToken := fmt.Sprintf("access-mock-%d", s.nextId)
+	s.items[accessToken] = &item{
+		id:       fmt.Sprintf("item-mock-%d", s.nextId),
+		accounts: accounts,
+	}
+	s.publicTokens[publicToken] = accessToken
+
+	return accessToken
+}
+
+// AddTransaction adds a transaction to an item, generating its id when empty
+func (s *Server) AddTransaction(accessToken string, tx plaid.Transaction) plaid.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tx.TransactionId == "" {
+		s.nextId++
+		tx.TransactionId = fmt.Sprintf("tx-mock-%d", s.nextId)
+	}
+
+	if tx.IsoCurrencyCode == "" {
+		tx.IsoCurrencyCode = "USD"
+	}
+
+	s.record(accessToken, changeAdded, tx)
+	return tx
+}
+
+func (s *Server) ModifyTransaction(accessToken string, tx plaid.Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(accessToken, changeModified, tx)
+}
+
+// PostTransaction replaces a pending transaction with its posted transaction
+// like Plaid does: the pending transaction is removed and the posted
+// transaction is added with a new id that links back to it
+func (s *Server) PostTransaction(accessToken string, pendingId string, posted plaid.Transaction) plaid.Transaction {
+	posted.Pending = false
+	posted.PendingTransactionId = pendingId
+	posted = s.AddTransaction(accessToken, posted)
+	s.RemoveTransaction(accessToken, pendingId)
+
+	return posted
+}
+
+func (s *Server) RemoveTransaction(accessToken string, transactionId string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(accessToken, changeRemoved, plaid.Transaction{TransactionId: transactionId})
+}
+
+func (s *Server) record(accessToken string, kind changeKind, tx plaid.Transaction) {
+	if item, ok := s.items[accessToken]; ok {
+		item.changes = append(item.changes, change{kind: kind, transaction: tx})
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/item/public_token/exchange":
+		s.exchange(w, r)
+	case "/transactions/sync":
+		s.sync(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "INVALID_REQUEST", "NOT_FOUND", "unknown endpoint "+r.URL.Path)
+	}
+}
+
+func (s *Server) exchange(w http.ResponseWriter, r *http.Request) {
+	var request plaid.ExchangeRequest
+
+	if !decode(w, r, &request) || !authorized(w, request.ClientId, request.Secret) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accessToken, ok := s.publicTokens[request.PublicToken]
+
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "INVALID_PUBLIC_TOKEN", "provided public token is in an invalid format")
+		return
+	}
+
+	writeJSON(w, plaid.ExchangeResponse{
+		AccessToken: accessToken,
+		ItemId:      s.items[accessToken].id,
+		RequestId:   "mock",
+	})
+}
+
+func (s *Server) sync(w http.ResponseWriter, r *http.Request) {
+	var request plaid.SyncRequest
+
+	if !decode(w, r, &request) || !authorized(w, request.ClientId, request.Secret) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[request.AccessToken]
+
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "INVALID_ACCESS_TOKEN", "provided access token is in an invalid format")
+		return
+	}
+
+	start := 0
+
+	if request.Cursor != "" {
+		offset, err := strconv.Atoi(request.Cursor)
+
+		if err != nil || offset < 0 || offset > len(item.changes) {
+			writeError(w, http.StatusBadRequest, "INVALID_INPUT", "INVALID_FIELD", "cursor is invalid")
+			return
+		}
+
+		start = offset
+	}
+
+	count := request.Count
+
+	if count <= 0 {
+		count = defaultCount
+	}
+
+	count = min(count, maxCount)
+	end := min(start+count, len(item.changes))
+	response := plaid.SyncResponse{
+		Accounts:   item.accounts,
+		Added:      []plaid.Transaction{},
+		Modified:   []plaid.Transaction{},
+		Removed:    []plaid.RemovedTransaction{},
+		NextCursor: strconv.Itoa(end),
+		HasMore:    end < len(item.changes),
+		RequestId:  "mock",
+	}
+
+	for _, change := range item.changes[start:end] {
+		switch change.kind {
+		case changeAdded:
+			response.Added = append(response.Added, change.transaction)
+		case changeModified:
+			response.Modified = append(response.Modified, change.transaction)
+		case changeRemoved:
+			response.Removed = append(response.Removed, plaid.RemovedTransaction{TransactionId: change.transaction.TransactionId})
+		}
+	}
+
+	writeJSON(w, response)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, request interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", "INVALID_BODY", err.Error())
+		return false
+	}
+
+	return true
+}
+
+func authorized(w http.ResponseWriter, clientId string, secret string) bool {
+	if clientId != ClientId || secret != Secret {
+		writeError(w, http.StatusBadRequest, "INVALID_INPUT", "INVALID_API_KEYS", "invalid client_id or secret provided")
+		return false
+	}
+
+	return true
+}
+
+func newAccount(id string, name string, mask string, accountType string, subtype string, current float64, available float64) plaid.Account {
+	return plaid.Account{
+		AccountId: id,
+		Name:      name,
+		Mask:      mask,
+		Type:      accountType,
+		Subtype:   subtype,
+		Balances: plaid.Balances{
+			Current:         &current,
+			Available:       &available,
+			IsoCurrencyCode: "USD",
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(plaid.Error{
+		ErrorType:    errorType,
+		ErrorCode:    code,
+		ErrorMessage: message,
+		RequestId:    "mock",
+	})
+}
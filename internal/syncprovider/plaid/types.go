@@ -0,0 +1,88 @@
+package plaid
+
+import "fmt"
+
+// Request and response bodies for the subset of the Plaid API we use
+
+type ExchangeRequest struct {
+	ClientId    string `json:"client_id"`
+	Secret      string `json:"secret"`
+	PublicToken string `json:"public_token"`
+}
+
+type ExchangeResponse struct {
+	AccessToken string `json:"access_token"`
+	ItemId      string `json:"item_id"`
+	RequestId   string `json:"request_id"`
+}
+
+type SyncRequest struct {
+	ClientId    string `json:"client_id"`
+	Secret      string `json:"secret"`
+	AccessToken string `json:"access_token"`
+	Cursor      string `json:"cursor,omitempty"`
+	Count       int    `json:"count,omitempty"`
+}
+
+type SyncResponse struct {
+	Accounts   []Account            `json:"accounts"`
+	Added      []Transaction        `json:"added"`
+	Modified   []Transaction        `json:"modified"`
+	Removed    []RemovedTransaction `json:"removed"`
+	NextCursor string               `json:"next_cursor"`
+	HasMore    bool                 `json:"has_more"`
+	RequestId  string               `json:"request_id"`
+}
+
+type Account struct {
+	AccountId    string   `json:"account_id"`
+	Name         string   `json:"name"`
+	OfficialName string   `json:"official_name,omitempty"`
+	Mask         string   `json:"mask,omitempty"`
+	Type         string   `json:"type"`
+	Subtype      string   `json:"subtype,omitempty"`
+	Balances     Balances `json:"balances"`
+}
+
+type Balances struct {
+	Current         *float64 `json:"current"`
+	Available       *float64 `json:"available"`
+	IsoCurrencyCode string   `json:"iso_currency_code,omitempty"`
+}
+
+type Transaction struct {
+	TransactionId    string  `json:"transaction_id"`
+	AccountId        string  `json:"account_id"`
+	Amount           float64 `json:"amount"`
+	IsoCurrencyCode  string  `json:"iso_currency_code,omitempty"`
+	Date             string  `json:"date"`
+	Name             string  `json:"name"`
+	MerchantName     string  `json:"merchant_name,omitempty"`
+	MerchantEntityId string  `json:"merchant_entity_id,omitempty"`
+	CheckNumber      string  `json:"check_number,omitempty"`
+	PaymentChannel   string  `json:"payment_channel,omitempty"`
+	TransactionCode  string  `json:"transaction_code,omitempty"`
+	Pending          bool    `json:"pending"`
+	// Set on a posted transaction to the id of the pending transaction it
+	// replaces. Plaid removes the pending transaction in the same sync
+	PendingTransactionId string `json:"pending_transaction_id,omitempty"`
+	AuthorizedDate       string `json:"authorized_date,omitempty"`
+}
+
+type RemovedTransaction struct {
+	TransactionId string `json:"transaction_id"`
+	AccountId     string `json:"account_id,omitempty"`
+}
+
+// Error is the body of a failed Plaid request
+type Error struct {
+	ErrorType      string `json:"error_type"`
+	ErrorCode      string `json:"error_code"`
+	ErrorMessage   string `json:"error_message"`
+	DisplayMessage string `json:"display_message,omitempty"`
+	RequestId      string `json:"request_id,omitempty"`
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("Plaid %s: %s", e.ErrorCode, e.ErrorMessage)
+}
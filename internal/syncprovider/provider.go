@@ -0,0 +1,53 @@
+package syncprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/proctorinc/banker/internal/chase"
+	"github.com/proctorinc/banker/internal/db"
+)
+
+// Provider refreshes accounts from a bank data aggregator instead of file
+// uploads. Changes are fetched incrementally using an opaque cursor
+type Provider interface {
+	// Source identifies the provider on connections and sync items
+	Source() db.UploadSource
+	// Link exchanges a token from the provider's link flow for a connection
+	Link(ctx context.Context, publicToken string) (*Connection, error)
+	// Sync fetches every change since cursor. An empty cursor fetches the
+	// full history
+	Sync(ctx context.Context, accessToken string, cursor string) (*SyncResult, error)
+}
+
+type Connection struct {
+	ItemId      string
+	AccessToken string
+}
+
+type SyncResult struct {
+	// Accounts with their added and modified transactions, in the
+	// same structures as uploaded statements
+	Statements []chase.ChaseOFXResult
+	// Source ids of transactions removed at the provider
+	Removed []string
+	// Cursor to pass to the next sync
+	Cursor string
+}
+
+// Providers holds the configured providers by their upload source
+type Providers map[db.UploadSource]Provider
+
+func (p Providers) Get(source db.UploadSource) (Provider, error) {
+	provider, ok := p[source]
+
+	if !ok {
+		return nil, fmt.Errorf("Sync provider %s is not configured", source)
+	}
+
+	return provider, nil
+}
+
+func (p Providers) Register(provider Provider) {
+	p[provider.Source()] = provider
+}
@@ -0,0 +1,15 @@
+// Package testutil holds helpers shared by the packages' tests
+package testutil
+
+import "time"
+
+// Date parses a date like 2024-01-15 in UTC. Panics on bad test data
+func Date(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
@@ -1,12 +1,12 @@
-if [ "$#" -ne 1 ]; then
-    echo "Invalid Parameters, usage: ./update_user_to_admin <user_id>"
+if [ "$#" -ne 2 ]; then
+    echo "Invalid Parameters, usage: ./csv_chase_upload_test <auth_token> <account_id>"
     exit 1
 fi
 
 if (curl localhost:8080/query --cookie "auth-token=$1" \
-  -F operations='{ "query": "mutation ($file: Upload!) { chaseCSVTransactionsUpload(file: $file) }", "variables": { "file": null } }' \
+  -F operations='{ "query": "mutation ($accountId: ID!, $file: Upload!) { chaseCSVUpload(accountId: $accountId, file: $file) { success accounts { updated failed } transactions { updated failed } } }", "variables": { "accountId": "'"$2"'", "file": null } }' \
   -F map='{ "0": ["variables.file"] }' \
-  -F 0=@$PWD/scripts/test-data/transactions.notcsv); then
+  -F 0=@$PWD/scripts/test-data/transactions.csv); then
   echo "done"
 else
     exit 1
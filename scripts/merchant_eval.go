@@ -0,0 +1,230 @@
+package main
+
+// Measures merchant name extraction against a labeled corpus of transaction
+// descriptions. Run from the repository root:
+//
+//	go run ./scripts -extractor default -misses
+//
+// An extraction is correct when it matches the label, ignoring case and
+// punctuation. Recall is correct extractions over labeled descriptions.
+// Precision is correct extractions over extractions made, and is reported
+// for each stage of a chain. The cleaned description fallback names every
+// description it sees, so over a whole chain precision would equal recall
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/proctorinc/banker/internal/importer"
+)
+
+var extractors = map[string]importer.MerchantExtractor{
+	"default": importer.DefaultMerchantExtractor,
+	// The cleaned description without dictionary or NLP
+	"cleanup": importer.ExtractorChain{
+		Cleaner:    importer.DefaultNoiseCleaner,
+		Extractors: []importer.MerchantExtractor{importer.CleanedDescription{}},
+	},
+	"dictionary": importer.ExtractorChain{
+		Cleaner:    importer.DefaultNoiseCleaner,
+		Extractors: []importer.MerchantExtractor{importer.DefaultMerchantDictionary},
+	},
+	// The default chain without the NLP fallback
+	"no-nlp": importer.ExtractorChain{
+		Cleaner: importer.DefaultNoiseCleaner,
+		Extractors: []importer.MerchantExtractor{
+			importer.DefaultMerchantDictionary,
+			importer.CleanedDescription{},
+		},
+	},
+	"nlp": importer.ExtractorChain{
+		Cleaner:    importer.DefaultNoiseCleaner,
+		Extractors: []importer.MerchantExtractor{importer.ProseExtractor{}},
+	},
+	// Merchant naming before extractor chains, for comparison
+	"tokens": importer.ExtractorChain{
+		Extractors: []importer.MerchantExtractor{importer.TokenExtractor{}},
+	},
+}
+
+type example struct {
+	description string
+	merchant    string
+}
+
+// Extractions made by one stage of a chain
+type stage struct {
+	name      string
+	extractor importer.MerchantExtractor
+	extracted int
+	correct   int
+}
+
+func main() {
+	corpus := flag.String("corpus", "internal/importer/testdata/merchant_names.csv", "CSV file of description,merchant rows")
+	name := flag.String("extractor", "default", "extractor to measure: "+strings.Join(extractorNames(), ", "))
+	misses := flag.Bool("misses", false, "print every description that was extracted incorrectly")
+	flag.Parse()
+
+	extractor, ok := extractors[*name]
+
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown extractor: %s\n", *name)
+		os.Exit(2)
+	}
+
+	examples, err := readCorpus(*corpus)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	cleaner, stages := chainStages(extractor)
+	correct := 0
+	start := time.Now()
+
+	for _, example := range examples {
+		description := example.description
+
+		if cleaner != nil {
+			description = cleaner.Clean(description)
+		}
+
+		merchant, stageName := "", "none"
+
+		for _, stage := range stages {
+			name, ok := stage.extractor.ExtractMerchant(description)
+
+			if !ok {
+				continue
+			}
+
+			merchant, stageName = name, stage.name
+			stage.extracted++
+
+			if normalize(name) == normalize(example.merchant) {
+				stage.correct++
+				correct++
+			}
+
+			break
+		}
+
+		if *misses && normalize(merchant) != normalize(example.merchant) {
+			fmt.Printf("miss  %-60q got %q from %s, want %q\n", example.description, merchant, stageName, example.merchant)
+		}
+	}
+
+	elapsed := time.Since(start)
+
+	fmt.Printf("extractor  %s\n", *name)
+	fmt.Printf("examples   %d\n", len(examples))
+	fmt.Printf("correct    %d\n", correct)
+	fmt.Printf("recall     %.3f\n", ratio(correct, len(examples)))
+	fmt.Printf("time       %s (%s per description)\n", elapsed.Round(time.Millisecond), (elapsed / time.Duration(max(len(examples), 1))).Round(time.Microsecond))
+	fmt.Println()
+	fmt.Printf("%-12s %9s %9s %9s\n", "stage", "extracted", "correct", "precision")
+
+	for _, stage := range stages {
+		fmt.Printf("%-12s %9d %9d %9.3f\n", stage.name, stage.extracted, stage.correct, ratio(stage.correct, stage.extracted))
+	}
+}
+
+// Splits a chain into its cleaner and stages, so each stage's extractions
+// are counted. Other extractors are a single stage
+func chainStages(extractor importer.MerchantExtractor) (*importer.NoiseCleaner, []*stage) {
+	chain, ok := extractor.(importer.ExtractorChain)
+
+	if !ok {
+		return nil, []*stage{{name: stageName(extractor), extractor: extractor}}
+	}
+
+	stages := []*stage{}
+
+	for _, extractor := range chain.Extractors {
+		stages = append(stages, &stage{name: stageName(extractor), extractor: extractor})
+	}
+
+	return chain.Cleaner, stages
+}
+
+func stageName(extractor importer.MerchantExtractor) string {
+	switch extractor.(type) {
+	case *importer.MerchantDictionary:
+		return "dictionary"
+	case importer.ProseExtractor:
+		return "nlp"
+	case importer.TokenExtractor:
+		return "tokens"
+	case importer.CleanedDescription:
+		return "cleanup"
+	}
+
+	return fmt.Sprintf("%T", extractor)
+}
+
+func readCorpus(path string) ([]example, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read corpus: %w", err)
+	}
+
+	examples := []example{}
+
+	// Skip the header row
+	for _, row := range rows[min(len(rows), 1):] {
+		if len(row) != 2 {
+			return nil, fmt.Errorf("Corpus rows need a description and merchant: %v", row)
+		}
+
+		examples = append(examples, example{description: row[0], merchant: row[1]})
+	}
+
+	return examples, nil
+}
+
+// Compares names by their letters and digits only
+func normalize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+
+		return -1
+	}, name)
+}
+
+func ratio(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(count) / float64(total)
+}
+
+func extractorNames() []string {
+	names := []string{}
+
+	for name := range extractors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}